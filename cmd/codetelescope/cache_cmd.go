@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"code-telescope/internal/cache"
+)
+
+// cacheUsage описывает подкоманды cache
+const cacheUsage = `Использование:
+  codetelescope cache stats [-config путь] [путь_к_проекту]
+  codetelescope cache clear [-config путь] [путь_к_проекту]`
+
+// runCacheCommand выполняет подкоманду cache и возвращает код завершения
+func runCacheCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(cacheUsage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "stats", "clear":
+	case "-h", "-help", "--help":
+		fmt.Println(cacheUsage)
+		return exitOK
+	default:
+		fmt.Println(cacheUsage)
+		return exitUsage
+	}
+
+	fs := newFlagSet("cache "+args[0], "cache "+args[0]+" [опции] [путь_к_проекту]",
+		"Работает с кэшем описаний символов, сохраненных при генерации карты кода.")
+	common := bindCommonFlags(fs, "cache")
+	if code, ok := parseFlags(fs, args[1:]); !ok {
+		return code
+	}
+
+	projectPath := "."
+	if fs.NArg() > 0 {
+		projectPath = fs.Arg(0)
+	}
+
+	cfg, err := common.loadConfig(projectPath)
+	if err != nil {
+		fmt.Printf("Ошибка загрузки конфигурации: %s\n", err)
+		return exitError
+	}
+
+	dir := cfg.Cache.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(projectPath, dir)
+	}
+
+	if args[0] == "clear" {
+		if err := cache.Clear(dir); err != nil {
+			fmt.Println(err)
+			return exitError
+		}
+		fmt.Printf("Кэш описаний очищен: %s\n", dir)
+		return exitOK
+	}
+
+	descriptionCache, err := cache.Open(dir)
+	if err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+	stats := descriptionCache.Stats()
+	fmt.Printf("Файл кэша: %s\n", stats.Path)
+	fmt.Printf("Описаний: %d\n", stats.Entries)
+	fmt.Printf("Размер: %d байт\n", stats.Size)
+	if !cfg.Cache.Enabled {
+		fmt.Println("Кэш отключен в конфигурации (cache.enabled: false)")
+	}
+	return exitOK
+}
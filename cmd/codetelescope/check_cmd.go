@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/orchestrator"
+)
+
+// runCheckCommand проверяет, что сохраненная карта кода соответствует проекту
+func runCheckCommand(args []string) int {
+	// Отчет о различиях выводится в stdout, поэтому логи перенаправляются в stderr
+	logger.SetOutput(os.Stderr)
+
+	fs := newFlagSet("check", "check [опции] <путь_к_проекту>",
+		"Строит карту кода в памяти и сравнивает ее с сохраненным файлом. Время генерации,\n"+
+			"даты изменения файлов и SHA коммитов в ссылках не учитываются. Описания\n"+
+			"берутся только из кэша описаний, запросы к ЛЛМ не выполняются; если кэш\n"+
+			"недоступен, используйте -ignore-descriptions. Если карта устарела, выводит\n"+
+			"различия и завершается с кодом 1.")
+	common := bindCommonFlags(fs)
+	outputPath := fs.String("output", "code_map.md", "Путь к сохраненной карте кода")
+	inPlace := fs.Bool("inplace", false, "Сравнивать документ, в котором карта кода находится между маркерами code-telescope")
+	ignoreDescriptions := fs.Bool("ignore-descriptions", false, "Не сравнивать описания символов, сгенерированные ЛЛМ")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать путь к проекту")
+	}
+	projectPath := fs.Arg(0)
+
+	cfg, err := common.loadConfig(projectPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки конфигурации: %s\n", err)
+		return exitError
+	}
+	if *inPlace {
+		cfg.Output.Mode = config.OutputModeInPlace
+	}
+
+	// Описания сравниваются с кэшем, только если карта генерируется с описаниями
+	describe := cfg.LLM.Describe && !*ignoreDescriptions
+	cfg.LLM.Describe = false
+
+	orch, err := orchestrator.New(cfg, *common.verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка инициализации оркестратора: %s\n", err)
+		return exitError
+	}
+	if describe {
+		orch.UseCachedDescriptions()
+	}
+
+	result, err := orch.Check(projectPath, *outputPath, orchestrator.CheckOptions{
+		IgnoreDescriptions: *ignoreDescriptions,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка проверки карты кода: %s\n", err)
+		return exitError
+	}
+
+	if result.Stale {
+		fmt.Print(result.Diff)
+		fmt.Fprintf(os.Stderr, "Карта кода %s устарела, обновите ее командой generate\n", *outputPath)
+		return exitError
+	}
+	if *common.verbose {
+		fmt.Fprintf(os.Stderr, "Карта кода %s актуальна\n", *outputPath)
+	}
+	return exitOK
+}
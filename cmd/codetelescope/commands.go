@@ -0,0 +1,264 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/filesystem"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/orchestrator"
+	"code-telescope/pkg/models"
+)
+
+// runWatchCommand генерирует карту кода и обновляет ее при изменении файлов проекта
+func runWatchCommand(args []string) int {
+	fs := newFlagSet("watch", "watch [опции] <путь_к_проекту>",
+		"Генерирует карту кода и обновляет ее при изменении файлов, пока не будет нажато Ctrl+C.\n"+
+			"Повторно парсятся только измененные файлы, описания запрашиваются только\n"+
+			"для символов с новой или измененной сигнатурой.")
+	common := bindCommonFlags(fs)
+	outputPath := fs.String("output", "code_map.md", "Путь для сохранения карты кода")
+	inPlace := fs.Bool("inplace", false, "Обновлять только области между маркерами code-telescope в существующем файле")
+	interval := fs.Duration("interval", orchestrator.DefaultWatchInterval, "Интервал опроса файловой системы")
+	debounce := fs.Duration("debounce", orchestrator.DefaultWatchDebounce, "Время без изменений, после которого карта обновляется")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать путь к проекту")
+	}
+	projectPath := fs.Arg(0)
+
+	orch, cfg, code := common.newOrchestrator(projectPath, true)
+	if orch == nil {
+		return code
+	}
+	if *inPlace {
+		cfg.Output.Mode = config.OutputModeInPlace
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := orch.Watch(ctx, projectPath, orchestrator.WatchOptions{
+		OutputPath: *outputPath,
+		Interval:   *interval,
+		Debounce:   *debounce,
+		OnUpdate: func(changes filesystem.Changes) {
+			fmt.Printf("Карта кода обновлена: добавлено %d, изменено %d, удалено %d, переименовано %d\n",
+				len(changes.Added), len(changes.Modified), len(changes.Removed), len(changes.Renamed))
+		},
+	})
+	if err != nil {
+		fmt.Printf("Ошибка режима наблюдения: %s\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// runScanCommand выводит файлы, которые будут обработаны, и причины пропуска остальных
+func runScanCommand(args []string) int {
+	// Результат команды выводится в stdout, поэтому логи перенаправляются в stderr
+	logger.SetOutput(os.Stderr)
+
+	fs := newFlagSet("scan", "scan [опции] <путь_к_проекту>",
+		"Показывает файлы, которые будут переданы парсерам, и причины, по которым\n"+
+			"остальные файлы и директории пропущены. Парсинг и запросы к ЛЛМ не выполняются.")
+	common := bindCommonFlags(fs, "filesystem", "parser")
+	showSkipped := fs.Bool("skipped", true, "Показывать пропущенные файлы и директории")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать путь к проекту")
+	}
+	projectPath := fs.Arg(0)
+
+	orch, _, code := common.newOrchestrator(projectPath, false)
+	if orch == nil {
+		return code
+	}
+
+	files, skipped, err := orch.Scan(projectPath)
+	if err != nil {
+		fmt.Printf("Ошибка сканирования проекта: %s\n", err)
+		return exitError
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Файлы для анализа: %d\n", len(files))
+	for _, file := range files {
+		fmt.Fprintf(w, "  %s\t%s\t%d байт\n", file.Path, file.LanguageName(), file.Size)
+	}
+
+	if *showSkipped {
+		fmt.Fprintf(w, "\nПропущено: %d\n", len(skipped))
+		for _, path := range skipped {
+			name := path.Path
+			if path.IsDir {
+				name += "/"
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", name, path.Reason)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return exitError
+	}
+	return exitOK
+}
+
+// runParseCommand выводит структуру одного файла в формате JSON
+func runParseCommand(args []string) int {
+	// Результат команды выводится в stdout, поэтому логи перенаправляются в stderr
+	logger.SetOutput(os.Stderr)
+
+	fs := newFlagSet("parse", "parse [опции] <файл>",
+		"Парсит один файл и выводит его структуру (функции, методы, типы, позиции)\n"+
+			"в формате JSON, как она сохраняется в модели карты кода. ЛЛМ не используется.")
+	common := bindCommonFlags(fs, "filesystem", "parser")
+	projectPath := fs.String("project", ".", "Корень проекта (для относительных путей и настроек поддиректорий)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать файл")
+	}
+
+	orch, _, code := common.newOrchestrator(*projectPath, false)
+	if orch == nil {
+		return code
+	}
+
+	codeStructure, fileConfig, err := orch.ParseFile(*projectPath, fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Ошибка парсинга файла: %s\n", err)
+		return exitError
+	}
+
+	fileStructure := models.ConvertToFileStructureWithOptions(codeStructure, models.ConvertOptions{
+		IncludePrivate: fileConfig.Parser.ParsePrivateMethods,
+	})
+	return printJSON(fileStructure)
+}
+
+// runDescribeCommand запрашивает у ЛЛМ описание одного символа
+func runDescribeCommand(args []string) int {
+	// Результат команды выводится в stdout, поэтому логи перенаправляются в stderr
+	logger.SetOutput(os.Stderr)
+
+	fs := newFlagSet("describe", "describe [опции] <файл> <символ>",
+		"Запрашивает у ЛЛМ описание одной функции или метода. Символ задается именем,\n"+
+			"в виде Тип.метод или идентификатором символа. Кэш описаний не используется.")
+	common := bindCommonFlags(fs, "filesystem", "parser", "llm", "redaction", "descriptions")
+	projectPath := fs.String("project", ".", "Корень проекта (для относительных путей и настроек поддиректорий)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 2 {
+		return usageError(fs, "Необходимо указать файл и символ")
+	}
+
+	orch, _, code := common.newOrchestrator(*projectPath, false)
+	if orch == nil {
+		return code
+	}
+
+	description, err := orch.DescribeSymbol(context.Background(), *projectPath, fs.Arg(0), fs.Arg(1))
+	if err != nil {
+		fmt.Printf("Ошибка получения описания: %s\n", err)
+		return exitError
+	}
+
+	fmt.Println(description)
+	return exitOK
+}
+
+// runRenderCommand строит документ из сохраненной JSON-модели карты кода
+func runRenderCommand(args []string) int {
+	fs := newFlagSet("render", "render [опции] <модель.json>",
+		"Строит документ из модели, сохраненной командой generate --format json,\n"+
+			"без повторного парсинга и запросов к ЛЛМ. Формат документа задается --format.")
+	common := bindCommonFlags(fs, "markdown", "output")
+	outputPath := fs.String("output", "code_map.md", "Путь для сохранения документа")
+	projectPath := fs.String("project", ".", "Корень проекта для ссылок на исходный код")
+	inPlace := fs.Bool("inplace", false, "Обновить только области между маркерами code-telescope в существующем файле")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать файл модели")
+	}
+
+	codeMap, err := loadCodeMap(fs.Arg(0))
+	if err != nil {
+		fmt.Printf("Ошибка чтения модели: %s\n", err)
+		return exitError
+	}
+
+	orch, cfg, code := common.newOrchestrator(*projectPath, false)
+	if orch == nil {
+		return code
+	}
+	if *inPlace {
+		cfg.Output.Mode = config.OutputModeInPlace
+	}
+
+	orch.SetDocumentPath(*outputPath)
+	orch.SetLinkRoot(*projectPath)
+	document, err := orch.Render(codeMap)
+	if err != nil {
+		fmt.Printf("Ошибка построения документа: %s\n", err)
+		return exitError
+	}
+
+	if err := orch.SaveCodeMap(document, *outputPath); err != nil {
+		fmt.Printf("Ошибка сохранения документа: %s\n", err)
+		return exitError
+	}
+
+	if *common.verbose {
+		fmt.Printf("Документ сохранен в файл: %s\n", *outputPath)
+	}
+	return exitOK
+}
+
+// loadCodeMap читает модель карты кода из файла JSON
+func loadCodeMap(path string) (*models.CodeMap, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var codeMap models.CodeMap
+	if err := json.Unmarshal(data, &codeMap); err != nil {
+		return nil, fmt.Errorf("некорректный JSON: %w", err)
+	}
+	if codeMap.Version != models.CodeMapVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия модели %d (ожидается %d), пересоздайте ее командой generate --format json",
+			codeMap.Version, models.CodeMapVersion)
+	}
+	if codeMap.Project == "" {
+		codeMap.Project = filepath.Base(filepath.Dir(path))
+	}
+
+	return &codeMap, nil
+}
+
+// printJSON выводит значение в формате JSON
+func printJSON(value interface{}) int {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		fmt.Printf("Ошибка сериализации JSON: %s\n", err)
+		return exitError
+	}
+	fmt.Println(string(data))
+	return exitOK
+}
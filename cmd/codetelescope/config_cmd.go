@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"code-telescope/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// configUsage описывает подкоманды config
+const configUsage = `Использование:
+  codetelescope config show [--resolved] [-config путь] [флаги настроек] [путь_к_проекту]
+  codetelescope config validate [-config путь] [путь_к_проекту]
+  codetelescope config schema`
+
+// runConfigCommand выполняет подкоманду config и возвращает код завершения
+func runConfigCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Println(configUsage)
+		return exitUsage
+	}
+
+	switch args[0] {
+	case "show":
+		return runConfigShow(args[1:])
+	case "validate":
+		return runConfigValidate(args[1:])
+	case "schema":
+		return runConfigSchema()
+	case "-h", "-help", "--help":
+		fmt.Println(configUsage)
+		return exitOK
+	default:
+		fmt.Println(configUsage)
+		return exitUsage
+	}
+}
+
+// runConfigShow выводит итоговую конфигурацию
+func runConfigShow(args []string) int {
+	fs := newFlagSet("config show", "config show [--resolved] [-config путь] [флаги настроек] [путь_к_проекту]",
+		"Выводит итоговую конфигурацию в формате YAML или таблицу значений с источниками.")
+	configPath := fs.String("config", "", "Путь к файлу конфигурации")
+	showSources := fs.Bool("resolved", false, "Показать источник каждого значения")
+	overrides := config.BindFlags(fs)
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	// Путь к проекту нужен для поиска .code-telescope.yaml в его корне
+	projectPath := "."
+	if fs.NArg() > 0 {
+		projectPath = fs.Arg(0)
+	}
+
+	resolved, err := config.Resolve(config.ResolveOptions{
+		ConfigPath:  *configPath,
+		ProjectPath: projectPath,
+		Flags:       overrides.Values(),
+	})
+	if err != nil {
+		fmt.Printf("Ошибка загрузки конфигурации: %s\n", err)
+		return exitError
+	}
+
+	if *showSources {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "НАСТРОЙКА\tЗНАЧЕНИЕ\tИСТОЧНИК")
+		for _, entry := range resolved.Entries() {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Key, entry.Value, entry.Source)
+		}
+		if err := w.Flush(); err != nil {
+			return exitError
+		}
+		return exitOK
+	}
+
+	// Итоговая конфигурация в формате YAML без секретов
+	cfg := *resolved.Config
+	if cfg.LLM.APIKey != "" {
+		cfg.LLM.APIKey = "***"
+	}
+	cfg.LLM.Fallbacks = append([]config.FallbackConfig(nil), cfg.LLM.Fallbacks...)
+	for i := range cfg.LLM.Fallbacks {
+		if cfg.LLM.Fallbacks[i].APIKey != "" {
+			cfg.LLM.Fallbacks[i].APIKey = "***"
+		}
+	}
+	data, err := yaml.Marshal(&cfg)
+	if err != nil {
+		fmt.Printf("Ошибка сериализации конфигурации: %s\n", err)
+		return exitError
+	}
+	fmt.Print(string(data))
+	return exitOK
+}
+
+// runConfigValidate проверяет файл конфигурации проекта и файлы во вложенных
+// директориях и выводит все найденные ошибки
+func runConfigValidate(args []string) int {
+	flags := newFlagSet("config validate", "config validate [-config путь] [путь_к_проекту]",
+		"Проверяет файл конфигурации проекта и файлы .code-telescope.yaml в поддиректориях.")
+	configPath := flags.String("config", "", "Путь к файлу конфигурации")
+	if code, ok := parseFlags(flags, args); !ok {
+		return code
+	}
+
+	projectPath := "."
+	if flags.NArg() > 0 {
+		projectPath = flags.Arg(0)
+	}
+
+	resolved, err := config.Resolve(config.ResolveOptions{
+		ConfigPath:  *configPath,
+		ProjectPath: projectPath,
+	})
+	if err != nil {
+		fmt.Println(err)
+		return exitError
+	}
+
+	// Файлы конфигурации поддиректорий проверяются относительно корневой конфигурации
+	failed := false
+	err = filepath.WalkDir(projectPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// Скрытые директории (.git и т.п.) не сканируются
+		if d.IsDir() && path != projectPath && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || d.Name() != config.ProjectConfigFileName {
+			return nil
+		}
+		if filepath.Dir(path) == filepath.Clean(projectPath) {
+			return nil
+		}
+		if _, err := config.LoadDirectoryConfig(path, resolved.Config); err != nil {
+			fmt.Println(err)
+			failed = true
+		}
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Ошибка обхода проекта: %s\n", err)
+		return exitError
+	}
+	if failed {
+		return exitError
+	}
+
+	if resolved.ConfigPath == "" {
+		fmt.Println("Файл конфигурации не найден, используются значения по умолчанию")
+		return exitOK
+	}
+	fmt.Printf("Конфигурация корректна: %s\n", resolved.ConfigPath)
+	return exitOK
+}
+
+// runConfigSchema выводит JSON Schema файла конфигурации
+func runConfigSchema() int {
+	schema, err := config.JSONSchema()
+	if err != nil {
+		fmt.Printf("Ошибка построения схемы: %s\n", err)
+		return exitError
+	}
+	fmt.Print(string(schema))
+	return exitOK
+}
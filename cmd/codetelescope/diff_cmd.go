@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"code-telescope/internal/apidiff"
+	"code-telescope/internal/config"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/orchestrator"
+	"code-telescope/pkg/models"
+)
+
+// runDiffCommand сравнивает публичный API двух версий проекта
+func runDiffCommand(args []string) int {
+	// Отчет может выводиться в stdout, поэтому логи перенаправляются в stderr
+	logger.SetOutput(os.Stderr)
+
+	fs := newFlagSet("diff", "diff [опции] <старая> [<новая>]",
+		"Сравнивает публичный API двух версий проекта: добавленные, удаленные и измененные\n"+
+			"функции, методы, типы, поля и экспорты. Версия задается JSON-моделью\n"+
+			"(generate --format json) или ревизией git (ветка, тег, SHA, HEAD~N); если\n"+
+			"новая версия не указана, используется рабочее дерево проекта. Изменения\n"+
+			"делятся на нарушающие совместимость и остальные, рекомендуется изменение\n"+
+			"версии по semver. Отчет выводится в Markdown для комментария к pull request\n"+
+			"или в JSON при --format json. ЛЛМ не используется.")
+	common := bindCommonFlags(fs, "filesystem", "parser", "output")
+	projectPath := fs.String("project", ".", "Корень проекта для сравнения ревизий git и рабочего дерева")
+	outputPath := fs.String("output", "", "Путь для сохранения отчета (по умолчанию stdout)")
+	failOnBreaking := fs.Bool("fail-on-breaking", false, "Завершиться с кодом 1, если есть изменения, нарушающие совместимость")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return usageError(fs, "Необходимо указать одну или две версии для сравнения")
+	}
+
+	orch, cfg, code := common.newOrchestrator(*projectPath, false)
+	if orch == nil {
+		return code
+	}
+
+	oldMap, err := loadVersion(orch, *projectPath, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка получения версии %s: %s\n", fs.Arg(0), err)
+		return exitError
+	}
+
+	newName := "рабочее дерево"
+	var newMap *models.CodeMap
+	if fs.NArg() == 2 {
+		newName = fs.Arg(1)
+		newMap, err = loadVersion(orch, *projectPath, newName)
+	} else {
+		newMap, err = orch.BuildModel(*projectPath)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка получения версии %s: %s\n", newName, err)
+		return exitError
+	}
+
+	report := apidiff.Compare(oldMap, newMap)
+	report.Old, report.New = fs.Arg(0), newName
+
+	var output string
+	if cfg.Output.Format == config.OutputFormatJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка сериализации JSON: %s\n", err)
+			return exitError
+		}
+		output = string(data) + "\n"
+	} else {
+		output = report.Markdown()
+	}
+
+	if *outputPath == "" {
+		fmt.Print(output)
+	} else if err := os.WriteFile(*outputPath, []byte(output), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка сохранения отчета: %s\n", err)
+		return exitError
+	}
+
+	if *failOnBreaking && report.Breaking > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+// loadVersion загружает версию проекта: JSON-модель, если аргумент указывает
+// на существующий файл .json, иначе модель ревизии git
+func loadVersion(orch *orchestrator.Orchestrator, projectPath, version string) (*models.CodeMap, error) {
+	if strings.EqualFold(filepath.Ext(version), ".json") {
+		if info, err := os.Stat(version); err == nil && !info.IsDir() {
+			return loadCodeMap(version)
+		}
+	}
+	codeMap, _, err := orch.BuildModelAt(projectPath, version)
+	return codeMap, err
+}
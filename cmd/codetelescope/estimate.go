@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/orchestrator"
+	"code-telescope/internal/usage"
+)
+
+// newEstimator загружает конфигурацию и создает оркестратор для оценки расхода,
+// который не обращается к ЛЛМ
+func newEstimator(common *commonFlags, projectPath string) (*orchestrator.Orchestrator, *config.Config) {
+	// Отчет выводится в stdout, поэтому логи перенаправляются в stderr
+	logger.SetOutput(os.Stderr)
+
+	cfg, err := common.loadConfig(projectPath)
+	if err != nil {
+		fmt.Printf("Ошибка загрузки конфигурации: %s\n", err)
+		return nil, nil
+	}
+
+	orch, err := orchestrator.NewEstimator(cfg, *common.verbose)
+	if err != nil {
+		fmt.Printf("Ошибка инициализации оркестратора: %s\n", err)
+		return nil, nil
+	}
+	return orch, cfg
+}
+
+// printEstimate выводит ожидаемый расход по файлам и моделям и стоимость
+// того же объема токенов для моделей из таблицы цен
+func printEstimate(cfg *config.Config, report usage.Report) int {
+	if report.Empty() {
+		fmt.Println("Запросы к ЛЛМ не потребуются: описания отключены или уже есть в кэше")
+		return exitOK
+	}
+	if err := report.Write(os.Stdout, "Оценка расхода ЛЛМ (запросы не выполнялись):"); err != nil {
+		return exitError
+	}
+
+	fmt.Printf("\nСтоимость %d входных и %d выходных токенов на других моделях:\n",
+		report.Total.InputTokens, report.Total.OutputTokens)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Провайдер\tМодель\tВход, $/1M\tВыход, $/1M\tСтоимость\t\n")
+	seen := make(map[string]bool)
+	for _, price := range append(append([]config.PriceConfig{}, cfg.LLM.Prices...), config.DefaultLLMPrices...) {
+		key := price.Provider + "/" + price.Model
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		cost := usage.Price{Input: price.Input, Output: price.Output}.Cost(report.Total.InputTokens, report.Total.OutputTokens)
+		fmt.Fprintf(w, "%s\t%s\t%g\t%g\t%s\t\n", orAny(price.Provider), orAny(price.Model),
+			price.Input, price.Output, usage.FormatCost(cost, true))
+	}
+	if err := w.Flush(); err != nil {
+		return exitError
+	}
+	return exitOK
+}
+
+// orAny возвращает "*" для пустого значения, которое подходит для любого провайдера или модели
+func orAny(value string) string {
+	if value == "" {
+		return "*"
+	}
+	return value
+}
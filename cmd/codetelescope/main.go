@@ -1,9 +1,11 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"code-telescope/internal/config"
 	"code-telescope/internal/orchestrator"
@@ -12,75 +14,322 @@ import (
 // Версия программы, устанавливается при сборке через ldflags
 var Version = "dev"
 
+// Коды завершения программы
+const (
+	// exitOK успешное выполнение
+	exitOK = 0
+	// exitError ошибка выполнения команды
+	exitError = 1
+	// exitUsage неверные аргументы командной строки
+	exitUsage = 2
+)
+
+// command описывает подкоманду командной строки
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) int
+}
+
+// Список подкоманд заполняется в init, так как команда help обращается к нему
+var commands []command
+
+func init() {
+	commands = []command{
+		{"generate", "Сгенерировать карту кода проекта (команда по умолчанию)", runGenerateCommand},
+		{"watch", "Поддерживать карту кода в актуальном состоянии при изменении файлов", runWatchCommand},
+		{"check", "Проверить, что сохраненная карта кода соответствует проекту", runCheckCommand},
+		{"mcp", "Сервер Model Context Protocol на stdio для ИИ-ассистентов", runMCPCommand},
+		{"serve", "Просматривать карту кода и обращаться к ней через локальный HTTP-сервер", runServeCommand},
+		{"scan", "Показать файлы, которые будут обработаны, и причины пропуска остальных", runScanCommand},
+		{"parse", "Вывести структуру одного файла в формате JSON", runParseCommand},
+		{"describe", "Получить описание одного символа от ЛЛМ", runDescribeCommand},
+		{"review", "Показать описания, написанные вручную, которые устарели после изменения кода", runReviewCommand},
+		{"render", "Построить документ из сохраненной JSON-модели без повторного парсинга", runRenderCommand},
+		{"diff", "Сравнить публичный API двух версий проекта и оценить изменение версии", runDiffCommand},
+		{"config", "Показать, проверить конфигурацию или вывести ее JSON Schema", runConfigCommand},
+		{"cache", "Статистика и очистка кэша описаний", runCacheCommand},
+		{"version", "Показать версию программы", runVersionCommand},
+		{"help", "Показать справку по команде", runHelpCommand},
+	}
+}
+
 func main() {
-	// Парсим аргументы командной строки
-	configPath := flag.String("config", "", "Путь к файлу конфигурации")
-	outputPath := flag.String("output", "code_map.md", "Путь для сохранения карты кода")
-	verbose := flag.Bool("verbose", false, "Подробный вывод")
-	flag.Parse()
+	os.Exit(run(os.Args[1:]))
+}
 
-	// Проверяем наличие пути к проекту
-	args := flag.Args()
-	if len(args) < 1 {
-		fmt.Println("Необходимо указать путь к проекту для анализа")
-		fmt.Println("Использование: codetelescope [опции] <путь_к_проекту>")
-		flag.PrintDefaults()
-		os.Exit(1)
+// run выбирает подкоманду по первому аргументу. Если подкоманда не указана,
+// выполняется generate, чтобы сохранить прежний вызов codetelescope [опции] <путь>.
+func run(args []string) int {
+	if len(args) == 0 {
+		printUsage()
+		return exitUsage
+	}
+
+	if cmd := findCommand(args[0]); cmd != nil {
+		return cmd.run(args[1:])
+	}
+	if args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		printUsage()
+		return exitOK
+	}
+	if !strings.HasPrefix(args[0], "-") && !isDir(args[0]) {
+		fmt.Printf("Неизвестная команда: %s\n\n", args[0])
+		printUsage()
+		return exitUsage
+	}
+
+	return runGenerateCommand(args)
+}
+
+// findCommand возвращает подкоманду по имени
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
 	}
-	projectPath := args[0]
+	return nil
+}
+
+// printUsage выводит общую справку со списком подкоманд
+func printUsage() {
+	fmt.Println("Использование: codetelescope <команда> [опции] [аргументы]")
+	fmt.Println()
+	fmt.Println("Команды:")
+	for _, cmd := range commands {
+		fmt.Printf("  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Println()
+	fmt.Println("Справка по команде: codetelescope help <команда> или codetelescope <команда> -h")
+}
+
+// isDir проверяет, является ли аргумент существующей директорией
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// newFlagSet создает набор флагов подкоманды со справкой
+func newFlagSet(name, usage, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Использование: codetelescope %s\n\n%s\n\nОпции:\n", usage, description)
+		fs.PrintDefaults()
+	}
+	return fs
+}
 
-	// Загружаем конфигурацию
-	cfg, err := loadConfig(*configPath)
+// parseFlags разбирает флаги подкоманды. Возвращает false и код завершения,
+// если выполнение нужно прекратить (ошибка в аргументах или запрошена справка).
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitUsage, false
+	}
+	return exitOK, true
+}
+
+// usageError выводит сообщение о неверных аргументах и справку подкоманды
+func usageError(fs *flag.FlagSet, message string) int {
+	fmt.Fprintln(fs.Output(), message)
+	fs.Usage()
+	return exitUsage
+}
+
+// commonFlags содержит флаги, общие для команд, работающих с конфигурацией
+type commonFlags struct {
+	configPath *string
+	verbose    *bool
+	overrides  *config.FlagOverrides
+}
+
+// bindCommonFlags регистрирует флаги конфигурации и подробного вывода.
+// Флаги настроек регистрируются только для разделов конфигурации, которые
+// использует команда; без разделов — для всех.
+func bindCommonFlags(fs *flag.FlagSet, sections ...string) *commonFlags {
+	return &commonFlags{
+		configPath: fs.String("config", "", "Путь к файлу конфигурации"),
+		verbose:    fs.Bool("verbose", false, "Подробный вывод"),
+		overrides:  config.BindFlags(fs, sections...),
+	}
+}
+
+// loadConfig загружает конфигурацию: значения по умолчанию, файл (-config или
+// .code-telescope.yaml в корне проекта), переменные окружения и флаги
+func (c *commonFlags) loadConfig(projectPath string) (*config.Config, error) {
+	resolved, err := config.Resolve(config.ResolveOptions{
+		ConfigPath:  *c.configPath,
+		ProjectPath: projectPath,
+		Flags:       c.overrides.Values(),
+	})
 	if err != nil {
-		fmt.Printf("Ошибка загрузки конфигурации: %s\n", err)
-		os.Exit(1)
+		return nil, err
+	}
+	// Сообщение выводится в stderr, так как stdout некоторых команд занят данными
+	if *c.verbose && resolved.ConfigPath != "" {
+		fmt.Fprintf(os.Stderr, "Используется файл конфигурации: %s\n", resolved.ConfigPath)
 	}
+	return resolved.Config, nil
+}
 
-	// Создаем оркестратор
-	orch, err := orchestrator.New(cfg, *verbose)
+// newOrchestrator загружает конфигурацию и создает оркестратор.
+// Если команде не нужны описания от ЛЛМ, провайдер не инициализируется.
+// Ошибки выводятся в stderr: stdout команды mcp занят протоколом JSON-RPC.
+func (c *commonFlags) newOrchestrator(projectPath string, describe bool) (*orchestrator.Orchestrator, *config.Config, int) {
+	cfg, err := c.loadConfig(projectPath)
 	if err != nil {
-		fmt.Printf("Ошибка инициализации оркестратора: %s\n", err)
-		os.Exit(1)
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки конфигурации: %s\n", err)
+		return nil, nil, exitError
+	}
+	if !describe {
+		cfg.LLM.Describe = false
+	}
+
+	orch, err := orchestrator.New(cfg, *c.verbose)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка инициализации оркестратора: %s\n", err)
+		return nil, nil, exitError
+	}
+	return orch, cfg, exitOK
+}
+
+// runGenerateCommand генерирует карту кода проекта
+func runGenerateCommand(args []string) int {
+	fs := newFlagSet("generate", "generate [опции] <путь_к_проекту>",
+		"Сканирует проект, парсит файлы, запрашивает описания у ЛЛМ и сохраняет карту кода.\n"+
+			"С --format json сохраняется модель карты кода для команды render.\n"+
+			"С -since заново обрабатываются только файлы, измененные с указанной ревизии git,\n"+
+			"а остальные берутся из ранее сохраненной JSON-модели (-base).")
+	common := bindCommonFlags(fs)
+	outputPath := fs.String("output", "code_map.md", "Путь для сохранения карты кода")
+	inPlace := fs.Bool("inplace", false, "Обновить только области между маркерами code-telescope в существующем файле")
+	since := fs.String("since", "", "Обработать только файлы, измененные с ревизии git (ветка, тег, SHA, HEAD~N)")
+	basePath := fs.String("base", "", "JSON-модель, в которую объединяются изменения для -since (по умолчанию -output при --format json)")
+	estimate := fs.Bool("estimate", false, "Оценить количество запросов, токены и стоимость без обращения к ЛЛМ; карта кода не сохраняется")
+	failOnSecret := fs.Bool("fail-on-secret", false, "Не отправлять в ЛЛМ файлы с секретами и завершиться с ошибкой, если секреты найдены")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+
+	// Проверяем наличие пути к проекту
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать путь к проекту для анализа")
+	}
+	projectPath := fs.Arg(0)
+	if *basePath != "" && *since == "" {
+		return usageError(fs, "Флаг -base используется только вместе с -since")
+	}
+
+	var orch *orchestrator.Orchestrator
+	var cfg *config.Config
+	if *estimate {
+		orch, cfg = newEstimator(common, projectPath)
+	} else {
+		var code int
+		orch, cfg, code = common.newOrchestrator(projectPath, true)
+		if orch == nil {
+			return code
+		}
+	}
+	if orch == nil {
+		return exitError
+	}
+	if *inPlace {
+		cfg.Output.Mode = config.OutputModeInPlace
+	}
+	if *failOnSecret {
+		cfg.Redaction.FailOnSecret = true
 	}
 
 	// Генерируем карту кода
-	if *verbose {
+	if *common.verbose {
 		fmt.Println("Начало генерации карты кода...")
 	}
 
-	codeMap, err := orch.GenerateCodeMap(projectPath)
+	var codeMap string
+	var err error
+	orch.SetDocumentPath(*outputPath)
+	if *since != "" {
+		codeMap, err = generateSince(orch, cfg, projectPath, *since, *basePath, *outputPath, *common.verbose)
+	} else {
+		codeMap, err = orch.GenerateCodeMap(projectPath)
+	}
 	if err != nil {
 		fmt.Printf("Ошибка генерации карты кода: %s\n", err)
-		os.Exit(1)
+		return exitError
+	}
+
+	if *estimate {
+		return printEstimate(cfg, orch.Usage())
 	}
 
 	// Сохраняем результат
 	if err := orch.SaveCodeMap(codeMap, *outputPath); err != nil {
 		fmt.Printf("Ошибка сохранения карты кода: %s\n", err)
-		os.Exit(1)
+		return exitError
 	}
 
-	if *verbose {
+	if *common.verbose {
 		fmt.Printf("Карта кода успешно сохранена в файл: %s\n", *outputPath)
 	}
+	if report := orch.Usage(); !report.Empty() {
+		fmt.Println()
+		if err := report.Write(os.Stdout, "Расход ЛЛМ:"); err != nil {
+			return exitError
+		}
+	}
+	return exitOK
 }
 
-func loadConfig(configPath string) (*config.Config, error) {
-	// Если путь к конфигурации не указан, используем конфигурацию по умолчанию
-	if configPath == "" {
-		return config.DefaultConfig(), nil
+// generateSince объединяет файлы, измененные с ревизии since, с ранее сохраненной моделью
+// и строит документ. Если модель не указана, используется файл вывода в формате JSON.
+func generateSince(orch *orchestrator.Orchestrator, cfg *config.Config, projectPath, since, basePath, outputPath string, verbose bool) (string, error) {
+	if basePath == "" {
+		if cfg.Output.Format != config.OutputFormatJSON {
+			return "", fmt.Errorf("для -since укажите JSON-модель через -base или сохраняйте карту с --format json")
+		}
+		basePath = outputPath
 	}
-
-	// Проверяем существование файла
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("файл конфигурации не найден: %s", configPath)
+	base, err := loadCodeMap(basePath)
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения модели %s: %w", basePath, err)
 	}
 
-	// Загружаем конфигурацию из файла
-	cfg, err := config.LoadConfig(configPath)
+	result, err := orch.BuildModelSince(projectPath, since, base)
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+	if verbose {
+		fmt.Printf("Изменения с %s (%.12s): добавлено %d, изменено %d, удалено %d, переименовано %d; обработано файлов %d, взято из модели %d\n",
+			since, result.Commit, len(result.Changes.Added), len(result.Changes.Modified), len(result.Changes.Removed),
+			len(result.Changes.Renamed), result.Processed, result.Reused)
+	}
+
+	orch.SetLinkRoot(projectPath)
+	return orch.Render(result.CodeMap)
+}
+
+// runVersionCommand выводит версию программы
+func runVersionCommand(args []string) int {
+	fmt.Printf("codetelescope %s\n", Version)
+	return exitOK
+}
+
+// runHelpCommand выводит справку по команде или общий список команд
+func runHelpCommand(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		printUsage()
+		return exitOK
 	}
 
-	return cfg, nil
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Printf("Неизвестная команда: %s\n\n", args[0])
+		printUsage()
+		return exitUsage
+	}
+	return cmd.run([]string{"-h"})
 }
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"code-telescope/internal/logger"
+	"code-telescope/internal/mcp"
+	"code-telescope/internal/orchestrator"
+)
+
+// runMCPCommand запускает сервер Model Context Protocol на stdin/stdout
+func runMCPCommand(args []string) int {
+	// stdout занят протоколом, поэтому логи перенаправляются в stderr
+	logger.SetOutput(os.Stderr)
+
+	fs := newFlagSet("mcp", "mcp [опции] <путь_к_проекту>",
+		"Запускает сервер Model Context Protocol на stdin/stdout для ИИ-ассистентов.\n"+
+			"Инструменты: list_files, get_file_outline, find_symbol, get_dependencies,\n"+
+			"get_description и search. Структура строится парсерами, описания берутся\n"+
+			"из кэша описаний; запросы к ЛЛМ не выполняются. При изменении файлов\n"+
+			"проекта модель обновляется автоматически.")
+	common := bindCommonFlags(fs, "filesystem", "parser", "llm", "cache", "redaction", "descriptions")
+	interval := fs.Duration("interval", orchestrator.DefaultWatchInterval, "Интервал опроса файловой системы")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать путь к проекту")
+	}
+	projectPath := fs.Arg(0)
+
+	orch, _, code := common.newOrchestrator(projectPath, false)
+	if orch == nil {
+		return code
+	}
+	orch.UseCachedDescriptions()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := mcp.New(Version)
+	watchErr := make(chan error, 1)
+	go func() {
+		err := orch.Watch(ctx, projectPath, orchestrator.WatchOptions{
+			Interval: *interval,
+			OnModel:  srv.Update,
+		})
+		if err != nil {
+			// Без модели инструменты не смогут ответить, поэтому сервер останавливается
+			fmt.Fprintf(os.Stderr, "Ошибка построения карты кода: %s\n", err)
+			stop()
+		}
+		watchErr <- err
+	}()
+
+	// Сервер работает, пока клиент не закроет stdin
+	serveErr := srv.Serve(ctx, os.Stdin, os.Stdout)
+	stop()
+
+	if serveErr != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка сервера MCP: %s\n", serveErr)
+		return exitError
+	}
+	if err := <-watchErr; err != nil {
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"code-telescope/internal/logger"
+	"code-telescope/internal/orchestrator"
+)
+
+// runReviewCommand выводит описания из файла описаний, которые нужно проверить
+func runReviewCommand(args []string) int {
+	// Отчет выводится в stdout, поэтому логи перенаправляются в stderr
+	logger.SetOutput(os.Stderr)
+
+	fs := newFlagSet("review", "review [опции] <путь_к_проекту>",
+		"Сверяет описания, написанные вручную в файле descriptions.overrides_file, с символами\n"+
+			"проекта и выводит устаревшие (сигнатура символа изменилась), описания без хэша\n"+
+			"сигнатуры и описания несуществующих символов. С -update хэши текущих сигнатур\n"+
+			"записываются в файл: описания считаются проверенными. Если есть устаревшие\n"+
+			"описания или описания несуществующих символов, завершается с кодом 1.")
+	common := bindCommonFlags(fs, "filesystem", "parser", "descriptions")
+	update := fs.Bool("update", false, "Записать хэши текущих сигнатур символов в файл описаний")
+	all := fs.Bool("all", false, "Показывать и актуальные описания")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать путь к проекту")
+	}
+	projectPath := fs.Arg(0)
+
+	orch, _, code := common.newOrchestrator(projectPath, false)
+	if orch == nil {
+		return code
+	}
+
+	result, err := orch.ReviewOverrides(projectPath, *update)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка проверки описаний: %s\n", err)
+		return exitError
+	}
+	if len(result.Entries) == 0 {
+		fmt.Fprintf(os.Stderr, "В файле описаний %s нет описаний\n", result.Path)
+		return exitOK
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	missing := false
+	for _, entry := range result.Entries {
+		if entry.Status == orchestrator.ReviewOK && !*all {
+			continue
+		}
+		missing = missing || entry.Status == orchestrator.ReviewMissing
+		fmt.Fprintf(w, "%s\t%s\t%s\n", entry.Status, entry.ID, entry.Description)
+	}
+	if err := w.Flush(); err != nil {
+		return exitError
+	}
+
+	if *update {
+		fmt.Fprintf(os.Stderr, "Обновлены хэши сигнатур %d описаний в %s\n", result.Pinned, result.Path)
+		if missing {
+			fmt.Fprintln(os.Stderr, "Описания несуществующих символов удалите из файла вручную")
+			return exitError
+		}
+		return exitOK
+	}
+	if result.NeedsReview() {
+		fmt.Fprintln(os.Stderr, "Проверьте описания и подтвердите их командой review -update")
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"code-telescope/internal/orchestrator"
+	"code-telescope/internal/server"
+	"code-telescope/pkg/models"
+)
+
+// Адрес локального сервера по умолчанию
+const defaultServeAddr = "127.0.0.1:8080"
+
+// Время на завершение активных запросов при остановке сервера
+const serveShutdownTimeout = 5 * time.Second
+
+// runServeCommand строит карту кода и отдает ее через локальный HTTP-сервер
+func runServeCommand(args []string) int {
+	fs := newFlagSet("serve", "serve [опции] <путь_к_проекту>",
+		"Строит карту кода и отдает ее на локальном адресе: HTML-страницы для просмотра\n"+
+			"и JSON API (/api/files, /api/files/<путь>, /api/search?q=, /api/deps/<путь>?depth=).\n"+
+			"При изменении файлов проекта модель обновляется без перезапуска сервера.")
+	common := bindCommonFlags(fs)
+	addr := fs.String("addr", defaultServeAddr, "Адрес прослушивания (только локальный интерфейс)")
+	outputPath := fs.String("output", "", "Дополнительно сохранять карту кода в файл")
+	interval := fs.Duration("interval", orchestrator.DefaultWatchInterval, "Интервал опроса файловой системы")
+	debounce := fs.Duration("debounce", orchestrator.DefaultWatchDebounce, "Время без изменений, после которого модель обновляется")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		return usageError(fs, "Необходимо указать путь к проекту")
+	}
+	if err := server.CheckLoopback(*addr); err != nil {
+		return usageError(fs, err.Error())
+	}
+	projectPath := fs.Arg(0)
+
+	orch, _, code := common.newOrchestrator(projectPath, true)
+	if orch == nil {
+		return code
+	}
+
+	// Порт занимается до построения модели, чтобы сразу сообщить об ошибке
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		fmt.Printf("Ошибка запуска сервера: %s\n", err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	srv := server.New()
+	httpServer := &http.Server{
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+	fmt.Printf("Сервер карты кода запущен: http://%s/\n", listener.Addr())
+
+	// Режим наблюдения обновляет модель сервера после каждого изменения проекта
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- orch.Watch(ctx, projectPath, orchestrator.WatchOptions{
+			OutputPath: *outputPath,
+			Interval:   *interval,
+			Debounce:   *debounce,
+			OnModel: func(codeMap *models.CodeMap) {
+				srv.Update(codeMap)
+				if *common.verbose {
+					fmt.Printf("Модель карты кода обновлена: файлов %d\n", len(codeMap.Files))
+				}
+			},
+		})
+	}()
+
+	exitCode := exitOK
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Ошибка сервера: %s\n", err)
+			exitCode = exitError
+		}
+	case err := <-watchErr:
+		if err != nil {
+			fmt.Printf("Ошибка построения карты кода: %s\n", err)
+			exitCode = exitError
+		}
+	}
+	stop()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		fmt.Printf("Ошибка остановки сервера: %s\n", err)
+		exitCode = exitError
+	}
+	return exitCode
+}
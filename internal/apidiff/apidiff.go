@@ -0,0 +1,373 @@
+package apidiff
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"code-telescope/pkg/models"
+)
+
+// Вид изменения
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// Вид символа
+const (
+	SymbolFunction = "function"
+	SymbolMethod   = "method"
+	SymbolType     = "type"
+	SymbolField    = "field"
+	SymbolExport   = "export"
+)
+
+// Рекомендуемое изменение версии по semver
+const (
+	BumpNone  = "none"
+	BumpPatch = "patch"
+	BumpMinor = "minor"
+	BumpMajor = "major"
+)
+
+// Change описывает изменение одного публичного символа
+type Change struct {
+	// Вид изменения: added, removed или changed
+	Kind string `json:"kind"`
+
+	// Вид символа: function, method, type, field или export
+	Symbol string `json:"symbol"`
+
+	// Имя символа (для методов и полей — Тип.имя)
+	Name string `json:"name"`
+
+	// Файл, в котором находится символ (для удаленных — старый путь)
+	File string `json:"file"`
+
+	// Сигнатура или описание символа до и после изменения
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+
+	// Подробности изменения сигнатуры
+	Details []string `json:"details,omitempty"`
+
+	// Изменение нарушает совместимость с существующим кодом
+	Breaking bool `json:"breaking"`
+
+	// Изменение добавляет возможности, например необязательный параметр
+	additive bool
+}
+
+// Report содержит результат сравнения двух моделей карты кода
+type Report struct {
+	// Описание сравниваемых версий (путь к модели или ревизия)
+	Old string `json:"old"`
+	New string `json:"new"`
+
+	// Рекомендуемое изменение версии: none, patch, minor или major
+	Bump string `json:"bump"`
+
+	// Количество изменений по категориям
+	Breaking int `json:"breaking"`
+	Added    int `json:"added"`
+	Other    int `json:"other"`
+
+	// Изменения в порядке: нарушающие совместимость, добавления, прочие
+	Changes []Change `json:"changes"`
+}
+
+// symbol представляет публичный символ модели для сравнения
+type symbol struct {
+	kind      string
+	name      string
+	file      string
+	language  string
+	signature string
+	fieldType string
+	method    *models.MethodInfo
+	typ       *models.TypeInfo
+}
+
+// Compare сравнивает публичный API двух моделей карты кода. Символы
+// и экспорты функций и типов сопоставляются по идентификаторам, поэтому
+// перенос функции между файлами пакета Go не считается изменением. Если в одной из моделей идентификаторов
+// нет (модель сохранена старой версией), символы сопоставляются по пакету
+// (директории для Go, файлу для остальных языков), типу-владельцу и имени.
+func Compare(oldMap, newMap *models.CodeMap) *Report {
+	useIDs := hasIDs(oldMap) && hasIDs(newMap)
+	oldSymbols := collectSymbols(oldMap, useIDs)
+	newSymbols := collectSymbols(newMap, useIDs)
+
+	var changes []Change
+	for key, oldSymbol := range oldSymbols {
+		newSymbol, ok := newSymbols[key]
+		if !ok {
+			changes = append(changes, Change{
+				Kind:     ChangeRemoved,
+				Symbol:   oldSymbol.kind,
+				Name:     oldSymbol.name,
+				File:     oldSymbol.file,
+				Old:      oldSymbol.signature,
+				Breaking: true,
+			})
+			continue
+		}
+		if change, ok := compareSymbol(oldSymbol, newSymbol); ok {
+			changes = append(changes, change)
+		}
+	}
+	for key, newSymbol := range newSymbols {
+		if _, ok := oldSymbols[key]; !ok {
+			changes = append(changes, Change{
+				Kind:   ChangeAdded,
+				Symbol: newSymbol.kind,
+				Name:   newSymbol.name,
+				File:   newSymbol.file,
+				New:    newSymbol.signature,
+			})
+		}
+	}
+
+	changes = dropCoveredExports(changes)
+	return newReport(changes)
+}
+
+// newReport сортирует изменения, подсчитывает категории и определяет изменение версии
+func newReport(changes []Change) *Report {
+	sort.Slice(changes, func(i, j int) bool {
+		ci, cj := changes[i], changes[j]
+		if rank(ci) != rank(cj) {
+			return rank(ci) < rank(cj)
+		}
+		if ci.File != cj.File {
+			return ci.File < cj.File
+		}
+		if ci.Name != cj.Name {
+			return ci.Name < cj.Name
+		}
+		return ci.Symbol < cj.Symbol
+	})
+
+	report := &Report{Bump: BumpNone, Changes: changes}
+	if report.Changes == nil {
+		report.Changes = []Change{}
+	}
+	for _, change := range changes {
+		switch rank(change) {
+		case 0:
+			report.Breaking++
+		case 1:
+			report.Added++
+		default:
+			report.Other++
+		}
+	}
+
+	switch {
+	case report.Breaking > 0:
+		report.Bump = BumpMajor
+	case report.Added > 0:
+		report.Bump = BumpMinor
+	case report.Other > 0:
+		report.Bump = BumpPatch
+	}
+	return report
+}
+
+// rank возвращает категорию изменения: 0 — нарушает совместимость,
+// 1 — добавляет возможности, 2 — прочие
+func rank(change Change) int {
+	switch {
+	case change.Breaking:
+		return 0
+	case change.Kind == ChangeAdded || change.additive:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// collectSymbols собирает публичные функции, методы, типы, поля и экспорты
+// модели по ключам сопоставления: идентификаторам символов при useIDs
+func collectSymbols(codeMap *models.CodeMap, useIDs bool) map[string]*symbol {
+	symbols := make(map[string]*symbol)
+	if codeMap == nil {
+		return symbols
+	}
+
+	for i := range codeMap.Files {
+		file := &codeMap.Files[i]
+		scope := packageScope(file)
+
+		// Идентификаторы функций и типов файла по имени: экспорт сопоставляется
+		// по идентификатору одноименного символа, чтобы перенос функции между
+		// файлами пакета не выглядел как удаление и добавление экспорта
+		ids := make(map[string]string)
+
+		for j := range file.Types {
+			typ := &file.Types[j]
+			if !typ.IsPublic {
+				continue
+			}
+			typeKey := scope + "|type|" + typ.Name
+			if useIDs {
+				typeKey = typ.ID
+				ids[typ.Name] = typ.ID
+			}
+			symbols[typeKey] = &symbol{
+				kind: SymbolType, name: typ.Name, file: file.Path, language: file.Language,
+				signature: strings.TrimSpace(typ.Kind + " " + typ.Name), typ: typ,
+			}
+			for _, field := range typ.Fields {
+				name, fieldType := splitDeclaration(field)
+				if !isExportedField(file.Language, name) {
+					continue
+				}
+				fieldKey := scope + "|field|" + typ.Name + "." + name
+				if useIDs {
+					fieldKey = typ.ID + "." + name
+				}
+				symbols[fieldKey] = &symbol{
+					kind: SymbolField, name: typ.Name + "." + name, file: file.Path, language: file.Language,
+					signature: strings.TrimSpace(name + " " + fieldType), fieldType: fieldType,
+				}
+			}
+		}
+
+		for j := range file.Methods {
+			method := &file.Methods[j]
+			if !method.IsPublic {
+				continue
+			}
+			kind, name := SymbolFunction, method.Name
+			if method.BelongsTo != "" {
+				kind, name = SymbolMethod, method.BelongsTo+"."+method.Name
+			}
+			methodKey := scope + "|" + kind + "|" + name
+			if useIDs {
+				methodKey = method.ID
+				if method.BelongsTo == "" {
+					ids[method.Name] = method.ID
+				}
+			}
+			symbols[methodKey] = &symbol{
+				kind: kind, name: name, file: file.Path, language: file.Language,
+				signature: method.Signature, method: method,
+			}
+		}
+
+		for _, export := range file.Exports {
+			name := exportName(export)
+			exportKey := scope + "|export|" + name
+			if id, ok := ids[name]; ok {
+				exportKey = id + "|export"
+			}
+			symbols[exportKey] = &symbol{
+				kind: SymbolExport, name: name, file: file.Path, language: file.Language, signature: export,
+			}
+		}
+	}
+	return symbols
+}
+
+// hasIDs проверяет, что символы модели имеют идентификаторы
+func hasIDs(codeMap *models.CodeMap) bool {
+	if codeMap == nil {
+		return false
+	}
+	for _, file := range codeMap.Files {
+		for _, method := range file.Methods {
+			if method.ID == "" {
+				return false
+			}
+		}
+		for _, typ := range file.Types {
+			if typ.ID == "" {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// compareSymbol сравнивает две версии символа и возвращает изменение, если оно есть
+func compareSymbol(oldSymbol, newSymbol *symbol) (Change, bool) {
+	change := Change{
+		Kind:   ChangeChanged,
+		Symbol: newSymbol.kind,
+		Name:   newSymbol.name,
+		File:   newSymbol.file,
+		Old:    oldSymbol.signature,
+		New:    newSymbol.signature,
+	}
+
+	switch {
+	case oldSymbol.method != nil && newSymbol.method != nil:
+		change.Details, change.Breaking, change.additive = compareSignatures(newSymbol.language, oldSymbol.method, newSymbol.method)
+	case oldSymbol.typ != nil && newSymbol.typ != nil:
+		if oldSymbol.typ.Kind != newSymbol.typ.Kind {
+			change.Details = []string{fmt.Sprintf("изменен вид типа: `%s` → `%s`", oldSymbol.typ.Kind, newSymbol.typ.Kind)}
+			change.Breaking = true
+		}
+	case oldSymbol.kind == SymbolField && oldSymbol.fieldType != newSymbol.fieldType:
+		change.Details = []string{fmt.Sprintf("изменен тип поля: `%s` → `%s`", orNone(oldSymbol.fieldType), orNone(newSymbol.fieldType))}
+		change.Breaking = true
+	}
+
+	if len(change.Details) == 0 {
+		return Change{}, false
+	}
+	return change, true
+}
+
+// dropCoveredExports убирает изменения экспортов, которые уже отражены
+// добавлением или удалением одноименной функции или типа в том же файле
+func dropCoveredExports(changes []Change) []Change {
+	covered := make(map[string]bool)
+	for _, change := range changes {
+		if change.Symbol != SymbolExport && change.Kind != ChangeChanged {
+			covered[change.Kind+"|"+change.File+"|"+change.Name] = true
+		}
+	}
+
+	result := changes[:0]
+	for _, change := range changes {
+		if change.Symbol == SymbolExport && covered[change.Kind+"|"+change.File+"|"+change.Name] {
+			continue
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+// packageScope возвращает область видимости символов файла: пакет Go
+// определяется директорией, модуль остальных языков — файлом
+func packageScope(file *models.FileStructure) string {
+	filePath := strings.ReplaceAll(file.Path, "\\", "/")
+	if strings.EqualFold(file.Language, "go") {
+		return "go:" + path.Dir(filePath)
+	}
+	return filePath
+}
+
+// exportName извлекает имя из строки экспорта вида "Name (kind)"
+func exportName(export string) string {
+	if i := strings.Index(export, " ("); i > 0 {
+		return export[:i]
+	}
+	return strings.TrimSpace(export)
+}
+
+// isExportedField проверяет, что поле видно за пределами пакета
+func isExportedField(language, name string) bool {
+	if name == "" {
+		return false
+	}
+	if strings.EqualFold(language, "go") {
+		return name[0] >= 'A' && name[0] <= 'Z'
+	}
+	return !strings.HasPrefix(name, "_") && !strings.HasPrefix(name, "#")
+}
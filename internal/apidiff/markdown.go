@@ -0,0 +1,94 @@
+package apidiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Названия видов символов в отчете
+var symbolTitles = map[string]string{
+	SymbolFunction: "функция",
+	SymbolMethod:   "метод",
+	SymbolType:     "тип",
+	SymbolField:    "поле",
+	SymbolExport:   "экспорт",
+}
+
+// Названия видов изменений в отчете
+var changeTitles = map[string]string{
+	ChangeAdded:   "добавлен",
+	ChangeRemoved: "удален",
+	ChangeChanged: "изменен",
+}
+
+// Markdown формирует отчет в формате Markdown для комментария к pull request
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+
+	sb.WriteString("## Изменения публичного API\n\n")
+	if r.Old != "" || r.New != "" {
+		sb.WriteString(fmt.Sprintf("Сравнение `%s` → `%s`\n\n", r.Old, r.New))
+	}
+	if len(r.Changes) == 0 {
+		sb.WriteString("Изменений публичного API нет.\n")
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("**Рекомендуемое изменение версии: %s** (нарушают совместимость: %d, новые возможности: %d, прочие: %d)\n",
+		r.Bump, r.Breaking, r.Added, r.Other))
+
+	sections := []struct {
+		title string
+		rank  int
+	}{
+		{"Нарушают совместимость", 0},
+		{"Новые возможности", 1},
+		{"Прочие изменения", 2},
+	}
+	for _, section := range sections {
+		var rows []Change
+		for _, change := range r.Changes {
+			if rank(change) == section.rank {
+				rows = append(rows, change)
+			}
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", section.title))
+		sb.WriteString("| Символ | Файл | Изменение |\n")
+		sb.WriteString("|--------|------|-----------|\n")
+		for _, change := range rows {
+			sb.WriteString(fmt.Sprintf("| %s `%s` | %s | %s |\n",
+				symbolTitles[change.Symbol], change.Name, change.File, describeChange(change)))
+		}
+	}
+
+	return sb.String()
+}
+
+// describeChange формирует текст ячейки с описанием изменения
+func describeChange(change Change) string {
+	parts := []string{changeTitles[change.Kind]}
+	switch change.Kind {
+	case ChangeAdded:
+		if change.New != "" {
+			parts = append(parts, "`"+escapeCell(change.New)+"`")
+		}
+	case ChangeRemoved:
+		if change.Old != "" {
+			parts = append(parts, "`"+escapeCell(change.Old)+"`")
+		}
+	default:
+		for _, detail := range change.Details {
+			parts = append(parts, escapeCell(detail))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// escapeCell экранирует символы, ломающие таблицу Markdown
+func escapeCell(text string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(text, "|", "\\|"), "\n", " ")
+}
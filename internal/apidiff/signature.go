@@ -0,0 +1,161 @@
+package apidiff
+
+import (
+	"fmt"
+	"strings"
+
+	"code-telescope/pkg/models"
+)
+
+// parameter представляет параметр функции из модели карты кода
+type parameter struct {
+	name     string
+	typ      string
+	optional bool
+}
+
+// compareSignatures сравнивает параметры и результаты двух версий функции
+// и возвращает подробности изменения, признак нарушения совместимости
+// и признак добавления необязательных параметров
+func compareSignatures(language string, oldMethod, newMethod *models.MethodInfo) ([]string, bool, bool) {
+	var details []string
+	breaking, additive := false, false
+
+	oldParams := parseParameters(methodParams(oldMethod))
+	newParams := parseParameters(methodParams(newMethod))
+
+	common := len(oldParams)
+	if len(newParams) < common {
+		common = len(newParams)
+	}
+	for i := 0; i < common; i++ {
+		oldParam, newParam := oldParams[i], newParams[i]
+		if oldParam.typ != newParam.typ && oldParam.typ != "" && newParam.typ != "" {
+			details = append(details, fmt.Sprintf("изменен тип параметра `%s`: `%s` → `%s`", newParam.name, oldParam.typ, newParam.typ))
+			breaking = true
+		}
+		if oldParam.name != newParam.name {
+			details = append(details, fmt.Sprintf("параметр `%s` переименован в `%s`", oldParam.name, newParam.name))
+			// В Python параметры передаются по имени, поэтому переименование нарушает совместимость
+			if strings.EqualFold(language, "python") {
+				breaking = true
+			}
+		}
+		if oldParam.optional && !newParam.optional {
+			details = append(details, fmt.Sprintf("параметр `%s` стал обязательным", newParam.name))
+			breaking = true
+		}
+	}
+
+	for _, removed := range oldParams[common:] {
+		details = append(details, fmt.Sprintf("удален параметр `%s`", removed.name))
+		breaking = true
+	}
+	for _, added := range newParams[common:] {
+		// В Go любой новый параметр меняет тип функции
+		if added.optional && !strings.EqualFold(language, "go") {
+			details = append(details, fmt.Sprintf("добавлен необязательный параметр `%s`", added.name))
+			additive = true
+			continue
+		}
+		details = append(details, fmt.Sprintf("добавлен обязательный параметр `%s`", added.name))
+		breaking = true
+	}
+
+	oldReturns := strings.Join(methodReturns(oldMethod), ", ")
+	newReturns := strings.Join(methodReturns(newMethod), ", ")
+	if oldReturns != newReturns {
+		details = append(details, fmt.Sprintf("изменен тип результата: `%s` → `%s`", orNone(oldReturns), orNone(newReturns)))
+		breaking = true
+	}
+
+	// Сигнатура изменилась способом, который не удалось разобрать по параметрам
+	if len(details) == 0 && normalizeSignature(oldMethod.Signature) != normalizeSignature(newMethod.Signature) {
+		details = append(details, "изменена сигнатура")
+		breaking = true
+	}
+
+	return details, breaking, additive
+}
+
+// methodParams возвращает параметры метода из доступного поля модели
+func methodParams(method *models.MethodInfo) []string {
+	if len(method.Params) > 0 {
+		return method.Params
+	}
+	return method.Parameters
+}
+
+// methodReturns возвращает непустые типы результатов метода
+func methodReturns(method *models.MethodInfo) []string {
+	returns := method.Returns
+	if len(returns) == 0 {
+		returns = method.ReturnType
+	}
+	var result []string
+	for _, value := range returns {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+// parseParameters разбирает параметры вида "name: type", "name = default",
+// "...rest" или "*args"
+func parseParameters(params []string) []parameter {
+	result := make([]parameter, 0, len(params))
+	for i, raw := range params {
+		raw = strings.TrimSpace(raw)
+		if raw == "" || raw == "self" || raw == "cls" {
+			continue
+		}
+
+		var param parameter
+		declaration := raw
+		if i := strings.Index(raw, "="); i >= 0 {
+			declaration = strings.TrimSpace(raw[:i])
+			param.optional = true
+		}
+		param.name, param.typ = splitDeclaration(declaration)
+		param.typ = strings.TrimSpace(strings.TrimSuffix(param.typ, "?"))
+
+		// Вариативные параметры и параметры с необязательным типом можно не передавать
+		if strings.HasPrefix(param.name, "...") || strings.HasPrefix(param.name, "*") ||
+			strings.HasPrefix(param.typ, "...") || strings.HasSuffix(param.name, "?") {
+			param.optional = true
+		}
+		param.name = strings.TrimRight(strings.TrimLeft(param.name, ".*"), "?")
+		// Параметр без имени (Go, ": int") называется по позиции
+		if param.name == "" {
+			param.name = fmt.Sprintf("#%d", i+1)
+		}
+		result = append(result, param)
+	}
+	return result
+}
+
+// splitDeclaration разделяет объявление "name: type" или "name type" на имя и тип
+func splitDeclaration(declaration string) (string, string) {
+	declaration = strings.TrimSpace(declaration)
+	if name, typ, ok := strings.Cut(declaration, ":"); ok {
+		return strings.TrimSpace(name), strings.TrimSpace(typ)
+	}
+	if name, typ, ok := strings.Cut(declaration, " "); ok {
+		return strings.TrimSpace(name), strings.TrimSpace(typ)
+	}
+	return declaration, ""
+}
+
+// normalizeSignature убирает различия в пробелах
+func normalizeSignature(signature string) string {
+	return strings.Join(strings.Fields(signature), " ")
+}
+
+// orNone возвращает заглушку для пустого значения
+func orNone(value string) string {
+	if value == "" {
+		return "нет"
+	}
+	return value
+}
@@ -0,0 +1,236 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/apidiff"
+	"code-telescope/internal/config"
+	"code-telescope/internal/parser"
+	"code-telescope/pkg/models"
+
+	// Регистрация парсеров языков
+	_ "code-telescope/internal/parser/languages"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// function создает публичную функцию модели
+func function(name, signature string, params, returns []string) models.MethodInfo {
+	return models.MethodInfo{Name: name, Signature: signature, Parameters: params, ReturnType: returns, IsPublic: true}
+}
+
+// findChange ищет изменение по имени символа
+func findChange(t *testing.T, report *apidiff.Report, name string) apidiff.Change {
+	for _, change := range report.Changes {
+		if change.Name == name {
+			return change
+		}
+	}
+	require.Failf(t, "изменение не найдено", "символ %s", name)
+	return apidiff.Change{}
+}
+
+// TestCompareClassifiesChanges проверяет классификацию изменений и рекомендуемую версию
+func TestCompareClassifiesChanges(t *testing.T) {
+	oldMap := models.NewCodeMap("demo", []models.FileStructure{
+		{
+			Path:     "pkg/config.go",
+			Language: "go",
+			Types: []models.TypeInfo{
+				{Name: "Config", Kind: "struct_type", IsPublic: true, Fields: []string{"Name: string", "Port: int"}},
+			},
+			Methods: []models.MethodInfo{
+				function("Load", "Load(path string) (*Config, error)", []string{"path: string"}, []string{"*Config", "error"}),
+				function("Remove", "Remove(x int)", []string{"x: int"}, nil),
+				function("Rename", "Rename(from string)", []string{"from: string"}, nil),
+			},
+		},
+		{
+			Path:     "src/api.js",
+			Language: "javascript",
+			Methods:  []models.MethodInfo{function("fetch", "fetch(url)", []string{"url"}, nil)},
+			Exports:  []string{"fetch (function)"},
+		},
+	})
+	newMap := models.NewCodeMap("demo", []models.FileStructure{
+		{
+			// Функция Rename перенесена в другой файл того же пакета Go
+			Path:     "pkg/rename.go",
+			Language: "go",
+			Methods:  []models.MethodInfo{function("Rename", "Rename(to string)", []string{"to: string"}, nil)},
+		},
+		{
+			Path:     "pkg/config.go",
+			Language: "go",
+			Types: []models.TypeInfo{
+				{Name: "Config", Kind: "struct_type", IsPublic: true, Fields: []string{"Name: string", "Port: string", "Host: string"}},
+			},
+			Methods: []models.MethodInfo{
+				function("Load", "Load(path string, strict bool) (*Config, error)", []string{"path: string", "strict: bool"}, []string{"*Config", "error"}),
+			},
+		},
+		{
+			Path:     "src/api.js",
+			Language: "javascript",
+			Methods: []models.MethodInfo{
+				function("fetch", "fetch(url, options = {})", []string{"url", "options = {}"}, nil),
+				function("post", "post(url)", []string{"url"}, nil),
+			},
+			Exports: []string{"fetch (function)", "post (function)"},
+		},
+	})
+
+	report := apidiff.Compare(oldMap, newMap)
+
+	assert.Equal(t, apidiff.BumpMajor, report.Bump)
+	assert.Equal(t, 3, report.Breaking)
+	assert.Equal(t, 3, report.Added)
+	assert.Equal(t, 1, report.Other)
+	require.Len(t, report.Changes, 7, "экспорт post учтен вместе с функцией")
+
+	removed := findChange(t, report, "Remove")
+	assert.Equal(t, apidiff.ChangeRemoved, removed.Kind)
+	assert.True(t, removed.Breaking)
+
+	load := findChange(t, report, "Load")
+	assert.True(t, load.Breaking)
+	assert.Equal(t, []string{"добавлен обязательный параметр `strict`"}, load.Details)
+
+	port := findChange(t, report, "Config.Port")
+	assert.Equal(t, apidiff.SymbolField, port.Symbol)
+	assert.True(t, port.Breaking)
+
+	fetch := findChange(t, report, "fetch")
+	assert.False(t, fetch.Breaking, "необязательный параметр не нарушает совместимость")
+
+	rename := findChange(t, report, "Rename")
+	assert.False(t, rename.Breaking, "в Go переименование параметра не нарушает совместимость")
+	assert.Equal(t, "pkg/rename.go", rename.File)
+
+	assert.True(t, report.Changes[0].Breaking, "нарушающие совместимость изменения идут первыми")
+}
+
+// TestCompareBump проверяет рекомендуемое изменение версии без нарушений совместимости
+func TestCompareBump(t *testing.T) {
+	base := []models.FileStructure{{
+		Path:     "app.py",
+		Language: "python",
+		Methods:  []models.MethodInfo{function("greet", "greet(name)", []string{"name"}, nil)},
+	}}
+	codeMap := models.NewCodeMap("demo", base)
+
+	report := apidiff.Compare(codeMap, codeMap)
+	assert.Equal(t, apidiff.BumpNone, report.Bump)
+	assert.Empty(t, report.Changes)
+	assert.Contains(t, report.Markdown(), "Изменений публичного API нет")
+
+	added := models.NewCodeMap("demo", append(base, models.FileStructure{
+		Path:     "util.py",
+		Language: "python",
+		Methods:  []models.MethodInfo{function("slugify", "slugify(text)", []string{"text"}, nil)},
+	}))
+	report = apidiff.Compare(codeMap, added)
+	assert.Equal(t, apidiff.BumpMinor, report.Bump)
+	assert.Contains(t, report.Markdown(), "### Новые возможности")
+
+	renamed := models.NewCodeMap("demo", []models.FileStructure{{
+		Path:     "app.py",
+		Language: "python",
+		Methods:  []models.MethodInfo{function("greet", "greet(person)", []string{"person"}, nil)},
+	}})
+	report = apidiff.Compare(codeMap, renamed)
+	assert.Equal(t, apidiff.BumpMajor, report.Bump, "в Python параметры можно передавать по имени")
+}
+
+// TestCompareMatchesByID проверяет сопоставление символов по идентификаторам:
+// смена получателя метода Go с указателя на значение меняет набор методов
+// типа и считается удалением и добавлением метода
+func TestCompareMatchesByID(t *testing.T) {
+	method := func(id string) models.MethodInfo {
+		info := function("String", "String() string", nil, []string{"string"})
+		info.ID, info.BelongsTo, info.Kind = id, "Cart", "method"
+		return info
+	}
+	model := func(id string) *models.CodeMap {
+		return models.NewCodeMap("shop", []models.FileStructure{{
+			Path:     "cart.go",
+			Language: "Go",
+			Methods:  []models.MethodInfo{method(id)},
+		}})
+	}
+
+	report := apidiff.Compare(model("go:example.com/shop.Cart.String"), model("go:example.com/shop.(*Cart).String"))
+	require.Len(t, report.Changes, 2)
+	assert.Equal(t, apidiff.BumpMajor, report.Bump)
+
+	legacy := model("")
+	report = apidiff.Compare(legacy, model("go:example.com/shop.(*Cart).String"))
+	assert.Empty(t, report.Changes, "модели без идентификаторов сравниваются по именам")
+}
+
+// TestCompareIgnoresMoveWithinPackage проверяет, что перенос функции между
+// файлами одного пакета или модуля не отражается ни на функции, ни на ее
+// экспорте
+func TestCompareIgnoresMoveWithinPackage(t *testing.T) {
+	model := func(path, language, id string) *models.CodeMap {
+		run := function("Run", "Run(a)", []string{"a"}, nil)
+		run.ID = id
+		return models.NewCodeMap("shop", []models.FileStructure{
+			{Path: path, Language: language, Methods: []models.MethodInfo{run}, Exports: []string{"Run (function)"}},
+		})
+	}
+
+	for _, move := range [][4]string{
+		{"Go", "go:example.com/shop.Run", "run.go", "runner.go"},
+		{"JavaScript", "js:src/shop.Run", "src/shop.js", "src/shop.jsx"},
+	} {
+		report := apidiff.Compare(model(move[2], move[0], move[1]), model(move[3], move[0], move[1]))
+		assert.Empty(t, report.Changes, "%s → %s", move[2], move[3])
+		assert.Equal(t, apidiff.BumpNone, report.Bump)
+	}
+}
+
+// goModel разбирает исходный код Go парсером проекта и возвращает модель
+// карты кода из одного файла
+func goModel(t *testing.T, source string) *models.CodeMap {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "api.go")
+	require.NoError(t, os.WriteFile(path, []byte(source), 0644))
+
+	metadata, err := models.NewFileMetadata(path, dir)
+	require.NoError(t, err)
+	goParser, err := parser.NewLanguageFactory(config.DefaultConfig()).GetParserForFile(path)
+	require.NoError(t, err)
+	structure, err := goParser.Parse(metadata)
+	require.NoError(t, err)
+	return models.NewCodeMap("api", []models.FileStructure{models.ConvertToFileStructure(structure)})
+}
+
+// TestCompareGoParameterLists проверяет, что изменения в группах
+// параметров, вариативных параметрах и параметрах без имени, разобранных
+// парсером Go, нарушают совместимость
+func TestCompareGoParameterLists(t *testing.T) {
+	for _, change := range []struct {
+		name     string
+		old, new string
+	}{
+		{"Put", "func Put(key, value string) {}", "func Put(key, value, model string) {}"},
+		{"Put", "func Put(key, value string) {}", "func Put(key string, value int) {}"},
+		{"Log", "func Log(f string, args ...int) {}", "func Log(f string, args ...string) {}"},
+		{"Log", "func Log(f string, args []int) {}", "func Log(f string, args ...int) {}"},
+		{"Handle", "func Handle(int, string) {}", "func Handle(int, error) {}"},
+		{"Handle", "func Handle(int, string) {}", "func Handle(int) {}"},
+	} {
+		report := apidiff.Compare(goModel(t, "package api\n\n"+change.old+"\n"), goModel(t, "package api\n\n"+change.new+"\n"))
+		require.Len(t, report.Changes, 1, "%s → %s", change.old, change.new)
+		assert.Equal(t, change.name, report.Changes[0].Name)
+		assert.True(t, report.Changes[0].Breaking, "%s → %s", change.old, change.new)
+		assert.Equal(t, apidiff.BumpMajor, report.Bump)
+	}
+
+	same := "package api\n\nfunc Put(key, value string, opts ...int) {}\n\nfunc Handle(int, string) {}\n"
+	assert.Empty(t, apidiff.Compare(goModel(t, same), goModel(t, same)).Changes)
+}
@@ -0,0 +1,186 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Имя файла с описаниями внутри директории кэша
+const descriptionsFileName = "descriptions.json"
+
+// Версия формата файла кэша. При изменении формата старый кэш игнорируется.
+const formatVersion = 1
+
+// Entry представляет сохраненное описание символа
+type Entry struct {
+	// Описание, полученное от ЛЛМ
+	Description string `json:"description"`
+
+	// Модель, сгенерировавшая описание
+	Model string `json:"model,omitempty"`
+
+	// Время сохранения описания
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Stats содержит статистику кэша
+type Stats struct {
+	// Путь к файлу кэша
+	Path string
+
+	// Количество сохраненных описаний
+	Entries int
+
+	// Размер файла кэша в байтах
+	Size int64
+
+	// Попадания и промахи с момента открытия кэша
+	Hits   int
+	Misses int
+}
+
+// cacheFile представляет содержимое файла кэша
+type cacheFile struct {
+	Version int              `json:"version"`
+	Entries map[string]Entry `json:"entries"`
+}
+
+// DescriptionCache хранит описания символов, полученные от ЛЛМ, чтобы не
+// запрашивать их повторно для неизмененного кода
+type DescriptionCache struct {
+	mu      sync.Mutex
+	path    string
+	entries map[string]Entry
+	dirty   bool
+	hits    int
+	misses  int
+}
+
+// Open открывает кэш описаний в указанной директории.
+// Если файла кэша нет или он создан другой версией программы, кэш пуст.
+func Open(dir string) (*DescriptionCache, error) {
+	c := &DescriptionCache{
+		path:    filepath.Join(dir, descriptionsFileName),
+		entries: make(map[string]Entry),
+	}
+
+	data, err := os.ReadFile(c.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения кэша описаний: %w", err)
+	}
+
+	var file cacheFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("поврежден файл кэша описаний %s: %w", c.path, err)
+	}
+	if file.Version == formatVersion && file.Entries != nil {
+		c.entries = file.Entries
+	}
+
+	return c, nil
+}
+
+// Key формирует ключ кэша из частей: провайдера, модели, языка, сигнатуры,
+// исходного кода символа и т.д. Любое изменение частей дает новый ключ.
+func Key(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get возвращает описание по ключу
+func (c *DescriptionCache) Get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return "", false
+	}
+	c.hits++
+	return entry.Description, true
+}
+
+// Put сохраняет описание по ключу
+func (c *DescriptionCache) Put(key, description, model string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = Entry{
+		Description: description,
+		Model:       model,
+		CreatedAt:   time.Now().UTC().Truncate(time.Second),
+	}
+	c.dirty = true
+}
+
+// Save записывает кэш на диск, если он изменился
+func (c *DescriptionCache) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.dirty {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(cacheFile{Version: formatVersion, Entries: c.entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации кэша описаний: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("ошибка создания директории кэша: %w", err)
+	}
+
+	// Запись через временный файл, чтобы прерванный процесс не повредил кэш
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("ошибка записи кэша описаний: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return fmt.Errorf("ошибка записи кэша описаний: %w", err)
+	}
+
+	c.dirty = false
+	return nil
+}
+
+// Stats возвращает статистику кэша
+func (c *DescriptionCache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	stats := Stats{
+		Path:    c.path,
+		Entries: len(c.entries),
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+	if info, err := os.Stat(c.path); err == nil {
+		stats.Size = info.Size()
+	}
+	return stats
+}
+
+// Clear удаляет файл кэша описаний из указанной директории
+func Clear(dir string) error {
+	err := os.Remove(filepath.Join(dir, descriptionsFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("ошибка удаления кэша описаний: %w", err)
+	}
+	return nil
+}
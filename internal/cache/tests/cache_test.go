@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"testing"
+
+	"code-telescope/internal/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDescriptionCachePersists проверяет сохранение описаний между запусками
+func TestDescriptionCachePersists(t *testing.T) {
+	dir := t.TempDir()
+	key := cache.Key("openai", "gpt-4", "ru", "main.go", "Run() error", "func Run() error { return nil }")
+
+	c, err := cache.Open(dir)
+	require.NoError(t, err)
+	_, ok := c.Get(key)
+	assert.False(t, ok)
+
+	c.Put(key, "Запускает приложение", "gpt-4")
+	require.NoError(t, c.Save())
+
+	reopened, err := cache.Open(dir)
+	require.NoError(t, err)
+	description, ok := reopened.Get(key)
+	assert.True(t, ok)
+	assert.Equal(t, "Запускает приложение", description)
+
+	stats := reopened.Stats()
+	assert.Equal(t, 1, stats.Entries)
+	assert.Equal(t, 1, stats.Hits)
+	assert.Positive(t, stats.Size)
+
+	require.NoError(t, cache.Clear(dir))
+	cleared, err := cache.Open(dir)
+	require.NoError(t, err)
+	assert.Equal(t, 0, cleared.Stats().Entries)
+}
+
+// TestKeyDependsOnAllParts проверяет, что изменение любой части дает новый ключ
+func TestKeyDependsOnAllParts(t *testing.T) {
+	base := cache.Key("openai", "gpt-4", "func A() {}")
+
+	assert.Equal(t, base, cache.Key("openai", "gpt-4", "func A() {}"))
+	assert.NotEqual(t, base, cache.Key("openai", "gpt-4o", "func A() {}"))
+	assert.NotEqual(t, base, cache.Key("openai", "gpt-4", "func A() { x() }"))
+	assert.NotEqual(t, cache.Key("ab", "c"), cache.Key("a", "bc"))
+}
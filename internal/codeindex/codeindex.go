@@ -0,0 +1,203 @@
+package codeindex
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"code-telescope/internal/depgraph"
+	"code-telescope/pkg/models"
+)
+
+// Index содержит модель карты кода и построенные по ней индексы для запросов:
+// поиск файла по пути, поиск символов и окрестности в графе зависимостей.
+// Индекс не изменяется после создания, поэтому его можно читать из нескольких горутин.
+type Index struct {
+	codeMap *models.CodeMap
+	files   map[string]*models.FileStructure
+	graph   *depgraph.Graph
+}
+
+// FileSummary представляет файл в списке файлов
+type FileSummary struct {
+	Path      string `json:"path"`
+	Language  string `json:"language"`
+	LineCount int    `json:"line_count"`
+	Methods   int    `json:"methods"`
+	Types     int    `json:"types"`
+}
+
+// Symbol представляет найденный символ: тип, функцию или метод
+type Symbol struct {
+	ID          string          `json:"id,omitempty"`
+	File        string          `json:"file"`
+	Name        string          `json:"name"`
+	Kind        string          `json:"kind"`
+	BelongsTo   string          `json:"belongs_to,omitempty"`
+	Signature   string          `json:"signature,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Position    models.Position `json:"position"`
+}
+
+// Dependencies представляет окрестность файла в графе зависимостей
+type Dependencies struct {
+	File         string               `json:"file"`
+	Depth        int                  `json:"depth"`
+	Dependencies []string             `json:"dependencies"`
+	Dependents   []string             `json:"dependents"`
+	Nodes        []depgraph.Neighbour `json:"nodes"`
+	Edges        []depgraph.Edge      `json:"edges"`
+}
+
+// New строит индекс по модели карты кода
+func New(codeMap *models.CodeMap) *Index {
+	files := make(map[string]*models.FileStructure, len(codeMap.Files))
+	for i := range codeMap.Files {
+		files[NormalizePath(codeMap.Files[i].Path)] = &codeMap.Files[i]
+	}
+	return &Index{
+		codeMap: codeMap,
+		files:   files,
+		graph:   depgraph.Build(codeMap.Files),
+	}
+}
+
+// CodeMap возвращает модель, по которой построен индекс
+func (i *Index) CodeMap() *models.CodeMap {
+	return i.codeMap
+}
+
+// Files возвращает краткие сведения о файлах модели
+func (i *Index) Files() []FileSummary {
+	files := make([]FileSummary, 0, len(i.codeMap.Files))
+	for _, file := range i.codeMap.Files {
+		files = append(files, FileSummary{
+			Path:      file.Path,
+			Language:  file.Language,
+			LineCount: file.LineCount,
+			Methods:   len(file.Methods),
+			Types:     len(file.Types),
+		})
+	}
+	return files
+}
+
+// File возвращает структуру файла по относительному пути
+func (i *Index) File(filePath string) (*models.FileStructure, bool) {
+	file, ok := i.files[NormalizePath(filePath)]
+	return file, ok
+}
+
+// Symbols возвращает все типы, функции и методы модели в порядке файлов
+func (i *Index) Symbols() []Symbol {
+	var symbols []Symbol
+	for _, file := range i.codeMap.Files {
+		symbols = append(symbols, fileSymbols(file)...)
+	}
+	return symbols
+}
+
+// FindSymbol возвращает символы с указанным именем без учета регистра.
+// Имя может быть задано в виде Тип.метод или идентификатором символа.
+func (i *Index) FindSymbol(name string) []Symbol {
+	var found []Symbol
+	for _, symbol := range i.Symbols() {
+		if strings.EqualFold(symbol.Name, name) || strings.EqualFold(symbol.BelongsTo+"."+symbol.Name, name) || symbol.ID == name {
+			found = append(found, symbol)
+		}
+	}
+	return found
+}
+
+// Search ищет символы по имени или описанию без учета регистра и возвращает
+// не больше limit результатов. Совпадения по имени идут первыми, точные
+// совпадения имени выше частичных.
+func (i *Index) Search(query string, limit int) []Symbol {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return nil
+	}
+
+	var byName, byDescription []Symbol
+	for _, symbol := range i.Symbols() {
+		switch {
+		case strings.Contains(strings.ToLower(symbol.Name), query):
+			byName = append(byName, symbol)
+		case strings.Contains(strings.ToLower(symbol.Description), query):
+			byDescription = append(byDescription, symbol)
+		}
+	}
+
+	sort.SliceStable(byName, func(a, b int) bool {
+		return strings.EqualFold(byName[a].Name, query) && !strings.EqualFold(byName[b].Name, query)
+	})
+
+	results := append(byName, byDescription...)
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// Dependencies возвращает зависимости файла, зависящие от него файлы
+// и окрестность файла в графе зависимостей глубиной depth
+func (i *Index) Dependencies(filePath string, depth int) Dependencies {
+	filePath = NormalizePath(filePath)
+	nodes, edges := i.graph.Neighbourhood(filePath, depth)
+	result := Dependencies{
+		File:         filePath,
+		Depth:        depth,
+		Dependencies: i.graph.Dependencies(filePath),
+		Dependents:   i.graph.Dependents(filePath),
+		Nodes:        nodes,
+		Edges:        edges,
+	}
+
+	// Пустые списки сериализуются как [], а не null
+	if result.Dependencies == nil {
+		result.Dependencies = []string{}
+	}
+	if result.Dependents == nil {
+		result.Dependents = []string{}
+	}
+	if result.Nodes == nil {
+		result.Nodes = []depgraph.Neighbour{}
+	}
+	if result.Edges == nil {
+		result.Edges = []depgraph.Edge{}
+	}
+	return result
+}
+
+// fileSymbols возвращает типы, функции и методы файла
+func fileSymbols(file models.FileStructure) []Symbol {
+	symbols := make([]Symbol, 0, len(file.Types)+len(file.Methods))
+	for _, typ := range file.Types {
+		symbols = append(symbols, Symbol{
+			ID:          typ.ID,
+			File:        file.Path,
+			Name:        typ.Name,
+			Kind:        typ.Kind,
+			Description: typ.Description,
+			Position:    typ.Position,
+		})
+	}
+	for _, method := range file.Methods {
+		symbols = append(symbols, Symbol{
+			ID:          method.ID,
+			File:        file.Path,
+			Name:        method.Name,
+			Kind:        method.Kind,
+			BelongsTo:   method.BelongsTo,
+			Signature:   method.Signature,
+			Description: method.Description,
+			Position:    method.Position,
+		})
+	}
+	return symbols
+}
+
+// NormalizePath приводит путь файла к виду с прямыми слешами
+func NormalizePath(p string) string {
+	return path.Clean(strings.ReplaceAll(p, "\\", "/"))
+}
@@ -2,17 +2,23 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
-
-	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"regexp"
+	"strings"
 )
 
 // Config представляет основную конфигурацию приложения
 type Config struct {
-	FileSystem FileSystemConfig `yaml:"filesystem"`
-	Parser     ParserConfig     `yaml:"parser"`
-	LLM        LLMConfig        `yaml:"llm"`
-	Markdown   MarkdownConfig   `yaml:"markdown"`
+	FileSystem   FileSystemConfig   `yaml:"filesystem"`
+	Parser       ParserConfig       `yaml:"parser"`
+	LLM          LLMConfig          `yaml:"llm"`
+	Markdown     MarkdownConfig     `yaml:"markdown"`
+	Output       OutputConfig       `yaml:"output"`
+	Cache        CacheConfig        `yaml:"cache"`
+	Redaction    RedactionConfig    `yaml:"redaction"`
+	Descriptions DescriptionsConfig `yaml:"descriptions"`
 }
 
 // FileSystemConfig содержит настройки для модуля файловой системы
@@ -30,13 +36,192 @@ type ParserConfig struct {
 
 // LLMConfig содержит настройки для модуля взаимодействия с ЛЛМ
 type LLMConfig struct {
-	Provider    string  `yaml:"provider"`
-	Model       string  `yaml:"model"`
-	APIKey      string  `yaml:"api_key"`
-	Temperature float64 `yaml:"temperature"`
-	MaxTokens   int     `yaml:"max_tokens"`
-	BatchSize   int     `yaml:"batch_size"`
-	BatchDelay  int     `yaml:"batch_delay"`
+	Provider       string  `yaml:"provider"`
+	Model          string  `yaml:"model"`
+	APIKey         string  `yaml:"api_key"`
+	BaseURL        string  `yaml:"base_url"`
+	Temperature    float64 `yaml:"temperature"`
+	MaxTokens      int     `yaml:"max_tokens"`
+	BatchSize      int     `yaml:"batch_size"`
+	BatchDelay     int     `yaml:"batch_delay"`
+	OutputLanguage string  `yaml:"output_language"`
+	Describe       bool    `yaml:"describe"`
+
+	// Директория с файлами <язык>.yaml, переопределяющими встроенные
+	// шаблоны промптов (пусто — только встроенные шаблоны)
+	PromptTemplatesDir string `yaml:"prompt_templates_dir"`
+
+	// Дополнительные указания проекта, добавляемые к промптам
+	Instructions InstructionsConfig `yaml:"instructions"`
+
+	// Ограничения расхода за запуск (0 — без ограничения)
+	MaxBudgetTokens int     `yaml:"max_budget_tokens"`
+	MaxBudgetUSD    float64 `yaml:"max_budget_usd"`
+
+	// Цены моделей, дополняющие и переопределяющие DefaultLLMPrices
+	Prices []PriceConfig `yaml:"prices"`
+
+	// Размер контекста модели в токенах, в который укладывается промпт
+	// вместе с ответом (0 — по таблице DefaultLLMContextWindows)
+	ContextTokens int `yaml:"context_tokens"`
+
+	// Запись и воспроизведение ответов ЛЛМ
+	Replay ReplayConfig `yaml:"replay"`
+
+	// Резервные провайдеры в порядке очередности, к которым запрос переходит,
+	// если основной провайдер недоступен
+	Fallbacks []FallbackConfig `yaml:"fallbacks"`
+
+	// Отключение недоступного провайдера цепочки
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit_breaker"`
+
+	// Асинхронные пакетные задания провайдера вместо отдельных запросов
+	BatchAPI BatchAPIConfig `yaml:"batch_api"`
+
+	// Проверка качества описаний и повторные запросы
+	Quality QualityConfig `yaml:"quality"`
+}
+
+// FallbackConfig описывает резервного провайдера ЛЛМ. Если API ключ не указан,
+// он берется из переменной окружения <ПРОВАЙДЕР>_API_KEY (например,
+// OPENAI_API_KEY), а для провайдера основной модели — из llm.api_key.
+type FallbackConfig struct {
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+	APIKey   string `yaml:"api_key"`
+	BaseURL  string `yaml:"base_url"`
+}
+
+// ResolvedAPIKey возвращает API ключ резервного провайдера
+func (f FallbackConfig) ResolvedAPIKey(llm LLMConfig) string {
+	if f.APIKey != "" {
+		return f.APIKey
+	}
+	if key := os.Getenv(strings.ToUpper(f.Provider) + "_API_KEY"); key != "" {
+		return key
+	}
+	if f.Provider == llm.Provider {
+		return llm.APIKey
+	}
+	return ""
+}
+
+// InstructionsConfig содержит указания проекта для ЛЛМ: тон описаний,
+// глоссарий терминов в виде строк "термин — значение", термины, которых
+// следует избегать, и произвольный текст
+type InstructionsConfig struct {
+	Tone           string   `yaml:"tone"`
+	Glossary       []string `yaml:"glossary"`
+	ForbiddenTerms []string `yaml:"forbidden_terms"`
+	Extra          string   `yaml:"extra"`
+}
+
+// Empty сообщает, что указания не заданы
+func (i InstructionsConfig) Empty() bool {
+	return strings.TrimSpace(i.Tone) == "" && len(i.Glossary) == 0 && len(i.ForbiddenTerms) == 0 && strings.TrimSpace(i.Extra) == ""
+}
+
+// CircuitBreakerConfig задает, после скольких ошибок подряд провайдер цепочки
+// отключается и через сколько секунд ему снова отправляется пробный запрос
+type CircuitBreakerConfig struct {
+	FailureThreshold int `yaml:"failure_threshold"`
+	CooldownSeconds  int `yaml:"cooldown_seconds"`
+}
+
+// BatchAPIConfig включает отправку всех промптов генерации одним пакетным
+// заданием провайдера (OpenAI Batch API, Anthropic Message Batches). Такие
+// задания дешевле, но выполняются асинхронно, поэтому подходят для ночной
+// перегенерации всего проекта. Идентификатор задания сохраняется в StateDir,
+// и прерванный запуск продолжает ожидание результатов того же задания.
+type BatchAPIConfig struct {
+	Enabled                bool   `yaml:"enabled"`
+	PollIntervalSeconds    int    `yaml:"poll_interval_seconds"`
+	MaxPollIntervalSeconds int    `yaml:"max_poll_interval_seconds"`
+	StateDir               string `yaml:"state_dir"`
+}
+
+// QualityConfig задает проверку описаний, полученных от ЛЛМ: обрезанные,
+// малоинформативные описания, описания с несуществующими параметрами,
+// на другом языке или с остатками форматирования запрашиваются повторно
+// по одному с указанием недостатков, не больше MaxRetries раз
+type QualityConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	MaxRetries int  `yaml:"max_retries"`
+	MinWords   int  `yaml:"min_words"`
+}
+
+// ReplayConfig содержит настройки записи ответов ЛЛМ в кассету и их
+// воспроизведения без обращения к провайдеру
+type ReplayConfig struct {
+	Mode     string `yaml:"mode"`
+	Cassette string `yaml:"cassette"`
+}
+
+// PriceConfig задает цену модели ЛЛМ в долларах США за миллион токенов.
+// Модель сопоставляется по префиксу имени, поэтому "gpt-4o" подходит
+// и для "gpt-4o-2024-08-06"; пустой провайдер подходит для любого провайдера.
+type PriceConfig struct {
+	Provider string  `yaml:"provider"`
+	Model    string  `yaml:"model"`
+	Input    float64 `yaml:"input"`
+	Output   float64 `yaml:"output"`
+}
+
+// PriceFor возвращает цену модели: сначала ищется в llm.prices, затем
+// в DefaultLLMPrices. Из подходящих записей выбирается самый длинный префикс.
+func (c LLMConfig) PriceFor(provider, model string) (PriceConfig, bool) {
+	for _, prices := range [][]PriceConfig{c.Prices, DefaultLLMPrices} {
+		var best PriceConfig
+		found := false
+		for _, price := range prices {
+			if price.Provider != "" && price.Provider != provider {
+				continue
+			}
+			if !strings.HasPrefix(model, price.Model) || (found && len(price.Model) <= len(best.Model)) {
+				continue
+			}
+			best, found = price, true
+		}
+		if found {
+			return best, true
+		}
+	}
+	return PriceConfig{}, false
+}
+
+// ContextWindow задает размер контекста модели в токенах. Модель
+// сопоставляется по префиксу имени, как и в PriceConfig.
+type ContextWindow struct {
+	Provider string
+	Model    string
+	Tokens   int
+}
+
+// ContextWindowFor возвращает размер контекста модели: llm.context_tokens,
+// если он задан, иначе запись DefaultLLMContextWindows с самым длинным
+// подходящим префиксом, иначе DefaultContextTokens
+func (c LLMConfig) ContextWindowFor(provider, model string) int {
+	if c.ContextTokens > 0 {
+		return c.ContextTokens
+	}
+	best := ContextWindow{Tokens: DefaultContextTokens}
+	found := false
+	for _, window := range DefaultLLMContextWindows {
+		if window.Provider != "" && window.Provider != provider {
+			continue
+		}
+		if !strings.HasPrefix(model, window.Model) || (found && len(window.Model) <= len(best.Model)) {
+			continue
+		}
+		best, found = window, true
+	}
+	return best.Tokens
+}
+
+// PromptTokensFor возвращает бюджет промпта модели в токенах: размер
+// контекста за вычетом токенов, зарезервированных под ответ
+func (c LLMConfig) PromptTokensFor(provider, model string) int {
+	return max(c.ContextWindowFor(provider, model)-c.MaxTokens, 0)
 }
 
 // MarkdownConfig содержит настройки для модуля генерации Markdown
@@ -48,23 +233,87 @@ type MarkdownConfig struct {
 	CodeStyle               string `yaml:"code_style"`
 }
 
-// LoadConfig загружает конфигурацию из файла YAML
+// OutputConfig содержит настройки формата итогового документа
+type OutputConfig struct {
+	Format    string         `yaml:"format"`
+	MaxTokens int            `yaml:"max_tokens"`
+	Mode      string         `yaml:"mode"`
+	Regions   []RegionConfig `yaml:"regions"`
+	Links     LinksConfig    `yaml:"links"`
+}
+
+// RegionConfig описывает именованную область документа, в которую
+// выводится карта кода только указанной поддиректории проекта
+type RegionConfig struct {
+	Name string `yaml:"name"`
+	Path string `yaml:"path"`
+}
+
+// LinksConfig содержит настройки ссылок на исходный код символов
+type LinksConfig struct {
+	Mode        string `yaml:"mode"`
+	Forge       string `yaml:"forge"`
+	RepoURL     string `yaml:"repo_url"`
+	URLTemplate string `yaml:"url_template"`
+}
+
+// CacheConfig содержит настройки кэша описаний символов
+type CacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"`
+}
+
+// DescriptionsConfig задает описания символов, написанные вручную: файл
+// описаний по идентификаторам символов и аннотации telescope:describe
+// в документирующих комментариях. Такие описания заменяют описания от ЛЛМ
+// и сохраняются при повторной генерации.
+type DescriptionsConfig struct {
+	OverridesFile string `yaml:"overrides_file"`
+	Annotations   bool   `yaml:"annotations"`
+}
+
+// RedactionConfig содержит настройки скрытия секретов и персональных данных
+// в исходном коде перед отправкой в ЛЛМ
+type RedactionConfig struct {
+	Enabled bool `yaml:"enabled"`
+
+	// Не отправлять файлы с найденными секретами и завершать генерацию ошибкой
+	FailOnSecret bool `yaml:"fail_on_secret"`
+
+	// Строковые литералы длиной от EntropyMinLength с энтропией Шеннона не
+	// ниже EntropyThreshold бит на символ считаются секретами (0 — не проверять)
+	EntropyThreshold float64 `yaml:"entropy_threshold"`
+	EntropyMinLength int     `yaml:"entropy_min_length"`
+
+	// Отключенные встроенные правила и собственные регулярные выражения
+	DisabledRules []string           `yaml:"disabled_rules"`
+	Patterns      []RedactionPattern `yaml:"patterns"`
+
+	// Журнал скрытых секретов (относительно корня проекта)
+	AuditLog string `yaml:"audit_log"`
+}
+
+// RedactionPattern задает собственное правило поиска секретов. Если в
+// выражении есть группа, скрывается только текст первой группы.
+type RedactionPattern struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+// LoadConfig загружает конфигурацию из файла YAML поверх значений по умолчанию.
+// Ключи, отсутствующие в файле, сохраняют значения из DefaultConfig.
 func LoadConfig(configPath string) (*Config, error) {
-	data, err := os.ReadFile(configPath)
+	config := DefaultConfig()
+	keyLines, err := decodeFile(configPath, config)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка чтения файла конфигурации: %w", err)
-	}
-
-	var config Config
-	if err := yaml.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("ошибка парсинга YAML: %w", err)
+		return nil, err
 	}
 
-	if err := validateConfig(&config); err != nil {
-		return nil, fmt.Errorf("ошибка валидации конфигурации: %w", err)
+	if err := validateConfig(config); err != nil {
+		return nil, fmt.Errorf("ошибка валидации конфигурации: %w", withFileLines(err, configPath, keyLines))
 	}
 
-	return &config, nil
+	return config, nil
 }
 
 // DefaultConfig возвращает конфигурацию по умолчанию
@@ -87,13 +336,29 @@ func DefaultConfig() *Config {
 			MaxFileSize:         1048576, // 1MB
 		},
 		LLM: LLMConfig{
-			Provider:    "openai",
-			Model:       "gpt-4",
-			APIKey:      os.Getenv("LLM_API_KEY"),
-			Temperature: 0.3,
-			MaxTokens:   1000,
-			BatchSize:   5,
-			BatchDelay:  1,
+			Provider:       "openai",
+			Model:          "gpt-4",
+			APIKey:         os.Getenv("LLM_API_KEY"),
+			Temperature:    0.3,
+			MaxTokens:      1000,
+			BatchSize:      5,
+			BatchDelay:     1,
+			OutputLanguage: DefaultOutputLanguage,
+			Describe:       true,
+			CircuitBreaker: CircuitBreakerConfig{
+				FailureThreshold: DefaultFailureThreshold,
+				CooldownSeconds:  DefaultCooldownSeconds,
+			},
+			BatchAPI: BatchAPIConfig{
+				PollIntervalSeconds:    DefaultBatchPollInterval,
+				MaxPollIntervalSeconds: DefaultBatchMaxPollInterval,
+				StateDir:               DefaultBatchStateDir,
+			},
+			Quality: QualityConfig{
+				Enabled:    DefaultQualityEnabled,
+				MaxRetries: DefaultQualityMaxRetries,
+				MinWords:   DefaultQualityMinWords,
+			},
 		},
 		Markdown: MarkdownConfig{
 			IncludeTOC:              true,
@@ -102,30 +367,287 @@ func DefaultConfig() *Config {
 			GroupMethodsByType:      true,
 			CodeStyle:               "github",
 		},
+		Output: OutputConfig{
+			Format:    DefaultOutputFormat,
+			MaxTokens: DefaultOutputMaxTokens,
+			Mode:      DefaultOutputMode,
+			Links: LinksConfig{
+				Mode: DefaultLinksMode,
+			},
+		},
+		Cache: CacheConfig{
+			Enabled: DefaultCacheEnabled,
+			Dir:     DefaultCacheDir,
+		},
+		Redaction: RedactionConfig{
+			Enabled:          DefaultRedactionEnabled,
+			EntropyThreshold: DefaultEntropyThreshold,
+			EntropyMinLength: DefaultEntropyMinLength,
+			AuditLog:         DefaultRedactionAuditLog,
+		},
+		Descriptions: DescriptionsConfig{
+			OverridesFile: DefaultOverridesFile,
+			Annotations:   DefaultAnnotations,
+		},
 	}
 }
 
-// validateConfig проверяет корректность настроек конфигурации
+// validateConfig проверяет корректность всех секций конфигурации.
+// Возвращает *ValidationError со списком всех найденных ошибок.
 func validateConfig(cfg *Config) error {
+	verr := &ValidationError{}
+
+	// Проверка настроек файловой системы
+	validatePatterns(verr, "filesystem.include_patterns", cfg.FileSystem.IncludePatterns)
+	validatePatterns(verr, "filesystem.exclude_patterns", cfg.FileSystem.ExcludePatterns)
+	if cfg.FileSystem.MaxDepth < 1 {
+		verr.add("filesystem.max_depth", "максимальная глубина должна быть положительной, получено: %d", cfg.FileSystem.MaxDepth)
+	}
+
+	// Проверка настроек парсера
+	if cfg.Parser.MaxFileSize < 1 {
+		verr.add("parser.max_file_size", "максимальный размер файла должен быть положительным, получено: %d", cfg.Parser.MaxFileSize)
+	}
+
 	// Проверка настроек LLM
-	if cfg.LLM.Provider != "openai" && cfg.LLM.Provider != "anthropic" {
-		return fmt.Errorf("неподдерживаемый провайдер ЛЛМ: %s", cfg.LLM.Provider)
+	if cfg.LLM.Provider == "" || !isOneOf(cfg.LLM.Provider, SupportedLLMProviders) {
+		verr.add("llm.provider", "неподдерживаемый провайдер ЛЛМ: %q, допустимые значения: %s",
+			cfg.LLM.Provider, strings.Join(SupportedLLMProviders, ", "))
+	}
+
+	if cfg.LLM.Describe && strings.TrimSpace(cfg.LLM.Model) == "" {
+		verr.add("llm.model", "не указана модель ЛЛМ")
 	}
 
 	if cfg.LLM.Temperature < 0 || cfg.LLM.Temperature > 1 {
-		return fmt.Errorf("температура должна быть в диапазоне [0, 1], получено: %f", cfg.LLM.Temperature)
+		verr.add("llm.temperature", "температура должна быть в диапазоне [0, 1], получено: %g", cfg.LLM.Temperature)
+	}
+
+	if cfg.LLM.MaxTokens < 1 {
+		verr.add("llm.max_tokens", "максимальное количество токенов должно быть положительным, получено: %d", cfg.LLM.MaxTokens)
 	}
 
 	if cfg.LLM.BatchSize < 1 {
-		return fmt.Errorf("размер пакета должен быть положительным, получено: %d", cfg.LLM.BatchSize)
+		verr.add("llm.batch_size", "размер пакета должен быть положительным, получено: %d", cfg.LLM.BatchSize)
 	}
 
-	// Проверка настроек файловой системы
-	if cfg.FileSystem.MaxDepth < 1 {
-		return fmt.Errorf("максимальная глубина должна быть положительной, получено: %d", cfg.FileSystem.MaxDepth)
+	if cfg.LLM.BatchDelay < 0 {
+		verr.add("llm.batch_delay", "пауза между пакетами не может быть отрицательной, получено: %d", cfg.LLM.BatchDelay)
+	}
+
+	if cfg.LLM.MaxBudgetTokens < 0 {
+		verr.add("llm.max_budget_tokens", "бюджет токенов не может быть отрицательным, получено: %d", cfg.LLM.MaxBudgetTokens)
+	}
+
+	if cfg.LLM.ContextTokens < 0 {
+		verr.add("llm.context_tokens", "размер контекста не может быть отрицательным, получено: %d", cfg.LLM.ContextTokens)
+	} else if cfg.LLM.ContextTokens > 0 && cfg.LLM.ContextTokens <= cfg.LLM.MaxTokens {
+		verr.add("llm.context_tokens", "размер контекста должен быть больше max_tokens (%d), получено: %d", cfg.LLM.MaxTokens, cfg.LLM.ContextTokens)
+	}
+
+	if cfg.LLM.MaxBudgetUSD < 0 {
+		verr.add("llm.max_budget_usd", "бюджет в долларах не может быть отрицательным, получено: %g", cfg.LLM.MaxBudgetUSD)
+	}
+
+	for _, price := range cfg.LLM.Prices {
+		switch {
+		case strings.TrimSpace(price.Model) == "":
+			verr.add("llm.prices", "у цены не указана модель")
+		case price.Input < 0 || price.Output < 0:
+			verr.add("llm.prices", "цена модели %s не может быть отрицательной", price.Model)
+		}
+	}
+
+	if _, ok := cfg.LLM.PriceFor(cfg.LLM.Provider, cfg.LLM.Model); cfg.LLM.MaxBudgetUSD > 0 && !ok {
+		verr.add("llm.max_budget_usd", "цена модели %s неизвестна, укажите ее в llm.prices", cfg.LLM.Model)
+	}
+
+	if !isOneOf(cfg.LLM.Replay.Mode, SupportedReplayModes) {
+		verr.add("llm.replay.mode", "неподдерживаемый режим кассеты: %s, допустимые значения: %s",
+			cfg.LLM.Replay.Mode, strings.Join(SupportedReplayModes, ", "))
+	}
+
+	if cfg.LLM.Replay.Mode != "" && strings.TrimSpace(cfg.LLM.Replay.Cassette) == "" {
+		verr.add("llm.replay.cassette", "не указан файл кассеты для режима %s", cfg.LLM.Replay.Mode)
+	}
+
+	validateBaseURL(verr, "llm.base_url", cfg.LLM.BaseURL)
+	for i, fallback := range cfg.LLM.Fallbacks {
+		if !isOneOf(fallback.Provider, SupportedLLMProviders) || fallback.Provider == "" {
+			verr.add("llm.fallbacks", "резервный провайдер %d: неподдерживаемый провайдер ЛЛМ: %q, допустимые значения: %s",
+				i+1, fallback.Provider, strings.Join(SupportedLLMProviders, ", "))
+		}
+		if strings.TrimSpace(fallback.Model) == "" {
+			verr.add("llm.fallbacks", "резервный провайдер %d: не указана модель", i+1)
+		}
+		validateBaseURL(verr, "llm.fallbacks", fallback.BaseURL)
+	}
+
+	if cfg.LLM.CircuitBreaker.FailureThreshold < 1 {
+		verr.add("llm.circuit_breaker.failure_threshold", "количество ошибок должно быть положительным, получено: %d", cfg.LLM.CircuitBreaker.FailureThreshold)
+	}
+	if cfg.LLM.CircuitBreaker.CooldownSeconds < 0 {
+		verr.add("llm.circuit_breaker.cooldown_seconds", "пауза не может быть отрицательной, получено: %d", cfg.LLM.CircuitBreaker.CooldownSeconds)
+	}
+
+	if cfg.LLM.Quality.MaxRetries < 0 {
+		verr.add("llm.quality.max_retries", "количество повторных запросов не может быть отрицательным, получено: %d", cfg.LLM.Quality.MaxRetries)
+	}
+	if cfg.LLM.Quality.MinWords < 0 {
+		verr.add("llm.quality.min_words", "количество слов не может быть отрицательным, получено: %d", cfg.LLM.Quality.MinWords)
+	}
+
+	if batch := cfg.LLM.BatchAPI; batch.Enabled {
+		if batch.PollIntervalSeconds < 1 {
+			verr.add("llm.batch_api.poll_interval_seconds", "интервал опроса должен быть положительным, получено: %d", batch.PollIntervalSeconds)
+		}
+		if batch.MaxPollIntervalSeconds < batch.PollIntervalSeconds {
+			verr.add("llm.batch_api.max_poll_interval_seconds", "максимальный интервал опроса меньше начального: %d < %d",
+				batch.MaxPollIntervalSeconds, batch.PollIntervalSeconds)
+		}
+		if strings.TrimSpace(batch.StateDir) == "" {
+			verr.add("llm.batch_api.state_dir", "не указана директория состояния пакетных заданий")
+		}
+	}
+
+	if !isOneOf(cfg.LLM.OutputLanguage, SupportedOutputLanguages) {
+		verr.add("llm.output_language", "неподдерживаемый язык описаний: %s, допустимые значения: %s",
+			cfg.LLM.OutputLanguage, strings.Join(SupportedOutputLanguages, ", "))
+	}
+
+	// Проверка настроек Markdown
+	if cfg.Markdown.MaxMethodDescriptionLen < 0 {
+		verr.add("markdown.max_method_description_length", "максимальная длина описания не может быть отрицательной, получено: %d", cfg.Markdown.MaxMethodDescriptionLen)
+	}
+
+	if !isOneOf(cfg.Markdown.CodeStyle, SupportedCodeStyles) {
+		verr.add("markdown.code_style", "неподдерживаемый стиль кода: %s, допустимые значения: %s",
+			cfg.Markdown.CodeStyle, strings.Join(SupportedCodeStyles, ", "))
 	}
 
-	// Другие проверки...
+	// Проверка настроек вывода
+	if !isSupportedOutputFormat(cfg.Output.Format) {
+		verr.add("output.format", "неподдерживаемый формат вывода: %s, допустимые значения: %s",
+			cfg.Output.Format, strings.Join(SupportedOutputFormats, ", "))
+	}
+
+	if cfg.Output.MaxTokens < 0 {
+		verr.add("output.max_tokens", "бюджет токенов вывода не может быть отрицательным, получено: %d", cfg.Output.MaxTokens)
+	}
+
+	if !isOneOf(cfg.Output.Mode, SupportedOutputModes) {
+		verr.add("output.mode", "неподдерживаемый режим вывода: %s, допустимые значения: %s",
+			cfg.Output.Mode, strings.Join(SupportedOutputModes, ", "))
+	}
+
+	regionNames := make(map[string]bool, len(cfg.Output.Regions))
+	for _, region := range cfg.Output.Regions {
+		switch {
+		case region.Name == "":
+			verr.add("output.regions", "у области вывода не указано имя (путь: %s)", region.Path)
+		case regionNames[region.Name]:
+			verr.add("output.regions", "область вывода %s указана несколько раз", region.Name)
+		case filepath.IsAbs(region.Path) || strings.HasPrefix(filepath.ToSlash(filepath.Clean(region.Path)), "../"):
+			verr.add("output.regions", "путь области %s должен быть относительным путем внутри проекта: %s", region.Name, region.Path)
+		}
+		regionNames[region.Name] = true
+	}
+
+	if !isOneOf(cfg.Output.Links.Mode, SupportedLinkModes) {
+		verr.add("output.links.mode", "неподдерживаемый режим ссылок: %s, допустимые значения: %s",
+			cfg.Output.Links.Mode, strings.Join(SupportedLinkModes, ", "))
+	}
+
+	if !isOneOf(cfg.Output.Links.Forge, SupportedForges) {
+		verr.add("output.links.forge", "неподдерживаемый тип хостинга репозитория: %s, допустимые значения: %s",
+			cfg.Output.Links.Forge, strings.Join(SupportedForges, ", "))
+	}
+
+	if cfg.Output.Links.RepoURL != "" {
+		parsed, err := url.Parse(cfg.Output.Links.RepoURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			verr.add("output.links.repo_url", "URL репозитория должен быть абсолютным http(s) адресом: %s", cfg.Output.Links.RepoURL)
+		}
+	}
+
+	if cfg.Output.Links.URLTemplate != "" && !strings.Contains(cfg.Output.Links.URLTemplate, "{path}") {
+		verr.add("output.links.url_template", "шаблон ссылки должен содержать подстановку {path}")
+	}
+
+	// Проверка настроек кэша
+	if cfg.Cache.Enabled && strings.TrimSpace(cfg.Cache.Dir) == "" {
+		verr.add("cache.dir", "не указана директория кэша")
+	}
+
+	// Проверка настроек скрытия секретов
+	if cfg.Redaction.EntropyThreshold < 0 {
+		verr.add("redaction.entropy_threshold", "порог энтропии не может быть отрицательным, получено: %g", cfg.Redaction.EntropyThreshold)
+	}
+	if cfg.Redaction.EntropyMinLength < 1 {
+		verr.add("redaction.entropy_min_length", "минимальная длина строки должна быть положительной, получено: %d", cfg.Redaction.EntropyMinLength)
+	}
+	for i, pattern := range cfg.Redaction.Patterns {
+		if strings.TrimSpace(pattern.Name) == "" {
+			verr.add("redaction.patterns", "правило %d: не указано имя", i+1)
+		}
+		if _, err := regexp.Compile(pattern.Pattern); err != nil || pattern.Pattern == "" {
+			verr.add("redaction.patterns", "правило %d: некорректное регулярное выражение %q", i+1, pattern.Pattern)
+		}
+	}
+	if (cfg.Redaction.Enabled || cfg.Redaction.FailOnSecret) && strings.TrimSpace(cfg.Redaction.AuditLog) == "" {
+		verr.add("redaction.audit_log", "не указан файл журнала скрытых секретов")
+	}
+
+	return verr.errorOrNil()
+}
 
-	return nil
+// validateBaseURL проверяет, что адрес API провайдера является абсолютным http(s) адресом
+func validateBaseURL(verr *ValidationError, key, baseURL string) {
+	if baseURL == "" {
+		return
+	}
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		verr.add(key, "адрес API должен быть абсолютным http(s) адресом: %s", baseURL)
+	}
+}
+
+// validatePatterns проверяет, что шаблоны файлов непустые и корректные
+func validatePatterns(verr *ValidationError, key string, patterns []string) {
+	for _, pattern := range patterns {
+		if strings.TrimSpace(pattern) == "" {
+			verr.add(key, "пустой шаблон")
+			continue
+		}
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			verr.add(key, "некорректный шаблон %q: %v", pattern, err)
+		}
+	}
+}
+
+// RegisterLLMProvider добавляет имя провайдера в список допустимых значений llm.provider.
+// Вызывается при регистрации провайдера в пакете llm.
+func RegisterLLMProvider(name string) {
+	if name != "" && !isOneOf(name, SupportedLLMProviders) {
+		SupportedLLMProviders = append(SupportedLLMProviders, name)
+	}
+}
+
+// isSupportedOutputFormat проверяет, входит ли формат в список поддерживаемых.
+// Пустое значение означает формат по умолчанию.
+func isSupportedOutputFormat(format string) bool {
+	return isOneOf(format, SupportedOutputFormats)
+}
+
+// isOneOf проверяет, что значение пустое или входит в список допустимых
+func isOneOf(value string, allowed []string) bool {
+	if value == "" {
+		return true
+	}
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
 }
@@ -17,12 +17,102 @@ const (
 	DefaultBatchSize   = 5
 	DefaultBatchDelay  = 1
 
+	DefaultOutputLanguage = OutputLanguageRussian
+
+	// Размер контекста модели, отсутствующей в DefaultLLMContextWindows
+	DefaultContextTokens = 8192
+
+	// Отключение провайдера после 3 ошибок подряд на 60 секунд
+	DefaultFailureThreshold = 3
+	DefaultCooldownSeconds  = 60
+
+	// Опрос пакетных заданий: с 30 секунд с удвоением до 10 минут
+	DefaultBatchPollInterval    = 30
+	DefaultBatchMaxPollInterval = 600
+	DefaultBatchStateDir        = ".code-telescope/batches" // Относительно корня проекта
+
+	// Проверка описаний: один повторный запрос, не меньше 3 слов
+	DefaultQualityEnabled    = true
+	DefaultQualityMaxRetries = 1
+	DefaultQualityMinWords   = 3
+
 	// Markdown
 	DefaultIncludeTOC              = true
 	DefaultIncludeFileInfo         = true
 	DefaultMaxMethodDescriptionLen = 200
 	DefaultGroupMethodsByType      = true
 	DefaultCodeStyle               = "github"
+
+	// Output
+	DefaultOutputFormat    = OutputFormatMarkdown
+	DefaultOutputMaxTokens = 0 // Без ограничения
+	DefaultOutputMode      = OutputModeFile
+	DefaultLinksMode       = LinkModeRelative
+
+	// Cache
+	DefaultCacheEnabled = true
+	DefaultCacheDir     = ".code-telescope/cache" // Относительно корня проекта
+
+	// Redaction
+	DefaultRedactionEnabled  = true
+	DefaultEntropyThreshold  = 4.0
+	DefaultEntropyMinLength  = 20
+	DefaultRedactionAuditLog = ".code-telescope/redaction.log" // Относительно корня проекта
+
+	// Descriptions
+	DefaultOverridesFile = ".code-telescope/descriptions.yaml" // Относительно корня проекта
+	DefaultAnnotations   = true
+)
+
+// Языки промптов, описаний и заголовков документа
+const (
+	OutputLanguageRussian = "ru"
+	OutputLanguageEnglish = "en"
+	OutputLanguageGerman  = "de"
+)
+
+// Режимы кассеты ответов ЛЛМ
+const (
+	// ReplayModeRecord отправляет запросы провайдеру и сохраняет ответы в кассету
+	ReplayModeRecord = "record"
+	// ReplayModeReplay берет ответы из кассеты и не обращается к провайдеру
+	ReplayModeReplay = "replay"
+)
+
+// Режимы ссылок на исходный код
+const (
+	// LinkModeNone отключает ссылки
+	LinkModeNone = "none"
+	// LinkModeRelative ссылки вида path#L10-L42 относительно корня проекта
+	LinkModeRelative = "relative"
+	// LinkModeForge постоянные ссылки на хостинг репозитория, привязанные к коммиту
+	LinkModeForge = "forge"
+)
+
+// Поддерживаемые хостинги репозиториев
+const (
+	ForgeGitHub = "github"
+	ForgeGitLab = "gitlab"
+	ForgeGitea  = "gitea"
+)
+
+// Форматы итогового документа
+const (
+	// OutputFormatMarkdown подробная Markdown-карта кода
+	OutputFormatMarkdown = "markdown"
+	// OutputFormatLLMs компактный формат в стиле llms.txt для контекста ЛЛМ
+	OutputFormatLLMs = "llms"
+	// OutputFormatJSON модель карты кода в JSON для команды render и внешних инструментов
+	OutputFormatJSON = "json"
+)
+
+// Режимы сохранения итогового документа
+const (
+	// OutputModeFile перезаписывает выходной файл целиком
+	OutputModeFile = "file"
+	// OutputModeInPlace обновляет только области между маркерами
+	// <!-- code-telescope:start --> и <!-- code-telescope:end --> в существующем файле
+	OutputModeInPlace = "inplace"
 )
 
 // Константы для шаблонов включения/исключения файлов
@@ -44,6 +134,79 @@ var (
 	SupportedLLMProviders = []string{
 		"openai",
 		"anthropic",
+		"ollama",
+	}
+
+	// Цены моделей по умолчанию в долларах США за миллион токенов ввода и вывода
+	DefaultLLMPrices = []PriceConfig{
+		{Provider: "openai", Model: "gpt-4", Input: 30, Output: 60},
+		{Provider: "openai", Model: "gpt-4-turbo", Input: 10, Output: 30},
+		{Provider: "openai", Model: "gpt-4o", Input: 2.5, Output: 10},
+		{Provider: "openai", Model: "gpt-4o-mini", Input: 0.15, Output: 0.6},
+		{Provider: "openai", Model: "gpt-4.1", Input: 2, Output: 8},
+		{Provider: "openai", Model: "gpt-4.1-mini", Input: 0.4, Output: 1.6},
+		{Provider: "openai", Model: "gpt-3.5-turbo", Input: 0.5, Output: 1.5},
+		{Provider: "anthropic", Model: "claude-3-haiku", Input: 0.25, Output: 1.25},
+		{Provider: "anthropic", Model: "claude-3-5-haiku", Input: 0.8, Output: 4},
+		{Provider: "anthropic", Model: "claude-3-5-sonnet", Input: 3, Output: 15},
+		{Provider: "anthropic", Model: "claude-3-7-sonnet", Input: 3, Output: 15},
+		{Provider: "anthropic", Model: "claude-sonnet-4", Input: 3, Output: 15},
+		{Provider: "anthropic", Model: "claude-3-opus", Input: 15, Output: 75},
+		{Provider: "anthropic", Model: "claude-opus-4", Input: 15, Output: 75},
+		// Локальные модели Ollama бесплатны
+		{Provider: "ollama", Model: "", Input: 0, Output: 0},
+	}
+
+	// Размеры контекста моделей по умолчанию в токенах
+	DefaultLLMContextWindows = []ContextWindow{
+		{Provider: "openai", Model: "gpt-4", Tokens: 8192},
+		{Provider: "openai", Model: "gpt-4-turbo", Tokens: 128000},
+		{Provider: "openai", Model: "gpt-4o", Tokens: 128000},
+		{Provider: "openai", Model: "gpt-4.1", Tokens: 1047576},
+		{Provider: "openai", Model: "gpt-3.5-turbo", Tokens: 16385},
+		{Provider: "anthropic", Model: "claude-", Tokens: 200000},
+		// Ollama по умолчанию ограничивает контекст, если в модели не задан num_ctx
+		{Provider: "ollama", Model: "", Tokens: 4096},
+	}
+
+	// Поддерживаемые режимы кассеты ответов ЛЛМ
+	SupportedReplayModes = []string{
+		ReplayModeRecord,
+		ReplayModeReplay,
+	}
+
+	// Поддерживаемые языки описаний
+	SupportedOutputLanguages = []string{
+		OutputLanguageRussian,
+		OutputLanguageEnglish,
+		OutputLanguageGerman,
+	}
+
+	// Поддерживаемые форматы вывода
+	SupportedOutputFormats = []string{
+		OutputFormatMarkdown,
+		OutputFormatLLMs,
+		OutputFormatJSON,
+	}
+
+	// Поддерживаемые режимы сохранения
+	SupportedOutputModes = []string{
+		OutputModeFile,
+		OutputModeInPlace,
+	}
+
+	// Поддерживаемые режимы ссылок на исходный код
+	SupportedLinkModes = []string{
+		LinkModeNone,
+		LinkModeRelative,
+		LinkModeForge,
+	}
+
+	// Поддерживаемые хостинги репозиториев
+	SupportedForges = []string{
+		ForgeGitHub,
+		ForgeGitLab,
+		ForgeGitea,
 	}
 
 	// Поддерживаемые стили кода в Markdown
@@ -0,0 +1,427 @@
+package config
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Префикс переменных окружения с настройками
+const EnvPrefix = "CODE_TELESCOPE_"
+
+// Источники значений настроек в порядке возрастания приоритета
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+)
+
+// Option описывает одну настройку конфигурации и способы ее переопределения
+type Option struct {
+	// Ключ настройки в YAML через точку (llm.batch_size)
+	Key string
+
+	// Имя переменной окружения (CODE_TELESCOPE_LLM_BATCH_SIZE)
+	EnvVar string
+
+	// Имя флага командной строки (llm-batch-size)
+	Flag string
+
+	// Тип значения для справки
+	Type string
+
+	// Секретная настройка (API ключ): задается только в файле
+	// конфигурации или переменной окружения, но не флагом
+	Secret bool
+
+	index []int
+}
+
+// Sources хранит источник значения для каждого ключа настройки
+type Sources map[string]string
+
+// Resolved содержит итоговую конфигурацию и источники ее значений
+type Resolved struct {
+	Config  *Config
+	Sources Sources
+
+	// Путь к файлу конфигурации (пусто, если файл не использовался)
+	ConfigPath string
+}
+
+// ResolvedEntry представляет значение настройки вместе с источником
+type ResolvedEntry struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// ResolveOptions задает входные данные для построения конфигурации по слоям
+type ResolveOptions struct {
+	// Путь к файлу конфигурации. Если не задан, используется
+	// .code-telescope.yaml из корня проекта, а при его отсутствии — только
+	// значения по умолчанию.
+	ConfigPath string
+
+	// Путь к корню проекта для поиска файла конфигурации
+	ProjectPath string
+
+	// Значения флагов командной строки по ключам настроек
+	Flags map[string]string
+
+	// Функция чтения переменных окружения (по умолчанию os.LookupEnv)
+	LookupEnv func(string) (string, bool)
+}
+
+// Псевдонимы флагов, сохраненные для совместимости с прежними версиями
+var flagAliases = map[string]string{
+	"format":     "output.format",
+	"max-tokens": "output.max_tokens",
+}
+
+// Options возвращает список всех настроек, которые можно переопределить
+// переменными окружения и флагами командной строки
+func Options() []Option {
+	var options []Option
+	collectOptions(reflect.TypeOf(Config{}), "", nil, &options)
+	return options
+}
+
+// collectOptions рекурсивно обходит поля структуры конфигурации по тегам yaml
+func collectOptions(t reflect.Type, prefix string, index []int, options *[]Option) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if tag == "" || tag == "-" {
+			continue
+		}
+
+		key := tag
+		if prefix != "" {
+			key = prefix + "." + tag
+		}
+		fieldIndex := append(append([]int{}, index...), i)
+
+		if field.Type.Kind() == reflect.Struct {
+			collectOptions(field.Type, key, fieldIndex, options)
+			continue
+		}
+		if !isScalarOption(field.Type) {
+			// Списки структур (output.regions) задаются только в файле
+			continue
+		}
+
+		*options = append(*options, Option{
+			Key:    key,
+			EnvVar: EnvPrefix + strings.ToUpper(strings.ReplaceAll(key, ".", "_")),
+			Flag:   strings.NewReplacer(".", "-", "_", "-").Replace(key),
+			Type:   typeName(field.Type),
+			Secret: strings.HasSuffix(key, "api_key"),
+			index:  fieldIndex,
+		})
+	}
+}
+
+// isScalarOption проверяет, можно ли задать поле одной строкой
+func isScalarOption(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int, reflect.Int64, reflect.Float64:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() == reflect.String
+	default:
+		return false
+	}
+}
+
+// typeName возвращает название типа значения для справки
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Slice {
+		return "list"
+	}
+	return t.Kind().String()
+}
+
+// Resolve строит итоговую конфигурацию по слоям: значения по умолчанию,
+// файл конфигурации, переменные окружения CODE_TELESCOPE_* и флаги командной строки
+func Resolve(opts ResolveOptions) (*Resolved, error) {
+	lookupEnv := opts.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	cfg := DefaultConfig()
+	options := Options()
+	sources := make(Sources, len(options))
+	for _, option := range options {
+		sources[option.Key] = SourceDefault
+	}
+	if _, ok := lookupEnv("LLM_API_KEY"); ok {
+		sources["llm.api_key"] = SourceEnv + ":LLM_API_KEY"
+	}
+
+	// Слой файла конфигурации
+	var fileKeyLines map[string]int
+	if opts.ConfigPath == "" && opts.ProjectPath != "" {
+		opts.ConfigPath = FindProjectConfig(opts.ProjectPath)
+	}
+	if opts.ConfigPath != "" {
+		keyLines, err := decodeFile(opts.ConfigPath, cfg)
+		if err != nil {
+			return nil, err
+		}
+		for key := range keyLines {
+			sources[key] = SourceFile + ":" + opts.ConfigPath
+		}
+		fileKeyLines = keyLines
+	}
+
+	// Слой переменных окружения
+	for _, option := range options {
+		value, ok := lookupEnv(option.EnvVar)
+		if !ok {
+			continue
+		}
+		if err := option.set(cfg, value); err != nil {
+			return nil, fmt.Errorf("переменная окружения %s: %w", option.EnvVar, err)
+		}
+		sources[option.Key] = SourceEnv + ":" + option.EnvVar
+	}
+
+	// Слой флагов командной строки
+	byKey := make(map[string]Option, len(options))
+	for _, option := range options {
+		byKey[option.Key] = option
+	}
+	flagKeys := make([]string, 0, len(opts.Flags))
+	for key := range opts.Flags {
+		flagKeys = append(flagKeys, key)
+	}
+	sort.Strings(flagKeys)
+	for _, key := range flagKeys {
+		option, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("неизвестная настройка: %s", key)
+		}
+		if option.Secret {
+			return nil, fmt.Errorf("настройка %s задается только в файле конфигурации или переменной окружения %s", key, option.EnvVar)
+		}
+		if err := option.set(cfg, opts.Flags[key]); err != nil {
+			return nil, fmt.Errorf("флаг --%s: %w", option.Flag, err)
+		}
+		sources[option.Key] = SourceFlag + ":--" + option.Flag
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		// Номера строк указываются только для значений, взятых из файла
+		fromFile := make(map[string]int)
+		for key, line := range fileKeyLines {
+			if strings.HasPrefix(sources[key], SourceFile) || sources[key] == "" {
+				fromFile[key] = line
+			}
+		}
+		return nil, fmt.Errorf("ошибка валидации конфигурации: %w", withFileLines(err, opts.ConfigPath, fromFile))
+	}
+
+	return &Resolved{Config: cfg, Sources: sources, ConfigPath: opts.ConfigPath}, nil
+}
+
+// decodeFile читает файл конфигурации поверх переданных значений и
+// возвращает ключи настроек, явно заданные в файле, с номерами строк.
+// Неизвестные ключи приводят к ошибке с подсказкой ближайшего известного ключа.
+func decodeFile(configPath string, cfg *Config) (map[string]int, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла конфигурации: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга YAML: %w", err)
+	}
+	keyLines := make(map[string]int)
+	if len(root.Content) == 0 {
+		return keyLines, nil
+	}
+
+	verr := &ValidationError{File: configPath}
+	checkKeys(root.Content[0], reflect.TypeOf(Config{}), "", keyLines, verr)
+	if err := verr.errorOrNil(); err != nil {
+		return nil, err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("ошибка парсинга YAML: %w", err)
+	}
+
+	return keyLines, nil
+}
+
+// set присваивает настройке значение, заданное строкой
+func (o Option) set(cfg *Config, value string) error {
+	field := reflect.ValueOf(cfg).Elem().FieldByIndex(o.index)
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("некорректное логическое значение %q для %s", value, o.Key)
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("некорректное целое значение %q для %s", value, o.Key)
+		}
+		field.SetInt(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("некорректное числовое значение %q для %s", value, o.Key)
+		}
+		field.SetFloat(parsed)
+	case reflect.Slice:
+		// Списки задаются через запятую
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("настройку %s нельзя задать строкой", o.Key)
+	}
+
+	return nil
+}
+
+// format возвращает значение настройки в виде строки
+func (o Option) format(cfg *Config) string {
+	field := reflect.ValueOf(cfg).Elem().FieldByIndex(o.index)
+	if field.Kind() == reflect.Slice {
+		items := make([]string, field.Len())
+		for i := range items {
+			items[i] = field.Index(i).String()
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(field.Interface())
+}
+
+// Entries возвращает значения всех настроек с их источниками.
+// Секретные значения (API ключи) маскируются.
+func (r *Resolved) Entries() []ResolvedEntry {
+	options := Options()
+	entries := make([]ResolvedEntry, 0, len(options)+1)
+	for _, option := range options {
+		value := option.format(r.Config)
+		if option.Secret && value != "" {
+			value = "***"
+		}
+		entries = append(entries, ResolvedEntry{
+			Key:    option.Key,
+			Value:  value,
+			Source: r.Sources[option.Key],
+		})
+	}
+
+	if len(r.Config.Output.Regions) > 0 {
+		regions := make([]string, 0, len(r.Config.Output.Regions))
+		for _, region := range r.Config.Output.Regions {
+			regions = append(regions, region.Name+"="+region.Path)
+		}
+		source := r.Sources["output.regions"]
+		if source == "" {
+			source = SourceDefault
+		}
+		entries = append(entries, ResolvedEntry{Key: "output.regions", Value: strings.Join(regions, ","), Source: source})
+	}
+
+	if len(r.Config.LLM.Fallbacks) > 0 {
+		fallbacks := make([]string, 0, len(r.Config.LLM.Fallbacks))
+		for _, fallback := range r.Config.LLM.Fallbacks {
+			fallbacks = append(fallbacks, fallback.Provider+"/"+fallback.Model)
+		}
+		source := r.Sources["llm.fallbacks"]
+		if source == "" {
+			source = SourceDefault
+		}
+		entries = append(entries, ResolvedEntry{Key: "llm.fallbacks", Value: strings.Join(fallbacks, ","), Source: source})
+	}
+
+	return entries
+}
+
+// FlagOverrides связывает флаги командной строки с настройками конфигурации
+type FlagOverrides struct {
+	fs     *flag.FlagSet
+	values map[string]*string
+}
+
+// BindFlags регистрирует флаги для настроек указанных разделов конфигурации
+// (filesystem, llm, output и т.д.), без разделов — для всех настроек.
+// Секретные настройки флагами не задаются, чтобы ключи не попадали в список
+// процессов и историю командной оболочки.
+func BindFlags(fs *flag.FlagSet, sections ...string) *FlagOverrides {
+	bound := func(key string) bool {
+		if len(sections) == 0 {
+			return true
+		}
+		section := strings.SplitN(key, ".", 2)[0]
+		for _, name := range sections {
+			if name == section {
+				return true
+			}
+		}
+		return false
+	}
+
+	overrides := &FlagOverrides{fs: fs, values: make(map[string]*string)}
+	for _, option := range Options() {
+		if option.Secret || !bound(option.Key) {
+			continue
+		}
+		usage := fmt.Sprintf("Переопределяет %s (%s, переменная окружения %s)", option.Key, option.Type, option.EnvVar)
+		overrides.values[option.Flag] = fs.String(option.Flag, "", usage)
+	}
+	for alias, key := range flagAliases {
+		if bound(key) {
+			overrides.values[alias] = fs.String(alias, "", "Псевдоним флага для "+key)
+		}
+	}
+	return overrides
+}
+
+// Values возвращает значения флагов, явно указанных в командной строке, по ключам настроек
+func (f *FlagOverrides) Values() map[string]string {
+	byFlag := make(map[string]string)
+	for _, option := range Options() {
+		byFlag[option.Flag] = option.Key
+	}
+	for alias, key := range flagAliases {
+		byFlag[alias] = key
+	}
+
+	values := make(map[string]string)
+	f.fs.Visit(func(fl *flag.Flag) {
+		value, registered := f.values[fl.Name]
+		if key, ok := byFlag[fl.Name]; ok && registered {
+			values[key] = *value
+		}
+	})
+	return values
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ProjectConfigFileName имя файла конфигурации проекта. Файл в корне проекта
+// загружается автоматически, файлы во вложенных директориях переопределяют
+// часть настроек для своего поддерева.
+const ProjectConfigFileName = ".code-telescope.yaml"
+
+// Ключи, которые можно переопределить во вложенных файлах конфигурации
+var directoryOverrideKeys = map[string]bool{
+	"filesystem":                   true,
+	"filesystem.include_patterns":  true,
+	"filesystem.exclude_patterns":  true,
+	"parser":                       true,
+	"parser.parse_private_methods": true,
+	"llm":                          true,
+	"llm.model":                    true,
+	"llm.output_language":          true,
+	"llm.describe":                 true,
+}
+
+// FindProjectConfig возвращает путь к файлу конфигурации в корне проекта
+// или пустую строку, если файла нет
+func FindProjectConfig(projectPath string) string {
+	configPath := filepath.Join(projectPath, ProjectConfigFileName)
+	if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
+		return configPath
+	}
+	return ""
+}
+
+// LoadDirectoryConfig загружает файл конфигурации поддиректории поверх
+// конфигурации родительской директории. Допускаются только ключи,
+// которые имеет смысл менять для части проекта.
+func LoadDirectoryConfig(configPath string, parent *Config) (*Config, error) {
+	cfg := parent.clone()
+
+	keyLines, err := decodeFile(configPath, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	forbidden := &ValidationError{File: configPath}
+	for key, line := range keyLines {
+		if !directoryOverrideKeys[key] {
+			forbidden.Problems = append(forbidden.Problems, Problem{
+				Key:     key,
+				Line:    line,
+				Message: "настройку нельзя переопределить для директории",
+			})
+		}
+	}
+	if len(forbidden.Problems) > 0 {
+		sort.Slice(forbidden.Problems, func(i, j int) bool {
+			return forbidden.Problems[i].Line < forbidden.Problems[j].Line
+		})
+		return nil, forbidden
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return nil, fmt.Errorf("ошибка валидации конфигурации: %w", withFileLines(err, configPath, keyLines))
+	}
+
+	return cfg, nil
+}
+
+// clone возвращает копию конфигурации, не разделяющую списки с исходной
+func (c *Config) clone() *Config {
+	clone := *c
+	clone.FileSystem.IncludePatterns = append([]string(nil), c.FileSystem.IncludePatterns...)
+	clone.FileSystem.ExcludePatterns = append([]string(nil), c.FileSystem.ExcludePatterns...)
+	clone.Output.Regions = append([]RegionConfig(nil), c.Output.Regions...)
+	return &clone
+}
+
+// DirectoryConfigs хранит действующие конфигурации директорий проекта
+type DirectoryConfigs struct {
+	root *Config
+	dirs map[string]*Config
+}
+
+// NewDirectoryConfigs создает набор конфигураций с корневой конфигурацией проекта
+func NewDirectoryConfigs(root *Config) *DirectoryConfigs {
+	return &DirectoryConfigs{
+		root: root,
+		dirs: make(map[string]*Config),
+	}
+}
+
+// Set задает конфигурацию для директории (путь относительно корня проекта)
+func (d *DirectoryConfigs) Set(dir string, cfg *Config) {
+	d.dirs[normalizeDir(dir)] = cfg
+}
+
+// ForDir возвращает конфигурацию директории: собственную или ближайшей родительской
+func (d *DirectoryConfigs) ForDir(dir string) *Config {
+	if d == nil {
+		return nil
+	}
+	for dir = normalizeDir(dir); dir != "."; dir = path.Dir(dir) {
+		if cfg, ok := d.dirs[dir]; ok {
+			return cfg
+		}
+	}
+	return d.root
+}
+
+// ForFile возвращает конфигурацию, действующую для файла
+func (d *DirectoryConfigs) ForFile(relPath string) *Config {
+	return d.ForDir(path.Dir(filepath.ToSlash(relPath)))
+}
+
+// Dirs возвращает отсортированный список директорий с собственной конфигурацией
+func (d *DirectoryConfigs) Dirs() []string {
+	dirs := make([]string, 0, len(d.dirs))
+	for dir := range d.dirs {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// normalizeDir приводит путь директории к виду с прямыми слешами
+func normalizeDir(dir string) string {
+	dir = path.Clean(filepath.ToSlash(dir))
+	if dir == "" || dir == "/" {
+		return "."
+	}
+	return strings.TrimPrefix(dir, "./")
+}
@@ -0,0 +1,225 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID идентификатор JSON Schema файла конфигурации
+const SchemaID = "https://github.com/vasia123/code-telescope/configs/config.schema.json"
+
+// Описания настроек для JSON Schema (подсказки в редакторе)
+var schemaDescriptions = map[string]string{
+	"filesystem":                              "Настройки сканирования файловой системы",
+	"filesystem.include_patterns":             "Шаблоны для включения файлов",
+	"filesystem.exclude_patterns":             "Шаблоны для исключения файлов",
+	"filesystem.max_depth":                    "Максимальная глубина рекурсии при сканировании директорий",
+	"parser":                                  "Настройки парсера кода",
+	"parser.parse_private_methods":            "Включать приватные методы и функции",
+	"parser.max_file_size":                    "Максимальный размер файла для анализа в байтах",
+	"llm":                                     "Настройки взаимодействия с ЛЛМ",
+	"llm.provider":                            "Провайдер ЛЛМ",
+	"llm.model":                               "Модель ЛЛМ",
+	"llm.api_key":                             "API ключ (лучше задавать через переменную окружения LLM_API_KEY)",
+	"llm.temperature":                         "Температура генерации",
+	"llm.max_tokens":                          "Максимальное количество токенов в ответе",
+	"llm.batch_size":                          "Количество методов в одном запросе",
+	"llm.batch_delay":                         "Пауза между пакетами запросов в секундах",
+	"llm.output_language":                     "Язык промптов, описаний и заголовков документа",
+	"llm.prompt_templates_dir":                "Директория с файлами <язык>.yaml, переопределяющими встроенные шаблоны промптов",
+	"llm.instructions":                        "Дополнительные указания проекта для ЛЛМ",
+	"llm.instructions.tone":                   "Тон описаний",
+	"llm.instructions.glossary":               "Глоссарий проекта: строки вида \"термин — значение\"",
+	"llm.instructions.forbidden_terms":        "Термины, которые не должны встречаться в описаниях",
+	"llm.instructions.extra":                  "Произвольные указания, добавляемые к промптам",
+	"llm.describe":                            "Генерировать описания символов с помощью ЛЛМ",
+	"llm.max_budget_tokens":                   "Максимум токенов ЛЛМ за запуск; после него символы остаются без описаний (0 — без ограничения)",
+	"llm.context_tokens":                      "Размер контекста модели в токенах для промпта и ответа (0 — по встроенной таблице моделей)",
+	"llm.max_budget_usd":                      "Максимальная стоимость запросов к ЛЛМ за запуск в долларах США (0 — без ограничения)",
+	"llm.prices":                              "Цены моделей в долларах США за миллион токенов (дополняют встроенную таблицу)",
+	"llm.prices.provider":                     "Провайдер ЛЛМ (пусто — любой)",
+	"llm.prices.model":                        "Модель или префикс имени модели",
+	"llm.prices.input":                        "Цена миллиона входных токенов",
+	"llm.prices.output":                       "Цена миллиона выходных токенов",
+	"llm.base_url":                            "Адрес API провайдера (для совместимых с OpenAI серверов и Ollama)",
+	"llm.fallbacks":                           "Резервные провайдеры в порядке очередности, если основной недоступен",
+	"llm.fallbacks.provider":                  "Провайдер ЛЛМ",
+	"llm.fallbacks.model":                     "Модель ЛЛМ",
+	"llm.fallbacks.api_key":                   "API ключ (по умолчанию из переменной окружения <ПРОВАЙДЕР>_API_KEY)",
+	"llm.fallbacks.base_url":                  "Адрес API провайдера",
+	"llm.circuit_breaker":                     "Временное отключение недоступных провайдеров цепочки",
+	"llm.circuit_breaker.failure_threshold":   "Количество ошибок подряд, после которого провайдер отключается",
+	"llm.circuit_breaker.cooldown_seconds":    "Через сколько секунд отключенному провайдеру отправляется пробный запрос",
+	"llm.batch_api":                           "Пакетные задания провайдера для ночной перегенерации (дешевле, но асинхронно)",
+	"llm.batch_api.enabled":                   "Отправлять все промпты генерации одним пакетным заданием (openai, anthropic)",
+	"llm.batch_api.poll_interval_seconds":     "Начальный интервал опроса статуса задания в секундах",
+	"llm.batch_api.max_poll_interval_seconds": "Максимальный интервал опроса; интервал удваивается после каждого опроса",
+	"llm.batch_api.state_dir":                 "Директория с идентификаторами незавершенных заданий (относительно корня проекта)",
+	"llm.quality":                             "Проверка качества описаний и повторные запросы непрошедших проверку",
+	"llm.quality.enabled":                     "Проверять описания: обрезку, информативность, упоминание несуществующих параметров, язык и форматирование",
+	"llm.quality.max_retries":                 "Сколько раз повторно запрашивать описание, не прошедшее проверку (0 — только оценивать)",
+	"llm.quality.min_words":                   "Минимальное количество слов описания, не считая слов из имени символа",
+	"llm.replay":                              "Запись ответов ЛЛМ в кассету и их воспроизведение",
+	"llm.replay.mode":                         "Режим кассеты: record — записывать ответы, replay — воспроизводить без обращения к ЛЛМ (пусто — отключено)",
+	"llm.replay.cassette":                     "Путь к файлу кассеты",
+	"markdown":                                "Настройки генерации Markdown",
+	"markdown.include_toc":                    "Включать оглавление",
+	"markdown.include_file_info":              "Включать информацию о файлах",
+	"markdown.max_method_description_length":  "Максимальная длина описания метода в символах (0 — без ограничения)",
+	"markdown.group_methods_by_type":          "Группировать методы по типам",
+	"markdown.code_style":                     "Стиль блоков кода",
+	"output":                                  "Настройки итогового документа",
+	"output.format":                           "Формат итогового документа",
+	"output.max_tokens":                       "Бюджет токенов документа (0 — без ограничения)",
+	"output.mode":                             "Режим сохранения документа",
+	"output.regions":                          "Именованные области документа для режима inplace",
+	"output.regions.name":                     "Имя области в маркере code-telescope:start name=...",
+	"output.regions.path":                     "Поддиректория проекта, выводимая в область",
+	"output.links":                            "Настройки ссылок на исходный код",
+	"output.links.mode":                       "Режим ссылок на исходный код",
+	"output.links.forge":                      "Тип хостинга репозитория",
+	"output.links.repo_url":                   "URL репозитория для постоянных ссылок",
+	"output.links.url_template":               "Шаблон ссылки с подстановками {repo}, {commit}, {path}, {start}, {end}",
+	"cache":                                   "Настройки кэша описаний символов",
+	"cache.enabled":                           "Сохранять описания ЛЛМ и не запрашивать их повторно для неизмененного кода",
+	"cache.dir":                               "Директория кэша (относительно корня проекта)",
+	"redaction":                               "Скрытие секретов и персональных данных перед отправкой кода в ЛЛМ",
+	"redaction.enabled":                       "Заменять найденные секреты заглушками [REDACTED:правило]",
+	"redaction.fail_on_secret":                "Не отправлять файлы с секретами и завершать генерацию ошибкой (для CI)",
+	"redaction.entropy_threshold":             "Энтропия строкового литерала в битах на символ, начиная с которой он считается секретом (0 — не проверять)",
+	"redaction.entropy_min_length":            "Минимальная длина строкового литерала для проверки энтропии",
+	"redaction.disabled_rules":                "Отключенные встроенные правила (например, email)",
+	"redaction.patterns":                      "Собственные правила поиска секретов",
+	"redaction.patterns.name":                 "Имя правила в заглушке и журнале",
+	"redaction.patterns.pattern":              "Регулярное выражение; если есть группа, скрывается только первая группа",
+	"redaction.audit_log":                     "Журнал скрытых секретов в формате JSON Lines (относительно корня проекта)",
+	"descriptions":                            "Описания символов, написанные вручную; заменяют описания от ЛЛМ",
+	"descriptions.overrides_file":             "Файл описаний по идентификаторам символов (относительно корня проекта)",
+	"descriptions.annotations":                "Брать описания из аннотаций telescope:describe \"...\" в документирующих комментариях",
+}
+
+// Допустимые значения настроек-перечислений
+func schemaEnums() map[string][]string {
+	return map[string][]string{
+		"llm.provider":        SupportedLLMProviders,
+		"llm.output_language": SupportedOutputLanguages,
+		"llm.replay.mode":     SupportedReplayModes,
+		"markdown.code_style": SupportedCodeStyles,
+		"output.format":       SupportedOutputFormats,
+		"output.mode":         SupportedOutputModes,
+		"output.links.mode":   SupportedLinkModes,
+		"output.links.forge":  SupportedForges,
+	}
+}
+
+// Минимальные значения числовых настроек
+var schemaMinimums = map[string]float64{
+	"filesystem.max_depth":                    1,
+	"parser.max_file_size":                    1,
+	"llm.temperature":                         0,
+	"llm.max_tokens":                          1,
+	"llm.batch_size":                          1,
+	"llm.batch_delay":                         0,
+	"llm.max_budget_tokens":                   0,
+	"llm.context_tokens":                      0,
+	"llm.circuit_breaker.failure_threshold":   1,
+	"llm.circuit_breaker.cooldown_seconds":    0,
+	"llm.batch_api.poll_interval_seconds":     1,
+	"llm.batch_api.max_poll_interval_seconds": 1,
+	"llm.quality.max_retries":                 0,
+	"llm.quality.min_words":                   0,
+	"llm.max_budget_usd":                      0,
+	"llm.prices.input":                        0,
+	"llm.prices.output":                       0,
+	"markdown.max_method_description_length":  0,
+	"output.max_tokens":                       0,
+	"redaction.entropy_threshold":             0,
+	"redaction.entropy_min_length":            1,
+}
+
+// Максимальные значения числовых настроек
+var schemaMaximums = map[string]float64{
+	"llm.temperature": 1,
+}
+
+// JSONSchema возвращает JSON Schema файла конфигурации для автодополнения
+// и проверки в редакторах. Схема строится по структуре Config, значения
+// по умолчанию берутся из DefaultConfig.
+func JSONSchema() ([]byte, error) {
+	defaults := DefaultConfig()
+	defaults.LLM.APIKey = ""
+
+	schema := buildSchema(reflect.TypeOf(Config{}), reflect.ValueOf(*defaults), "", schemaEnums())
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaID
+	schema["title"] = "Конфигурация Code Telescope"
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// buildSchema строит схему для типа поля конфигурации
+func buildSchema(t reflect.Type, defaults reflect.Value, key string, enums map[string][]string) map[string]interface{} {
+	schema := make(map[string]interface{})
+	if description, ok := schemaDescriptions[key]; ok {
+		schema["description"] = description
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		schema["type"] = "object"
+		schema["additionalProperties"] = false
+		properties := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "" || name == "-" {
+				continue
+			}
+			fieldKey := name
+			if key != "" {
+				fieldKey = key + "." + name
+			}
+			var fieldDefault reflect.Value
+			if defaults.IsValid() {
+				fieldDefault = defaults.Field(i)
+			}
+			properties[name] = buildSchema(field.Type, fieldDefault, fieldKey, enums)
+		}
+		schema["properties"] = properties
+		return schema
+	case reflect.Slice:
+		schema["type"] = "array"
+		items := buildSchema(t.Elem(), reflect.Value{}, key, enums)
+		// Описание относится к списку, а не к его элементам
+		delete(items, "description")
+		schema["items"] = items
+	case reflect.String:
+		schema["type"] = "string"
+		if values, ok := enums[key]; ok {
+			schema["enum"] = values
+		}
+	case reflect.Bool:
+		schema["type"] = "boolean"
+	case reflect.Int, reflect.Int64:
+		schema["type"] = "integer"
+	case reflect.Float64:
+		schema["type"] = "number"
+	}
+
+	if minimum, ok := schemaMinimums[key]; ok {
+		schema["minimum"] = minimum
+	}
+	if maximum, ok := schemaMaximums[key]; ok {
+		schema["maximum"] = maximum
+	}
+	if defaults.IsValid() && (!defaults.IsZero() || defaults.Kind() == reflect.Bool) {
+		schema["default"] = defaults.Interface()
+	}
+
+	return schema
+}
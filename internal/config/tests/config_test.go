@@ -154,3 +154,25 @@ func TestLoadNonExistentConfig(t *testing.T) {
 	assert.Error(t, err, "Должна возникнуть ошибка при загрузке несуществующего файла")
 	assert.Nil(t, cfg, "Конфигурация должна быть nil")
 }
+
+// TestLoadConfigInvalidCodeStyle проверяет отклонение неподдерживаемого стиля кода
+func TestLoadConfigInvalidCodeStyle(t *testing.T) {
+	yamlContent := `
+filesystem:
+  max_depth: 5
+
+llm:
+  provider: "openai"
+  batch_size: 1
+
+markdown:
+  code_style: "dracula"
+`
+	tmpfile := createTempConfigFile(t, yamlContent)
+	defer os.Remove(tmpfile.Name())
+
+	_, err := config.LoadConfig(tmpfile.Name())
+
+	assert.Error(t, err, "Неподдерживаемый стиль кода должен приводить к ошибке")
+	assert.Contains(t, err.Error(), "dracula")
+}
@@ -0,0 +1,114 @@
+package tests
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"code-telescope/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadConfigKeepsDefaults проверяет, что отсутствующие в файле ключи берутся из значений по умолчанию
+func TestLoadConfigKeepsDefaults(t *testing.T) {
+	tmpfile := createTempConfigFile(t, "llm:\n  model: \"gpt-4o\"\n")
+	defer os.Remove(tmpfile.Name())
+
+	cfg, err := config.LoadConfig(tmpfile.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, "gpt-4o", cfg.LLM.Model)
+	assert.Equal(t, config.DefaultMaxDepth, cfg.FileSystem.MaxDepth)
+	assert.Equal(t, "openai", cfg.LLM.Provider)
+}
+
+// TestResolveLayers проверяет приоритет слоев: файл, переменные окружения, флаги
+func TestResolveLayers(t *testing.T) {
+	tmpfile := createTempConfigFile(t, "llm:\n  model: \"gpt-4o\"\n  batch_size: 2\nmarkdown:\n  include_toc: false\n")
+	defer os.Remove(tmpfile.Name())
+
+	env := map[string]string{
+		"CODE_TELESCOPE_LLM_BATCH_SIZE":              "7",
+		"CODE_TELESCOPE_FILESYSTEM_INCLUDE_PATTERNS": "*.go, *.py",
+		"CODE_TELESCOPE_OUTPUT_FORMAT":               "markdown",
+	}
+
+	resolved, err := config.Resolve(config.ResolveOptions{
+		ConfigPath: tmpfile.Name(),
+		Flags:      map[string]string{"output.format": "llms"},
+		LookupEnv: func(key string) (string, bool) {
+			value, ok := env[key]
+			return value, ok
+		},
+	})
+	require.NoError(t, err)
+
+	cfg := resolved.Config
+	assert.Equal(t, "gpt-4o", cfg.LLM.Model)
+	assert.Equal(t, 7, cfg.LLM.BatchSize)
+	assert.False(t, cfg.Markdown.IncludeTOC)
+	assert.Equal(t, []string{"*.go", "*.py"}, cfg.FileSystem.IncludePatterns)
+	assert.Equal(t, config.OutputFormatLLMs, cfg.Output.Format)
+
+	assert.Equal(t, config.SourceDefault, resolved.Sources["filesystem.max_depth"])
+	assert.Equal(t, config.SourceFile+":"+tmpfile.Name(), resolved.Sources["llm.model"])
+	assert.Equal(t, config.SourceEnv+":CODE_TELESCOPE_LLM_BATCH_SIZE", resolved.Sources["llm.batch_size"])
+	assert.Equal(t, config.SourceFlag+":--output-format", resolved.Sources["output.format"])
+}
+
+// TestResolveInvalidValue проверяет сообщение об ошибке для некорректного значения
+func TestResolveInvalidValue(t *testing.T) {
+	_, err := config.Resolve(config.ResolveOptions{
+		LookupEnv: func(key string) (string, bool) {
+			if key == "CODE_TELESCOPE_FILESYSTEM_MAX_DEPTH" {
+				return "глубоко", true
+			}
+			return "", false
+		},
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CODE_TELESCOPE_FILESYSTEM_MAX_DEPTH")
+}
+
+// TestBindFlags проверяет, что флаги регистрируются для каждой настройки, кроме
+// секретных, и учитываются только явно заданные
+func TestBindFlags(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	overrides := config.BindFlags(fs)
+
+	for _, option := range config.Options() {
+		if option.Secret {
+			assert.Nil(t, fs.Lookup(option.Flag), "Секретная настройка %s не должна задаваться флагом", option.Key)
+			continue
+		}
+		assert.NotNil(t, fs.Lookup(option.Flag), "Флаг для %s должен быть зарегистрирован", option.Key)
+	}
+
+	require.NoError(t, fs.Parse([]string{"--llm-model", "claude-3", "-max-tokens", "300"}))
+	assert.Equal(t, map[string]string{
+		"llm.model":         "claude-3",
+		"output.max_tokens": "300",
+	}, overrides.Values())
+}
+
+// TestBindFlagsSections проверяет, что регистрируются только флаги указанных
+// разделов, а секретная настройка не принимается и через Resolve
+func TestBindFlagsSections(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	config.BindFlags(fs, "filesystem", "parser")
+
+	assert.NotNil(t, fs.Lookup("filesystem-max-depth"))
+	assert.NotNil(t, fs.Lookup("parser-max-file-size"))
+	assert.Nil(t, fs.Lookup("llm-model"))
+	assert.Nil(t, fs.Lookup("format"), "псевдоним раздела output не регистрируется")
+
+	_, err := config.Resolve(config.ResolveOptions{
+		Flags:     map[string]string{"llm.api_key": "sk-secret"},
+		LookupEnv: func(string) (string, bool) { return "", false },
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CODE_TELESCOPE_LLM_API_KEY")
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeProjectConfig записывает .code-telescope.yaml в указанную директорию
+func writeProjectConfig(t *testing.T, dir, content string) string {
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	configPath := filepath.Join(dir, config.ProjectConfigFileName)
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0644))
+	return configPath
+}
+
+// TestResolveDiscoversProjectConfig проверяет автоматический поиск конфигурации в корне проекта
+func TestResolveDiscoversProjectConfig(t *testing.T) {
+	project := t.TempDir()
+	configPath := writeProjectConfig(t, project, "llm:\n  model: \"gpt-4o\"\n")
+
+	resolved, err := config.Resolve(config.ResolveOptions{ProjectPath: project})
+	require.NoError(t, err)
+
+	assert.Equal(t, configPath, resolved.ConfigPath)
+	assert.Equal(t, "gpt-4o", resolved.Config.LLM.Model)
+}
+
+// TestDirectoryConfigs проверяет наследование настроек вложенными директориями
+func TestDirectoryConfigs(t *testing.T) {
+	project := t.TempDir()
+	root := config.DefaultConfig()
+
+	legacyPath := writeProjectConfig(t, filepath.Join(project, "legacy"),
+		"llm:\n  describe: false\nfilesystem:\n  include_patterns: [\"*.py\"]\n")
+	legacy, err := config.LoadDirectoryConfig(legacyPath, root)
+	require.NoError(t, err)
+
+	modulePath := writeProjectConfig(t, filepath.Join(project, "legacy", "module"),
+		"llm:\n  model: \"gpt-4o\"\n  output_language: \"en\"\n")
+	module, err := config.LoadDirectoryConfig(modulePath, legacy)
+	require.NoError(t, err)
+
+	dirs := config.NewDirectoryConfigs(root)
+	dirs.Set("legacy", legacy)
+	dirs.Set(filepath.Join("legacy", "module"), module)
+
+	assert.Same(t, root, dirs.ForFile("main.go"))
+	assert.Same(t, legacy, dirs.ForFile("legacy/old/util.py"))
+	assert.Same(t, module, dirs.ForFile("legacy/module/api.py"))
+
+	assert.False(t, module.LLM.Describe, "Настройка родительской директории должна наследоваться")
+	assert.Equal(t, []string{"*.py"}, module.FileSystem.IncludePatterns)
+	assert.Equal(t, "gpt-4o", module.LLM.Model)
+	assert.Equal(t, config.OutputLanguageEnglish, module.LLM.OutputLanguage)
+	assert.True(t, root.LLM.Describe, "Корневая конфигурация не должна изменяться")
+}
+
+// TestDirectoryConfigRejectsGlobalKeys проверяет запрет переопределения глобальных настроек в поддиректории
+func TestDirectoryConfigRejectsGlobalKeys(t *testing.T) {
+	configPath := writeProjectConfig(t, t.TempDir(), "llm:\n  provider: \"anthropic\"\noutput:\n  format: \"llms\"\n")
+
+	_, err := config.LoadDirectoryConfig(configPath, config.DefaultConfig())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.provider")
+	assert.Contains(t, err.Error(), "output.format")
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/config"
+	_ "code-telescope/internal/llm" // регистрация провайдеров для схемы
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadConfigUnknownKey проверяет подсказку для опечатки в ключе и номер строки
+func TestLoadConfigUnknownKey(t *testing.T) {
+	tmpfile := createTempConfigFile(t, "llm:\n  provider: \"openai\"\n  max_token: 500\n")
+	defer os.Remove(tmpfile.Name())
+
+	_, err := config.LoadConfig(tmpfile.Name())
+	require.Error(t, err)
+
+	var verr *config.ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Len(t, verr.Problems, 1)
+	assert.Equal(t, "llm.max_token", verr.Problems[0].Key)
+	assert.Equal(t, 3, verr.Problems[0].Line)
+	assert.Contains(t, verr.Problems[0].Message, "max_tokens")
+}
+
+// TestLoadConfigRenamedKey проверяет подсказку для ключа прежней версии конфигурации
+func TestLoadConfigRenamedKey(t *testing.T) {
+	tmpfile := createTempConfigFile(t, "llm:\n  prompt_language: \"en\"\n")
+	defer os.Remove(tmpfile.Name())
+
+	_, err := config.LoadConfig(tmpfile.Name())
+
+	var verr *config.ValidationError
+	require.True(t, errors.As(err, &verr))
+	require.Len(t, verr.Problems, 1)
+	assert.Equal(t, "llm.prompt_language", verr.Problems[0].Key)
+	assert.Contains(t, verr.Problems[0].Message, "llm.output_language")
+}
+
+// TestLoadConfigReportsAllProblems проверяет, что валидация собирает все ошибки сразу
+func TestLoadConfigReportsAllProblems(t *testing.T) {
+	tmpfile := createTempConfigFile(t, `filesystem:
+  include_patterns: ["[a"]
+parser:
+  max_file_size: 0
+llm:
+  provider: "unknown"
+  batch_size: 0
+`)
+	defer os.Remove(tmpfile.Name())
+
+	_, err := config.LoadConfig(tmpfile.Name())
+	require.Error(t, err)
+
+	var verr *config.ValidationError
+	require.True(t, errors.As(err, &verr))
+
+	lines := make(map[string]int)
+	for _, problem := range verr.Problems {
+		lines[problem.Key] = problem.Line
+	}
+	assert.Equal(t, map[string]int{
+		"filesystem.include_patterns": 2,
+		"parser.max_file_size":        4,
+		"llm.provider":                6,
+		"llm.batch_size":              7,
+	}, lines)
+}
+
+// TestJSONSchemaUpToDate проверяет, что опубликованная схема соответствует структуре конфигурации
+func TestJSONSchemaUpToDate(t *testing.T) {
+	schema, err := config.JSONSchema()
+	require.NoError(t, err)
+
+	published, err := os.ReadFile(filepath.Join("..", "..", "..", "configs", "config.schema.json"))
+	require.NoError(t, err)
+
+	assert.Equal(t, string(published), string(schema),
+		"Схема устарела, обновите ее командой: codetelescope config schema > configs/config.schema.json")
+}
+
+// TestPriceFor проверяет выбор цены по самому длинному префиксу модели
+func TestPriceFor(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	mini, ok := cfg.LLM.PriceFor("openai", "gpt-4o-mini-2024-07-18")
+	require.True(t, ok)
+	assert.Equal(t, "gpt-4o-mini", mini.Model)
+
+	cfg.LLM.Prices = []config.PriceConfig{{Model: "gpt-4o", Input: 1, Output: 1}}
+	custom, ok := cfg.LLM.PriceFor("openai", "gpt-4o-mini")
+	require.True(t, ok)
+	assert.Equal(t, 1.0, custom.Input, "цены из конфигурации имеют приоритет")
+
+	_, ok = cfg.LLM.PriceFor("openai", "local-model")
+	assert.False(t, ok)
+
+	tmpfile := createTempConfigFile(t, "llm:\n  model: \"local-model\"\n  max_budget_usd: 1\n")
+	defer os.Remove(tmpfile.Name())
+	_, err := config.LoadConfig(tmpfile.Name())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.max_budget_usd")
+}
+
+// TestContextWindowFor проверяет выбор размера контекста модели и бюджета промпта
+func TestContextWindowFor(t *testing.T) {
+	cfg := config.DefaultConfig()
+
+	assert.Equal(t, 128000, cfg.LLM.ContextWindowFor("openai", "gpt-4o-mini"))
+	assert.Equal(t, 8192, cfg.LLM.ContextWindowFor("openai", "gpt-4-0613"))
+	assert.Equal(t, 200000, cfg.LLM.ContextWindowFor("anthropic", "claude-3-5-haiku-latest"))
+	assert.Equal(t, config.DefaultContextTokens, cfg.LLM.ContextWindowFor("openai", "local-model"))
+	assert.Equal(t, config.DefaultContextTokens-cfg.LLM.MaxTokens, cfg.LLM.PromptTokensFor("openai", "local-model"))
+
+	cfg.LLM.ContextTokens = 4000
+	assert.Equal(t, 4000, cfg.LLM.ContextWindowFor("openai", "gpt-4o"), "размер из конфигурации имеет приоритет")
+
+	tmpfile := createTempConfigFile(t, "llm:\n  max_tokens: 1000\n  context_tokens: 500\n")
+	defer os.Remove(tmpfile.Name())
+	_, err := config.LoadConfig(tmpfile.Name())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "llm.context_tokens")
+}
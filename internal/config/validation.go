@@ -0,0 +1,219 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem описывает одну ошибку в конфигурации
+type Problem struct {
+	// Ключ настройки через точку (llm.max_tokens)
+	Key string
+
+	// Номер строки в файле конфигурации (0, если неизвестен)
+	Line int
+
+	// Описание ошибки
+	Message string
+}
+
+// ValidationError содержит все ошибки, найденные в конфигурации
+type ValidationError struct {
+	// Файл конфигурации (пусто, если ошибки не связаны с файлом)
+	File string
+
+	Problems []Problem
+}
+
+// Error возвращает все ошибки конфигурации, по одной на строку
+func (e *ValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, problem := range e.Problems {
+		lines = append(lines, e.format(problem))
+	}
+	if len(lines) == 1 {
+		return lines[0]
+	}
+	return fmt.Sprintf("найдено ошибок: %d\n  %s", len(lines), strings.Join(lines, "\n  "))
+}
+
+// format форматирует ошибку в виде файл:строка: ключ: сообщение
+func (e *ValidationError) format(problem Problem) string {
+	location := ""
+	switch {
+	case e.File != "" && problem.Line > 0:
+		location = fmt.Sprintf("%s:%d: ", e.File, problem.Line)
+	case e.File != "":
+		location = e.File + ": "
+	}
+	if problem.Key == "" {
+		return location + problem.Message
+	}
+	return location + problem.Key + ": " + problem.Message
+}
+
+// add добавляет ошибку для ключа настройки
+func (e *ValidationError) add(key, format string, args ...interface{}) {
+	e.Problems = append(e.Problems, Problem{Key: key, Message: fmt.Sprintf(format, args...)})
+}
+
+// errorOrNil возвращает ошибку, только если найдены проблемы
+func (e *ValidationError) errorOrNil() error {
+	if len(e.Problems) == 0 {
+		return nil
+	}
+	return e
+}
+
+// withFileLines дополняет ошибки валидации путем к файлу и номерами строк ключей
+func withFileLines(err error, configPath string, keyLines map[string]int) error {
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		return err
+	}
+	verr.File = configPath
+	for i, problem := range verr.Problems {
+		if line, ok := keyLines[problem.Key]; ok && problem.Line == 0 {
+			verr.Problems[i].Line = line
+		}
+	}
+	return verr
+}
+
+// Ключи прежних версий конфигурации и их новые имена
+var renamedKeys = map[string]string{
+	"llm.prompt_language": "llm.output_language",
+}
+
+// checkKeys сверяет ключи узла YAML с полями структуры конфигурации.
+// Известные ключи записываются в keyLines вместе с номерами строк,
+// неизвестные добавляются в ошибку с подсказкой ближайшего известного ключа.
+func checkKeys(node *yaml.Node, t reflect.Type, prefix string, keyLines map[string]int, verr *ValidationError) {
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			checkKeys(item, t, prefix, keyLines, verr)
+		}
+		return
+	case yaml.MappingNode:
+	default:
+		return
+	}
+
+	fields := yamlFields(t)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode := node.Content[i]
+		key := keyNode.Value
+		if prefix != "" {
+			key = prefix + "." + keyNode.Value
+		}
+
+		field, ok := fields[keyNode.Value]
+		if !ok {
+			message := "неизвестный ключ"
+			if renamed, ok := renamedKeys[key]; ok {
+				message = fmt.Sprintf("ключ переименован в %s", renamed)
+			} else if suggestion := suggestKey(keyNode.Value, fieldNames(fields)); suggestion != "" {
+				message += fmt.Sprintf(", возможно, имелся в виду %s", suggestion)
+			}
+			verr.Problems = append(verr.Problems, Problem{Key: key, Line: keyNode.Line, Message: message})
+			continue
+		}
+
+		if _, seen := keyLines[key]; !seen {
+			keyLines[key] = keyNode.Line
+		}
+		checkKeys(node.Content[i+1], field.Type, key, keyLines, verr)
+	}
+}
+
+// yamlFields возвращает поля структуры по именам из тегов yaml
+func yamlFields(t reflect.Type) map[string]reflect.StructField {
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = field
+		}
+	}
+	return fields
+}
+
+// fieldNames возвращает отсортированные имена полей
+func fieldNames(fields map[string]reflect.StructField) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// suggestKey подбирает наиболее похожий известный ключ для опечатки
+func suggestKey(key string, candidates []string) string {
+	best := ""
+	bestDistance := 0
+	for _, candidate := range candidates {
+		distance := levenshtein(strings.ToLower(key), candidate)
+		if best == "" || distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+
+	// Подсказка имеет смысл только для близких ключей
+	limit := len(key) / 3
+	if limit < 2 {
+		limit = 2
+	}
+	if best == "" || bestDistance > limit {
+		return ""
+	}
+	return best
+}
+
+// levenshtein вычисляет редакционное расстояние между строками
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = minInt(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+// minInt возвращает минимальное из чисел
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+	return result
+}
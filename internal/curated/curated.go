@@ -0,0 +1,168 @@
+package curated
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Аннотация описания в документирующем комментарии символа:
+// telescope:describe "Описание символа"
+var annotationPattern = regexp.MustCompile(`(?m)^\s*telescope:describe\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// Override описывает описание символа, написанное вручную
+type Override struct {
+	// Текст описания
+	Description string `yaml:"description"`
+
+	// Хэш сигнатуры символа, для которой написано описание. Если сигнатура
+	// изменилась, описание считается устаревшим. Пустой хэш не проверяется.
+	SignatureHash string `yaml:"signature_hash,omitempty"`
+}
+
+// File содержит описания из файла descriptions.overrides_file по
+// идентификаторам символов
+type File struct {
+	Descriptions map[string]Override `yaml:"descriptions"`
+}
+
+// Load читает файл описаний. Отсутствующий файл означает, что описаний,
+// написанных вручную, нет.
+func Load(path string) (*File, error) {
+	file := &File{}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return file, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла описаний: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("%s: ошибка разбора файла описаний: %w", path, err)
+	}
+	for id, override := range file.Descriptions {
+		if override.Description == "" {
+			return nil, fmt.Errorf("%s: пустое описание символа %s", path, id)
+		}
+	}
+	return file, nil
+}
+
+// Get возвращает описание символа по идентификатору
+func (f *File) Get(id string) (Override, bool) {
+	if f == nil {
+		return Override{}, false
+	}
+	override, ok := f.Descriptions[id]
+	return override, ok
+}
+
+// IDs возвращает идентификаторы символов файла в алфавитном порядке
+func (f *File) IDs() []string {
+	if f == nil {
+		return nil
+	}
+	ids := make([]string, 0, len(f.Descriptions))
+	for id := range f.Descriptions {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Stale проверяет, что описание написано для другой сигнатуры символа
+func (o Override) Stale(signatureHash string) bool {
+	return o.SignatureHash != "" && o.SignatureHash != signatureHash
+}
+
+// SignatureHash возвращает хэш сигнатуры символа: первые 12 символов SHA-256
+func SignatureHash(signature string) string {
+	hash := sha256.Sum256([]byte(signature))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// Annotation возвращает описание из аннотации telescope:describe
+// в документирующем комментарии символа
+func Annotation(doc string) (string, bool) {
+	match := annotationPattern.FindStringSubmatch(doc)
+	if match == nil {
+		return "", false
+	}
+	description, err := strconv.Unquote(match[1])
+	if err != nil || description == "" {
+		return "", false
+	}
+	return description, true
+}
+
+// Pin записывает в файл описаний текущие хэши сигнатур символов.
+// Файл изменяется на месте, комментарии и порядок описаний сохраняются.
+func Pin(path string, hashes map[string]string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("ошибка чтения файла описаний: %w", err)
+	}
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return fmt.Errorf("%s: ошибка разбора файла описаний: %w", path, err)
+	}
+
+	descriptions := mappingValue(&document, "descriptions")
+	if descriptions == nil || descriptions.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(descriptions.Content); i += 2 {
+		hash, ok := hashes[descriptions.Content[i].Value]
+		if !ok {
+			continue
+		}
+		override := descriptions.Content[i+1]
+		if value := mappingValue(override, "signature_hash"); value != nil {
+			value.Value = hash
+			continue
+		}
+		override.Content = append(override.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: "signature_hash"},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: hash, Style: yaml.DoubleQuotedStyle})
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return fmt.Errorf("ошибка записи файла описаний: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("ошибка создания директории файла описаний: %w", err)
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+// mappingValue возвращает значение ключа YAML-отображения или nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
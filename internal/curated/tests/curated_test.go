@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/curated"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAnnotation проверяет чтение аннотации telescope:describe из комментария
+func TestAnnotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		description string
+		ok          bool
+	}{
+		{"отдельная строка", "Add складывает числа.\ntelescope:describe \"Складывает числа.\"", "Складывает числа.", true},
+		{"экранированные кавычки", `telescope:describe "Возвращает \"ok\"."`, `Возвращает "ok".`, true},
+		{"нет аннотации", "Add складывает числа.", "", false},
+		{"пустое описание", `telescope:describe ""`, "", false},
+		{"внутри текста", `см. telescope:describe "x" ниже`, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description, ok := curated.Annotation(tt.doc)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.description, description)
+		})
+	}
+}
+
+// TestLoad проверяет чтение файла описаний и отсутствующий файл
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	file, err := curated.Load(filepath.Join(dir, "missing.yaml"))
+	require.NoError(t, err)
+	assert.Empty(t, file.IDs())
+
+	path := filepath.Join(dir, "descriptions.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("descriptions:\n  b.go:B:\n    description: B\n  a.go:A:\n    description: A\n    signature_hash: abc\n"), 0644))
+	file, err = curated.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a.go:A", "b.go:B"}, file.IDs())
+	override, ok := file.Get("a.go:A")
+	require.True(t, ok)
+	assert.True(t, override.Stale("def"))
+	assert.False(t, override.Stale("abc"))
+	b, _ := file.Get("b.go:B")
+	assert.False(t, b.Stale("def"), "описание без хэша не устаревает")
+
+	require.NoError(t, os.WriteFile(path, []byte("descriptions:\n  a.go:A:\n    text: A\n"), 0644))
+	_, err = curated.Load(path)
+	assert.Error(t, err)
+}
+
+// TestPinKeepsComments проверяет, что запись хэшей сохраняет комментарии файла
+func TestPinKeepsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "descriptions.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("# Описания API\ndescriptions:\n  a.go:A:\n    # проверено\n    description: A\n  b.go:B:\n    description: B\n    signature_hash: old\n"), 0644))
+
+	require.NoError(t, curated.Pin(path, map[string]string{"a.go:A": "new1", "b.go:B": "new2"}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Описания API")
+	assert.Contains(t, string(data), "# проверено")
+
+	file, err := curated.Load(path)
+	require.NoError(t, err)
+	a, _ := file.Get("a.go:A")
+	b, _ := file.Get("b.go:B")
+	assert.Equal(t, "new1", a.SignatureHash)
+	assert.Equal(t, "new2", b.SignatureHash)
+}
@@ -0,0 +1,273 @@
+package depgraph
+
+import (
+	"path"
+	"sort"
+	"strings"
+
+	"code-telescope/pkg/models"
+)
+
+// Расширения, которые пробуются при разрешении относительных импортов
+var relativeImportExtensions = []string{
+	".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx", ".py",
+}
+
+// Graph представляет граф зависимостей между файлами проекта
+type Graph struct {
+	// Пути всех файлов графа (в формате с прямыми слешами)
+	paths map[string]bool
+
+	// Файлы, сгруппированные по директориям
+	dirs map[string][]string
+
+	// Прямые зависимости: файл -> файлы, которые он импортирует
+	deps map[string][]string
+
+	// Обратные зависимости: файл -> файлы, которые его импортируют
+	rdeps map[string][]string
+}
+
+// Build строит граф зависимостей по импортам файловых структур.
+// Разрешаются только импорты, указывающие на файлы самого проекта.
+func Build(files []models.FileStructure) *Graph {
+	g := &Graph{
+		paths: make(map[string]bool, len(files)),
+		dirs:  make(map[string][]string),
+		deps:  make(map[string][]string),
+		rdeps: make(map[string][]string),
+	}
+
+	for _, file := range files {
+		p := normalize(file.Path)
+		g.paths[p] = true
+		dir := path.Dir(p)
+		g.dirs[dir] = append(g.dirs[dir], p)
+	}
+
+	for _, file := range files {
+		from := normalize(file.Path)
+		seen := make(map[string]bool)
+		for _, imp := range file.Imports {
+			for _, to := range g.resolve(from, importPath(imp)) {
+				if to == from || seen[to] {
+					continue
+				}
+				seen[to] = true
+				g.deps[from] = append(g.deps[from], to)
+				g.rdeps[to] = append(g.rdeps[to], from)
+			}
+		}
+	}
+
+	for p := range g.deps {
+		sort.Strings(g.deps[p])
+	}
+	for p := range g.rdeps {
+		sort.Strings(g.rdeps[p])
+	}
+
+	return g
+}
+
+// Dependencies возвращает файлы проекта, которые импортирует указанный файл
+func (g *Graph) Dependencies(filePath string) []string {
+	return g.deps[normalize(filePath)]
+}
+
+// Dependents возвращает файлы проекта, которые импортируют указанный файл
+func (g *Graph) Dependents(filePath string) []string {
+	return g.rdeps[normalize(filePath)]
+}
+
+// FanIn возвращает количество файлов проекта, импортирующих указанный файл
+func (g *Graph) FanIn(filePath string) int {
+	return len(g.rdeps[normalize(filePath)])
+}
+
+// Edge представляет зависимость: файл From импортирует файл To
+type Edge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// Neighbour представляет файл окрестности и расстояние до него в зависимостях
+type Neighbour struct {
+	Path     string `json:"path"`
+	Distance int    `json:"distance"`
+}
+
+// Neighbourhood возвращает файлы, связанные с указанным файлом зависимостями
+// в любом направлении не далее depth шагов, и зависимости между ними
+func (g *Graph) Neighbourhood(filePath string, depth int) ([]Neighbour, []Edge) {
+	start := normalize(filePath)
+	if !g.paths[start] {
+		return nil, nil
+	}
+
+	distances := map[string]int{start: 0}
+	queue := []string{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if distances[current] >= depth {
+			continue
+		}
+		for _, next := range append(append([]string{}, g.deps[current]...), g.rdeps[current]...) {
+			if _, seen := distances[next]; !seen {
+				distances[next] = distances[current] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	nodes := make([]Neighbour, 0, len(distances))
+	for p, distance := range distances {
+		nodes = append(nodes, Neighbour{Path: p, Distance: distance})
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Distance != nodes[j].Distance {
+			return nodes[i].Distance < nodes[j].Distance
+		}
+		return nodes[i].Path < nodes[j].Path
+	})
+
+	var edges []Edge
+	for _, node := range nodes {
+		for _, to := range g.deps[node.Path] {
+			if _, ok := distances[to]; ok {
+				edges = append(edges, Edge{From: node.Path, To: to})
+			}
+		}
+	}
+
+	return nodes, edges
+}
+
+// resolve сопоставляет путь импорта с файлами проекта
+func (g *Graph) resolve(from, imp string) []string {
+	if imp == "" {
+		return nil
+	}
+
+	// Относительные импорты JavaScript/TypeScript
+	if strings.HasPrefix(imp, "./") || strings.HasPrefix(imp, "../") {
+		return g.resolveFile(path.Join(path.Dir(from), imp))
+	}
+
+	// Относительные импорты Python (from .module import name)
+	if strings.HasPrefix(imp, ".") {
+		trimmed := strings.TrimLeft(imp, ".")
+		base := path.Dir(from)
+		for i := 1; i < len(imp)-len(trimmed); i++ {
+			base = path.Dir(base)
+		}
+		return g.resolveDotted(base, trimmed)
+	}
+
+	// Импорты Go указывают на директорию пакета
+	if strings.HasSuffix(from, ".go") {
+		return g.resolveGoPackage(imp)
+	}
+
+	if strings.HasSuffix(from, ".py") || strings.HasSuffix(from, ".pyw") {
+		return g.resolveDotted("", imp)
+	}
+
+	return nil
+}
+
+// resolveFile подбирает файл по пути без расширения или по индексному файлу директории
+func (g *Graph) resolveFile(candidate string) []string {
+	if g.paths[candidate] {
+		return []string{candidate}
+	}
+	for _, ext := range relativeImportExtensions {
+		if g.paths[candidate+ext] {
+			return []string{candidate + ext}
+		}
+	}
+	for _, ext := range relativeImportExtensions {
+		if index := path.Join(candidate, "index"+ext); g.paths[index] {
+			return []string{index}
+		}
+	}
+	return nil
+}
+
+// resolveDotted разрешает модуль Python вида a.b.c, отбрасывая
+// последние сегменты, которые могут быть именами внутри модуля
+func (g *Graph) resolveDotted(base, dotted string) []string {
+	parts := strings.Split(dotted, ".")
+	for n := len(parts); n > 0; n-- {
+		modulePath := path.Join(append([]string{base}, parts[:n]...)...)
+		candidates := []string{modulePath + ".py", path.Join(modulePath, "__init__.py")}
+		for _, candidate := range candidates {
+			if g.paths[candidate] {
+				return []string{candidate}
+			}
+			// Модуль может лежать внутри корня исходников (src/a/b.py)
+			if base == "" {
+				if match := g.findBySuffix("/" + candidate); match != "" {
+					return []string{match}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// resolveGoPackage находит директорию, путь импорта которой заканчивается на ее путь
+func (g *Graph) resolveGoPackage(imp string) []string {
+	best := ""
+	for dir := range g.dirs {
+		if dir == "." {
+			continue
+		}
+		if imp == dir || strings.HasSuffix(imp, "/"+dir) {
+			if len(dir) > len(best) {
+				best = dir
+			}
+		}
+	}
+	if best == "" {
+		return nil
+	}
+
+	var files []string
+	for _, p := range g.dirs[best] {
+		if strings.HasSuffix(p, ".go") {
+			files = append(files, p)
+		}
+	}
+	return files
+}
+
+// findBySuffix возвращает единственный файл, путь которого заканчивается на suffix
+func (g *Graph) findBySuffix(suffix string) string {
+	match := ""
+	for p := range g.paths {
+		if strings.HasSuffix(p, suffix) {
+			if match != "" {
+				return "" // Неоднозначное совпадение
+			}
+			match = p
+		}
+	}
+	return match
+}
+
+// importPath извлекает путь импорта из строки FileStructure.Imports,
+// где перед путем может стоять псевдоним
+func importPath(imp string) string {
+	fields := strings.Fields(imp)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.Trim(fields[len(fields)-1], `"'`)
+}
+
+// normalize приводит путь к формату с прямыми слешами
+func normalize(p string) string {
+	return path.Clean(strings.ReplaceAll(p, "\\", "/"))
+}
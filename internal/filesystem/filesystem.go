@@ -13,15 +13,72 @@ import (
 // Scanner отвечает за сканирование файловой системы и сбор метаданных о файлах
 type Scanner struct {
 	config *config.Config
+
+	// Конфигурации поддиректорий из вложенных файлов .code-telescope.yaml
+	dirConfigs *config.DirectoryConfigs
+
+	// Файлы и директории, пропущенные при последнем сканировании
+	skipped []SkippedPath
 }
 
 // New создает новый экземпляр Scanner
 func New(cfg *config.Config) *Scanner {
 	return &Scanner{
-		config: cfg,
+		config:     cfg,
+		dirConfigs: config.NewDirectoryConfigs(cfg),
 	}
 }
 
+// ConfigFor возвращает конфигурацию, действующую для файла с учетом
+// вложенных файлов .code-telescope.yaml, найденных при последнем сканировании
+func (s *Scanner) ConfigFor(relPath string) *config.Config {
+	return s.dirConfigs.ForFile(relPath)
+}
+
+// DirectoryConfigs возвращает конфигурации поддиректорий, найденные при последнем сканировании
+func (s *Scanner) DirectoryConfigs() *config.DirectoryConfigs {
+	return s.dirConfigs
+}
+
+// LoadConfigFor загружает вложенные файлы .code-telescope.yaml на пути от корня
+// проекта до директории файла и возвращает действующую для файла конфигурацию.
+// Используется, когда нужен один файл без сканирования всего проекта.
+func (s *Scanner) LoadConfigFor(projectPath, relPath string) (*config.Config, error) {
+	s.dirConfigs = config.NewDirectoryConfigs(s.config)
+
+	dir := ""
+	parts := strings.Split(filepath.ToSlash(filepath.Dir(relPath)), "/")
+	for _, part := range parts {
+		if part == "." || part == "" {
+			continue
+		}
+		dir = filepath.Join(dir, part)
+
+		dirConfigPath := filepath.Join(projectPath, dir, config.ProjectConfigFileName)
+		if _, err := os.Stat(dirConfigPath); err != nil {
+			continue
+		}
+		dirConfig, err := config.LoadDirectoryConfig(dirConfigPath, s.dirConfigs.ForDir(filepath.Dir(dir)))
+		if err != nil {
+			return nil, err
+		}
+		s.dirConfigs.Set(dir, dirConfig)
+	}
+
+	return s.dirConfigs.ForFile(relPath), nil
+}
+
+// Skipped возвращает файлы и директории, пропущенные при последнем сканировании,
+// с причинами пропуска
+func (s *Scanner) Skipped() []SkippedPath {
+	return s.skipped
+}
+
+// skip запоминает пропущенный путь и причину
+func (s *Scanner) skip(relPath string, isDir bool, reason string) {
+	s.skipped = append(s.skipped, SkippedPath{Path: relPath, IsDir: isDir, Reason: reason})
+}
+
 // ScanProject сканирует директорию проекта и возвращает метаданные всех релевантных файлов
 func (s *Scanner) ScanProject(projectPath string) ([]*models.FileMetadata, error) {
 	// Получаем абсолютный путь к проекту
@@ -42,6 +99,8 @@ func (s *Scanner) ScanProject(projectPath string) ([]*models.FileMetadata, error
 
 	// Список файлов для результата
 	var files []*models.FileMetadata
+	s.dirConfigs = config.NewDirectoryConfigs(s.config)
+	s.skipped = nil
 
 	// Рекурсивно обходим директорию
 	err = filepath.Walk(absProjectPath, func(path string, info os.FileInfo, err error) error {
@@ -62,15 +121,32 @@ func (s *Scanner) ScanProject(projectPath string) ([]*models.FileMetadata, error
 			if relPath != "." {
 				depth := len(strings.Split(relPath, string(os.PathSeparator)))
 				if depth > s.config.FileSystem.MaxDepth {
+					s.skip(relPath, true, fmt.Sprintf("превышена максимальная глубина %d", s.config.FileSystem.MaxDepth))
 					return filepath.SkipDir
 				}
 			}
 
+			if relPath == "." {
+				return nil
+			}
+
 			// Проверяем, не нужно ли пропустить директорию на основе шаблонов исключения
-			if s.shouldExclude(relPath, true) {
+			parentConfig := s.dirConfigs.ForDir(filepath.Dir(relPath))
+			if pattern := s.matchExclude(parentConfig, relPath); pattern != "" {
+				s.skip(relPath, true, fmt.Sprintf("исключена шаблоном %s", pattern))
 				return filepath.SkipDir
 			}
 
+			// Вложенный файл конфигурации переопределяет настройки для поддерева
+			dirConfigPath := filepath.Join(path, config.ProjectConfigFileName)
+			if _, err := os.Stat(dirConfigPath); err == nil {
+				dirConfig, err := config.LoadDirectoryConfig(dirConfigPath, parentConfig)
+				if err != nil {
+					return err
+				}
+				s.dirConfigs.Set(relPath, dirConfig)
+			}
+
 			return nil
 		}
 
@@ -80,8 +156,14 @@ func (s *Scanner) ScanProject(projectPath string) ([]*models.FileMetadata, error
 			return nil
 		}
 
-		// Проверяем, подходит ли файл по шаблонам включения/исключения
-		if !s.shouldInclude(relPath) || s.shouldExclude(relPath, false) {
+		// Проверяем, подходит ли файл по шаблонам включения/исключения его директории
+		fileConfig := s.dirConfigs.ForFile(relPath)
+		if !s.shouldInclude(fileConfig, relPath) {
+			s.skip(relPath, false, "не соответствует шаблонам включения")
+			return nil
+		}
+		if pattern := s.matchExclude(fileConfig, relPath); pattern != "" {
+			s.skip(relPath, false, fmt.Sprintf("исключен шаблоном %s", pattern))
 			return nil
 		}
 
@@ -93,6 +175,7 @@ func (s *Scanner) ScanProject(projectPath string) ([]*models.FileMetadata, error
 
 		// Проверяем размер файла
 		if fileMetadata.Size > s.config.Parser.MaxFileSize {
+			s.skip(relPath, false, fmt.Sprintf("размер %d байт превышает parser.max_file_size (%d)", fileMetadata.Size, s.config.Parser.MaxFileSize))
 			return nil
 		}
 
@@ -110,13 +193,13 @@ func (s *Scanner) ScanProject(projectPath string) ([]*models.FileMetadata, error
 }
 
 // shouldInclude проверяет, соответствует ли файл шаблонам включения
-func (s *Scanner) shouldInclude(relPath string) bool {
+func (s *Scanner) shouldInclude(cfg *config.Config, relPath string) bool {
 	// Если шаблоны включения не указаны, включаем все файлы
-	if len(s.config.FileSystem.IncludePatterns) == 0 {
+	if len(cfg.FileSystem.IncludePatterns) == 0 {
 		return true
 	}
 
-	for _, pattern := range s.config.FileSystem.IncludePatterns {
+	for _, pattern := range cfg.FileSystem.IncludePatterns {
 		matched, err := filepath.Match(pattern, filepath.Base(relPath))
 		if err == nil && matched {
 			return true
@@ -126,21 +209,22 @@ func (s *Scanner) shouldInclude(relPath string) bool {
 	return false
 }
 
-// shouldExclude проверяет, соответствует ли файл или директория шаблонам исключения
-func (s *Scanner) shouldExclude(relPath string, isDir bool) bool {
-	for _, pattern := range s.config.FileSystem.ExcludePatterns {
+// matchExclude возвращает шаблон исключения, которому соответствует файл
+// или директория, или пустую строку
+func (s *Scanner) matchExclude(cfg *config.Config, relPath string) string {
+	for _, pattern := range cfg.FileSystem.ExcludePatterns {
 		// Проверяем полный путь
 		matched, err := filepath.Match(pattern, relPath)
 		if err == nil && matched {
-			return true
+			return pattern
 		}
 
 		// Проверяем только имя файла или директории
 		matched, err = filepath.Match(pattern, filepath.Base(relPath))
 		if err == nil && matched {
-			return true
+			return pattern
 		}
 	}
 
-	return false
+	return ""
 }
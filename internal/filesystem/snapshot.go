@@ -0,0 +1,159 @@
+package filesystem
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"sort"
+	"time"
+
+	"code-telescope/pkg/models"
+)
+
+// FileState описывает состояние файла в снимке проекта
+type FileState struct {
+	// Размер файла в байтах
+	Size int64
+
+	// Дата последнего изменения
+	ModTime time.Time
+
+	// Хеш содержимого (заполняется при сравнении снимков)
+	Hash string
+}
+
+// Snapshot представляет состояние файлов проекта по относительным путям
+type Snapshot map[string]FileState
+
+// Changes описывает изменения между двумя снимками проекта
+type Changes struct {
+	// Новые файлы
+	Added []string
+
+	// Файлы с измененным содержимым
+	Modified []string
+
+	// Удаленные файлы
+	Removed []string
+
+	// Переименованные файлы: старый путь -> новый путь
+	Renamed map[string]string
+}
+
+// NewSnapshot создает снимок по результатам сканирования проекта
+func NewSnapshot(files []*models.FileMetadata) Snapshot {
+	snapshot := make(Snapshot, len(files))
+	for _, file := range files {
+		snapshot[file.Path] = FileState{Size: file.Size, ModTime: file.ModTime}
+	}
+	return snapshot
+}
+
+// SameFiles проверяет, совпадают ли наборы файлов и их размеры и даты изменения
+func (s Snapshot) SameFiles(other Snapshot) bool {
+	if len(s) != len(other) {
+		return false
+	}
+	for path, state := range s {
+		otherState, ok := other[path]
+		if !ok || state.Size != otherState.Size || !state.ModTime.Equal(otherState.ModTime) {
+			return false
+		}
+	}
+	return true
+}
+
+// Empty проверяет, что изменений нет
+func (c Changes) Empty() bool {
+	return len(c.Added) == 0 && len(c.Modified) == 0 && len(c.Removed) == 0 && len(c.Renamed) == 0
+}
+
+// Diff сравнивает снимки проекта. Файлы с изменившимися размером или датой
+// сравниваются по хешу содержимого, чтобы не считать изменением простое
+// обновление даты. Новый файл с тем же содержимым, что и удаленный,
+// считается переименованием. Хеши файлов записываются в current.
+func Diff(old, current Snapshot, hash func(relPath string) (string, error)) Changes {
+	changes := Changes{Renamed: make(map[string]string)}
+
+	for _, path := range sortedPaths(current) {
+		state := current[path]
+		oldState, existed := old[path]
+
+		if existed && state.Size == oldState.Size && state.ModTime.Equal(oldState.ModTime) {
+			state.Hash = oldState.Hash
+			current[path] = state
+			continue
+		}
+
+		if sum, err := hash(path); err == nil {
+			state.Hash = sum
+			current[path] = state
+		}
+
+		switch {
+		case !existed:
+			changes.Added = append(changes.Added, path)
+		case state.Hash == "" || state.Hash != oldState.Hash:
+			changes.Modified = append(changes.Modified, path)
+		}
+	}
+
+	// Удаленные файлы, содержимое которых появилось под новым путем, считаются переименованными
+	added := make(map[string][]string)
+	for _, path := range changes.Added {
+		if sum := current[path].Hash; sum != "" {
+			added[sum] = append(added[sum], path)
+		}
+	}
+	renamedTo := make(map[string]bool)
+	for _, path := range sortedPaths(old) {
+		if _, ok := current[path]; ok {
+			continue
+		}
+		if candidates := added[old[path].Hash]; old[path].Hash != "" && len(candidates) > 0 {
+			changes.Renamed[path] = candidates[0]
+			renamedTo[candidates[0]] = true
+			added[old[path].Hash] = candidates[1:]
+			continue
+		}
+		changes.Removed = append(changes.Removed, path)
+	}
+
+	if len(renamedTo) > 0 {
+		remaining := changes.Added[:0]
+		for _, path := range changes.Added {
+			if !renamedTo[path] {
+				remaining = append(remaining, path)
+			}
+		}
+		changes.Added = remaining
+	}
+
+	return changes
+}
+
+// HashFile возвращает хеш SHA-256 содержимого файла
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// sortedPaths возвращает пути снимка в отсортированном порядке
+func sortedPaths(snapshot Snapshot) []string {
+	paths := make([]string, 0, len(snapshot))
+	for path := range snapshot {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"code-telescope/internal/filesystem"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hashByContent возвращает функцию хеширования по заранее заданному содержимому файлов
+func hashByContent(contents map[string]string) func(string) (string, error) {
+	return func(path string) (string, error) {
+		return "hash:" + contents[path], nil
+	}
+}
+
+// TestDiffSnapshots проверяет определение новых, измененных, удаленных и переименованных файлов
+func TestDiffSnapshots(t *testing.T) {
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	after := before.Add(time.Minute)
+
+	old := filesystem.Snapshot{
+		"main.go":    {Size: 10, ModTime: before},
+		"touched.go": {Size: 20, ModTime: before},
+		"edited.go":  {Size: 30, ModTime: before},
+		"removed.go": {Size: 40, ModTime: before},
+		"old/api.go": {Size: 50, ModTime: before},
+	}
+	filesystem.Diff(filesystem.Snapshot{}, old, hashByContent(map[string]string{
+		"main.go": "main", "touched.go": "touched", "edited.go": "edited",
+		"removed.go": "removed", "old/api.go": "api",
+	}))
+
+	current := filesystem.Snapshot{
+		"main.go":    {Size: 10, ModTime: before},
+		"touched.go": {Size: 20, ModTime: after},
+		"edited.go":  {Size: 31, ModTime: after},
+		"new/api.go": {Size: 50, ModTime: after},
+		"added.go":   {Size: 60, ModTime: after},
+	}
+	changes := filesystem.Diff(old, current, hashByContent(map[string]string{
+		"touched.go": "touched", "edited.go": "edited v2",
+		"new/api.go": "api", "added.go": "added",
+	}))
+
+	assert.Equal(t, []string{"added.go"}, changes.Added)
+	assert.Equal(t, []string{"edited.go"}, changes.Modified, "Изменение только даты не считается изменением")
+	assert.Equal(t, []string{"removed.go"}, changes.Removed)
+	assert.Equal(t, map[string]string{"old/api.go": "new/api.go"}, changes.Renamed)
+	assert.Equal(t, "hash:main", current["main.go"].Hash, "Хеш неизмененного файла переносится из старого снимка")
+}
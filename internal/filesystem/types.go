@@ -21,6 +21,18 @@ type FileGroup struct {
 	SubGroups []*FileGroup
 }
 
+// SkippedPath описывает файл или директорию, пропущенные при сканировании
+type SkippedPath struct {
+	// Путь относительно корня проекта
+	Path string
+
+	// Является ли путь директорией (пропускается все поддерево)
+	IsDir bool
+
+	// Причина пропуска
+	Reason string
+}
+
 // NewFileGroup создает новую группу файлов с указанным именем и путем
 func NewFileGroup(name, path string) *FileGroup {
 	return &FileGroup{
@@ -0,0 +1,175 @@
+package git
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNotRepository возвращается, если директория не находится внутри git-репозитория
+var ErrNotRepository = errors.New("директория не является git-репозиторием")
+
+// Repository представляет локальный git-репозиторий, прочитанный напрямую из .git
+type Repository struct {
+	// Корень рабочего дерева
+	Root string
+
+	// Директория с данными git (.git или gitdir рабочего дерева)
+	GitDir string
+
+	// Общая директория данных (для дополнительных рабочих деревьев)
+	CommonDir string
+
+	// Индексы pack-файлов, загружаются при первом чтении объекта
+	packs []*packFile
+}
+
+// Open находит git-репозиторий, содержащий указанный путь, поднимаясь вверх по директориям
+func Open(path string) (*Repository, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения абсолютного пути: %w", err)
+	}
+
+	for dir := absPath; ; dir = filepath.Dir(dir) {
+		dotGit := filepath.Join(dir, ".git")
+		info, err := os.Stat(dotGit)
+		if err == nil {
+			gitDir := dotGit
+			if !info.IsDir() {
+				// Рабочее дерево или подмодуль: .git содержит "gitdir: <путь>"
+				gitDir, err = readGitDirFile(dotGit)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return &Repository{
+				Root:      dir,
+				GitDir:    gitDir,
+				CommonDir: readCommonDir(gitDir),
+			}, nil
+		}
+
+		if filepath.Dir(dir) == dir {
+			return nil, ErrNotRepository
+		}
+	}
+}
+
+// HeadCommit возвращает SHA коммита, на который указывает HEAD
+func (r *Repository) HeadCommit() (string, error) {
+	data, err := os.ReadFile(filepath.Join(r.GitDir, "HEAD"))
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения HEAD: %w", err)
+	}
+
+	head := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(head, "ref: ") {
+		// Отсоединенный HEAD содержит SHA напрямую
+		return head, nil
+	}
+
+	return r.ResolveRef(strings.TrimPrefix(head, "ref: "))
+}
+
+// ResolveRef возвращает SHA коммита для полного имени ссылки (refs/heads/main).
+// Символические ссылки (refs/remotes/origin/HEAD) разыменовываются.
+func (r *Repository) ResolveRef(ref string) (string, error) {
+	for _, dir := range []string{r.GitDir, r.CommonDir} {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err == nil {
+			value := strings.TrimSpace(string(data))
+			if target, ok := strings.CutPrefix(value, "ref: "); ok && target != ref {
+				return r.ResolveRef(target)
+			}
+			return value, nil
+		}
+	}
+
+	// Ссылка может быть упакована в packed-refs
+	file, err := os.Open(filepath.Join(r.CommonDir, "packed-refs"))
+	if err != nil {
+		return "", fmt.Errorf("ссылка %s не найдена", ref)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+
+	return "", fmt.Errorf("ссылка %s не найдена", ref)
+}
+
+// RemoteURL возвращает URL удаленного репозитория из .git/config
+func (r *Repository) RemoteURL(remote string) (string, error) {
+	file, err := os.Open(filepath.Join(r.CommonDir, "config"))
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения конфигурации git: %w", err)
+	}
+	defer file.Close()
+
+	section := fmt.Sprintf(`[remote "%s"]`, remote)
+	inSection := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inSection = line == section
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(key) == "url" {
+			return strings.TrimSpace(value), nil
+		}
+	}
+
+	return "", fmt.Errorf("удаленный репозиторий %s не найден", remote)
+}
+
+// readGitDirFile читает путь к данным git из файла .git
+func readGitDirFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("ошибка чтения %s: %w", path, err)
+	}
+
+	content := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(content, "gitdir: ") {
+		return "", fmt.Errorf("некорректный формат файла %s", path)
+	}
+
+	gitDir := strings.TrimPrefix(content, "gitdir: ")
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return filepath.Clean(gitDir), nil
+}
+
+// readCommonDir возвращает общую директорию данных git
+func readCommonDir(gitDir string) string {
+	data, err := os.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err != nil {
+		return gitDir
+	}
+
+	commonDir := strings.TrimSpace(string(data))
+	if !filepath.IsAbs(commonDir) {
+		commonDir = filepath.Join(gitDir, commonDir)
+	}
+	return filepath.Clean(commonDir)
+}
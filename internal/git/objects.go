@@ -0,0 +1,703 @@
+package git
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Типы объектов git
+const (
+	objectCommit = "commit"
+	objectTree   = "tree"
+	objectBlob   = "blob"
+	objectTag    = "tag"
+)
+
+// Типы объектов в pack-файле
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+// Максимальная глубина цепочки дельт в pack-файле
+const maxDeltaDepth = 128
+
+// ErrObjectNotFound возвращается, если объекта нет в репозитории
+// (например, в неглубоком клоне отсутствует старая история)
+var ErrObjectNotFound = errors.New("объект не найден в репозитории")
+
+// packFile представляет pack-файл и его индекс (формат idx версии 2)
+type packFile struct {
+	path    string
+	fanout  [256]uint32
+	hashes  []byte
+	offsets []byte
+	large   []byte
+}
+
+// BlobHash возвращает SHA объекта blob для содержимого файла,
+// совпадающий с результатом git hash-object
+func BlobHash(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha1.New()
+	fmt.Fprintf(hash, "%s %d\x00", objectBlob, len(content))
+	hash.Write(content)
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// ResolveRevision возвращает SHA коммита для ревизии: SHA (в том числе
+// сокращенного), HEAD, имени ветки, тега или удаленной ветки, а также
+// выражений с суффиксами ~N и ^N (HEAD~2, origin/main^)
+func (r *Repository) ResolveRevision(revision string) (string, error) {
+	name, suffix := revision, ""
+	if i := strings.IndexAny(revision, "~^"); i >= 0 {
+		name, suffix = revision[:i], revision[i:]
+	}
+	if name == "" {
+		return "", fmt.Errorf("некорректная ревизия %s", revision)
+	}
+
+	sha, err := r.resolveName(name)
+	if err != nil {
+		return "", err
+	}
+	commit, err := r.peelToCommit(sha)
+	if err != nil {
+		return "", err
+	}
+
+	for suffix != "" {
+		op := suffix[0]
+		suffix = suffix[1:]
+		digits := 0
+		for digits < len(suffix) && suffix[digits] >= '0' && suffix[digits] <= '9' {
+			digits++
+		}
+		n := 1
+		if digits > 0 {
+			n, _ = strconv.Atoi(suffix[:digits])
+			suffix = suffix[digits:]
+		}
+
+		if op == '~' {
+			for i := 0; i < n; i++ {
+				if commit, err = r.parent(commit, 1); err != nil {
+					return "", fmt.Errorf("ревизия %s: %w", revision, err)
+				}
+			}
+			continue
+		}
+		if n == 0 {
+			continue // ^0 означает сам коммит
+		}
+		if commit, err = r.parent(commit, n); err != nil {
+			return "", fmt.Errorf("ревизия %s: %w", revision, err)
+		}
+	}
+
+	return commit, nil
+}
+
+// TreeFiles возвращает SHA объектов blob всех файлов коммита внутри директории
+// dir (путь относительно корня репозитория, "" — весь репозиторий).
+// Ключи — пути относительно dir с прямыми слешами. Подмодули пропускаются.
+func (r *Repository) TreeFiles(commit, dir string) (map[string]string, error) {
+	objectType, data, err := r.readObject(commit)
+	if err != nil {
+		return nil, err
+	}
+	if objectType != objectCommit {
+		return nil, fmt.Errorf("объект %s не является коммитом", commit)
+	}
+	tree, ok := commitHeader(data, "tree")
+	if !ok {
+		return nil, fmt.Errorf("в коммите %s нет дерева", commit)
+	}
+
+	// Спускаемся к дереву директории
+	dir = strings.Trim(filepath.ToSlash(dir), "/")
+	if dir != "" && dir != "." {
+		for _, part := range strings.Split(dir, "/") {
+			entries, err := r.readTree(tree)
+			if err != nil {
+				return nil, err
+			}
+			found := false
+			for _, entry := range entries {
+				if entry.name == part && entry.isDir() {
+					tree, found = entry.sha, true
+					break
+				}
+			}
+			if !found {
+				return map[string]string{}, nil // Директории в коммите нет
+			}
+		}
+	}
+
+	files := make(map[string]string)
+	if err := r.walkTree(tree, "", files); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// ReadBlob возвращает содержимое объекта blob
+func (r *Repository) ReadBlob(sha string) ([]byte, error) {
+	objectType, data, err := r.readObject(sha)
+	if err != nil {
+		return nil, err
+	}
+	if objectType != objectBlob {
+		return nil, fmt.Errorf("объект %s не является файлом", sha)
+	}
+	return data, nil
+}
+
+// ExtractTree записывает файлы коммита из директории dir (путь относительно
+// корня репозитория) в директорию dest, сохраняя относительные пути
+func (r *Repository) ExtractTree(commit, dir, dest string) error {
+	files, err := r.TreeFiles(commit, dir)
+	if err != nil {
+		return err
+	}
+
+	for path, sha := range files {
+		if !filepath.IsLocal(filepath.FromSlash(path)) {
+			return fmt.Errorf("недопустимый путь в дереве коммита: %s", path)
+		}
+		content, err := r.ReadBlob(sha)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return fmt.Errorf("ошибка создания директории: %w", err)
+		}
+		if err := os.WriteFile(target, content, 0644); err != nil {
+			return fmt.Errorf("ошибка записи файла %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// treeEntry представляет запись объекта tree
+type treeEntry struct {
+	mode string
+	name string
+	sha  string
+}
+
+// isDir проверяет, что запись является поддеревом
+func (e treeEntry) isDir() bool {
+	return e.mode == "40000"
+}
+
+// isSubmodule проверяет, что запись является подмодулем
+func (e treeEntry) isSubmodule() bool {
+	return e.mode == "160000"
+}
+
+// walkTree рекурсивно собирает файлы дерева
+func (r *Repository) walkTree(tree, prefix string, files map[string]string) error {
+	entries, err := r.readTree(tree)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := entry.name
+		if prefix != "" {
+			path = prefix + "/" + entry.name
+		}
+		switch {
+		case entry.isDir():
+			if err := r.walkTree(entry.sha, path, files); err != nil {
+				return err
+			}
+		case entry.isSubmodule():
+			continue
+		default:
+			files[path] = entry.sha
+		}
+	}
+	return nil
+}
+
+// readTree читает записи объекта tree
+func (r *Repository) readTree(sha string) ([]treeEntry, error) {
+	objectType, data, err := r.readObject(sha)
+	if err != nil {
+		return nil, err
+	}
+	if objectType != objectTree {
+		return nil, fmt.Errorf("объект %s не является деревом", sha)
+	}
+
+	var entries []treeEntry
+	for len(data) > 0 {
+		space := bytes.IndexByte(data, ' ')
+		nul := bytes.IndexByte(data, 0)
+		if space < 0 || nul < space || nul+21 > len(data) {
+			return nil, fmt.Errorf("поврежден объект дерева %s", sha)
+		}
+		entries = append(entries, treeEntry{
+			mode: string(data[:space]),
+			name: string(data[space+1 : nul]),
+			sha:  hex.EncodeToString(data[nul+1 : nul+21]),
+		})
+		data = data[nul+21:]
+	}
+	return entries, nil
+}
+
+// resolveName находит SHA объекта по имени ссылки или SHA
+func (r *Repository) resolveName(name string) (string, error) {
+	if name == "HEAD" || name == "@" {
+		return r.HeadCommit()
+	}
+
+	candidates := []string{name}
+	if !strings.HasPrefix(name, "refs/") {
+		candidates = append(candidates,
+			"refs/"+name,
+			"refs/tags/"+name,
+			"refs/heads/"+name,
+			"refs/remotes/"+name,
+			"refs/remotes/"+name+"/HEAD",
+		)
+	}
+	for _, ref := range candidates {
+		if sha, err := r.ResolveRef(ref); err == nil {
+			return sha, nil
+		}
+	}
+
+	if isHex(name) && len(name) >= 4 && len(name) <= 40 {
+		return r.expandSHA(strings.ToLower(name))
+	}
+	return "", fmt.Errorf("ревизия %s не найдена", name)
+}
+
+// peelToCommit разыменовывает аннотированные теги до коммита
+func (r *Repository) peelToCommit(sha string) (string, error) {
+	for i := 0; i < 10; i++ {
+		objectType, data, err := r.readObject(sha)
+		if err != nil {
+			return "", err
+		}
+		switch objectType {
+		case objectCommit:
+			return sha, nil
+		case objectTag:
+			target, ok := commitHeader(data, "object")
+			if !ok {
+				return "", fmt.Errorf("поврежден тег %s", sha)
+			}
+			sha = target
+		default:
+			return "", fmt.Errorf("объект %s (%s) не является коммитом", sha, objectType)
+		}
+	}
+	return "", fmt.Errorf("слишком длинная цепочка тегов для %s", sha)
+}
+
+// parent возвращает n-го родителя коммита (нумерация с 1)
+func (r *Repository) parent(commit string, n int) (string, error) {
+	_, data, err := r.readObject(commit)
+	if err != nil {
+		return "", err
+	}
+
+	var parents []string
+	for _, line := range strings.Split(string(headerBlock(data)), "\n") {
+		if value, ok := strings.CutPrefix(line, "parent "); ok {
+			parents = append(parents, value)
+		}
+	}
+	if n > len(parents) {
+		return "", fmt.Errorf("у коммита %s нет родителя %d", commit, n)
+	}
+	return parents[n-1], nil
+}
+
+// headerBlock возвращает заголовки объекта commit или tag до пустой строки
+func headerBlock(data []byte) []byte {
+	if end := bytes.Index(data, []byte("\n\n")); end >= 0 {
+		return data[:end]
+	}
+	return data
+}
+
+// commitHeader возвращает значение первого заголовка объекта commit или tag
+func commitHeader(data []byte, name string) (string, bool) {
+	for _, line := range strings.Split(string(headerBlock(data)), "\n") {
+		if value, ok := strings.CutPrefix(line, name+" "); ok {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// readObject читает объект из отдельного файла или из pack-файла
+func (r *Repository) readObject(sha string) (string, []byte, error) {
+	if len(sha) != 40 || !isHex(sha) {
+		return "", nil, fmt.Errorf("некорректный SHA %q", sha)
+	}
+
+	objectType, data, err := r.readLooseObject(sha)
+	if err == nil {
+		return objectType, data, nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", nil, err
+	}
+
+	packs, err := r.packFiles()
+	if err != nil {
+		return "", nil, err
+	}
+	raw, _ := hex.DecodeString(sha)
+	for _, pack := range packs {
+		if offset, ok := pack.find(raw); ok {
+			return r.readPackedObject(pack, offset, 0)
+		}
+	}
+	return "", nil, fmt.Errorf("%w: %s", ErrObjectNotFound, sha)
+}
+
+// readLooseObject читает объект из .git/objects/xx/yyyy
+func (r *Repository) readLooseObject(sha string) (string, []byte, error) {
+	file, err := os.Open(filepath.Join(r.objectsDir(), sha[:2], sha[2:]))
+	if err != nil {
+		return "", nil, err
+	}
+	defer file.Close()
+
+	reader, err := zlib.NewReader(file)
+	if err != nil {
+		return "", nil, fmt.Errorf("поврежден объект %s: %w", sha, err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", nil, fmt.Errorf("поврежден объект %s: %w", sha, err)
+	}
+	nul := bytes.IndexByte(data, 0)
+	space := bytes.IndexByte(data, ' ')
+	if nul < 0 || space < 0 || space > nul {
+		return "", nil, fmt.Errorf("поврежден заголовок объекта %s", sha)
+	}
+	return string(data[:space]), data[nul+1:], nil
+}
+
+// objectsDir возвращает директорию объектов репозитория
+func (r *Repository) objectsDir() string {
+	return filepath.Join(r.CommonDir, "objects")
+}
+
+// packFiles загружает индексы pack-файлов при первом обращении
+func (r *Repository) packFiles() ([]*packFile, error) {
+	if r.packs != nil {
+		return r.packs, nil
+	}
+
+	indexes, err := filepath.Glob(filepath.Join(r.objectsDir(), "pack", "*.idx"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(indexes)
+
+	r.packs = make([]*packFile, 0, len(indexes))
+	for _, index := range indexes {
+		pack, err := loadPackIndex(index)
+		if err != nil {
+			return nil, err
+		}
+		r.packs = append(r.packs, pack)
+	}
+	return r.packs, nil
+}
+
+// loadPackIndex читает индекс pack-файла версии 2
+func loadPackIndex(path string) (*packFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения индекса %s: %w", path, err)
+	}
+	if len(data) < 8+256*4 || !bytes.Equal(data[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(data[4:8]) != 2 {
+		return nil, fmt.Errorf("неподдерживаемый формат индекса %s", path)
+	}
+
+	pack := &packFile{path: strings.TrimSuffix(path, ".idx") + ".pack"}
+	for i := 0; i < 256; i++ {
+		pack.fanout[i] = binary.BigEndian.Uint32(data[8+i*4:])
+	}
+
+	count := int(pack.fanout[255])
+	hashesStart := 8 + 256*4
+	offsetsStart := hashesStart + count*20 + count*4
+	largeStart := offsetsStart + count*4
+	if len(data) < largeStart {
+		return nil, fmt.Errorf("поврежден индекс %s", path)
+	}
+	pack.hashes = data[hashesStart : hashesStart+count*20]
+	pack.offsets = data[offsetsStart:largeStart]
+	pack.large = data[largeStart:]
+	return pack, nil
+}
+
+// find возвращает смещение объекта в pack-файле
+func (p *packFile) find(sha []byte) (int64, bool) {
+	lo := 0
+	if sha[0] > 0 {
+		lo = int(p.fanout[sha[0]-1])
+	}
+	hi := int(p.fanout[sha[0]])
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(p.hashes[(lo+i)*20:(lo+i+1)*20], sha) >= 0
+	})
+	if i >= hi || !bytes.Equal(p.hashes[i*20:(i+1)*20], sha) {
+		return 0, false
+	}
+
+	offset := binary.BigEndian.Uint32(p.offsets[i*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+	largeIndex := int(offset & 0x7fffffff)
+	if len(p.large) < (largeIndex+1)*8 {
+		return 0, false
+	}
+	return int64(binary.BigEndian.Uint64(p.large[largeIndex*8:])), true
+}
+
+// readPackedObject читает объект из pack-файла, применяя дельты
+func (r *Repository) readPackedObject(pack *packFile, offset int64, depth int) (string, []byte, error) {
+	if depth > maxDeltaDepth {
+		return "", nil, fmt.Errorf("слишком длинная цепочка дельт в %s", pack.path)
+	}
+
+	file, err := os.Open(pack.path)
+	if err != nil {
+		return "", nil, fmt.Errorf("ошибка чтения %s: %w", pack.path, err)
+	}
+	defer file.Close()
+
+	// Заголовок: тип и размер переменной длины
+	header := make([]byte, 32)
+	n, err := file.ReadAt(header, offset)
+	if n == 0 {
+		return "", nil, fmt.Errorf("ошибка чтения %s: %w", pack.path, err)
+	}
+	header = header[:n]
+	packType := (header[0] >> 4) & 0x07
+	pos := 1
+	for header[pos-1]&0x80 != 0 && pos < len(header) {
+		pos++
+	}
+
+	var baseType string
+	var base []byte
+	switch packType {
+	case packOfsDelta:
+		// Смещение базового объекта относительно текущего
+		c := header[pos]
+		pos++
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 && pos < len(header) {
+			c = header[pos]
+			pos++
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+		baseType, base, err = r.readPackedObject(pack, offset-rel, depth+1)
+	case packRefDelta:
+		if len(header) < pos+20 {
+			return "", nil, fmt.Errorf("поврежден %s", pack.path)
+		}
+		baseSHA := hex.EncodeToString(header[pos : pos+20])
+		pos += 20
+		baseType, base, err = r.readObject(baseSHA)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	reader, err := zlib.NewReader(io.NewSectionReader(file, offset+int64(pos), 1<<62))
+	if err != nil {
+		return "", nil, fmt.Errorf("поврежден объект в %s: %w", pack.path, err)
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return "", nil, fmt.Errorf("поврежден объект в %s: %w", pack.path, err)
+	}
+
+	switch packType {
+	case packCommit:
+		return objectCommit, data, nil
+	case packTree:
+		return objectTree, data, nil
+	case packBlob:
+		return objectBlob, data, nil
+	case packTag:
+		return objectTag, data, nil
+	case packOfsDelta, packRefDelta:
+		result, err := applyDelta(base, data)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", pack.path, err)
+		}
+		return baseType, result, nil
+	default:
+		return "", nil, fmt.Errorf("неизвестный тип объекта %d в %s", packType, pack.path)
+	}
+}
+
+// applyDelta применяет дельту git к базовому объекту
+func applyDelta(base, delta []byte) ([]byte, error) {
+	errCorrupt := errors.New("повреждена дельта объекта")
+
+	readSize := func() (int, bool) {
+		size, shift := 0, 0
+		for len(delta) > 0 {
+			c := delta[0]
+			delta = delta[1:]
+			size |= int(c&0x7f) << shift
+			shift += 7
+			if c&0x80 == 0 {
+				return size, true
+			}
+		}
+		return 0, false
+	}
+
+	baseSize, ok := readSize()
+	if !ok || baseSize != len(base) {
+		return nil, errCorrupt
+	}
+	resultSize, ok := readSize()
+	if !ok {
+		return nil, errCorrupt
+	}
+
+	result := make([]byte, 0, resultSize)
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		if op&0x80 == 0 {
+			// Вставка op байт из дельты
+			if op == 0 || int(op) > len(delta) {
+				return nil, errCorrupt
+			}
+			result = append(result, delta[:op]...)
+			delta = delta[op:]
+			continue
+		}
+
+		// Копирование из базового объекта
+		var offset, size int
+		for i := 0; i < 4; i++ {
+			if op&(1<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errCorrupt
+				}
+				offset |= int(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		for i := 0; i < 3; i++ {
+			if op&(0x10<<i) != 0 {
+				if len(delta) == 0 {
+					return nil, errCorrupt
+				}
+				size |= int(delta[0]) << (8 * i)
+				delta = delta[1:]
+			}
+		}
+		if size == 0 {
+			size = 0x10000
+		}
+		if offset+size > len(base) {
+			return nil, errCorrupt
+		}
+		result = append(result, base[offset:offset+size]...)
+	}
+
+	if len(result) != resultSize {
+		return nil, errCorrupt
+	}
+	return result, nil
+}
+
+// expandSHA находит объект по сокращенному SHA
+func (r *Repository) expandSHA(prefix string) (string, error) {
+	if len(prefix) == 40 {
+		return prefix, nil
+	}
+
+	matches := make(map[string]bool)
+	entries, _ := os.ReadDir(filepath.Join(r.objectsDir(), prefix[:2]))
+	for _, entry := range entries {
+		if sha := prefix[:2] + entry.Name(); strings.HasPrefix(sha, prefix) {
+			matches[sha] = true
+		}
+	}
+
+	packs, err := r.packFiles()
+	if err != nil {
+		return "", err
+	}
+	for _, pack := range packs {
+		count := len(pack.hashes) / 20
+		start := sort.Search(count, func(i int) bool {
+			return hex.EncodeToString(pack.hashes[i*20:(i+1)*20]) >= prefix
+		})
+		for i := start; i < count; i++ {
+			sha := hex.EncodeToString(pack.hashes[i*20 : (i+1)*20])
+			if !strings.HasPrefix(sha, prefix) {
+				break
+			}
+			matches[sha] = true
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("ревизия %s не найдена", prefix)
+	case 1:
+		for sha := range matches {
+			return sha, nil
+		}
+	}
+	return "", fmt.Errorf("сокращенный SHA %s неоднозначен", prefix)
+}
+
+// isHex проверяет, что строка состоит из шестнадцатеричных цифр
+func isHex(s string) bool {
+	for _, c := range s {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,172 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/git"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepo создает объекты git в директории .git без использования git
+type testRepo struct {
+	t    *testing.T
+	root string
+}
+
+// newTestRepo создает пустой репозиторий
+func newTestRepo(t *testing.T) *testRepo {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "objects"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "refs", "heads"), 0755))
+	return &testRepo{t: t, root: root}
+}
+
+// object записывает объект и возвращает его SHA
+func (r *testRepo) object(objectType string, content []byte) string {
+	data := append([]byte(fmt.Sprintf("%s %d\x00", objectType, len(content))), content...)
+	sum := sha1.Sum(data)
+	sha := hex.EncodeToString(sum[:])
+
+	var compressed bytes.Buffer
+	writer := zlib.NewWriter(&compressed)
+	_, err := writer.Write(data)
+	require.NoError(r.t, err)
+	require.NoError(r.t, writer.Close())
+
+	dir := filepath.Join(r.root, ".git", "objects", sha[:2])
+	require.NoError(r.t, os.MkdirAll(dir, 0755))
+	require.NoError(r.t, os.WriteFile(filepath.Join(dir, sha[2:]), compressed.Bytes(), 0644))
+	return sha
+}
+
+// tree записывает дерево из записей имя -> (режим, SHA)
+func (r *testRepo) tree(entries ...[3]string) string {
+	var content []byte
+	for _, entry := range entries {
+		raw, err := hex.DecodeString(entry[2])
+		require.NoError(r.t, err)
+		content = append(content, []byte(entry[0]+" "+entry[1]+"\x00")...)
+		content = append(content, raw...)
+	}
+	return r.object("tree", content)
+}
+
+// commit записывает коммит с деревом и родителями
+func (r *testRepo) commit(tree string, parents ...string) string {
+	content := "tree " + tree + "\n"
+	for _, parent := range parents {
+		content += "parent " + parent + "\n"
+	}
+	content += "author a <a@example.com> 0 +0000\ncommitter a <a@example.com> 0 +0000\n\nmessage\n"
+	return r.object("commit", []byte(content))
+}
+
+// ref записывает ссылку
+func (r *testRepo) ref(name, value string) {
+	path := filepath.Join(r.root, ".git", filepath.FromSlash(name))
+	require.NoError(r.t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(r.t, os.WriteFile(path, []byte(value+"\n"), 0644))
+}
+
+// TestTreeFilesAndRevisions проверяет разрешение ревизий и чтение файлов коммита
+func TestTreeFilesAndRevisions(t *testing.T) {
+	repo := newTestRepo(t)
+
+	mainV1 := repo.object("blob", []byte("package main\n"))
+	mainV2 := repo.object("blob", []byte("package main\n\nfunc main() {}\n"))
+	util := repo.object("blob", []byte("package util\n"))
+
+	first := repo.commit(repo.tree(
+		[3]string{"100644", "main.go", mainV1},
+	))
+	second := repo.commit(repo.tree(
+		[3]string{"100644", "main.go", mainV2},
+		[3]string{"40000", "pkg", repo.tree([3]string{"100644", "util.go", util})},
+		[3]string{"160000", "vendor-sub", mainV1},
+	), first)
+	repo.ref("refs/heads/main", second)
+	repo.ref("refs/remotes/origin/HEAD", "ref: refs/heads/main")
+	repo.ref("HEAD", "ref: refs/heads/main")
+
+	r, err := git.Open(repo.root)
+	require.NoError(t, err)
+
+	for revision, expected := range map[string]string{
+		"HEAD":            second,
+		"main":            second,
+		"origin":          second,
+		"main~1":          first,
+		"HEAD^":           first,
+		second[:10]:       second,
+		"refs/heads/main": second,
+	} {
+		sha, err := r.ResolveRevision(revision)
+		require.NoError(t, err, revision)
+		assert.Equal(t, expected, sha, revision)
+	}
+	_, err = r.ResolveRevision("main~2")
+	assert.Error(t, err)
+	_, err = r.ResolveRevision("missing")
+	assert.Error(t, err)
+
+	files, err := r.TreeFiles(second, "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"main.go": mainV2, "pkg/util.go": util}, files, "подмодули пропускаются")
+
+	files, err = r.TreeFiles(second, "pkg")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"util.go": util}, files)
+
+	files, err = r.TreeFiles(first, "pkg")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+// TestBlobHash проверяет, что хеш файла совпадает с SHA объекта blob
+func TestBlobHash(t *testing.T) {
+	repo := newTestRepo(t)
+	content := []byte("package main\n")
+	expected := repo.object("blob", content)
+
+	path := filepath.Join(repo.root, "main.go")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+
+	sha, err := git.BlobHash(path)
+	require.NoError(t, err)
+	assert.Equal(t, expected, sha)
+}
+
+// TestExtractTree проверяет извлечение файлов директории коммита
+func TestExtractTree(t *testing.T) {
+	repo := newTestRepo(t)
+	util := repo.object("blob", []byte("package util\n"))
+	commit := repo.commit(repo.tree(
+		[3]string{"100644", "main.go", repo.object("blob", []byte("package main\n"))},
+		[3]string{"40000", "pkg", repo.tree([3]string{"100644", "util.go", util})},
+	))
+
+	r, err := git.Open(repo.root)
+	require.NoError(t, err)
+
+	content, err := r.ReadBlob(util)
+	require.NoError(t, err)
+	assert.Equal(t, "package util\n", string(content))
+	_, err = r.ReadBlob(commit)
+	assert.Error(t, err, "коммит не является файлом")
+
+	dest := t.TempDir()
+	require.NoError(t, r.ExtractTree(commit, "pkg", dest))
+	data, err := os.ReadFile(filepath.Join(dest, "util.go"))
+	require.NoError(t, err)
+	assert.Equal(t, "package util\n", string(data))
+	assert.NoFileExists(t, filepath.Join(dest, "main.go"))
+}
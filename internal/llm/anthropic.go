@@ -20,6 +20,7 @@ type AnthropicProvider struct {
 	model      string
 	baseURL    string
 	httpClient *http.Client
+	batch      BatchSettings
 }
 
 // AnthropicConfig содержит параметры конфигурации для Anthropic
@@ -28,6 +29,7 @@ type AnthropicConfig struct {
 	Model   string `json:"model"`
 	BaseURL string `json:"base_url"`
 	Timeout int    `json:"timeout_seconds"`
+	BatchSettings
 }
 
 // SetHTTPClient устанавливает HTTP клиент для провайдера (используется для тестирования)
@@ -90,6 +92,7 @@ func NewAnthropicProvider(config map[string]interface{}) (LLMProvider, error) {
 		httpClient: &http.Client{
 			Timeout: time.Duration(cfg.Timeout) * time.Second,
 		},
+		batch: cfg.BatchSettings,
 	}, nil
 }
 
@@ -100,97 +103,163 @@ func (p *AnthropicProvider) Name() string {
 
 // anthropicRequest представляет запрос к Anthropic API
 type anthropicRequest struct {
-	Model       string             `json:"model"`
-	Messages    []anthropicMessage `json:"messages"`
-	MaxTokens   int                `json:"max_tokens,omitempty"`
-	Temperature float64            `json:"temperature"`
+	Model       string                  `json:"model"`
+	System      []anthropicContentBlock `json:"system,omitempty"`
+	Messages    []anthropicMessage      `json:"messages"`
+	MaxTokens   int                     `json:"max_tokens,omitempty"`
+	Temperature float64                 `json:"temperature"`
 }
 
 // anthropicMessage представляет сообщение в запросе к Anthropic API
 type anthropicMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
 }
 
+// anthropicContentBlock представляет текстовый блок системного промпта или сообщения
+type anthropicContentBlock struct {
+	Type         string                 `json:"type"`
+	Text         string                 `json:"text"`
+	CacheControl *anthropicCacheControl `json:"cache_control,omitempty"`
+}
+
+// anthropicCacheControl отмечает конец кэшируемого префикса промпта
+type anthropicCacheControl struct {
+	Type string `json:"type"`
+}
+
+// ephemeralCache — точка кэширования префикса промпта на стороне Anthropic
+var ephemeralCache = &anthropicCacheControl{Type: "ephemeral"}
+
 // anthropicResponse представляет ответ от Anthropic API
 type anthropicResponse struct {
 	Content []struct {
 		Text string `json:"text"`
 	} `json:"content"`
 	Usage struct {
-		InputTokens  int `json:"input_tokens"`
-		OutputTokens int `json:"output_tokens"`
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens"`
 	} `json:"usage"`
 	StopReason string `json:"stop_reason"`
 }
 
 // GenerateText отправляет запрос к Anthropic API и возвращает сгенерированный текст
 func (p *AnthropicProvider) GenerateText(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	jsonData, err := json.Marshal(p.newAPIRequest(request))
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("ошибка при маршалинге запроса: %w", err)
+	}
+
+	var apiResponse anthropicResponse
+	if err := p.do(ctx, http.MethodPost, p.baseURL, bytes.NewBuffer(jsonData), &apiResponse); err != nil {
+		return LLMResponse{}, err
+	}
+	return p.newResponse(apiResponse)
+}
+
+// newAPIRequest формирует запрос к Messages API. Инструкции передаются
+// системным блоком, общий контекст — первым блоком сообщения; после каждого
+// из них ставится точка кэширования, чтобы следующие запросы с теми же
+// инструкциями и контекстом читали префикс из кэша.
+func (p *AnthropicProvider) newAPIRequest(request LLMRequest) anthropicRequest {
 	apiRequest := anthropicRequest{
-		Model: p.model,
-		Messages: []anthropicMessage{
-			{
-				Role:    "user",
-				Content: request.Prompt,
-			},
-		},
+		Model:       p.model,
 		MaxTokens:   request.MaxTokens,
 		Temperature: request.Temperature,
 	}
+	if request.System != "" {
+		apiRequest.System = []anthropicContentBlock{{Type: "text", Text: request.System, CacheControl: ephemeralCache}}
+	}
 
-	jsonData, err := json.Marshal(apiRequest)
-	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ошибка при маршалинге запроса: %w", err)
+	var content []anthropicContentBlock
+	if request.SharedPrefix != "" {
+		content = append(content, anthropicContentBlock{Type: "text", Text: request.SharedPrefix, CacheControl: ephemeralCache})
+	}
+	if request.Prompt != "" || len(content) == 0 {
+		content = append(content, anthropicContentBlock{Type: "text", Text: request.Prompt})
 	}
+	apiRequest.Messages = []anthropicMessage{{Role: "user", Content: content}}
+	return apiRequest
+}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL, bytes.NewBuffer(jsonData))
+// newResponse преобразует ответ Messages API
+func (p *AnthropicProvider) newResponse(apiResponse anthropicResponse) (LLMResponse, error) {
+	if len(apiResponse.Content) == 0 {
+		return LLMResponse{}, ErrInvalidResponse
+	}
+
+	truncated := false
+	if apiResponse.StopReason == "max_tokens" {
+		truncated = true
+	}
+
+	usage := apiResponse.Usage
+	totalTokens := usage.InputTokens + usage.OutputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+
+	return LLMResponse{
+		Text:             apiResponse.Content[0].Text,
+		TokensUsed:       totalTokens,
+		InputTokens:      usage.InputTokens,
+		OutputTokens:     usage.OutputTokens,
+		Truncated:        truncated,
+		Provider:         p.Name(),
+		Model:            p.model,
+		CacheReadTokens:  usage.CacheReadInputTokens,
+		CacheWriteTokens: usage.CacheCreationInputTokens,
+	}, nil
+}
+
+// do выполняет HTTP-запрос к Anthropic API. Если out не nil, ответ декодируется
+// как JSON, если out — *[]byte, в него записывается тело ответа.
+func (p *AnthropicProvider) do(ctx context.Context, method, url string, body io.Reader, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ошибка при создании HTTP-запроса: %w", err)
+		return fmt.Errorf("ошибка при создании HTTP-запроса: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
+	if body != nil {
+		httpReq.Header.Set("Content-Type", "application/json")
+	}
 	httpReq.Header.Set("X-Api-Key", p.apiKey)
 	httpReq.Header.Set("Anthropic-Version", "2023-06-01")
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ошибка при выполнении HTTP-запроса: %w", err)
+		return fmt.Errorf("ошибка при выполнении HTTP-запроса: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return LLMResponse{}, fmt.Errorf("ошибка API: статус %d: %s", resp.StatusCode, string(bodyBytes))
+		return fmt.Errorf("ошибка API: статус %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	var apiResponse anthropicResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return LLMResponse{}, fmt.Errorf("ошибка при декодировании ответа: %w", err)
-	}
-
-	if len(apiResponse.Content) == 0 {
-		return LLMResponse{}, ErrInvalidResponse
+	if raw, ok := out.(*[]byte); ok {
+		*raw, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("ошибка при чтении ответа: %w", err)
+		}
+		return nil
 	}
-
-	truncated := false
-	if apiResponse.StopReason == "max_tokens" {
-		truncated = true
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("ошибка при декодировании ответа: %w", err)
+		}
 	}
-
-	totalTokens := apiResponse.Usage.InputTokens + apiResponse.Usage.OutputTokens
-
-	return LLMResponse{
-		Text:       apiResponse.Content[0].Text,
-		TokensUsed: totalTokens,
-		Truncated:  truncated,
-	}, nil
+	return nil
 }
 
-// BatchGenerateText отправляет несколько запросов к Anthropic API пакетом
+// BatchGenerateText отправляет несколько запросов к Anthropic API. Если включен
+// пакетный API, запросы отправляются одним заданием Message Batches, иначе
+// обрабатываются последовательно.
 func (p *AnthropicProvider) BatchGenerateText(ctx context.Context, requests []LLMRequest) ([]LLMResponse, error) {
-	responses := make([]LLMResponse, len(requests))
+	if p.batch.BatchAPI {
+		return runBatch(ctx, p, p.model, p.batch, requests)
+	}
 
-	// Anthropic также не поддерживает нативный батчинг, обрабатываем запросы последовательно
+	responses := make([]LLMResponse, len(requests))
 	for i, req := range requests {
 		resp, err := p.GenerateText(ctx, req)
 		if err != nil {
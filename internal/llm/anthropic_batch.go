@@ -0,0 +1,111 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// anthropicBatchRequest представляет запрос внутри задания Message Batches
+type anthropicBatchRequest struct {
+	CustomID string           `json:"custom_id"`
+	Params   anthropicRequest `json:"params"`
+}
+
+// anthropicBatch представляет задание Message Batches
+type anthropicBatch struct {
+	ID               string `json:"id"`
+	ProcessingStatus string `json:"processing_status"`
+	ResultsURL       string `json:"results_url"`
+}
+
+// anthropicBatchResult представляет строку файла результатов задания
+type anthropicBatchResult struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string            `json:"type"`
+		Message anthropicResponse `json:"message"`
+	} `json:"result"`
+}
+
+// batchesURL возвращает адрес заданий Message Batches
+func (p *AnthropicProvider) batchesURL() string {
+	return strings.TrimRight(p.baseURL, "/") + "/batches"
+}
+
+// submitBatch создает задание из всех запросов
+func (p *AnthropicProvider) submitBatch(ctx context.Context, requests []LLMRequest) (string, error) {
+	batchRequests := make([]anthropicBatchRequest, 0, len(requests))
+	for i, request := range requests {
+		batchRequests = append(batchRequests, anthropicBatchRequest{
+			CustomID: batchCustomID(i),
+			Params:   p.newAPIRequest(request),
+		})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"requests": batchRequests})
+	if err != nil {
+		return "", fmt.Errorf("ошибка при маршалинге запроса: %w", err)
+	}
+
+	var batch anthropicBatch
+	if err := p.do(ctx, http.MethodPost, p.batchesURL(), bytes.NewReader(body), &batch); err != nil {
+		return "", err
+	}
+	if batch.ID == "" {
+		return "", ErrInvalidResponse
+	}
+	return batch.ID, nil
+}
+
+// checkBatch возвращает состояние задания. Задание считается завершенным,
+// когда обработаны все запросы, в том числе с ошибкой или после отмены.
+func (p *AnthropicProvider) checkBatch(ctx context.Context, id string) (batchStatus, error) {
+	var batch anthropicBatch
+	if err := p.do(ctx, http.MethodGet, p.batchesURL()+"/"+id, nil, &batch); err != nil {
+		return batchStatus{}, err
+	}
+	if batch.ProcessingStatus != "ended" {
+		return batchStatus{}, nil
+	}
+	if batch.ResultsURL == "" {
+		return batchStatus{}, fmt.Errorf("%w: нет ссылки на результаты", ErrBatchFailed)
+	}
+	return batchStatus{Done: true, Results: batch.ResultsURL}, nil
+}
+
+// batchResults загружает результаты задания; запросы, завершившиеся ошибкой,
+// отменой или истечением срока, пропускаются
+func (p *AnthropicProvider) batchResults(ctx context.Context, status batchStatus) (map[string]LLMResponse, error) {
+	var content []byte
+	if err := p.do(ctx, http.MethodGet, status.Results, nil, &content); err != nil {
+		return nil, err
+	}
+
+	results := make(map[string]LLMResponse)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var result anthropicBatchResult
+		if err := json.Unmarshal(line, &result); err != nil {
+			return nil, fmt.Errorf("ошибка при декодировании результата: %w", err)
+		}
+		if result.Result.Type != "succeeded" {
+			continue
+		}
+		response, err := p.newResponse(result.Result.Message)
+		if err != nil {
+			continue
+		}
+		results[result.CustomID] = response
+	}
+	return results, scanner.Err()
+}
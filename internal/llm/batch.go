@@ -0,0 +1,226 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"code-telescope/internal/logger"
+)
+
+// ErrBatchFailed возвращается, если пакетное задание завершилось ошибкой,
+// истекло или было отменено на стороне провайдера. Сохраненный идентификатор
+// такого задания удаляется, и следующий запуск отправит задание заново.
+var ErrBatchFailed = errors.New("пакетное задание провайдера завершилось ошибкой")
+
+// Интервалы опроса пакетного задания по умолчанию
+const (
+	defaultBatchPollInterval    = 30 * time.Second
+	defaultBatchMaxPollInterval = 10 * time.Minute
+)
+
+// BatchSettings содержит общие для провайдеров настройки пакетных заданий.
+// Встраивается в конфигурацию провайдеров, поддерживающих пакетный API.
+type BatchSettings struct {
+	// Отправлять BatchGenerateText одним асинхронным заданием провайдера
+	BatchAPI bool `json:"batch_api"`
+
+	// Директория, где хранятся идентификаторы незавершенных заданий
+	// (пусто — идентификаторы не сохраняются)
+	BatchStateDir string `json:"batch_state_dir"`
+
+	// Начальный и максимальный интервал опроса статуса задания
+	BatchPollInterval    time.Duration `json:"batch_poll_interval"`
+	BatchMaxPollInterval time.Duration `json:"batch_max_poll_interval"`
+}
+
+// withDefaults возвращает настройки с заполненными интервалами опроса
+func (s BatchSettings) withDefaults() BatchSettings {
+	if s.BatchPollInterval <= 0 {
+		s.BatchPollInterval = defaultBatchPollInterval
+	}
+	if s.BatchMaxPollInterval < s.BatchPollInterval {
+		s.BatchMaxPollInterval = max(defaultBatchMaxPollInterval, s.BatchPollInterval)
+	}
+	return s
+}
+
+// batchStatus описывает состояние пакетного задания
+type batchStatus struct {
+	// Задание завершено, результаты можно забирать
+	Done bool
+	// Ссылка на результаты: идентификатор файла или URL, в зависимости от провайдера
+	Results string
+}
+
+// batchVendor реализует пакетный API конкретного провайдера
+type batchVendor interface {
+	Name() string
+
+	// submitBatch создает задание; запросы идентифицируются batchCustomID(i)
+	submitBatch(ctx context.Context, requests []LLMRequest) (string, error)
+
+	// checkBatch возвращает состояние задания или ErrBatchFailed
+	checkBatch(ctx context.Context, id string) (batchStatus, error)
+
+	// batchResults загружает ответы по custom_id
+	batchResults(ctx context.Context, status batchStatus) (map[string]LLMResponse, error)
+}
+
+// batchJob представляет сохраненное незавершенное задание
+type batchJob struct {
+	Provider    string    `json:"provider"`
+	Model       string    `json:"model"`
+	ID          string    `json:"id"`
+	Requests    int       `json:"requests"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// batchCustomID возвращает идентификатор запроса внутри задания
+func batchCustomID(i int) string {
+	return "request-" + strconv.Itoa(i)
+}
+
+// batchJobKey возвращает ключ набора запросов. Повторный запуск с теми же
+// промптами получает тот же ключ и продолжает ожидание сохраненного задания.
+func batchJobKey(provider, model string, requests []LLMRequest) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00%s\x00%d\x00", provider, model, len(requests))
+	for _, request := range requests {
+		fmt.Fprintf(hash, "%d\x00%g\x00%s\x00", request.MaxTokens, request.Temperature, PromptKey(request.FullPrompt()))
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:32]
+}
+
+// runBatch выполняет запросы одним пакетным заданием провайдера: отправляет
+// задание или продолжает ожидание сохраненного, опрашивает его статус с
+// растущим интервалом и сопоставляет результаты с запросами по custom_id.
+// Для запросов без результата возвращается пустой ответ.
+func runBatch(ctx context.Context, vendor batchVendor, model string, settings BatchSettings, requests []LLMRequest) ([]LLMResponse, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+	settings = settings.withDefaults()
+	statePath := ""
+	if settings.BatchStateDir != "" {
+		statePath = filepath.Join(settings.BatchStateDir, vendor.Name()+"-"+batchJobKey(vendor.Name(), model, requests)+".json")
+	}
+
+	job, ok := loadBatchJob(statePath)
+	if ok {
+		logger.WithFields(logger.Fields{
+			"provider": vendor.Name(),
+			"batch_id": job.ID,
+		}).Info("Продолжение ожидания ранее отправленного пакетного задания")
+	} else {
+		id, err := vendor.submitBatch(ctx, requests)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при отправке пакетного задания: %w", err)
+		}
+		job = batchJob{Provider: vendor.Name(), Model: model, ID: id, Requests: len(requests), SubmittedAt: time.Now().UTC()}
+		if err := saveBatchJob(statePath, job); err != nil {
+			logger.WithError(err).Warn("Не удалось сохранить идентификатор пакетного задания")
+		}
+		logger.WithFields(logger.Fields{
+			"provider": vendor.Name(),
+			"batch_id": id,
+			"requests": len(requests),
+		}).Info("Пакетное задание отправлено")
+	}
+
+	status, err := pollBatch(ctx, settings, func(ctx context.Context) (batchStatus, error) {
+		return vendor.checkBatch(ctx, job.ID)
+	})
+	if err != nil {
+		if errors.Is(err, ErrBatchFailed) && statePath != "" {
+			os.Remove(statePath)
+		}
+		return nil, fmt.Errorf("пакетное задание %s: %w", job.ID, err)
+	}
+
+	results, err := vendor.batchResults(ctx, status)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при загрузке результатов пакетного задания %s: %w", job.ID, err)
+	}
+	if statePath != "" {
+		os.Remove(statePath)
+	}
+
+	responses := make([]LLMResponse, len(requests))
+	missing := 0
+	for i := range requests {
+		response, ok := results[batchCustomID(i)]
+		if !ok {
+			missing++
+			continue
+		}
+		responses[i] = response
+	}
+	if missing > 0 {
+		logger.Warnf("Пакетное задание %s: нет результатов для %d из %d запросов", job.ID, missing, len(requests))
+	}
+	return responses, nil
+}
+
+// pollBatch опрашивает статус задания, удваивая паузу между опросами
+// до BatchMaxPollInterval, пока задание не завершится
+func pollBatch(ctx context.Context, settings BatchSettings, check func(context.Context) (batchStatus, error)) (batchStatus, error) {
+	interval := settings.BatchPollInterval
+	for {
+		status, err := check(ctx)
+		if err != nil || status.Done {
+			return status, err
+		}
+
+		logger.Debugf("Пакетное задание выполняется, следующий опрос через %s", interval)
+		select {
+		case <-ctx.Done():
+			return batchStatus{}, ctx.Err()
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, settings.BatchMaxPollInterval)
+	}
+}
+
+// loadBatchJob читает сохраненное задание
+func loadBatchJob(path string) (batchJob, bool) {
+	if path == "" {
+		return batchJob{}, false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return batchJob{}, false
+	}
+	var job batchJob
+	if err := json.Unmarshal(data, &job); err != nil || job.ID == "" {
+		logger.WithField("path", path).Warn("Некорректный файл состояния пакетного задания, задание будет отправлено заново")
+		return batchJob{}, false
+	}
+	return job, true
+}
+
+// saveBatchJob сохраняет задание через временный файл
+func saveBatchJob(path string, job batchJob) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
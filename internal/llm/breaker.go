@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"sync"
+	"time"
+)
+
+// Состояния автоматического выключателя
+const (
+	// BreakerClosed запросы проходят, ошибки подряд подсчитываются
+	BreakerClosed = "closed"
+	// BreakerOpen запросы не отправляются до окончания паузы
+	BreakerOpen = "open"
+	// BreakerHalfOpen после паузы пропускается один пробный запрос
+	BreakerHalfOpen = "half-open"
+)
+
+// CircuitBreaker отключает провайдера после нескольких ошибок подряд, чтобы
+// не тратить время на заведомо неудачные запросы. После паузы пропускается
+// пробный запрос: при успехе провайдер снова включается, при ошибке
+// отключается на следующую паузу. Безопасен для использования из нескольких горутин.
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	state     string
+	openedAt  time.Time
+	probing   bool
+}
+
+// NewCircuitBreaker создает выключатель, который срабатывает после threshold
+// ошибок подряд и пропускает пробный запрос через cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     BreakerClosed,
+	}
+}
+
+// Allow сообщает, можно ли отправить запрос провайдеру
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		// Пока пробный запрос не завершился, остальные не отправляются
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// Success отмечает успешный запрос и включает провайдера
+func (b *CircuitBreaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.state = BreakerClosed
+	b.probing = false
+}
+
+// Failure отмечает неудачный запрос. Возвращает true, если провайдер был отключен.
+func (b *CircuitBreaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || b.failures >= b.threshold {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		return true
+	}
+	return false
+}
+
+// State возвращает текущее состояние выключателя
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.cooldown {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code-telescope/internal/logger"
+)
+
+// ErrChainUnavailable возвращается, когда ни один провайдер цепочки не ответил
+var ErrChainUnavailable = errors.New("все провайдеры ЛЛМ недоступны")
+
+// ChainLink описывает провайдера в цепочке и его выключатель
+type ChainLink struct {
+	Provider LLMProvider
+	Model    string
+	Breaker  *CircuitBreaker
+}
+
+// ChainProvider отправляет запрос провайдерам по очереди, пока один из них не
+// ответит. Провайдеры с сработавшим выключателем пропускаются.
+type ChainProvider struct {
+	links []ChainLink
+}
+
+// NewChainProvider создает цепочку провайдеров. Первый провайдер основной,
+// остальные резервные в порядке очередности.
+func NewChainProvider(links []ChainLink) (*ChainProvider, error) {
+	if len(links) == 0 {
+		return nil, fmt.Errorf("цепочка провайдеров ЛЛМ пуста")
+	}
+	for i, link := range links {
+		if link.Provider == nil || link.Breaker == nil {
+			return nil, fmt.Errorf("провайдер %d цепочки не инициализирован", i+1)
+		}
+	}
+	return &ChainProvider{links: links}, nil
+}
+
+// Name возвращает имя основного провайдера цепочки
+func (c *ChainProvider) Name() string {
+	return c.links[0].Provider.Name()
+}
+
+// GenerateText отправляет запрос первому доступному провайдеру цепочки.
+// Ответ содержит провайдера и модель, которые его сгенерировали.
+func (c *ChainProvider) GenerateText(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	var errs []error
+	for i, link := range c.links {
+		name := link.Provider.Name() + "/" + link.Model
+		if !link.Breaker.Allow() {
+			errs = append(errs, fmt.Errorf("%s: провайдер временно отключен после ошибок", name))
+			continue
+		}
+
+		response, err := link.Provider.GenerateText(ctx, request)
+		if err == nil {
+			link.Breaker.Success()
+			if response.Provider == "" {
+				response.Provider = link.Provider.Name()
+			}
+			if response.Model == "" {
+				response.Model = link.Model
+			}
+			return response, nil
+		}
+
+		// Отмена запроса не говорит о недоступности провайдера
+		if ctx.Err() != nil {
+			return LLMResponse{}, ctx.Err()
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		switch {
+		case link.Breaker.Failure():
+			logger.WithError(err).Warnf("Провайдер ЛЛМ %s временно отключен после ошибок подряд", name)
+		case i+1 < len(c.links):
+			logger.WithError(err).Warnf("Провайдер ЛЛМ %s не ответил, запрос передан следующему провайдеру", name)
+		}
+	}
+	return LLMResponse{}, fmt.Errorf("%w: %w", ErrChainUnavailable, errors.Join(errs...))
+}
+
+// BatchGenerateText передает все запросы первому доступному провайдеру
+// цепочки целиком, чтобы сохранить его пакетный режим. Если провайдер не
+// справился с пакетом, пакет передается следующему провайдеру.
+func (c *ChainProvider) BatchGenerateText(ctx context.Context, requests []LLMRequest) ([]LLMResponse, error) {
+	var errs []error
+	for i, link := range c.links {
+		name := link.Provider.Name() + "/" + link.Model
+		if !link.Breaker.Allow() {
+			errs = append(errs, fmt.Errorf("%s: провайдер временно отключен после ошибок", name))
+			continue
+		}
+
+		responses, err := link.Provider.BatchGenerateText(ctx, requests)
+		if err == nil {
+			link.Breaker.Success()
+			for j := range responses {
+				if responses[j].Text == "" {
+					continue
+				}
+				if responses[j].Provider == "" {
+					responses[j].Provider = link.Provider.Name()
+				}
+				if responses[j].Model == "" {
+					responses[j].Model = link.Model
+				}
+			}
+			return responses, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		switch {
+		case link.Breaker.Failure():
+			logger.WithError(err).Warnf("Провайдер ЛЛМ %s временно отключен после ошибок подряд", name)
+		case i+1 < len(c.links):
+			logger.WithError(err).Warnf("Провайдер ЛЛМ %s не обработал пакет запросов, пакет передан следующему провайдеру", name)
+		}
+	}
+	return nil, fmt.Errorf("%w: %w", ErrChainUnavailable, errors.Join(errs...))
+}
@@ -3,6 +3,9 @@ package llm
 import (
 	"context"
 	"errors"
+	"strings"
+
+	"code-telescope/internal/config"
 )
 
 // Возможные ошибки
@@ -12,19 +15,45 @@ var (
 	ErrInvalidResponse  = errors.New("некорректный ответ от ЛЛМ")
 )
 
-// LLMRequest представляет запрос к ЛЛМ
+// LLMRequest представляет запрос к ЛЛМ. Промпт может состоять из частей,
+// общих для нескольких запросов: инструкций System и контекста SharedPrefix.
+// Провайдеры с кэшированием промптов кэшируют общие части между запросами,
+// остальные получают полный промпт FullPrompt.
 type LLMRequest struct {
-	Prompt      string            // Текст промпта
-	MaxTokens   int               // Максимальное количество токенов в ответе
-	Temperature float64           // Температура (креативность) генерации
-	Metadata    map[string]string // Дополнительные метаданные
+	System       string            // Инструкции, общие для всех запросов
+	SharedPrefix string            // Контекст, общий для группы запросов (например, файла)
+	Prompt       string            // Текст промпта, уникальный для запроса
+	MaxTokens    int               // Максимальное количество токенов в ответе
+	Temperature  float64           // Температура (креативность) генерации
+	Metadata     map[string]string // Дополнительные метаданные
+}
+
+// FullPrompt возвращает промпт целиком: инструкции, общий контекст и текст
+// запроса, разделенные пустой строкой
+func (r LLMRequest) FullPrompt() string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{r.System, r.SharedPrefix, r.Prompt} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n\n")
 }
 
 // LLMResponse представляет ответ от ЛЛМ
 type LLMResponse struct {
-	Text       string // Сгенерированный текст
-	TokensUsed int    // Количество использованных токенов
-	Truncated  bool   // Флаг, указывающий, был ли ответ обрезан
+	Text         string // Сгенерированный текст
+	TokensUsed   int    // Количество использованных токенов
+	InputTokens  int    // Количество токенов промпта
+	OutputTokens int    // Количество токенов ответа
+	Truncated    bool   // Флаг, указывающий, был ли ответ обрезан
+	Provider     string // Провайдер, сгенерировавший ответ
+	Model        string // Модель, сгенерировавшая ответ
+
+	// Токены промпта, прочитанные из кэша провайдера и записанные в него.
+	// Не входят в InputTokens.
+	CacheReadTokens  int
+	CacheWriteTokens int
 }
 
 // LLMProvider интерфейс для взаимодействия с различными провайдерами ЛЛМ
@@ -45,9 +74,11 @@ type ProviderFactory func(config map[string]interface{}) (LLMProvider, error)
 // Реестр провайдеров ЛЛМ
 var providerRegistry = make(map[string]ProviderFactory)
 
-// RegisterProvider регистрирует новый провайдер ЛЛМ
+// RegisterProvider регистрирует новый провайдер ЛЛМ и делает его имя
+// допустимым значением настройки llm.provider
 func RegisterProvider(name string, factory ProviderFactory) {
 	providerRegistry[name] = factory
+	config.RegisterLLMProvider(name)
 }
 
 // GetProvider возвращает провайдер ЛЛМ по имени
@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+func init() {
+	RegisterProvider("ollama", NewOllamaProvider)
+}
+
+// OllamaProvider реализует интерфейс LLMProvider для локального сервера Ollama
+type OllamaProvider struct {
+	model      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// OllamaConfig содержит параметры конфигурации для Ollama
+type OllamaConfig struct {
+	Model   string `json:"model"`
+	BaseURL string `json:"base_url"`
+	Timeout int    `json:"timeout_seconds"`
+}
+
+// NewOllamaProvider создает новый экземпляр OllamaProvider. API ключ не требуется.
+func NewOllamaProvider(config map[string]interface{}) (LLMProvider, error) {
+	// Преобразование map в структуру конфигурации
+	jsonConfig, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при маршалинге конфигурации: %w", err)
+	}
+
+	var cfg OllamaConfig
+	if err := json.Unmarshal(jsonConfig, &cfg); err != nil {
+		return nil, fmt.Errorf("ошибка при анмаршалинге конфигурации: %w", err)
+	}
+
+	// Установка значений по умолчанию
+	if cfg.Model == "" {
+		cfg.Model = "llama3.1"
+	}
+
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = "http://localhost:11434/api/generate"
+	}
+
+	// Локальные модели отвечают медленнее облачных
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 120
+	}
+
+	return &OllamaProvider{
+		model:   cfg.Model,
+		baseURL: cfg.BaseURL,
+		httpClient: &http.Client{
+			Timeout: time.Duration(cfg.Timeout) * time.Second,
+		},
+	}, nil
+}
+
+// Name возвращает имя провайдера
+func (p *OllamaProvider) Name() string {
+	return "ollama"
+}
+
+// ollamaOptions представляет параметры генерации Ollama
+type ollamaOptions struct {
+	Temperature float64 `json:"temperature"`
+	NumPredict  int     `json:"num_predict,omitempty"`
+}
+
+// ollamaRequest представляет запрос к Ollama API
+type ollamaRequest struct {
+	Model   string        `json:"model"`
+	Prompt  string        `json:"prompt"`
+	Stream  bool          `json:"stream"`
+	Options ollamaOptions `json:"options"`
+}
+
+// ollamaResponse представляет ответ от Ollama API
+type ollamaResponse struct {
+	Response        string `json:"response"`
+	DoneReason      string `json:"done_reason"`
+	PromptEvalCount int    `json:"prompt_eval_count"`
+	EvalCount       int    `json:"eval_count"`
+}
+
+// GenerateText отправляет запрос к Ollama API и возвращает сгенерированный текст
+func (p *OllamaProvider) GenerateText(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	apiRequest := ollamaRequest{
+		Model:  p.model,
+		Prompt: request.FullPrompt(),
+		Options: ollamaOptions{
+			Temperature: request.Temperature,
+			NumPredict:  request.MaxTokens,
+		},
+	}
+
+	jsonData, err := json.Marshal(apiRequest)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("ошибка при маршалинге запроса: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("ошибка при создании HTTP-запроса: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.httpClient.Do(httpReq)
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("ошибка при выполнении HTTP-запроса: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return LLMResponse{}, fmt.Errorf("ошибка API: статус %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	var apiResponse ollamaResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
+		return LLMResponse{}, fmt.Errorf("ошибка при декодировании ответа: %w", err)
+	}
+
+	if apiResponse.Response == "" {
+		return LLMResponse{}, ErrInvalidResponse
+	}
+
+	return LLMResponse{
+		Text:         apiResponse.Response,
+		TokensUsed:   apiResponse.PromptEvalCount + apiResponse.EvalCount,
+		InputTokens:  apiResponse.PromptEvalCount,
+		OutputTokens: apiResponse.EvalCount,
+		Truncated:    apiResponse.DoneReason == "length",
+		Provider:     p.Name(),
+		Model:        p.model,
+	}, nil
+}
+
+// BatchGenerateText обрабатывает запросы к Ollama последовательно
+func (p *OllamaProvider) BatchGenerateText(ctx context.Context, requests []LLMRequest) ([]LLMResponse, error) {
+	responses := make([]LLMResponse, len(requests))
+	for i, req := range requests {
+		resp, err := p.GenerateText(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при обработке запроса %d: %w", i, err)
+		}
+		responses[i] = resp
+	}
+	return responses, nil
+}
@@ -20,6 +20,7 @@ type OpenAIProvider struct {
 	model      string
 	baseURL    string
 	httpClient *http.Client
+	batch      BatchSettings
 }
 
 // OpenAIConfig содержит параметры конфигурации для OpenAI
@@ -28,6 +29,7 @@ type OpenAIConfig struct {
 	Model   string `json:"model"`
 	BaseURL string `json:"base_url"`
 	Timeout int    `json:"timeout_seconds"`
+	BatchSettings
 }
 
 // SetHTTPClient устанавливает HTTP клиент для провайдера (используется для тестирования)
@@ -90,6 +92,7 @@ func NewOpenAIProvider(config map[string]interface{}) (LLMProvider, error) {
 		httpClient: &http.Client{
 			Timeout: time.Duration(cfg.Timeout) * time.Second,
 		},
+		batch: cfg.BatchSettings,
 	}, nil
 }
 
@@ -124,74 +127,111 @@ type openAIResponseChoice struct {
 type openAIResponse struct {
 	Choices []openAIResponseChoice `json:"choices"`
 	Usage   struct {
-		TotalTokens int `json:"total_tokens"`
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
 	} `json:"usage"`
 }
 
 // GenerateText отправляет запрос к OpenAI API и возвращает сгенерированный текст
 func (p *OpenAIProvider) GenerateText(ctx context.Context, request LLMRequest) (LLMResponse, error) {
-	apiRequest := openAIRequest{
+	jsonData, err := json.Marshal(p.newAPIRequest(request))
+	if err != nil {
+		return LLMResponse{}, fmt.Errorf("ошибка при маршалинге запроса: %w", err)
+	}
+
+	var apiResponse openAIResponse
+	if err := p.do(ctx, http.MethodPost, p.baseURL, "application/json", bytes.NewBuffer(jsonData), &apiResponse); err != nil {
+		return LLMResponse{}, err
+	}
+	return p.newResponse(apiResponse)
+}
+
+// newAPIRequest формирует запрос к Chat Completions API
+func (p *OpenAIProvider) newAPIRequest(request LLMRequest) openAIRequest {
+	return openAIRequest{
 		Model: p.model,
 		Messages: []openAIRequestMessage{
 			{
 				Role:    "user",
-				Content: request.Prompt,
+				Content: request.FullPrompt(),
 			},
 		},
 		MaxTokens:   request.MaxTokens,
 		Temperature: request.Temperature,
 	}
+}
 
-	jsonData, err := json.Marshal(apiRequest)
-	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ошибка при маршалинге запроса: %w", err)
+// newResponse преобразует ответ Chat Completions API
+func (p *OpenAIProvider) newResponse(apiResponse openAIResponse) (LLMResponse, error) {
+	if len(apiResponse.Choices) == 0 {
+		return LLMResponse{}, ErrInvalidResponse
+	}
+
+	truncated := false
+	if apiResponse.Choices[0].FinishReason == "length" {
+		truncated = true
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", p.baseURL, bytes.NewBuffer(jsonData))
+	return LLMResponse{
+		Text:         apiResponse.Choices[0].Message.Content,
+		TokensUsed:   apiResponse.Usage.TotalTokens,
+		InputTokens:  apiResponse.Usage.PromptTokens,
+		OutputTokens: apiResponse.Usage.CompletionTokens,
+		Truncated:    truncated,
+		Provider:     p.Name(),
+		Model:        p.model,
+	}, nil
+}
+
+// do выполняет HTTP-запрос к OpenAI API. Если out не nil, ответ декодируется
+// как JSON, если out — *[]byte, в него записывается тело ответа.
+func (p *OpenAIProvider) do(ctx context.Context, method, url, contentType string, body io.Reader, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ошибка при создании HTTP-запроса: %w", err)
+		return fmt.Errorf("ошибка при создании HTTP-запроса: %w", err)
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
 	httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
 
 	resp, err := p.httpClient.Do(httpReq)
 	if err != nil {
-		return LLMResponse{}, fmt.Errorf("ошибка при выполнении HTTP-запроса: %w", err)
+		return fmt.Errorf("ошибка при выполнении HTTP-запроса: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return LLMResponse{}, fmt.Errorf("ошибка API: статус %d: %s", resp.StatusCode, string(bodyBytes))
-	}
-
-	var apiResponse openAIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
-		return LLMResponse{}, fmt.Errorf("ошибка при декодировании ответа: %w", err)
+		return fmt.Errorf("ошибка API: статус %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	if len(apiResponse.Choices) == 0 {
-		return LLMResponse{}, ErrInvalidResponse
+	if raw, ok := out.(*[]byte); ok {
+		*raw, err = io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("ошибка при чтении ответа: %w", err)
+		}
+		return nil
 	}
-
-	truncated := false
-	if apiResponse.Choices[0].FinishReason == "length" {
-		truncated = true
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("ошибка при декодировании ответа: %w", err)
+		}
 	}
-
-	return LLMResponse{
-		Text:       apiResponse.Choices[0].Message.Content,
-		TokensUsed: apiResponse.Usage.TotalTokens,
-		Truncated:  truncated,
-	}, nil
+	return nil
 }
 
-// BatchGenerateText отправляет несколько запросов к OpenAI API пакетом
+// BatchGenerateText отправляет несколько запросов к OpenAI API. Если включен
+// пакетный API, запросы отправляются одним заданием Batch API, иначе
+// обрабатываются последовательно.
 func (p *OpenAIProvider) BatchGenerateText(ctx context.Context, requests []LLMRequest) ([]LLMResponse, error) {
-	responses := make([]LLMResponse, len(requests))
+	if p.batch.BatchAPI {
+		return runBatch(ctx, p, p.model, p.batch, requests)
+	}
 
-	// OpenAI не поддерживает батчинг, поэтому обрабатываем запросы последовательно
+	responses := make([]LLMResponse, len(requests))
 	for i, req := range requests {
 		resp, err := p.GenerateText(ctx, req)
 		if err != nil {
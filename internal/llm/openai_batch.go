@@ -0,0 +1,174 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+)
+
+// Эндпоинт, к которому относятся запросы пакетного задания OpenAI
+const openAIBatchEndpoint = "/v1/chat/completions"
+
+// openAIBatchLine представляет строку входного JSONL-файла задания
+type openAIBatchLine struct {
+	CustomID string        `json:"custom_id"`
+	Method   string        `json:"method"`
+	URL      string        `json:"url"`
+	Body     openAIRequest `json:"body"`
+}
+
+// openAIBatch представляет пакетное задание OpenAI
+type openAIBatch struct {
+	ID           string `json:"id"`
+	Status       string `json:"status"`
+	OutputFileID string `json:"output_file_id"`
+	ErrorFileID  string `json:"error_file_id"`
+	Errors       struct {
+		Data []struct {
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"errors"`
+}
+
+// openAIBatchResult представляет строку выходного файла задания
+type openAIBatchResult struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int            `json:"status_code"`
+		Body       openAIResponse `json:"body"`
+	} `json:"response"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// apiRoot возвращает корень API (например, https://api.openai.com/v1),
+// от которого строятся адреса файлов и заданий
+func (p *OpenAIProvider) apiRoot() string {
+	return strings.TrimSuffix(strings.TrimRight(p.baseURL, "/"), "/chat/completions")
+}
+
+// submitBatch загружает JSONL-файл с запросами и создает задание
+func (p *OpenAIProvider) submitBatch(ctx context.Context, requests []LLMRequest) (string, error) {
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for i, request := range requests {
+		line := openAIBatchLine{
+			CustomID: batchCustomID(i),
+			Method:   http.MethodPost,
+			URL:      openAIBatchEndpoint,
+			Body:     p.newAPIRequest(request),
+		}
+		if err := encoder.Encode(line); err != nil {
+			return "", fmt.Errorf("ошибка при маршалинге запроса %d: %w", i, err)
+		}
+	}
+
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	if err := writer.WriteField("purpose", "batch"); err != nil {
+		return "", err
+	}
+	part, err := writer.CreateFormFile("file", "code-telescope-batch.jsonl")
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(input.Bytes()); err != nil {
+		return "", err
+	}
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := p.do(ctx, http.MethodPost, p.apiRoot()+"/files", writer.FormDataContentType(), &form, &file); err != nil {
+		return "", fmt.Errorf("ошибка при загрузке файла запросов: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"input_file_id":     file.ID,
+		"endpoint":          openAIBatchEndpoint,
+		"completion_window": "24h",
+	})
+	if err != nil {
+		return "", err
+	}
+	var batch openAIBatch
+	if err := p.do(ctx, http.MethodPost, p.apiRoot()+"/batches", "application/json", bytes.NewReader(body), &batch); err != nil {
+		return "", err
+	}
+	if batch.ID == "" {
+		return "", ErrInvalidResponse
+	}
+	return batch.ID, nil
+}
+
+// checkBatch возвращает состояние задания. Истекшее или отмененное задание
+// считается завершенным, если у него есть файл с частью результатов.
+func (p *OpenAIProvider) checkBatch(ctx context.Context, id string) (batchStatus, error) {
+	var batch openAIBatch
+	if err := p.do(ctx, http.MethodGet, p.apiRoot()+"/batches/"+id, "", nil, &batch); err != nil {
+		return batchStatus{}, err
+	}
+
+	switch batch.Status {
+	case "completed":
+		return batchStatus{Done: true, Results: batch.OutputFileID}, nil
+	case "expired", "cancelled":
+		if batch.OutputFileID != "" {
+			return batchStatus{Done: true, Results: batch.OutputFileID}, nil
+		}
+		return batchStatus{}, fmt.Errorf("%w: статус %s", ErrBatchFailed, batch.Status)
+	case "failed":
+		messages := make([]string, 0, len(batch.Errors.Data))
+		for _, e := range batch.Errors.Data {
+			messages = append(messages, e.Message)
+		}
+		return batchStatus{}, fmt.Errorf("%w: %s", ErrBatchFailed, strings.Join(messages, "; "))
+	default:
+		return batchStatus{}, nil
+	}
+}
+
+// batchResults загружает выходной файл задания. Если все запросы завершились
+// ошибкой, выходного файла нет и результатов тоже нет.
+func (p *OpenAIProvider) batchResults(ctx context.Context, status batchStatus) (map[string]LLMResponse, error) {
+	results := make(map[string]LLMResponse)
+	if status.Results == "" {
+		return results, nil
+	}
+
+	var content []byte
+	if err := p.do(ctx, http.MethodGet, p.apiRoot()+"/files/"+status.Results+"/content", "", nil, &content); err != nil {
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var result openAIBatchResult
+		if err := json.Unmarshal(line, &result); err != nil {
+			return nil, fmt.Errorf("ошибка при декодировании результата: %w", err)
+		}
+		if result.Error != nil || result.Response == nil || result.Response.StatusCode != http.StatusOK {
+			continue
+		}
+		response, err := p.newResponse(result.Response.Body)
+		if err != nil {
+			continue
+		}
+		results[result.CustomID] = response
+	}
+	return results, scanner.Err()
+}
@@ -2,49 +2,217 @@ package llm
 
 import (
 	"fmt"
+	"math"
+	"sort"
 	"strings"
 
+	"code-telescope/internal/config"
+	"code-telescope/internal/quality"
 	"code-telescope/pkg/models"
+	"code-telescope/pkg/utils"
 )
 
+// Язык промптов по умолчанию
+const defaultPromptLanguage = config.DefaultOutputLanguage
+
+// Бюджет промпта в токенах по умолчанию
+const defaultPromptTokens = 7000
+
+// Контекст файла занимает не больше трети бюджета промпта,
+// остальное достается документации и исходному коду методов
+const contextBudgetShare = 3
+
 // PromptBuilder предоставляет методы для создания промптов для различных задач
 type PromptBuilder struct {
-	maxContextLength int
+	maxPromptTokens int
+	language        string
+	texts           PromptTemplate
+	templates       map[string]PromptTemplate
+	instructions    config.InstructionsConfig
 }
 
-// NewPromptBuilder создает новый экземпляр PromptBuilder
-func NewPromptBuilder(maxContextLength int) *PromptBuilder {
-	if maxContextLength <= 0 {
-		maxContextLength = 8000
+// NewPromptBuilder создает новый экземпляр PromptBuilder. maxPromptTokens
+// задает бюджет промпта в токенах: контекст файла и исходный код методов
+// обрезаются так, чтобы промпт уложился в него.
+func NewPromptBuilder(maxPromptTokens int) *PromptBuilder {
+	if maxPromptTokens <= 0 {
+		maxPromptTokens = defaultPromptTokens
 	}
 	return &PromptBuilder{
-		maxContextLength: maxContextLength,
+		maxPromptTokens: maxPromptTokens,
+		language:        defaultPromptLanguage,
+		texts:           builtinPromptTemplates[defaultPromptLanguage],
+		templates:       builtinPromptTemplates,
 	}
 }
 
-// BuildMethodDescriptionPrompt создает промпт для генерации описания метода
-func (pb *PromptBuilder) BuildMethodDescriptionPrompt(methodInfo models.MethodInfo, fileContext string) string {
-	// Базовый шаблон промпта
-	templateStr := `Проанализируй следующий код метода и предоставь краткое, точное описание 
-его функциональности в одном абзаце (3-4 предложения максимум). 
-Фокусируйся на том, что метод делает, его входных и выходных данных, и основных побочных эффектах.
+// WithTemplates возвращает конструктор промптов с шаблонами, загруженными
+// LoadPromptTemplates. Язык конструктора сохраняется.
+func (pb *PromptBuilder) WithTemplates(templates map[string]PromptTemplate) *PromptBuilder {
+	texts, ok := templates[pb.language]
+	if !ok {
+		return pb
+	}
+	clone := *pb
+	clone.templates = templates
+	clone.texts = texts
+	return &clone
+}
+
+// WithInstructions возвращает конструктор промптов, добавляющий к
+// инструкциям для ЛЛМ указания проекта: тон, глоссарий и запрещенные термины
+func (pb *PromptBuilder) WithInstructions(instructions config.InstructionsConfig) *PromptBuilder {
+	clone := *pb
+	clone.instructions = instructions
+	return &clone
+}
+
+// WithLanguage возвращает конструктор промптов для указанного языка (ru, en, de).
+// Для неизвестного языка возвращается исходный конструктор.
+func (pb *PromptBuilder) WithLanguage(language string) *PromptBuilder {
+	texts, ok := pb.templates[language]
+	if !ok || language == pb.language {
+		return pb
+	}
+	clone := *pb
+	clone.language = language
+	clone.texts = texts
+	return &clone
+}
+
+// WithPromptTokens возвращает конструктор промптов с бюджетом промпта
+// другой модели. Для неположительного бюджета возвращается исходный конструктор.
+func (pb *PromptBuilder) WithPromptTokens(maxPromptTokens int) *PromptBuilder {
+	if maxPromptTokens <= 0 || maxPromptTokens == pb.maxPromptTokens {
+		return pb
+	}
+	clone := *pb
+	clone.maxPromptTokens = maxPromptTokens
+	return &clone
+}
+
+// Language возвращает язык промптов
+func (pb *PromptBuilder) Language() string {
+	return pb.language
+}
 
-Метод: %s
-Сигнатура: %s
-Контекст файла: %s
+// Version возвращает версию промптов: язык, версию шаблона и отпечаток
+// переопределенных текстов и указаний проекта. Версия входит в ключ кэша
+// описаний, поэтому смена промптов приводит к повторному запросу описаний.
+func (pb *PromptBuilder) Version() string {
+	return promptVersion(pb.language, pb.texts, pb.instructions)
+}
 
-Предоставь только описание метода без дополнительного форматирования, пояснений или вступлений.`
+// MaxPromptTokens возвращает бюджет промпта в токенах
+func (pb *PromptBuilder) MaxPromptTokens() int {
+	return pb.maxPromptTokens
+}
 
-	// Обрезаем контекст файла, если он слишком длинный
-	truncatedContext := fileContext
-	if len(fileContext) > pb.maxContextLength {
-		truncatedContext = fileContext[:pb.maxContextLength] + "...[контекст обрезан из-за длины]"
+// truncateContext обрезает контекст файла до maxTokens токенов
+func (pb *PromptBuilder) truncateContext(fileContext string, maxTokens int) string {
+	if utils.EstimateTokens(fileContext) <= maxTokens {
+		return fileContext
 	}
+	marker := "\n" + pb.texts.ContextTruncate
+	truncated, _ := utils.TruncateTokens(fileContext, maxTokens-utils.EstimateTokens(marker))
+	return truncated + marker
+}
 
-	return fmt.Sprintf(templateStr,
+// methodSource возвращает документацию и исходный код метода, уложенные
+// в maxTokens токенов. Документация, уже входящая в код (строка
+// документации Python), повторно не добавляется.
+func (pb *PromptBuilder) methodSource(method models.MethodInfo, maxTokens int) string {
+	var sb strings.Builder
+
+	doc := strings.TrimSpace(method.Doc)
+	if firstLine, _, _ := strings.Cut(doc, "\n"); doc != "" && !strings.Contains(method.Body, firstLine) {
+		overhead := utils.EstimateTokens(fmt.Sprintf(pb.texts.MethodDoc, ""))
+		if text, _ := utils.TruncateTokens(doc, maxTokens-overhead); text != "" {
+			block := fmt.Sprintf(pb.texts.MethodDoc, text)
+			sb.WriteString(block)
+			maxTokens -= utils.EstimateTokens(block)
+		}
+	}
+
+	if body := strings.TrimSpace(method.Body); body != "" {
+		marker := "\n" + pb.texts.CodeTruncate
+		overhead := utils.EstimateTokens(fmt.Sprintf(pb.texts.MethodCode, marker))
+		code, truncated := utils.TruncateTokens(body, maxTokens-overhead)
+		if code != "" {
+			if truncated {
+				code += marker
+			}
+			sb.WriteString(fmt.Sprintf(pb.texts.MethodCode, code))
+		}
+	}
+	return sb.String()
+}
+
+// methodSources распределяет бюджет между методами поровну. Методы, которым
+// хватает меньшей доли, передаются целиком, а освободившийся бюджет делится
+// между остальными, код которых обрезается.
+func (pb *PromptBuilder) methodSources(methods []models.MethodInfo, budget int) []string {
+	sources := make([]string, len(methods))
+	tokens := make([]int, len(methods))
+	order := make([]int, len(methods))
+	for i, method := range methods {
+		sources[i] = pb.methodSource(method, math.MaxInt)
+		tokens[i] = utils.EstimateTokens(sources[i])
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return tokens[order[a]] < tokens[order[b]] })
+
+	for n, i := range order {
+		share := budget / (len(order) - n)
+		if tokens[i] > share {
+			sources[i] = pb.methodSource(methods[i], share)
+		}
+		budget -= utils.EstimateTokens(sources[i])
+	}
+	return sources
+}
+
+// BuildMethodDescriptionPrompt создает промпт для генерации описания метода
+func (pb *PromptBuilder) BuildMethodDescriptionPrompt(methodInfo models.MethodInfo, fileContext string) string {
+	instructions := pb.instructionsText()
+	budget := pb.maxPromptTokens - utils.EstimateTokens(fmt.Sprintf(pb.texts.Method, methodInfo.Name, methodInfo.Signature, "", "")+instructions)
+	fileContext = pb.truncateContext(fileContext, budget/contextBudgetShare)
+	source := pb.methodSources([]models.MethodInfo{methodInfo}, budget-utils.EstimateTokens(fileContext))[0]
+
+	return fmt.Sprintf(pb.texts.Method,
 		methodInfo.Name,
 		methodInfo.Signature,
-		truncatedContext)
+		source,
+		fileContext) + instructions
+}
+
+// BuildMethodRetryPrompt создает промпт повторного запроса описания метода,
+// не прошедшего проверку: к промпту описания добавляются недостатки
+// и прежнее описание
+func (pb *PromptBuilder) BuildMethodRetryPrompt(methodInfo models.MethodInfo, fileContext, previous string, issues []quality.Issue) string {
+	var feedback strings.Builder
+	for _, issue := range issues {
+		feedback.WriteString(pb.issueText(issue))
+		feedback.WriteString("\n")
+	}
+	return pb.BuildMethodDescriptionPrompt(methodInfo, fileContext) + fmt.Sprintf(pb.texts.Retry, feedback.String(), previous)
+}
+
+// issueText возвращает текст недостатка описания для повторного запроса
+func (pb *PromptBuilder) issueText(issue quality.Issue) string {
+	switch issue.Code {
+	case quality.IssueTruncated:
+		return pb.texts.IssueTruncated
+	case quality.IssueLowInformation:
+		return pb.texts.IssueLowInformation
+	case quality.IssueUnknownParameter:
+		return fmt.Sprintf(pb.texts.IssueUnknownParameter, issue.Detail)
+	case quality.IssueLanguage:
+		return pb.texts.IssueLanguage
+	case quality.IssueFormatting:
+		return pb.texts.IssueFormatting
+	}
+	return "- " + issue.String()
 }
 
 // BuildFileSummaryPrompt создает промпт для генерации общего описания файла
@@ -62,73 +230,91 @@ func (pb *PromptBuilder) BuildFileSummaryPrompt(fileInfo models.FileStructure) s
 	// Собираем методы в строку
 	var methods strings.Builder
 	for _, method := range fileInfo.Methods {
-		methods.WriteString(fmt.Sprintf("Метод: %s\nСигнатура: %s\n\n",
+		methods.WriteString(fmt.Sprintf(pb.texts.FileMethod,
 			method.Name, method.Signature))
 	}
 
-	// Базовый шаблон промпта
-	templateStr := `Проанализируй структуру файла и предоставь краткое описание его назначения 
-и функциональности в одном абзаце (максимум 3-4 предложения).
-Фокусируйся на том, что файл реализует, его основном назначении и взаимодействии с другими компонентами.
-
-Информация о файле:
-Имя файла: %s
-Язык: %s
-Импорты:
-%s
-
-Экспорты:
-%s
-
-Публичные методы:
-%s
-
-Предоставь только описание файла без дополнительного форматирования, пояснений или вступлений.`
-
-	return fmt.Sprintf(templateStr,
+	return fmt.Sprintf(pb.texts.FileSummary,
 		fileInfo.Path,
 		fileInfo.Language,
 		imports,
 		exports.String(),
-		methods.String())
+		methods.String()) + pb.instructionsText()
+}
+
+// BuildFileContext создает контекст файла для промптов: путь, язык
+// и определения типов, которые используют описываемые символы
+func (pb *PromptBuilder) BuildFileContext(path, language string, typeDefinitions []string) string {
+	fileContext := fmt.Sprintf(pb.texts.FileContext, path, language)
+	if len(typeDefinitions) > 0 {
+		fileContext += fmt.Sprintf(pb.texts.TypeDefinitions, strings.Join(typeDefinitions, "\n\n"))
+	}
+	return fileContext
+}
+
+// instructionsText возвращает указания проекта, которые добавляются
+// к инструкциям промпта, или пустую строку, если указания не заданы
+func (pb *PromptBuilder) instructionsText() string {
+	var items []string
+	if tone := strings.TrimSpace(pb.instructions.Tone); tone != "" {
+		items = append(items, fmt.Sprintf(pb.texts.Tone, tone))
+	}
+	if len(pb.instructions.Glossary) > 0 {
+		items = append(items, fmt.Sprintf(pb.texts.Glossary, "  - "+strings.Join(pb.instructions.Glossary, "\n  - ")))
+	}
+	if len(pb.instructions.ForbiddenTerms) > 0 {
+		items = append(items, fmt.Sprintf(pb.texts.ForbiddenTerms, strings.Join(pb.instructions.ForbiddenTerms, ", ")))
+	}
+	if extra := strings.TrimSpace(pb.instructions.Extra); extra != "" {
+		items = append(items, extra)
+	}
+	if len(items) == 0 {
+		return ""
+	}
+	return "\n\n" + fmt.Sprintf(pb.texts.Instructions, strings.Join(items, "\n"))
 }
 
 // BuildBatchMethodPrompt создает промпт для пакетной обработки методов
 func (pb *PromptBuilder) BuildBatchMethodPrompt(methods []models.MethodInfo, fileContext string) string {
-	var methodsStr strings.Builder
+	return pb.BuildBatchMethodRequest(methods, fileContext).FullPrompt()
+}
 
+// BuildBatchMethodRequest создает запрос для пакетной обработки методов.
+// Инструкции одинаковы для всех файлов проекта, контекст файла — для всех пакетов
+// файла, поэтому провайдеры с кэшированием промптов не оплачивают их повторно.
+// Заголовки методов передаются всегда, а контекст файла, документация и код
+// методов обрезаются по бюджету промпта.
+func (pb *PromptBuilder) BuildBatchMethodRequest(methods []models.MethodInfo, fileContext string) LLMRequest {
+	request := LLMRequest{System: pb.texts.BatchSystem + pb.instructionsText()}
+	budget := pb.maxPromptTokens - utils.EstimateTokens(request.System)
+
+	// Доля контекста не зависит от пакета, чтобы общий префикс
+	// оставался одинаковым для всех пакетов файла
+	request.SharedPrefix = fmt.Sprintf(pb.texts.BatchContext,
+		pb.truncateContext(strings.TrimSpace(fileContext), budget/contextBudgetShare))
+	budget -= utils.EstimateTokens(request.SharedPrefix) + utils.EstimateTokens(fmt.Sprintf(pb.texts.BatchMethods, ""))
+
+	headers := make([]string, len(methods))
 	for i, method := range methods {
-		methodsStr.WriteString(fmt.Sprintf("Метод %d: %s\nСигнатура: %s\n\n",
-			i+1, method.Name, method.Signature))
+		headers[i] = fmt.Sprintf(pb.texts.BatchMethod, i+1, method.Name, method.Signature)
+		budget -= utils.EstimateTokens(headers[i] + "\n")
 	}
+	sources := pb.methodSources(methods, budget)
 
-	// Обрезаем контекст файла, если он слишком длинный
-	truncatedContext := fileContext
-	if len(fileContext) > pb.maxContextLength {
-		truncatedContext = fileContext[:pb.maxContextLength] + "...[контекст обрезан из-за длины]"
+	var methodsStr strings.Builder
+	for i := range methods {
+		methodsStr.WriteString(headers[i])
+		methodsStr.WriteString(sources[i])
+		methodsStr.WriteString("\n")
 	}
 
-	templateStr := `Проанализируй следующие методы из одного файла и предоставь краткое, точное описание 
-для каждого метода. Для каждого метода напиши один абзац (3-4 предложения максимум).
-Фокусируйся на том, что метод делает, его входных и выходных данных, и основных побочных эффектах.
-
-Методы:
-%s
-
-Контекст файла:
-%s
-
-Формат вывода:
-Метод 1: [Описание метода 1]
-Метод 2: [Описание метода 2]
-...и так далее
-
-Предоставь только описания методов в указанном формате без дополнительных пояснений или вступлений.`
-
-	return fmt.Sprintf(templateStr, methodsStr.String(), truncatedContext)
+	request.Prompt = fmt.Sprintf(pb.texts.BatchMethods, strings.TrimRight(methodsStr.String(), "\n"))
+	return request
 }
 
-// ParseBatchResponse разбирает ответ от ЛЛМ, содержащий описания нескольких методов
+// ParseBatchResponse разбирает ответ от ЛЛМ, содержащий описания нескольких
+// методов. Описания возвращаются по MethodInfo.Key: одноименные методы разных
+// типов (например, два String) не затирают описания друг друга.
 func (pb *PromptBuilder) ParseBatchResponse(response string, methods []models.MethodInfo) map[string]string {
 	result := make(map[string]string)
 
@@ -147,7 +333,7 @@ func (pb *PromptBuilder) ParseBatchResponse(response string, methods []models.Me
 		// Проверяем, является ли строка заголовком метода
 		isMethodHeader := false
 		for i, method := range methods {
-			prefix := fmt.Sprintf("Метод %d:", i+1)
+			prefix := fmt.Sprintf(pb.texts.BatchPrefix, i+1)
 			if strings.HasPrefix(trimmed, prefix) {
 				// Если у нас уже есть текущий метод, сохраняем его описание
 				if currentMethod != "" {
@@ -156,7 +342,7 @@ func (pb *PromptBuilder) ParseBatchResponse(response string, methods []models.Me
 				}
 
 				// Устанавливаем новый текущий метод
-				currentMethod = method.Name
+				currentMethod = method.Key()
 				descriptionPart := strings.TrimPrefix(trimmed, prefix)
 				currentDescription.WriteString(strings.TrimSpace(descriptionPart))
 				isMethodHeader = true
@@ -0,0 +1,178 @@
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"code-telescope/internal/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Встроенные шаблоны промптов, по одному файлу <язык>.yaml на язык
+//
+//go:embed prompts/*.yaml
+var builtinPromptFiles embed.FS
+
+// PromptTemplate содержит тексты промптов на одном языке. Тексты являются
+// форматными строками fmt и при переопределении должны сохранять набор
+// подстановок встроенного шаблона.
+type PromptTemplate struct {
+	// Версия текстов; входит в ключ кэша описаний
+	Version string `yaml:"prompt_version"`
+
+	Method          string `yaml:"method"`
+	FileSummary     string `yaml:"file_summary"`
+	FileMethod      string `yaml:"file_method"`
+	BatchSystem     string `yaml:"batch_system"`
+	BatchContext    string `yaml:"batch_context"`
+	BatchMethods    string `yaml:"batch_methods"`
+	BatchMethod     string `yaml:"batch_method"`
+	BatchPrefix     string `yaml:"batch_prefix"`
+	MethodDoc       string `yaml:"method_doc"`
+	MethodCode      string `yaml:"method_code"`
+	FileContext     string `yaml:"file_context"`
+	TypeDefinitions string `yaml:"type_definitions"`
+	ContextTruncate string `yaml:"context_truncate"`
+	CodeTruncate    string `yaml:"code_truncate"`
+	Instructions    string `yaml:"instructions"`
+	Tone            string `yaml:"tone"`
+	Glossary        string `yaml:"glossary"`
+	ForbiddenTerms  string `yaml:"forbidden_terms"`
+
+	// Повторный запрос описания, не прошедшего проверку, и тексты недостатков
+	Retry                 string `yaml:"retry"`
+	IssueTruncated        string `yaml:"issue_truncated"`
+	IssueLowInformation   string `yaml:"issue_low_information"`
+	IssueUnknownParameter string `yaml:"issue_unknown_parameter"`
+	IssueLanguage         string `yaml:"issue_language"`
+	IssueFormatting       string `yaml:"issue_formatting"`
+
+	// Шаблон переопределен файлом проекта
+	custom bool
+}
+
+// Шаблоны промптов, встроенные в программу
+var builtinPromptTemplates = mustLoadBuiltinPromptTemplates()
+
+// Подстановки форматных строк fmt
+var formatVerbPattern = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// mustLoadBuiltinPromptTemplates разбирает встроенные шаблоны промптов.
+// Ошибка во встроенном шаблоне — ошибка сборки, поэтому она приводит к панике.
+func mustLoadBuiltinPromptTemplates() map[string]PromptTemplate {
+	templates := make(map[string]PromptTemplate, len(config.SupportedOutputLanguages))
+	for _, language := range config.SupportedOutputLanguages {
+		data, err := builtinPromptFiles.ReadFile("prompts/" + language + ".yaml")
+		if err != nil {
+			panic(fmt.Sprintf("нет встроенного шаблона промптов для языка %s: %v", language, err))
+		}
+		var template PromptTemplate
+		if err := yaml.Unmarshal(data, &template); err != nil {
+			panic(fmt.Sprintf("ошибка разбора встроенного шаблона промптов %s: %v", language, err))
+		}
+		templates[language] = template
+	}
+	return templates
+}
+
+// LoadPromptTemplates возвращает шаблоны промптов для всех поддерживаемых
+// языков. Файлы <язык>.yaml из директории dir переопределяют встроенные
+// шаблоны: файл может задать только часть текстов, остальные берутся из
+// встроенного шаблона. Пустая dir означает только встроенные шаблоны.
+func LoadPromptTemplates(dir string) (map[string]PromptTemplate, error) {
+	templates := make(map[string]PromptTemplate, len(builtinPromptTemplates))
+	for language, template := range builtinPromptTemplates {
+		templates[language] = template
+	}
+	if dir == "" {
+		return templates, nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(paths) == 0 {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("директория шаблонов промптов недоступна: %w", err)
+		}
+	}
+
+	for _, path := range paths {
+		language := strings.TrimSuffix(filepath.Base(path), ".yaml")
+		builtin, ok := builtinPromptTemplates[language]
+		if !ok {
+			return nil, fmt.Errorf("%s: неподдерживаемый язык шаблона промптов %s, допустимые значения: %s",
+				path, language, strings.Join(config.SupportedOutputLanguages, ", "))
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения шаблона промптов: %w", err)
+		}
+		template := builtin
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&template); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s: ошибка разбора шаблона промптов: %w", path, err)
+		}
+		if err := checkPromptTemplate(template, builtin); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		template.custom = true
+		templates[language] = template
+	}
+	return templates, nil
+}
+
+// checkPromptTemplate проверяет, что тексты шаблона содержат те же
+// подстановки, что и встроенный шаблон, иначе промпты соберутся с ошибками
+func checkPromptTemplate(template, builtin PromptTemplate) error {
+	value, builtinValue := reflect.ValueOf(template), reflect.ValueOf(builtin)
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if !field.IsExported() || field.Name == "Version" {
+			continue
+		}
+		got := formatVerbPattern.FindAllString(value.Field(i).String(), -1)
+		want := formatVerbPattern.FindAllString(builtinValue.Field(i).String(), -1)
+		if strings.Join(got, " ") != strings.Join(want, " ") {
+			return fmt.Errorf("текст %s должен содержать подстановки %q, получено: %q",
+				field.Tag.Get("yaml"), strings.Join(want, " "), strings.Join(got, " "))
+		}
+	}
+	return nil
+}
+
+// promptVersion возвращает версию промптов для ключа кэша: язык и версию
+// шаблона, а для переопределенного шаблона или указаний проекта — еще и
+// отпечаток их текстов, чтобы правка без смены версии не оставляла
+// в кэше описания, полученные по прежним промптам
+func promptVersion(language string, template PromptTemplate, instructions config.InstructionsConfig) string {
+	version := language + "@" + template.Version
+	if !template.custom && instructions.Empty() {
+		return version
+	}
+
+	hash := sha256.New()
+	value := reflect.ValueOf(template)
+	for i := 0; i < value.NumField(); i++ {
+		if value.Type().Field(i).IsExported() {
+			hash.Write([]byte(value.Field(i).String()))
+			hash.Write([]byte{0})
+		}
+	}
+	fmt.Fprintf(hash, "%q", instructions)
+	return version + "+" + hex.EncodeToString(hash.Sum(nil))[:12]
+}
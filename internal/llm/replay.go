@@ -0,0 +1,289 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"code-telescope/internal/config"
+)
+
+// Версия формата файла кассеты
+const cassetteVersion = 1
+
+// ErrReplayMiss возвращается в режиме воспроизведения для промпта, которого нет в кассете
+var ErrReplayMiss = errors.New("промпт отсутствует в кассете")
+
+// Interaction представляет записанную пару запрос/ответ
+type Interaction struct {
+	// Хэш нормализованного промпта
+	Key string `json:"key"`
+
+	// Провайдер и модель, от которых получен ответ
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+
+	// Промпт сохраняется для просмотра кассеты человеком
+	Prompt string `json:"prompt"`
+
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteResponse представляет ответ ЛЛМ в кассете. Токены кэша промптов
+// сохраняются, чтобы при воспроизведении расход и поправка оценки токенов
+// совпадали с записанными.
+type CassetteResponse struct {
+	Text             string `json:"text"`
+	InputTokens      int    `json:"input_tokens,omitempty"`
+	OutputTokens     int    `json:"output_tokens,omitempty"`
+	CacheReadTokens  int    `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int    `json:"cache_write_tokens,omitempty"`
+	Truncated        bool   `json:"truncated,omitempty"`
+}
+
+// cassetteFile представляет содержимое файла кассеты
+type cassetteFile struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Cassette хранит ответы ЛЛМ по хэшу нормализованного промпта.
+// Безопасна для использования из нескольких горутин.
+type Cassette struct {
+	mu           sync.Mutex
+	path         string
+	interactions map[string]Interaction
+}
+
+// OpenCassette открывает файл кассеты. Если файла нет, кассета пуста.
+func OpenCassette(path string) (*Cassette, error) {
+	c := &Cassette{
+		path:         path,
+		interactions: make(map[string]Interaction),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения кассеты: %w", err)
+	}
+
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("поврежден файл кассеты %s: %w", path, err)
+	}
+	if file.Version != cassetteVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия кассеты %s: %d", path, file.Version)
+	}
+	for _, interaction := range file.Interactions {
+		c.interactions[interaction.Key] = interaction
+	}
+
+	return c, nil
+}
+
+// Len возвращает количество записанных ответов
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.interactions)
+}
+
+// Get возвращает записанный ответ на промпт
+func (c *Cassette) Get(prompt string) (Interaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	interaction, ok := c.interactions[PromptKey(prompt)]
+	return interaction, ok
+}
+
+// Put записывает ответ на промпт и сохраняет кассету на диск
+func (c *Cassette) Put(provider, model, prompt string, response LLMResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := PromptKey(prompt)
+	c.interactions[key] = Interaction{
+		Key:      key,
+		Provider: provider,
+		Model:    model,
+		Prompt:   prompt,
+		Response: CassetteResponse{
+			Text:             response.Text,
+			InputTokens:      response.InputTokens,
+			OutputTokens:     response.OutputTokens,
+			CacheReadTokens:  response.CacheReadTokens,
+			CacheWriteTokens: response.CacheWriteTokens,
+			Truncated:        response.Truncated,
+		},
+	}
+	return c.save()
+}
+
+// save записывает кассету на диск. Записи упорядочены по ключу, чтобы
+// перезапись кассеты давала минимальные различия в системе контроля версий.
+func (c *Cassette) save() error {
+	file := cassetteFile{
+		Version:      cassetteVersion,
+		Interactions: make([]Interaction, 0, len(c.interactions)),
+	}
+	for _, interaction := range c.interactions {
+		file.Interactions = append(file.Interactions, interaction)
+	}
+	sort.Slice(file.Interactions, func(i, j int) bool {
+		return file.Interactions[i].Key < file.Interactions[j].Key
+	})
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации кассеты: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0755); err != nil {
+		return fmt.Errorf("ошибка создания директории кассеты: %w", err)
+	}
+
+	// Запись через временный файл, чтобы прерванный процесс не повредил кассету
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("ошибка записи кассеты: %w", err)
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		return fmt.Errorf("ошибка записи кассеты: %w", err)
+	}
+	return nil
+}
+
+// PromptKey возвращает хэш нормализованного промпта: переводы строк
+// приводятся к \n, пробелы в конце строк и пустые строки по краям
+// отбрасываются
+func PromptKey(prompt string) string {
+	lines := strings.Split(strings.ReplaceAll(prompt, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	normalized := strings.Trim(strings.Join(lines, "\n"), "\n")
+
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
+
+// ReplayProvider оборачивает провайдера ЛЛМ: в режиме записи сохраняет его
+// ответы в кассету, в режиме воспроизведения отвечает из кассеты и
+// возвращает ErrReplayMiss для неизвестных промптов
+type ReplayProvider struct {
+	inner    LLMProvider
+	cassette *Cassette
+	mode     string
+	model    string
+}
+
+// NewReplayProvider создает провайдера с кассетой в режиме config.ReplayModeRecord
+// или config.ReplayModeReplay. При воспроизведении вложенный провайдер не
+// используется и может быть nil.
+func NewReplayProvider(inner LLMProvider, cassette *Cassette, mode, model string) (*ReplayProvider, error) {
+	switch mode {
+	case config.ReplayModeRecord:
+		if inner == nil {
+			return nil, fmt.Errorf("для записи кассеты необходим провайдер ЛЛМ")
+		}
+	case config.ReplayModeReplay:
+	default:
+		return nil, fmt.Errorf("неподдерживаемый режим кассеты: %s", mode)
+	}
+
+	return &ReplayProvider{
+		inner:    inner,
+		cassette: cassette,
+		mode:     mode,
+		model:    model,
+	}, nil
+}
+
+// Name возвращает имя вложенного провайдера или "replay" при воспроизведении
+func (p *ReplayProvider) Name() string {
+	if p.inner != nil {
+		return p.inner.Name()
+	}
+	return "replay"
+}
+
+// GenerateText возвращает ответ из кассеты или запрашивает и записывает его
+func (p *ReplayProvider) GenerateText(ctx context.Context, request LLMRequest) (LLMResponse, error) {
+	if p.mode == config.ReplayModeReplay {
+		interaction, ok := p.cassette.Get(request.FullPrompt())
+		if !ok {
+			return LLMResponse{}, fmt.Errorf("%w: %s", ErrReplayMiss, PromptKey(request.FullPrompt()))
+		}
+		response := interaction.Response
+		return LLMResponse{
+			Text:             response.Text,
+			TokensUsed:       response.InputTokens + response.OutputTokens + response.CacheReadTokens + response.CacheWriteTokens,
+			InputTokens:      response.InputTokens,
+			OutputTokens:     response.OutputTokens,
+			Truncated:        response.Truncated,
+			Provider:         interaction.Provider,
+			Model:            interaction.Model,
+			CacheReadTokens:  response.CacheReadTokens,
+			CacheWriteTokens: response.CacheWriteTokens,
+		}, nil
+	}
+
+	response, err := p.inner.GenerateText(ctx, request)
+	if err != nil {
+		return LLMResponse{}, err
+	}
+	provider, model := p.inner.Name(), p.model
+	if response.Provider != "" {
+		provider, model = response.Provider, response.Model
+	}
+	if err := p.cassette.Put(provider, model, request.FullPrompt(), response); err != nil {
+		return LLMResponse{}, err
+	}
+	return response, nil
+}
+
+// BatchGenerateText при воспроизведении берет ответы из кассеты; для
+// промптов, которых нет в кассете, возвращается пустой ответ. При записи
+// запросы передаются вложенному провайдеру одним пакетом, а полученные
+// ответы сохраняются в кассету.
+func (p *ReplayProvider) BatchGenerateText(ctx context.Context, requests []LLMRequest) ([]LLMResponse, error) {
+	if p.mode == config.ReplayModeReplay {
+		responses := make([]LLMResponse, len(requests))
+		for i, req := range requests {
+			resp, err := p.GenerateText(ctx, req)
+			if err != nil && !errors.Is(err, ErrReplayMiss) {
+				return nil, fmt.Errorf("ошибка при обработке запроса %d: %w", i, err)
+			}
+			responses[i] = resp
+		}
+		return responses, nil
+	}
+
+	responses, err := p.inner.BatchGenerateText(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+	for i, response := range responses {
+		if response.Text == "" {
+			continue
+		}
+		provider, model := p.inner.Name(), p.model
+		if response.Provider != "" {
+			provider, model = response.Provider, response.Model
+		}
+		if err := p.cassette.Put(provider, model, requests[i].FullPrompt(), response); err != nil {
+			return nil, err
+		}
+	}
+	return responses, nil
+}
@@ -2,6 +2,7 @@ package tests
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"net/http"
 	"strings"
@@ -11,14 +12,15 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Тест для конструктора Anthropic провайдера
 func TestNewAnthropicProvider(t *testing.T) {
 	// Создаем конфигурацию
 	config := map[string]interface{}{
-		"APIKey": "test-api-key",
-		"Model":  "claude-1",
+		"api_key": "test-api-key",
+		"model":   "claude-1",
 	}
 
 	// Получаем провайдера
@@ -37,7 +39,7 @@ func TestAnthropicProviderGenerateText(t *testing.T) {
 
 	// Создаем ожидаемый ответ
 	expectedResponse := `{
-		"completion": "Это тестовое описание функции от Claude.",
+		"content": [{"type": "text", "text": "Это тестовое описание функции от Claude."}],
 		"stop_reason": "end_turn",
 		"usage": {
 			"input_tokens": 20,
@@ -56,8 +58,8 @@ func TestAnthropicProviderGenerateText(t *testing.T) {
 
 	// Создаем конфигурацию
 	config := map[string]interface{}{
-		"APIKey": "test-api-key",
-		"Model":  "claude-1",
+		"api_key": "test-api-key",
+		"model":   "claude-1",
 	}
 
 	// Получаем провайдера через фабрику
@@ -98,7 +100,7 @@ func TestAnthropicProviderBatchGenerateText(t *testing.T) {
 	// Создаем ожидаемые ответы для двух запросов
 	expectedResponses := []string{
 		`{
-			"completion": "Описание функции 1 от Claude",
+			"content": [{"type": "text", "text": "Описание функции 1 от Claude"}],
 			"stop_reason": "end_turn",
 			"usage": {
 				"input_tokens": 15,
@@ -106,7 +108,7 @@ func TestAnthropicProviderBatchGenerateText(t *testing.T) {
 			}
 		}`,
 		`{
-			"completion": "Описание функции 2 от Claude",
+			"content": [{"type": "text", "text": "Описание функции 2 от Claude"}],
 			"stop_reason": "end_turn",
 			"usage": {
 				"input_tokens": 15,
@@ -126,8 +128,8 @@ func TestAnthropicProviderBatchGenerateText(t *testing.T) {
 
 	// Создаем конфигурацию
 	config := map[string]interface{}{
-		"APIKey": "test-api-key",
-		"Model":  "claude-1",
+		"api_key": "test-api-key",
+		"model":   "claude-1",
 	}
 
 	// Получаем провайдера
@@ -168,3 +170,63 @@ func TestAnthropicProviderBatchGenerateText(t *testing.T) {
 	// Проверяем, что HTTP клиент был вызван дважды
 	mockHTTPClient.AssertExpectations(t)
 }
+
+// TestAnthropicPromptCaching проверяет, что инструкции и общий контекст
+// отправляются блоками с точками кэширования, а токены кэша попадают в ответ
+func TestAnthropicPromptCaching(t *testing.T) {
+	var body []byte
+	mockHTTPClient := new(MockHTTPClient)
+	mockHTTPClient.On("Do", mock.Anything).Run(func(args mock.Arguments) {
+		body, _ = io.ReadAll(args.Get(0).(*http.Request).Body)
+	}).Return(&http.Response{
+		StatusCode: 200,
+		Body: io.NopCloser(strings.NewReader(`{
+			"content": [{"type": "text", "text": "Метод 1: Описание"}],
+			"stop_reason": "end_turn",
+			"usage": {"input_tokens": 12, "output_tokens": 8, "cache_creation_input_tokens": 0, "cache_read_input_tokens": 1500}
+		}`)),
+	}, nil)
+
+	provider, err := llm.GetProvider("anthropic", map[string]interface{}{"api_key": "test-api-key"})
+	require.NoError(t, err)
+	provider.(*llm.AnthropicProvider).SetHTTPClient(mockHTTPClient)
+
+	response, err := provider.GenerateText(context.Background(), llm.LLMRequest{
+		System:       "Опиши методы",
+		SharedPrefix: "Контекст файла: calc.go",
+		Prompt:       "Метод 1: Add",
+		MaxTokens:    100,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 12, response.InputTokens)
+	assert.Equal(t, 1500, response.CacheReadTokens)
+	assert.Zero(t, response.CacheWriteTokens)
+	assert.Equal(t, 1520, response.TokensUsed)
+
+	type block struct {
+		Text         string `json:"text"`
+		CacheControl *struct {
+			Type string `json:"type"`
+		} `json:"cache_control"`
+	}
+	var request struct {
+		System   []block `json:"system"`
+		Messages []struct {
+			Content []block `json:"content"`
+		} `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(body, &request))
+
+	require.Len(t, request.System, 1)
+	assert.Equal(t, "Опиши методы", request.System[0].Text)
+	require.NotNil(t, request.System[0].CacheControl)
+	assert.Equal(t, "ephemeral", request.System[0].CacheControl.Type)
+
+	require.Len(t, request.Messages, 1)
+	content := request.Messages[0].Content
+	require.Len(t, content, 2)
+	assert.Equal(t, "Контекст файла: calc.go", content[0].Text)
+	assert.NotNil(t, content[0].CacheControl, "общий контекст кэшируется")
+	assert.Equal(t, "Метод 1: Add", content[1].Text)
+	assert.Nil(t, content[1].CacheControl, "текст запроса не кэшируется")
+}
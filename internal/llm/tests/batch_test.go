@@ -0,0 +1,256 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"code-telescope/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBatchServer имитирует пакетные API OpenAI и Anthropic. Задание
+// завершается после pollsBeforeDone опросов; на каждый промпт отвечает
+// "ответ: <промпт>", кроме промптов, начинающихся с "ошибка".
+type fakeBatchServer struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	pollsBeforeDone int
+	polls           int
+	submits         int
+	prompts         map[string]string // custom_id -> промпт
+}
+
+func newFakeBatchServer(t *testing.T, pollsBeforeDone int) *fakeBatchServer {
+	f := &fakeBatchServer{pollsBeforeDone: pollsBeforeDone, prompts: make(map[string]string)}
+	mux := http.NewServeMux()
+
+	// OpenAI: загрузка файла, создание и опрос задания, выходной файл
+	mux.HandleFunc("POST /v1/files", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test-key", r.Header.Get("Authorization"))
+		assert.Equal(t, "batch", r.FormValue("purpose"))
+		file, _, err := r.FormFile("file")
+		require.NoError(t, err)
+		data, _ := io.ReadAll(file)
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			var request struct {
+				CustomID string `json:"custom_id"`
+				URL      string `json:"url"`
+				Body     struct {
+					Messages []struct {
+						Content string `json:"content"`
+					} `json:"messages"`
+				} `json:"body"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(line), &request))
+			assert.Equal(t, "/v1/chat/completions", request.URL)
+			f.addPrompt(request.CustomID, request.Body.Messages[0].Content)
+		}
+		fmt.Fprint(w, `{"id":"file-in"}`)
+	})
+	mux.HandleFunc("POST /v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		f.submit()
+		fmt.Fprint(w, `{"id":"batch_1","status":"validating"}`)
+	})
+	mux.HandleFunc("GET /v1/batches/batch_1", func(w http.ResponseWriter, r *http.Request) {
+		if f.poll() {
+			fmt.Fprint(w, `{"id":"batch_1","status":"completed","output_file_id":"file-out"}`)
+			return
+		}
+		fmt.Fprint(w, `{"id":"batch_1","status":"in_progress"}`)
+	})
+	mux.HandleFunc("GET /v1/files/file-out/content", func(w http.ResponseWriter, r *http.Request) {
+		f.writeResults(w, func(id, prompt string) interface{} {
+			if strings.HasPrefix(prompt, "ошибка") {
+				return map[string]interface{}{"custom_id": id, "error": map[string]string{"message": "invalid request"}}
+			}
+			return map[string]interface{}{"custom_id": id, "response": map[string]interface{}{"status_code": 200, "body": map[string]interface{}{
+				"choices": []map[string]interface{}{{"message": map[string]string{"content": "ответ: " + prompt}, "finish_reason": "stop"}},
+				"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+			}}}
+		})
+	})
+
+	// Anthropic: создание и опрос задания, файл результатов
+	mux.HandleFunc("POST /v1/messages/batches", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test-key", r.Header.Get("X-Api-Key"))
+		var body struct {
+			Requests []struct {
+				CustomID string `json:"custom_id"`
+				Params   struct {
+					Messages []struct {
+						Content []struct {
+							Text string `json:"text"`
+						} `json:"content"`
+					} `json:"messages"`
+				} `json:"params"`
+			} `json:"requests"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		for _, request := range body.Requests {
+			f.addPrompt(request.CustomID, request.Params.Messages[0].Content[0].Text)
+		}
+		f.submit()
+		fmt.Fprint(w, `{"id":"msgbatch_1","processing_status":"in_progress"}`)
+	})
+	mux.HandleFunc("GET /v1/messages/batches/msgbatch_1", func(w http.ResponseWriter, r *http.Request) {
+		if f.poll() {
+			fmt.Fprintf(w, `{"id":"msgbatch_1","processing_status":"ended","results_url":"%s/v1/messages/batches/msgbatch_1/results"}`, f.URL)
+			return
+		}
+		fmt.Fprint(w, `{"id":"msgbatch_1","processing_status":"in_progress"}`)
+	})
+	mux.HandleFunc("GET /v1/messages/batches/msgbatch_1/results", func(w http.ResponseWriter, r *http.Request) {
+		f.writeResults(w, func(id, prompt string) interface{} {
+			if strings.HasPrefix(prompt, "ошибка") {
+				return map[string]interface{}{"custom_id": id, "result": map[string]interface{}{"type": "errored"}}
+			}
+			return map[string]interface{}{"custom_id": id, "result": map[string]interface{}{"type": "succeeded", "message": map[string]interface{}{
+				"content":     []map[string]string{{"type": "text", "text": "ответ: " + prompt}},
+				"usage":       map[string]int{"input_tokens": 10, "output_tokens": 5},
+				"stop_reason": "end_turn",
+			}}}
+		})
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeBatchServer) addPrompt(id, prompt string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prompts[id] = prompt
+}
+
+func (f *fakeBatchServer) submit() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.submits++
+}
+
+// poll отмечает опрос и сообщает, завершено ли задание
+func (f *fakeBatchServer) poll() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.polls++
+	return f.polls > f.pollsBeforeDone
+}
+
+// writeResults записывает результаты в JSONL в обратном порядке, чтобы
+// проверить сопоставление по custom_id, а не по позиции
+func (f *fakeBatchServer) writeResults(w io.Writer, result func(id, prompt string) interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := len(f.prompts) - 1; i >= 0; i-- {
+		id := fmt.Sprintf("request-%d", i)
+		data, _ := json.Marshal(result(id, f.prompts[id]))
+		fmt.Fprintln(w, string(data))
+	}
+}
+
+// batchProvider создает провайдера с пакетным API, направленного на фейковый сервер
+func batchProvider(t *testing.T, name, baseURL, stateDir string) llm.LLMProvider {
+	provider, err := llm.GetProvider(name, map[string]interface{}{
+		"api_key":             "test-key",
+		"model":               "test-model",
+		"base_url":            baseURL,
+		"batch_api":           true,
+		"batch_state_dir":     stateDir,
+		"batch_poll_interval": time.Millisecond,
+	})
+	require.NoError(t, err)
+	return provider
+}
+
+// batchRequests возвращает запросы с указанными промптами
+func batchRequests(prompts ...string) []llm.LLMRequest {
+	requests := make([]llm.LLMRequest, 0, len(prompts))
+	for _, prompt := range prompts {
+		requests = append(requests, llm.LLMRequest{Prompt: prompt, MaxTokens: 100})
+	}
+	return requests
+}
+
+// TestBatchGenerateTextVendorAPIs проверяет пакетные задания обоих провайдеров:
+// опрос до завершения и сопоставление результатов с запросами по custom_id
+func TestBatchGenerateTextVendorAPIs(t *testing.T) {
+	tests := []struct {
+		provider string
+		path     string
+	}{
+		{provider: "openai", path: "/v1/chat/completions"},
+		{provider: "anthropic", path: "/v1/messages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.provider, func(t *testing.T) {
+			server := newFakeBatchServer(t, 2)
+			stateDir := t.TempDir()
+			provider := batchProvider(t, tt.provider, server.URL+tt.path, stateDir)
+
+			responses, err := provider.BatchGenerateText(context.Background(), batchRequests("первый", "ошибка", "третий"))
+			require.NoError(t, err)
+			require.Len(t, responses, 3)
+
+			assert.Equal(t, "ответ: первый", responses[0].Text)
+			assert.Equal(t, "ответ: третий", responses[2].Text)
+			assert.Equal(t, 10, responses[2].InputTokens)
+			assert.Equal(t, tt.provider, responses[2].Provider)
+			assert.Equal(t, "test-model", responses[2].Model)
+			assert.Empty(t, responses[1].Text, "запрос с ошибкой остается без ответа")
+
+			assert.Equal(t, 1, server.submits)
+			assert.Equal(t, 3, server.polls)
+			entries, err := os.ReadDir(stateDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries, "состояние завершенного задания удаляется")
+		})
+	}
+}
+
+// TestBatchGenerateTextResumes проверяет, что прерванный запуск сохраняет
+// идентификатор задания, а повторный запуск забирает его результаты
+// без отправки нового задания
+func TestBatchGenerateTextResumes(t *testing.T) {
+	server := newFakeBatchServer(t, 1000)
+	stateDir := t.TempDir()
+	provider := batchProvider(t, "anthropic", server.URL+"/v1/messages", stateDir)
+	requests := batchRequests("первый", "второй")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := provider.BatchGenerateText(ctx, requests)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	states, err := filepath.Glob(filepath.Join(stateDir, "anthropic-*.json"))
+	require.NoError(t, err)
+	require.Len(t, states, 1)
+	state, err := os.ReadFile(states[0])
+	require.NoError(t, err)
+	assert.Contains(t, string(state), "msgbatch_1")
+
+	server.mu.Lock()
+	server.pollsBeforeDone = 0
+	server.mu.Unlock()
+
+	responses, err := batchProvider(t, "anthropic", server.URL+"/v1/messages", stateDir).
+		BatchGenerateText(context.Background(), requests)
+	require.NoError(t, err)
+	assert.Equal(t, "ответ: второй", responses[1].Text)
+	assert.Equal(t, 1, server.submits, "повторный запуск не отправляет новое задание")
+	assert.NoFileExists(t, states[0])
+}
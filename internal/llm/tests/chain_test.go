@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"code-telescope/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// failingProvider возвращает мок провайдера, который всегда отвечает ошибкой
+func failingProvider(name string) *MockLLMProvider {
+	provider := &MockLLMProvider{}
+	provider.On("Name").Return(name)
+	provider.On("GenerateText", mock.Anything, mock.Anything).
+		Return(llm.LLMResponse{}, errors.New("overloaded_error"))
+	return provider
+}
+
+// TestChainProviderFallsBack проверяет переход к резервному провайдеру и
+// отключение основного после ошибок подряд
+func TestChainProviderFallsBack(t *testing.T) {
+	primary := failingProvider("anthropic")
+	fallback := &MockLLMProvider{}
+	fallback.On("Name").Return("openai")
+	fallback.On("GenerateText", mock.Anything, mock.Anything).
+		Return(CreateTestLLMResponse("Описание от резервного провайдера"), nil)
+
+	breaker := llm.NewCircuitBreaker(2, time.Hour)
+	chain, err := llm.NewChainProvider([]llm.ChainLink{
+		{Provider: primary, Model: "claude-sonnet-4", Breaker: breaker},
+		{Provider: fallback, Model: "gpt-4o", Breaker: llm.NewCircuitBreaker(2, time.Hour)},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "anthropic", chain.Name())
+
+	for i := 0; i < 3; i++ {
+		response, err := chain.GenerateText(context.Background(), llm.LLMRequest{Prompt: "Опиши функцию"})
+		require.NoError(t, err)
+		assert.Equal(t, "openai", response.Provider)
+		assert.Equal(t, "gpt-4o", response.Model)
+	}
+
+	assert.Equal(t, llm.BreakerOpen, breaker.State())
+	primary.AssertNumberOfCalls(t, "GenerateText", 2)
+	fallback.AssertNumberOfCalls(t, "GenerateText", 3)
+}
+
+// TestChainProviderUnavailable проверяет ошибку, когда не ответил ни один провайдер
+func TestChainProviderUnavailable(t *testing.T) {
+	chain, err := llm.NewChainProvider([]llm.ChainLink{
+		{Provider: failingProvider("anthropic"), Model: "claude-sonnet-4", Breaker: llm.NewCircuitBreaker(3, time.Minute)},
+		{Provider: failingProvider("ollama"), Model: "llama3.1", Breaker: llm.NewCircuitBreaker(3, time.Minute)},
+	})
+	require.NoError(t, err)
+
+	_, err = chain.GenerateText(context.Background(), llm.LLMRequest{Prompt: "Опиши функцию"})
+	assert.ErrorIs(t, err, llm.ErrChainUnavailable)
+	assert.Contains(t, err.Error(), "ollama/llama3.1")
+}
+
+// TestCircuitBreakerHalfOpen проверяет пробный запрос после паузы
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker := llm.NewCircuitBreaker(1, 20*time.Millisecond)
+	require.True(t, breaker.Allow())
+	assert.True(t, breaker.Failure(), "выключатель срабатывает после первой ошибки")
+	assert.False(t, breaker.Allow())
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, llm.BreakerHalfOpen, breaker.State())
+	assert.True(t, breaker.Allow(), "после паузы пропускается пробный запрос")
+	assert.False(t, breaker.Allow(), "второй запрос ждет результата пробного")
+
+	breaker.Failure()
+	assert.Equal(t, llm.BreakerOpen, breaker.State(), "неудачный пробный запрос снова отключает провайдера")
+
+	time.Sleep(30 * time.Millisecond)
+	require.True(t, breaker.Allow())
+	breaker.Success()
+	assert.Equal(t, llm.BreakerClosed, breaker.State())
+	assert.True(t, breaker.Allow())
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"code-telescope/internal/llm"
 
@@ -80,7 +81,7 @@ func SetupMockProvider(methodDescriptions map[string]string) *MockLLMProvider {
 		func(ctx context.Context, requests []llm.LLMRequest) []llm.LLMResponse {
 			responses := make([]llm.LLMResponse, len(requests))
 			for i, req := range requests {
-				responses[i] = CreateTestLLMResponse("Пакетный ответ #" + string(i+'0') + ": " + req.Prompt[:30] + "...")
+				responses[i] = CreateTestLLMResponse("Пакетный ответ #" + strconv.Itoa(i) + ": " + req.Prompt[:30] + "...")
 			}
 			return responses
 		},
@@ -17,8 +17,8 @@ import (
 func TestNewOpenAIProvider(t *testing.T) {
 	// Создаем конфигурацию
 	config := map[string]interface{}{
-		"APIKey": "test-api-key",
-		"Model":  "gpt-3.5-turbo",
+		"api_key": "test-api-key",
+		"model":   "gpt-3.5-turbo",
 	}
 
 	// Получаем провайдера
@@ -61,8 +61,8 @@ func TestOpenAIProviderGenerateText(t *testing.T) {
 
 	// Создаем конфигурацию
 	config := map[string]interface{}{
-		"APIKey": "test-api-key",
-		"Model":  "gpt-3.5-turbo",
+		"api_key": "test-api-key",
+		"model":   "gpt-3.5-turbo",
 	}
 
 	// Получаем провайдера через фабрику и внедряем мок HTTP клиента
@@ -141,8 +141,8 @@ func TestOpenAIProviderBatchGenerateText(t *testing.T) {
 
 	// Создаем конфигурацию
 	config := map[string]interface{}{
-		"APIKey": "test-api-key",
-		"Model":  "gpt-3.5-turbo",
+		"api_key": "test-api-key",
+		"model":   "gpt-3.5-turbo",
 	}
 
 	// Получаем провайдера
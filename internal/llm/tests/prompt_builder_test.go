@@ -0,0 +1,80 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"code-telescope/internal/llm"
+	"code-telescope/pkg/models"
+	"code-telescope/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestBuildBatchMethodRequestIncludesSource проверяет, что в промпт попадают
+// документация и исходный код методов, а строка документации, входящая в код,
+// не дублируется
+func TestBuildBatchMethodRequestIncludesSource(t *testing.T) {
+	methods := []models.MethodInfo{
+		{
+			Name:      "Add",
+			Signature: "Add(a: int, b: int) int",
+			Doc:       "Add возвращает сумму двух чисел",
+			Body:      "func Add(a, b int) int {\n\treturn a + b\n}",
+		},
+		{
+			Name:      "push",
+			Signature: "push(value)",
+			Doc:       "Кладет значение на вершину",
+			Body:      "def push(self, value):\n    \"\"\"Кладет значение на вершину\"\"\"\n    self.items.append(value)",
+		},
+	}
+
+	request := llm.NewPromptBuilder(0).BuildBatchMethodRequest(methods, "Файл: calc.go\nЯзык: Go\n")
+
+	assert.Contains(t, request.SharedPrefix, "Язык: Go")
+	assert.Contains(t, request.Prompt, "Метод 1: Add\nСигнатура: Add(a: int, b: int) int\nДокументация:\nAdd возвращает сумму двух чисел\nКод:\n```\nfunc Add(a, b int) int {")
+	assert.Contains(t, request.Prompt, "self.items.append(value)")
+	assert.Equal(t, 1, strings.Count(request.Prompt, "Кладет значение на вершину"), "строка документации уже входит в код")
+}
+
+// TestBuildBatchMethodRequestFitsBudget проверяет, что промпт укладывается
+// в бюджет токенов: короткий метод передается целиком, а длинный обрезается
+func TestBuildBatchMethodRequestFitsBudget(t *testing.T) {
+	long := "func Long() {\n" + strings.Repeat("\tprocess(item, options, callback)\n", 500) + "}"
+	methods := []models.MethodInfo{
+		{Name: "Long", Signature: "Long()", Body: long},
+		{Name: "Short", Signature: "Short() int", Body: "func Short() int {\n\treturn 42\n}"},
+	}
+	fileContext := "Файл: big.go\n" + strings.Repeat("type Option struct { Name string }\n", 500)
+	budget := 1500
+
+	request := llm.NewPromptBuilder(budget).BuildBatchMethodRequest(methods, fileContext)
+
+	assert.LessOrEqual(t, utils.EstimateTokens(request.FullPrompt()), budget)
+	assert.Contains(t, request.SharedPrefix, "...[контекст обрезан из-за длины]")
+	assert.Contains(t, request.Prompt, "func Short() int {\n\treturn 42\n}", "короткий метод передается целиком")
+	assert.Contains(t, request.Prompt, "...[код обрезан из-за длины]")
+	assert.Contains(t, request.Prompt, "Метод 1: Long\nСигнатура: Long()")
+
+	// Контекст файла не зависит от состава пакета, чтобы его можно было кэшировать
+	other := llm.NewPromptBuilder(budget).BuildBatchMethodRequest(methods[1:], fileContext)
+	assert.Equal(t, request.SharedPrefix, other.SharedPrefix)
+}
+
+// TestParseBatchResponseKeysByID проверяет, что одноименные методы разных
+// типов получают свои описания
+func TestParseBatchResponseKeysByID(t *testing.T) {
+	methods := []models.MethodInfo{
+		{ID: "go:example.com/shop.(*Cart).String", Name: "String", BelongsTo: "Cart"},
+		{ID: "go:example.com/shop.Item.String", Name: "String", BelongsTo: "Item"},
+	}
+	response := "Метод 1: Возвращает содержимое корзины.\nМетод 2: Возвращает название товара."
+
+	descriptions := llm.NewPromptBuilder(0).ParseBatchResponse(response, methods)
+
+	assert.Equal(t, map[string]string{
+		"go:example.com/shop.(*Cart).String": "Возвращает содержимое корзины.",
+		"go:example.com/shop.Item.String":    "Возвращает название товара.",
+	}, descriptions)
+}
@@ -0,0 +1,71 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/llm"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoadPromptTemplatesOverride проверяет, что файл проекта переопределяет
+// часть текстов встроенного шаблона и меняет версию промптов
+func TestLoadPromptTemplatesOverride(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.yaml"),
+		[]byte("batch_context: \"Project file:\\n%s\"\n"), 0644))
+
+	templates, err := llm.LoadPromptTemplates(dir)
+	require.NoError(t, err)
+
+	builtin := llm.NewPromptBuilder(0).WithLanguage(config.OutputLanguageEnglish)
+	custom := llm.NewPromptBuilder(0).WithTemplates(templates).WithLanguage(config.OutputLanguageEnglish)
+	methods := []models.MethodInfo{{Name: "Add", Signature: "Add(a, b int) int"}}
+
+	request := custom.BuildBatchMethodRequest(methods, "File: calc.go\n")
+	assert.Equal(t, "Project file:\nFile: calc.go", request.SharedPrefix)
+	assert.Contains(t, request.Prompt, "Method 1: Add\nSignature: Add(a, b int) int")
+
+	assert.Equal(t, "en@1", builtin.Version())
+	assert.NotEqual(t, builtin.Version(), custom.Version(), "переопределенные промпты должны менять ключ кэша")
+	assert.Equal(t, llm.NewPromptBuilder(0).Version(), custom.WithLanguage(config.OutputLanguageRussian).Version(),
+		"шаблон другого языка не переопределен")
+}
+
+// TestLoadPromptTemplatesRejectsBrokenFormat проверяет, что шаблон с другим
+// набором подстановок отклоняется
+func TestLoadPromptTemplatesRejectsBrokenFormat(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "de.yaml"),
+		[]byte("batch_method: \"Methode %d: %s\\n\"\n"), 0644))
+
+	_, err := llm.LoadPromptTemplates(dir)
+	assert.ErrorContains(t, err, "batch_method")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fr.yaml"), []byte("{}\n"), 0644))
+	require.NoError(t, os.Remove(filepath.Join(dir, "de.yaml")))
+	_, err = llm.LoadPromptTemplates(dir)
+	assert.ErrorContains(t, err, "fr")
+}
+
+// TestPromptInstructions проверяет, что указания проекта попадают в
+// инструкции пакетного запроса и меняют версию промптов
+func TestPromptInstructions(t *testing.T) {
+	builder := llm.NewPromptBuilder(0).WithInstructions(config.InstructionsConfig{
+		Tone:           "деловой",
+		Glossary:       []string{"карта кода — итоговый документ"},
+		ForbiddenTerms: []string{"утилита", "хелпер"},
+	})
+
+	request := builder.BuildBatchMethodRequest([]models.MethodInfo{{Name: "Add", Signature: "Add()"}}, "")
+
+	assert.Contains(t, request.System, "Дополнительные требования:\n- Тон описаний: деловой\n")
+	assert.Contains(t, request.System, "  - карта кода — итоговый документ\n")
+	assert.Contains(t, request.System, "- Не используй термины: утилита, хелпер")
+	assert.NotEqual(t, llm.NewPromptBuilder(0).Version(), builder.Version())
+}
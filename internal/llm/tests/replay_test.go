@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/llm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplayProviderRecordAndReplay проверяет запись ответов в кассету и их воспроизведение
+func TestReplayProviderRecordAndReplay(t *testing.T) {
+	cassettePath := filepath.Join(t.TempDir(), "cassette.json")
+	request := llm.LLMRequest{Prompt: "Опиши функцию Run\r\nСигнатура: Run() error  \n"}
+
+	inner := &MockLLMProvider{}
+	inner.On("Name").Return("openai")
+	inner.On("GenerateText", mock.Anything, mock.Anything).
+		Return(llm.LLMResponse{Text: "Запускает приложение.", InputTokens: 12, OutputTokens: 5, CacheReadTokens: 40, CacheWriteTokens: 8}, nil).Once()
+
+	cassette, err := llm.OpenCassette(cassettePath)
+	require.NoError(t, err)
+	recorder, err := llm.NewReplayProvider(inner, cassette, config.ReplayModeRecord, "gpt-4o-mini")
+	require.NoError(t, err)
+	_, err = recorder.GenerateText(context.Background(), request)
+	require.NoError(t, err)
+	inner.AssertExpectations(t)
+
+	cassette, err = llm.OpenCassette(cassettePath)
+	require.NoError(t, err)
+	player, err := llm.NewReplayProvider(nil, cassette, config.ReplayModeReplay, "gpt-4o-mini")
+	require.NoError(t, err)
+
+	// Промпт нормализуется: переводы строк и пробелы в конце строк не влияют на ключ
+	response, err := player.GenerateText(context.Background(), llm.LLMRequest{Prompt: "Опиши функцию Run\nСигнатура: Run() error"})
+	require.NoError(t, err)
+	assert.Equal(t, "Запускает приложение.", response.Text)
+	assert.Equal(t, 12, response.InputTokens)
+	assert.Equal(t, 40, response.CacheReadTokens)
+	assert.Equal(t, 8, response.CacheWriteTokens)
+	assert.Equal(t, 65, response.TokensUsed)
+
+	_, err = player.GenerateText(context.Background(), llm.LLMRequest{Prompt: "Опиши функцию Stop"})
+	assert.ErrorIs(t, err, llm.ErrReplayMiss)
+}
+
+// TestNewReplayProviderRequiresProviderForRecord проверяет, что запись без провайдера невозможна
+func TestNewReplayProviderRequiresProviderForRecord(t *testing.T) {
+	cassette, err := llm.OpenCassette(filepath.Join(t.TempDir(), "cassette.json"))
+	require.NoError(t, err)
+
+	_, err = llm.NewReplayProvider(nil, cassette, config.ReplayModeRecord, "gpt-4o-mini")
+	assert.Error(t, err)
+}
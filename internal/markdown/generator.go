@@ -4,31 +4,59 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"unicode"
 
 	"code-telescope/internal/config"
 	"code-telescope/pkg/models"
 )
 
+// Идентификаторы языков для подсветки синтаксиса в блоках кода
+var codeFenceLanguages = map[string]string{
+	"Go":           "go",
+	"JavaScript":   "javascript",
+	"TypeScript":   "typescript",
+	"Python":       "python",
+	"Java":         "java",
+	"C":            "c",
+	"C++":          "cpp",
+	"C/C++ Header": "cpp",
+}
+
 // Generator представляет генератор Markdown документации
 type Generator struct {
-	config *config.Config
+	config    *config.Config
+	links     *LinkBuilder
+	templates Templates
 }
 
 // New создает новый экземпляр генератора Markdown
 func New(cfg *config.Config) *Generator {
+	language := config.DefaultOutputLanguage
+	if cfg != nil {
+		language = cfg.LLM.OutputLanguage
+	}
 	return &Generator{
-		config: cfg,
+		config:    cfg,
+		templates: TemplatesFor(language),
 	}
 }
 
+// SetLinkBuilder устанавливает построитель ссылок на исходный код символов
+func (g *Generator) SetLinkBuilder(links *LinkBuilder) {
+	g.links = links
+}
+
 // GenerateCodeMap генерирует полную карту кода на основе структур файлов
 func (g *Generator) GenerateCodeMap(fileStructures []models.FileStructure, projectName string) string {
 	// Заголовок
-	codeMap := fmt.Sprintf(CodeMapHeaderTemplate, projectName)
+	codeMap := fmt.Sprintf(g.templates.CodeMapHeader, projectName)
+	codeMap += g.templates.CodeMapIntro
 
-	// Создаем оглавление
-	toc := g.generateTableOfContents(fileStructures)
-	codeMap += fmt.Sprintf(CodeMapIntroTemplate, toc)
+	// Создаем оглавление, если оно включено в конфигурации
+	if g.markdownConfig().IncludeTOC {
+		toc := g.generateTableOfContents(fileStructures)
+		codeMap += fmt.Sprintf(g.templates.TableOfContents, toc)
+	}
 
 	// Добавляем разделы для каждого файла
 	for _, fileStructure := range fileStructures {
@@ -43,37 +71,122 @@ func (g *Generator) GenerateCodeMap(fileStructures []models.FileStructure, proje
 func (g *Generator) GenerateFileSection(fileStructure models.FileStructure) string {
 	content := fmt.Sprintf(FileHeaderTemplate, fileStructure.Path)
 
+	// Добавляем информацию о файле
+	if g.markdownConfig().IncludeFileInfo {
+		content += g.generateFileInfo(fileStructure)
+	}
+
 	// Добавляем секцию импортов/экспортов
 	importsExportsContent := g.generateImportsExportsSection(fileStructure.Imports, fileStructure.Exports)
-	content += fmt.Sprintf(ImportsExportsTemplate, importsExportsContent)
+	content += fmt.Sprintf(g.templates.ImportsExports, importsExportsContent)
 
-	// Если есть методы, добавляем их
-	if len(fileStructure.Methods) > 0 {
-		content += PublicMethodsHeaderTemplate
+	methods := fileStructure.Methods
 
-		for _, method := range fileStructure.Methods {
-			// Формируем параметры метода для отображения
-			paramsStr := g.formatParameters(method.Params)
+	// Группируем методы по типам, которым они принадлежат
+	if g.markdownConfig().GroupMethodsByType && len(fileStructure.Types) > 0 {
+		var grouped string
+		grouped, methods = g.generateTypesSection(fileStructure)
+		content += grouped
+	}
 
-			// Формируем возвращаемые значения
-			returnsStr := g.formatReturns(method.Returns)
+	// Оставшиеся функции и методы выводим общим списком
+	if len(methods) > 0 {
+		content += g.templates.PublicMethodsHeader
 
-			// Добавляем в карту кода
-			content += fmt.Sprintf(MethodTemplate, method.Name, paramsStr, returnsStr, 
-				method.Signature)
+		for _, method := range methods {
+			content += g.formatMethod(g.templates.Method, fileStructure, method)
 		}
 	}
 
 	return content + "\n"
 }
 
+// generateTypesSection генерирует секцию типов с их полями и методами.
+// Возвращает содержимое секции и методы, не принадлежащие ни одному из типов файла.
+func (g *Generator) generateTypesSection(fileStructure models.FileStructure) (string, []models.MethodInfo) {
+	byOwner := make(map[string][]models.MethodInfo, len(fileStructure.Types))
+	for _, typ := range fileStructure.Types {
+		byOwner[typ.Name] = nil
+	}
+
+	var rest []models.MethodInfo
+	for _, method := range fileStructure.Methods {
+		if _, ok := byOwner[method.BelongsTo]; ok && method.BelongsTo != "" {
+			byOwner[method.BelongsTo] = append(byOwner[method.BelongsTo], method)
+			continue
+		}
+		rest = append(rest, method)
+	}
+
+	content := g.templates.TypesHeader
+	for _, typ := range fileStructure.Types {
+		content += formatAnchor(typ.ID)
+		content += fmt.Sprintf(TypeTemplate, kindLabel(typ.Kind), typ.Name)
+		content += g.formatSourceLink(fileStructure.Path, typ.Position)
+		if typ.Description != "" {
+			content += fmt.Sprintf(g.templates.TypeDescription, g.truncateDescription(typ.Description))
+		}
+		content += g.formatFields(typ.Fields)
+		content += "\n"
+
+		for _, method := range byOwner[typ.Name] {
+			content += g.formatMethod(g.templates.TypeMethod, fileStructure, method)
+		}
+	}
+
+	return content, rest
+}
+
+// formatMethod форматирует описание метода по указанному шаблону
+func (g *Generator) formatMethod(template string, fileStructure models.FileStructure, method models.MethodInfo) string {
+	// Формируем ссылку на исходный код, сигнатуру и параметры метода для отображения
+	paramsStr := g.formatSourceLink(fileStructure.Path, method.Position) +
+		g.formatSignature(fileStructure.Language, method.Signature) +
+		g.formatParameters(method.Params)
+
+	// Формируем возвращаемые значения
+	returnsStr := g.formatReturns(method.Returns)
+
+	description := g.truncateDescription(method.Description)
+	if description == "" {
+		description = g.templates.NoDescription
+	}
+
+	return formatAnchor(method.ID) + fmt.Sprintf(template, method.Name, paramsStr, returnsStr, description)
+}
+
+// formatAnchor форматирует якорь символа; у символов моделей, сохраненных
+// до появления идентификаторов, якоря нет
+func formatAnchor(id string) string {
+	if id == "" {
+		return ""
+	}
+	return fmt.Sprintf(SymbolAnchorTemplate, models.SymbolAnchor(id))
+}
+
+// generateFileInfo формирует блок информации о файле: язык, размер, количество строк и дату изменения
+func (g *Generator) generateFileInfo(fileStructure models.FileStructure) string {
+	info := ""
+	if fileStructure.Language != "" {
+		info += fmt.Sprintf(g.templates.FileInfoLanguage, fileStructure.Language)
+	}
+	info += fmt.Sprintf(g.templates.FileInfoSize, g.formatSize(fileStructure.Size))
+	if fileStructure.LineCount > 0 {
+		info += fmt.Sprintf(g.templates.FileInfoLines, fileStructure.LineCount)
+	}
+	if !fileStructure.ModTime.IsZero() {
+		info += fmt.Sprintf(g.templates.FileInfoModTime, fileStructure.ModTime.Format("2006-01-02 15:04"))
+	}
+	return info + "\n"
+}
+
 // generateImportsExportsSection генерирует секцию импортов и экспортов
 func (g *Generator) generateImportsExportsSection(imports []string, exports []string) string {
 	var content string
 
 	// Добавляем импорты
 	if len(imports) > 0 {
-		content += "Импорты:\n"
+		content += g.templates.Imports
 		for _, imp := range imports {
 			content += fmt.Sprintf("- %s\n", imp)
 		}
@@ -84,7 +197,7 @@ func (g *Generator) generateImportsExportsSection(imports []string, exports []st
 		if len(imports) > 0 {
 			content += "\n"
 		}
-		content += "Экспорты:\n"
+		content += g.templates.Exports
 		for _, exp := range exports {
 			content += fmt.Sprintf("- %s\n", exp)
 		}
@@ -93,13 +206,44 @@ func (g *Generator) generateImportsExportsSection(imports []string, exports []st
 	return content
 }
 
+// formatSourceLink форматирует ссылку на строки исходного кода символа
+func (g *Generator) formatSourceLink(filePath string, pos models.Position) string {
+	link := g.links.Link(filePath, pos)
+	if link == "" {
+		return ""
+	}
+	return fmt.Sprintf(g.templates.SourceLink, g.links.Label(filePath, pos), link)
+}
+
+// formatSignature форматирует сигнатуру метода в блоке кода с учетом стиля кода
+func (g *Generator) formatSignature(language, signature string) string {
+	if signature == "" {
+		return ""
+	}
+	return fmt.Sprintf(SignatureTemplate, g.codeFenceLanguage(language), signature)
+}
+
+// formatFields форматирует поля типа для отображения в Markdown
+func (g *Generator) formatFields(fields []string) string {
+	if len(fields) == 0 {
+		return ""
+	}
+
+	fieldsStr := g.templates.Fields
+	for _, field := range fields {
+		fieldsStr += fmt.Sprintf("  - %s\n", field)
+	}
+
+	return fieldsStr
+}
+
 // formatParameters форматирует параметры метода для отображения в Markdown
 func (g *Generator) formatParameters(parameters []string) string {
 	if len(parameters) == 0 {
 		return ""
 	}
 
-	paramsStr := "- **Входные параметры**: \n"
+	paramsStr := g.templates.Parameters
 	for _, param := range parameters {
 		paramsStr += fmt.Sprintf("  - %s\n", param)
 	}
@@ -113,7 +257,7 @@ func (g *Generator) formatReturns(returns []string) string {
 		return ""
 	}
 
-	returnsStr := "- **Выходные параметры**: \n"
+	returnsStr := g.templates.Returns
 	for _, ret := range returns {
 		returnsStr += fmt.Sprintf("  - %s\n", ret)
 	}
@@ -121,6 +265,60 @@ func (g *Generator) formatReturns(returns []string) string {
 	return returnsStr
 }
 
+// truncateDescription сокращает описание до максимальной длины из конфигурации,
+// обрезая текст по границе слова. Длина считается в символах, а не в байтах.
+func (g *Generator) truncateDescription(description string) string {
+	description = strings.TrimSpace(description)
+	maxLen := g.markdownConfig().MaxMethodDescriptionLen
+	runes := []rune(description)
+	if maxLen <= 0 || len(runes) <= maxLen {
+		return description
+	}
+
+	cut := maxLen
+	for cut > 0 && !unicode.IsSpace(runes[cut]) {
+		cut--
+	}
+	if cut == 0 {
+		// Одно длинное слово: обрезаем по максимальной длине
+		cut = maxLen
+	}
+
+	return strings.TrimRightFunc(string(runes[:cut]), func(r rune) bool {
+		return unicode.IsSpace(r) || unicode.IsPunct(r)
+	}) + "…"
+}
+
+// codeFenceLanguage возвращает идентификатор языка для блоков кода.
+// Стиль default отключает подсветку синтаксиса, остальные стили ее используют.
+func (g *Generator) codeFenceLanguage(language string) string {
+	if g.markdownConfig().CodeStyle == "default" {
+		return ""
+	}
+	return codeFenceLanguages[language]
+}
+
+// markdownConfig возвращает настройки Markdown или значения по умолчанию, если конфигурация не задана
+func (g *Generator) markdownConfig() config.MarkdownConfig {
+	if g.config == nil {
+		return config.DefaultConfig().Markdown
+	}
+	return g.config.Markdown
+}
+
+// formatSize форматирует размер файла в удобочитаемом виде
+func (g *Generator) formatSize(size int64) string {
+	units := g.templates.SizeUnits
+	switch {
+	case size < 1024:
+		return fmt.Sprintf("%d %s", size, units[0])
+	case size < 1024*1024:
+		return fmt.Sprintf("%.1f %s", float64(size)/1024, units[1])
+	default:
+		return fmt.Sprintf("%.1f %s", float64(size)/(1024*1024), units[2])
+	}
+}
+
 // generateTableOfContents генерирует оглавление для карты кода
 func (g *Generator) generateTableOfContents(fileStructures []models.FileStructure) string {
 	var toc string
@@ -138,13 +336,13 @@ func (g *Generator) generateTableOfContents(fileStructures []models.FileStructur
 func (g *Generator) createAnchor(text string) string {
 	// Преобразуем в нижний регистр
 	anchor := strings.ToLower(text)
-	
+
 	// Заменяем пробелы на дефисы
 	anchor = strings.ReplaceAll(anchor, " ", "-")
-	
+
 	// Удаляем символы, не являющиеся буквами, цифрами, дефисами или подчеркиваниями
 	re := regexp.MustCompile(`[^a-z0-9\-_]`)
 	anchor = re.ReplaceAllString(anchor, "")
-	
+
 	return anchor
 }
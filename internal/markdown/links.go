@@ -0,0 +1,212 @@
+package markdown
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/git"
+	"code-telescope/pkg/models"
+)
+
+// Шаблоны постоянных ссылок для поддерживаемых хостингов репозиториев
+var forgeURLTemplates = map[string]string{
+	config.ForgeGitHub: "{repo}/blob/{commit}/{path}#L{start}-L{end}",
+	config.ForgeGitLab: "{repo}/-/blob/{commit}/{path}#L{start}-{end}",
+	config.ForgeGitea:  "{repo}/src/commit/{commit}/{path}#L{start}-L{end}",
+}
+
+// LinkBuilder формирует ссылки на строки исходного кода символов
+type LinkBuilder struct {
+	mode       string
+	template   string
+	repoURL    string
+	commit     string
+	pathPrefix string
+}
+
+// NewLinkBuilder создает построитель ссылок для проекта.
+// В режиме relative ссылки строятся от директории документа documentDir
+// (пустая строка — корень проекта). В режиме forge коммит читается из
+// локальной директории .git, а URL репозитория и тип хостинга при
+// необходимости определяются по remote origin.
+func NewLinkBuilder(cfg config.LinksConfig, projectPath, documentDir string) (*LinkBuilder, error) {
+	mode := cfg.Mode
+	if mode == "" {
+		mode = config.DefaultLinksMode
+	}
+
+	lb := &LinkBuilder{mode: mode}
+	if mode == config.LinkModeRelative && documentDir != "" {
+		prefix, err := relativeRoot(projectPath, documentDir)
+		if err != nil {
+			return nil, err
+		}
+		lb.pathPrefix = prefix
+	}
+	if mode != config.LinkModeForge {
+		return lb, nil
+	}
+
+	repo, err := git.Open(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lb.commit, err = repo.HeadCommit()
+	if err != nil {
+		return nil, err
+	}
+
+	// Пути файлов считаются от корня проекта, а ссылки — от корня репозитория
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	prefix, err := filepath.Rel(repo.Root, absProject)
+	if err != nil {
+		return nil, err
+	}
+	if prefix != "." {
+		lb.pathPrefix = filepath.ToSlash(prefix)
+	}
+
+	lb.repoURL = strings.TrimSuffix(cfg.RepoURL, "/")
+	if lb.repoURL == "" {
+		remote, err := repo.RemoteURL("origin")
+		if err != nil {
+			return nil, err
+		}
+		lb.repoURL = normalizeRemoteURL(remote)
+	}
+
+	lb.template = cfg.URLTemplate
+	if lb.template == "" {
+		forge := cfg.Forge
+		if forge == "" {
+			forge = detectForge(lb.repoURL)
+		}
+		template, ok := forgeURLTemplates[forge]
+		if !ok {
+			return nil, fmt.Errorf("не удалось определить хостинг репозитория для %s, укажите output.links.forge", lb.repoURL)
+		}
+		lb.template = template
+	}
+
+	return lb, nil
+}
+
+// Link возвращает ссылку на строки символа или пустую строку, если ссылки отключены
+func (lb *LinkBuilder) Link(filePath string, pos models.Position) string {
+	if lb == nil || lb.mode == config.LinkModeNone || pos.StartLine <= 0 {
+		return ""
+	}
+
+	relPath := filepath.ToSlash(filePath)
+	start := pos.StartLine
+	end := pos.EndLine
+	if end < start {
+		end = start
+	}
+
+	if lb.pathPrefix != "" {
+		relPath = path.Join(lb.pathPrefix, relPath)
+	}
+	if lb.mode == config.LinkModeRelative {
+		return escapePath(relPath) + lineFragment(start, end)
+	}
+
+	replacer := strings.NewReplacer(
+		"{repo}", lb.repoURL,
+		"{commit}", lb.commit,
+		"{path}", escapePath(relPath),
+		"{start}", strconv.Itoa(start),
+		"{end}", strconv.Itoa(end),
+	)
+	return replacer.Replace(lb.template)
+}
+
+// relativeRoot возвращает путь к корню проекта от директории документа
+// через "/" или пустую строку, если документ лежит в корне проекта. Если
+// относительного пути нет (другой диск), возвращается абсолютный путь.
+func relativeRoot(projectPath, documentDir string) (string, error) {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "", err
+	}
+	absDocument, err := filepath.Abs(documentDir)
+	if err != nil {
+		return "", err
+	}
+	prefix, err := filepath.Rel(absDocument, absProject)
+	if err != nil {
+		prefix = absProject
+	}
+	if prefix == "." {
+		return "", nil
+	}
+	return filepath.ToSlash(prefix), nil
+}
+
+// Label возвращает подпись ссылки вида path#L10-L42
+func (lb *LinkBuilder) Label(filePath string, pos models.Position) string {
+	end := pos.EndLine
+	if end < pos.StartLine {
+		end = pos.StartLine
+	}
+	return filepath.ToSlash(filePath) + lineFragment(pos.StartLine, end)
+}
+
+// lineFragment формирует якорь строк #L10 или #L10-L42
+func lineFragment(start, end int) string {
+	if end == start {
+		return fmt.Sprintf("#L%d", start)
+	}
+	return fmt.Sprintf("#L%d-L%d", start, end)
+}
+
+// escapePath экранирует сегменты пути для использования в URL
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// normalizeRemoteURL преобразует URL удаленного репозитория (в том числе SSH) в HTTPS
+func normalizeRemoteURL(remote string) string {
+	remote = strings.TrimSuffix(strings.TrimSpace(remote), ".git")
+
+	// Формат scp: git@github.com:org/repo
+	if !strings.Contains(remote, "://") {
+		if at := strings.Index(remote, "@"); at >= 0 {
+			remote = remote[at+1:]
+		}
+		return "https://" + strings.Replace(remote, ":", "/", 1)
+	}
+
+	parsed, err := url.Parse(remote)
+	if err != nil {
+		return remote
+	}
+	return "https://" + parsed.Hostname() + strings.TrimSuffix(parsed.Path, "/")
+}
+
+// detectForge определяет тип хостинга по адресу репозитория
+func detectForge(repoURL string) string {
+	switch {
+	case strings.Contains(repoURL, "github"):
+		return config.ForgeGitHub
+	case strings.Contains(repoURL, "gitlab"):
+		return config.ForgeGitLab
+	case strings.Contains(repoURL, "gitea"), strings.Contains(repoURL, "codeberg"):
+		return config.ForgeGitea
+	default:
+		return ""
+	}
+}
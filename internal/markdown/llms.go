@@ -0,0 +1,281 @@
+package markdown
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"code-telescope/internal/depgraph"
+	"code-telescope/pkg/models"
+	"code-telescope/pkg/utils"
+)
+
+// Веса, определяющие приоритет символа при усечении по бюджету токенов
+const (
+	scorePublic          = 10
+	scoreEntrypoint      = 20
+	scoreEntrypointFile  = 5
+	scorePerDependent    = 3
+	scoreType            = 2
+	scoreWithDescription = 1
+)
+
+// Максимальное количество файлов, перечисляемых в сводке пропущенного
+const maxOmittedFilesListed = 10
+
+// Имена файлов, которые считаются точками входа
+var entrypointFileNames = map[string]bool{
+	"main.go":     true,
+	"main.js":     true,
+	"main.ts":     true,
+	"index.js":    true,
+	"index.ts":    true,
+	"main.py":     true,
+	"__main__.py": true,
+	"app.py":      true,
+	"manage.py":   true,
+}
+
+// llmsSymbol представляет одну строку компактного формата
+type llmsSymbol struct {
+	file   string
+	order  int
+	line   string
+	tokens int
+	score  int
+	sortBy string
+}
+
+// GenerateLLMsTxt генерирует компактную карту кода в стиле llms.txt:
+// одна строка на символ с сигнатурой и однострочным описанием.
+// Если в конфигурации задан бюджет токенов, в документ попадают
+// наиболее важные символы, а остальные перечисляются в сводке.
+func (g *Generator) GenerateLLMsTxt(fileStructures []models.FileStructure, projectName string) string {
+	files := make([]models.FileStructure, len(fileStructures))
+	copy(files, fileStructures)
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+
+	graph := depgraph.Build(files)
+
+	var symbols []llmsSymbol
+	for _, file := range files {
+		symbols = append(symbols, g.collectLLMsSymbols(file, graph)...)
+	}
+
+	header := fmt.Sprintf(g.templates.LLMsHeader, projectName, projectName, len(files), len(symbols))
+
+	maxTokens := 0
+	if g.config != nil {
+		maxTokens = g.config.Output.MaxTokens
+	}
+
+	selected := make(map[string]map[int]bool)
+	total := utils.EstimateTokens(header)
+	for _, file := range files {
+		total += utils.EstimateTokens(fmt.Sprintf(LLMsFileHeaderTemplate, file.Path))
+	}
+	for _, symbol := range symbols {
+		total += symbol.tokens
+	}
+
+	var omitted []llmsSymbol
+	if maxTokens <= 0 || total <= maxTokens {
+		for _, symbol := range symbols {
+			markSelected(selected, symbol)
+		}
+	} else {
+		omitted = selectByBudget(symbols, selected, maxTokens-utils.EstimateTokens(header)-summaryReserve(maxTokens))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(header)
+
+	for _, file := range files {
+		chosen := selected[file.Path]
+		if len(chosen) == 0 {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(LLMsFileHeaderTemplate, file.Path))
+		for _, symbol := range symbols {
+			if symbol.file == file.Path && chosen[symbol.order] {
+				sb.WriteString(symbol.line)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(omitted) > 0 {
+		sb.WriteString(g.generateOmittedSummary(omitted, len(symbols), maxTokens))
+	}
+
+	return sb.String()
+}
+
+// collectLLMsSymbols формирует строки для типов и методов одного файла
+func (g *Generator) collectLLMsSymbols(file models.FileStructure, graph *depgraph.Graph) []llmsSymbol {
+	fileScore := graph.FanIn(file.Path) * scorePerDependent
+	if entrypointFileNames[path.Base(strings.ReplaceAll(file.Path, "\\", "/"))] {
+		fileScore += scoreEntrypointFile
+	}
+
+	symbols := make([]llmsSymbol, 0, len(file.Types)+len(file.Methods))
+
+	for _, typ := range file.Types {
+		signature := kindLabel(typ.Kind) + " " + typ.Name
+		score := fileScore + scoreType
+		if typ.IsPublic {
+			score += scorePublic
+		}
+		link := g.links.Link(file.Path, typ.Position)
+		symbols = append(symbols, newLLMsSymbol(file.Path, len(symbols), signature, link, typ.Description, score, typ.Position))
+	}
+
+	for _, method := range file.Methods {
+		signature := method.Signature
+		if method.BelongsTo != "" {
+			signature = method.BelongsTo + "." + signature
+		}
+		score := fileScore
+		if method.IsPublic {
+			score += scorePublic
+		}
+		if method.Kind == "function" && method.Name == "main" {
+			score += scoreEntrypoint
+		}
+		link := g.links.Link(file.Path, method.Position)
+		symbols = append(symbols, newLLMsSymbol(file.Path, len(symbols), signature, link, method.Description, score, method.Position))
+	}
+
+	return symbols
+}
+
+// newLLMsSymbol создает строку компактного формата и оценивает ее размер.
+// При наличии ссылки строка оформляется как элемент списка ссылок llms.txt.
+func newLLMsSymbol(file string, order int, signature, link, description string, score int, pos models.Position) llmsSymbol {
+	line := fmt.Sprintf("- `%s`", signature)
+	if link != "" {
+		line = fmt.Sprintf("- [`%s`](%s)", signature, link)
+	}
+	if sentence := firstSentence(description); sentence != "" {
+		line += ": " + sentence
+		score += scoreWithDescription
+	}
+	line += "\n"
+
+	return llmsSymbol{
+		file:   file,
+		order:  order,
+		line:   line,
+		tokens: utils.EstimateTokens(line),
+		score:  score,
+		sortBy: fmt.Sprintf("%s:%08d:%s", file, pos.StartLine, signature),
+	}
+}
+
+// selectByBudget выбирает символы по убыванию важности, пока они помещаются
+// в бюджет, и возвращает список пропущенных символов
+func selectByBudget(symbols []llmsSymbol, selected map[string]map[int]bool, budget int) []llmsSymbol {
+	ranked := make([]llmsSymbol, len(symbols))
+	copy(ranked, symbols)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].sortBy < ranked[j].sortBy
+	})
+
+	var omitted []llmsSymbol
+	used := 0
+	for _, symbol := range ranked {
+		cost := symbol.tokens
+		if len(selected[symbol.file]) == 0 {
+			cost += utils.EstimateTokens(fmt.Sprintf(LLMsFileHeaderTemplate, symbol.file))
+		}
+		if used+cost > budget {
+			omitted = append(omitted, symbol)
+			continue
+		}
+		used += cost
+		markSelected(selected, symbol)
+	}
+
+	return omitted
+}
+
+// generateOmittedSummary формирует сводку символов, не поместившихся в бюджет
+func (g *Generator) generateOmittedSummary(omitted []llmsSymbol, totalSymbols, maxTokens int) string {
+	perFile := make(map[string]int)
+	omittedTokens := 0
+	for _, symbol := range omitted {
+		perFile[symbol.file]++
+		omittedTokens += symbol.tokens
+	}
+
+	files := make([]string, 0, len(perFile))
+	for file := range perFile {
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if perFile[files[i]] != perFile[files[j]] {
+			return perFile[files[i]] > perFile[files[j]]
+		}
+		return files[i] < files[j]
+	})
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(g.templates.LLMsOmitted, maxTokens, len(omitted), totalSymbols, omittedTokens, len(files)))
+	for i, file := range files {
+		if i == maxOmittedFilesListed {
+			sb.WriteString(fmt.Sprintf(g.templates.LLMsOmittedMore, len(files)-maxOmittedFilesListed))
+			break
+		}
+		sb.WriteString(fmt.Sprintf(LLMsOmittedFileTemplate, file, perFile[file]))
+	}
+
+	return sb.String()
+}
+
+// summaryReserve возвращает количество токенов, резервируемое под сводку пропущенного
+func summaryReserve(maxTokens int) int {
+	reserve := maxTokens / 5
+	if reserve > 150 {
+		reserve = 150
+	}
+	return reserve
+}
+
+// markSelected отмечает символ как включенный в документ
+func markSelected(selected map[string]map[int]bool, symbol llmsSymbol) {
+	if selected[symbol.file] == nil {
+		selected[symbol.file] = make(map[int]bool)
+	}
+	selected[symbol.file][symbol.order] = true
+}
+
+// kindLabel преобразует вид типа из дерева разбора в короткую метку
+func kindLabel(kind string) string {
+	label := strings.TrimSuffix(kind, "_type")
+	if label == "" {
+		return "type"
+	}
+	return label
+}
+
+// firstSentence возвращает первое предложение описания в одну строку
+func firstSentence(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return ""
+	}
+
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '.', '!', '?':
+			if i == len(text)-1 || text[i+1] == ' ' {
+				return text[:i+1]
+			}
+		}
+	}
+	return text
+}
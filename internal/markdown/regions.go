@@ -0,0 +1,111 @@
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Маркеры областей документа, которые обновляются генератором:
+//
+//	<!-- code-telescope:start -->            область по умолчанию (весь проект)
+//	<!-- code-telescope:start name=core -->  именованная область
+//	<!-- code-telescope:end -->
+var (
+	regionStartRe = regexp.MustCompile(`<!--\s*code-telescope:start(?:\s+name=["']?([\w.\-/]+)["']?)?\s*-->`)
+	regionEndRe   = regexp.MustCompile(`<!--\s*code-telescope:end(?:\s+name=["']?([\w.\-/]+)["']?)?\s*-->`)
+)
+
+// Region представляет область документа между маркерами
+type Region struct {
+	// Имя области (пусто для области по умолчанию)
+	Name string
+
+	// Смещение начала содержимого (сразу после открывающего маркера)
+	ContentStart int
+
+	// Смещение конца содержимого (перед закрывающим маркером)
+	ContentEnd int
+}
+
+// FindRegions находит все области между маркерами в документе
+func FindRegions(doc string) ([]Region, error) {
+	starts := regionStartRe.FindAllStringSubmatchIndex(doc, -1)
+	ends := regionEndRe.FindAllStringSubmatchIndex(doc, -1)
+
+	var regions []Region
+	endIdx := 0
+	for i, start := range starts {
+		name := submatch(doc, start, 1)
+
+		// Пропускаем закрывающие маркеры, стоящие до открывающего
+		for endIdx < len(ends) && ends[endIdx][0] < start[1] {
+			endIdx++
+		}
+		if endIdx >= len(ends) {
+			return nil, fmt.Errorf("область %s не закрыта маркером code-telescope:end", regionLabel(name))
+		}
+
+		end := ends[endIdx]
+		if i+1 < len(starts) && starts[i+1][0] < end[0] {
+			return nil, fmt.Errorf("область %s содержит вложенную область, вложенность не поддерживается", regionLabel(name))
+		}
+		if endName := submatch(doc, end, 1); endName != "" && endName != name {
+			return nil, fmt.Errorf("область %s закрыта маркером области %s", regionLabel(name), regionLabel(endName))
+		}
+
+		regions = append(regions, Region{
+			Name:         name,
+			ContentStart: start[1],
+			ContentEnd:   end[0],
+		})
+		endIdx++
+	}
+
+	return regions, nil
+}
+
+// ReplaceRegions заменяет содержимое областей документа, сохраняя все остальное.
+// Функция render возвращает новое содержимое области и признак того, что
+// область нужно обновить; необновляемые области остаются без изменений.
+func ReplaceRegions(doc string, render func(name string) (string, bool)) (string, int, error) {
+	regions, err := FindRegions(doc)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var sb strings.Builder
+	last := 0
+	replaced := 0
+	for _, region := range regions {
+		content, ok := render(region.Name)
+		if !ok {
+			continue
+		}
+		sb.WriteString(doc[last:region.ContentStart])
+		sb.WriteString("\n")
+		sb.WriteString(strings.TrimRight(content, "\n"))
+		sb.WriteString("\n")
+		last = region.ContentEnd
+		replaced++
+	}
+	sb.WriteString(doc[last:])
+
+	return sb.String(), replaced, nil
+}
+
+// submatch возвращает группу совпадения регулярного выражения или пустую строку
+func submatch(s string, match []int, group int) string {
+	if match[2*group] < 0 {
+		return ""
+	}
+	return s[match[2*group]:match[2*group+1]]
+}
+
+// regionLabel возвращает имя области для сообщений об ошибках
+func regionLabel(name string) string {
+	if name == "" {
+		return "по умолчанию"
+	}
+	return `"` + name + `"`
+}
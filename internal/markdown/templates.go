@@ -1,33 +1,212 @@
 package markdown
 
+import "code-telescope/internal/config"
+
 // Шаблоны для генерации Markdown документации
 
 // FileHeaderTemplate шаблон для заголовка файла
 const FileHeaderTemplate = "## %s\n\n"
 
-// ImportsExportsTemplate шаблон для секции импортов/экспортов
-const ImportsExportsTemplate = "### Импорты/Экспорты\n```\n%s\n```\n\n"
+// TypeTemplate шаблон для заголовка типа с его видом
+const TypeTemplate = "#### %s %s\n"
+
+// SymbolAnchorTemplate шаблон якоря символа перед его заголовком; якорь
+// строится по идентификатору символа, поэтому ссылки на символ не зависят
+// от порядка и имен соседних символов
+const SymbolAnchorTemplate = "<a id=\"%s\"></a>\n"
+
+// SignatureTemplate шаблон блока кода с сигнатурой метода
+const SignatureTemplate = "```%s\n%s\n```\n"
+
+// TableOfContentsItemTemplate шаблон для элемента оглавления
+const TableOfContentsItemTemplate = "- [%s](#%s)\n"
+
+// LLMsFileHeaderTemplate шаблон раздела файла в компактном формате
+const LLMsFileHeaderTemplate = "## %s\n\n"
+
+// LLMsOmittedFileTemplate шаблон строки сводки для одного файла
+const LLMsOmittedFileTemplate = "- %s: %d\n"
+
+// Templates содержит шаблоны и подписи документа на одном языке
+type Templates struct {
+	// Секция импортов/экспортов и ее подзаголовки
+	ImportsExports string
+	Imports        string
+	Exports        string
 
-// PublicMethodsHeaderTemplate шаблон для заголовка публичных методов
-const PublicMethodsHeaderTemplate = "### Публичные методы\n\n"
+	// Публичные методы и типы
+	PublicMethodsHeader string
+	Method              string
+	TypesHeader         string
+	TypeDescription     string
+	TypeMethod          string
+	NoDescription       string
+	Fields              string
+	Parameters          string
+	Returns             string
 
-// MethodTemplate шаблон для описания метода
-const MethodTemplate = "#### %s\n%s\n%s\n- **Описание**: %s\n\n"
+	// Информация о файле и единицы размера: байты, килобайты, мегабайты
+	FileInfoLanguage string
+	FileInfoSize     string
+	FileInfoLines    string
+	FileInfoModTime  string
+	SizeUnits        [3]string
 
-// CodeMapHeaderTemplate шаблон для заголовка карты кода
-const CodeMapHeaderTemplate = "# Карта кода проекта %s\n\n"
+	// Ссылка на исходный код символа
+	SourceLink string
 
-// CodeMapIntroTemplate шаблон для введения карты кода
-const CodeMapIntroTemplate = `## Общая информация
+	// Заголовок, введение и оглавление карты кода
+	CodeMapHeader   string
+	CodeMapIntro    string
+	TableOfContents string
+
+	// Компактный формат в стиле llms.txt
+	LLMsHeader      string
+	LLMsOmitted     string
+	LLMsOmittedMore string
+}
+
+// Шаблоны документа по языкам описаний
+var languageTemplates = map[string]Templates{
+	config.OutputLanguageRussian: {
+		ImportsExports:      "### Импорты/Экспорты\n```\n%s\n```\n\n",
+		Imports:             "Импорты:\n",
+		Exports:             "Экспорты:\n",
+		PublicMethodsHeader: "### Публичные методы\n\n",
+		Method:              "#### %s\n%s\n%s\n- **Описание**: %s\n\n",
+		TypesHeader:         "### Типы\n\n",
+		TypeDescription:     "- **Описание**: %s\n",
+		TypeMethod:          "##### %s\n%s\n%s\n- **Описание**: %s\n\n",
+		NoDescription:       "Нет описания",
+		Fields:              "- **Поля**: \n",
+		Parameters:          "- **Входные параметры**: \n",
+		Returns:             "- **Выходные параметры**: \n",
+		FileInfoLanguage:    "- **Язык**: %s\n",
+		FileInfoSize:        "- **Размер**: %s\n",
+		FileInfoLines:       "- **Строк**: %d\n",
+		FileInfoModTime:     "- **Изменен**: %s\n",
+		SizeUnits:           [3]string{"Б", "КБ", "МБ"},
+		SourceLink:          "- **Исходный код**: [%s](%s)\n",
+		CodeMapHeader:       "# Карта кода проекта %s\n\n",
+		CodeMapIntro: `## Общая информация
 
 Эта карта кода представляет высокоуровневое описание проекта. Каждый файл представлен как "черный ящик" 
 с его интерфейсами (импорты/экспорты) и публичными методами.
 
-## Содержание
+`,
+		TableOfContents: `## Содержание
 
 %s
 
-`
+`,
+		LLMsHeader: `# %s
 
-// TableOfContentsItemTemplate шаблон для элемента оглавления
-const TableOfContentsItemTemplate = "- [%s](#%s)\n"
+> Компактная карта кода проекта %s: %d файлов, %d символов.
+> Одна строка на символ: сигнатура и краткое описание.
+
+`,
+		LLMsOmitted: `## Пропущено
+
+Бюджет в %d токенов исчерпан: пропущено %d из %d символов (~%d токенов) в %d файлах.
+
+`,
+		LLMsOmittedMore: "- ...и еще %d файлов\n",
+	},
+	config.OutputLanguageEnglish: {
+		ImportsExports:      "### Imports/Exports\n```\n%s\n```\n\n",
+		Imports:             "Imports:\n",
+		Exports:             "Exports:\n",
+		PublicMethodsHeader: "### Public methods\n\n",
+		Method:              "#### %s\n%s\n%s\n- **Description**: %s\n\n",
+		TypesHeader:         "### Types\n\n",
+		TypeDescription:     "- **Description**: %s\n",
+		TypeMethod:          "##### %s\n%s\n%s\n- **Description**: %s\n\n",
+		NoDescription:       "No description",
+		Fields:              "- **Fields**: \n",
+		Parameters:          "- **Parameters**: \n",
+		Returns:             "- **Returns**: \n",
+		FileInfoLanguage:    "- **Language**: %s\n",
+		FileInfoSize:        "- **Size**: %s\n",
+		FileInfoLines:       "- **Lines**: %d\n",
+		FileInfoModTime:     "- **Modified**: %s\n",
+		SizeUnits:           [3]string{"B", "KB", "MB"},
+		SourceLink:          "- **Source**: [%s](%s)\n",
+		CodeMapHeader:       "# Code map of project %s\n\n",
+		CodeMapIntro: `## Overview
+
+This code map gives a high-level description of the project. Each file is presented as a "black box"
+with its interfaces (imports/exports) and public methods.
+
+`,
+		TableOfContents: `## Contents
+
+%s
+
+`,
+		LLMsHeader: `# %s
+
+> Compact code map of project %s: %d files, %d symbols.
+> One line per symbol: signature and short description.
+
+`,
+		LLMsOmitted: `## Omitted
+
+The budget of %d tokens is exhausted: %d of %d symbols (~%d tokens) omitted in %d files.
+
+`,
+		LLMsOmittedMore: "- ...and %d more files\n",
+	},
+	config.OutputLanguageGerman: {
+		ImportsExports:      "### Importe/Exporte\n```\n%s\n```\n\n",
+		Imports:             "Importe:\n",
+		Exports:             "Exporte:\n",
+		PublicMethodsHeader: "### Öffentliche Methoden\n\n",
+		Method:              "#### %s\n%s\n%s\n- **Beschreibung**: %s\n\n",
+		TypesHeader:         "### Typen\n\n",
+		TypeDescription:     "- **Beschreibung**: %s\n",
+		TypeMethod:          "##### %s\n%s\n%s\n- **Beschreibung**: %s\n\n",
+		NoDescription:       "Keine Beschreibung",
+		Fields:              "- **Felder**: \n",
+		Parameters:          "- **Parameter**: \n",
+		Returns:             "- **Rückgabewerte**: \n",
+		FileInfoLanguage:    "- **Sprache**: %s\n",
+		FileInfoSize:        "- **Größe**: %s\n",
+		FileInfoLines:       "- **Zeilen**: %d\n",
+		FileInfoModTime:     "- **Geändert**: %s\n",
+		SizeUnits:           [3]string{"B", "KB", "MB"},
+		SourceLink:          "- **Quellcode**: [%s](%s)\n",
+		CodeMapHeader:       "# Code-Karte des Projekts %s\n\n",
+		CodeMapIntro: `## Überblick
+
+Diese Code-Karte beschreibt das Projekt auf hoher Ebene. Jede Datei wird als "Black Box"
+mit ihren Schnittstellen (Importe/Exporte) und öffentlichen Methoden dargestellt.
+
+`,
+		TableOfContents: `## Inhalt
+
+%s
+
+`,
+		LLMsHeader: `# %s
+
+> Kompakte Code-Karte des Projekts %s: %d Dateien, %d Symbole.
+> Eine Zeile pro Symbol: Signatur und Kurzbeschreibung.
+
+`,
+		LLMsOmitted: `## Ausgelassen
+
+Das Budget von %d Tokens ist erschöpft: %d von %d Symbolen (~%d Tokens) in %d Dateien ausgelassen.
+
+`,
+		LLMsOmittedMore: "- ...und %d weitere Dateien\n",
+	},
+}
+
+// TemplatesFor возвращает шаблоны документа для языка описаний.
+// Для неизвестного языка используются шаблоны языка по умолчанию.
+func TemplatesFor(language string) Templates {
+	if templates, ok := languageTemplates[language]; ok {
+		return templates
+	}
+	return languageTemplates[config.DefaultOutputLanguage]
+}
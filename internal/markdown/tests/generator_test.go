@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/markdown"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// sampleFileStructure создает файл с типом, методом типа и свободной функцией
+func sampleFileStructure() models.FileStructure {
+	return models.FileStructure{
+		Path:      "internal/store/store.go",
+		Language:  "Go",
+		Size:      2048,
+		ModTime:   time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC),
+		LineCount: 87,
+		Types: []models.TypeInfo{
+			{Name: "Store", Kind: "struct_type", IsPublic: true, Fields: []string{"Path: string"}},
+		},
+		Methods: []models.MethodInfo{
+			{Name: "Get", Signature: "Get(key string) string", Kind: "method", BelongsTo: "Store",
+				Description: "Возвращает значение по ключу из хранилища без блокировки"},
+			{Name: "Open", Signature: "Open(path string) *Store", Kind: "function"},
+		},
+	}
+}
+
+// TestGenerateCodeMapOptions проверяет оглавление, информацию о файле и группировку по типам
+func TestGenerateCodeMapOptions(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Markdown.MaxMethodDescriptionLen = 30
+
+	result := markdown.New(cfg).GenerateCodeMap([]models.FileStructure{sampleFileStructure()}, "demo")
+
+	assert.Contains(t, result, "## Содержание")
+	assert.Contains(t, result, "- **Язык**: Go\n- **Размер**: 2.0 КБ\n- **Строк**: 87\n- **Изменен**: 2024-05-01 12:30\n")
+	assert.Contains(t, result, "### Типы\n\n#### struct Store\n- **Поля**: \n  - Path: string\n")
+	assert.Contains(t, result, "##### Get\n")
+	assert.Contains(t, result, "```go\nGet(key string) string\n```\n")
+	assert.Contains(t, result, "- **Описание**: Возвращает значение по ключу…\n")
+	assert.Contains(t, result, "### Публичные методы\n\n#### Open\n")
+	assert.NotContains(t, result, "\n#### Get\n")
+
+	cfg.Markdown.IncludeTOC = false
+	cfg.Markdown.IncludeFileInfo = false
+	cfg.Markdown.GroupMethodsByType = false
+	cfg.Markdown.CodeStyle = "default"
+
+	result = markdown.New(cfg).GenerateCodeMap([]models.FileStructure{sampleFileStructure()}, "demo")
+
+	assert.NotContains(t, result, "## Содержание")
+	assert.NotContains(t, result, "- **Размер**")
+	assert.NotContains(t, result, "### Типы")
+	assert.Contains(t, result, "\n#### Get\n")
+	assert.Contains(t, result, "```\nGet(key string) string\n```\n")
+}
+
+// TestGenerateCodeMapOutputLanguage проверяет заголовки документа на языке описаний
+func TestGenerateCodeMapOutputLanguage(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.LLM.OutputLanguage = config.OutputLanguageEnglish
+
+	result := markdown.New(cfg).GenerateCodeMap([]models.FileStructure{sampleFileStructure()}, "demo")
+
+	assert.Contains(t, result, "# Code map of project demo\n")
+	assert.Contains(t, result, "- **Language**: Go\n- **Size**: 2.0 KB\n- **Lines**: 87\n")
+	assert.Contains(t, result, "### Types\n\n#### struct Store\n- **Fields**: \n")
+	assert.Contains(t, result, "### Public methods\n\n#### Open\n")
+	assert.Contains(t, result, "- **Description**: No description\n")
+	assert.NotContains(t, result, "Описание")
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/markdown"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createFakeRepository создает минимальную директорию .git с веткой main и remote origin
+func createFakeRepository(t *testing.T, remoteURL string) string {
+	root := t.TempDir()
+	gitDir := filepath.Join(root, ".git")
+	require.NoError(t, os.MkdirAll(filepath.Join(gitDir, "refs", "heads"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "refs", "heads", "main"), []byte("0123456789abcdef\n"), 0644))
+	gitConfig := "[core]\n\tbare = false\n[remote \"origin\"]\n\turl = " + remoteURL + "\n"
+	require.NoError(t, os.WriteFile(filepath.Join(gitDir, "config"), []byte(gitConfig), 0644))
+	return root
+}
+
+// TestRelativeLinks проверяет относительные ссылки на строки файла
+func TestRelativeLinks(t *testing.T) {
+	links, err := markdown.NewLinkBuilder(config.LinksConfig{Mode: config.LinkModeRelative}, ".", "")
+	require.NoError(t, err)
+
+	pos := models.Position{StartLine: 10, EndLine: 42}
+	assert.Equal(t, "src/app.js#L10-L42", links.Link("src/app.js", pos))
+	assert.Equal(t, "src/app.js#L7", links.Link("src/app.js", models.Position{StartLine: 7, EndLine: 7}))
+}
+
+// TestRelativeLinksFromDocumentDir проверяет, что относительные ссылки
+// строятся от директории документа, а не от корня проекта
+func TestRelativeLinksFromDocumentDir(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	pos := models.Position{StartLine: 10, EndLine: 42}
+
+	for documentDir, expected := range map[string]string{
+		project:                               "src/app.js#L10-L42",
+		filepath.Join(project, "docs"):        "../src/app.js#L10-L42",
+		filepath.Join(project, "docs", "api"): "../../src/app.js#L10-L42",
+		filepath.Join(root, "site"):           "../project/src/app.js#L10-L42",
+	} {
+		links, err := markdown.NewLinkBuilder(config.LinksConfig{Mode: config.LinkModeRelative}, project, documentDir)
+		require.NoError(t, err)
+		assert.Equal(t, expected, links.Link("src/app.js", pos), documentDir)
+		assert.Equal(t, "src/app.js#L10-L42", links.Label("src/app.js", pos), "подпись остается путем от корня проекта")
+	}
+}
+
+// TestForgeLinks проверяет постоянные ссылки, привязанные к коммиту из .git
+func TestForgeLinks(t *testing.T) {
+	root := createFakeRepository(t, "git@github.com:org/repo.git")
+	project := filepath.Join(root, "service")
+	require.NoError(t, os.MkdirAll(project, 0755))
+
+	links, err := markdown.NewLinkBuilder(config.LinksConfig{Mode: config.LinkModeForge}, project, "")
+	require.NoError(t, err)
+
+	pos := models.Position{StartLine: 3, EndLine: 5}
+	assert.Equal(t, "https://github.com/org/repo/blob/0123456789abcdef/service/main.go#L3-L5", links.Link("main.go", pos))
+
+	gitlab, err := markdown.NewLinkBuilder(config.LinksConfig{
+		Mode:    config.LinkModeForge,
+		Forge:   config.ForgeGitLab,
+		RepoURL: "https://git.example.com/team/repo",
+	}, root, "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://git.example.com/team/repo/-/blob/0123456789abcdef/main.go#L3-5", gitlab.Link("main.go", pos))
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/markdown"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// createTestFileStructures создает набор файлов, где util.js импортируется из app.js
+func createTestFileStructures() []models.FileStructure {
+	return []models.FileStructure{
+		{
+			Path:    "src/util.js",
+			Imports: []string{},
+			Methods: []models.MethodInfo{
+				{Name: "helper", Signature: "helper(x)", Kind: "function", IsPublic: true,
+					Description: "Возвращает аргумент без изменений. Используется в тестах."},
+				{Name: "internal", Signature: "internal()", Kind: "function"},
+			},
+		},
+		{
+			Path:    "src/app.js",
+			Imports: []string{"./util"},
+			Types: []models.TypeInfo{
+				{Name: "Server", Kind: "class", IsPublic: true},
+			},
+			Methods: []models.MethodInfo{
+				{Name: "main", Signature: "main(argv)", Kind: "function", IsPublic: true},
+				{Name: "start", Signature: "start(host)", Kind: "method", BelongsTo: "Server", IsPublic: true},
+			},
+		},
+	}
+}
+
+// TestGenerateLLMsTxtWithoutBudget проверяет, что без бюджета выводятся все символы
+func TestGenerateLLMsTxtWithoutBudget(t *testing.T) {
+	cfg := config.DefaultConfig()
+	generator := markdown.New(cfg)
+
+	output := generator.GenerateLLMsTxt(createTestFileStructures(), "demo")
+
+	assert.True(t, strings.HasPrefix(output, "# demo\n"), "Документ должен начинаться с заголовка проекта")
+	assert.Contains(t, output, "- `class Server`\n")
+	assert.Contains(t, output, "- `Server.start(host)`\n")
+	assert.Contains(t, output, "- `helper(x)`: Возвращает аргумент без изменений.\n", "Описание должно сокращаться до одного предложения")
+	assert.Less(t, strings.Index(output, "## src/app.js"), strings.Index(output, "## src/util.js"), "Файлы должны быть отсортированы")
+	assert.NotContains(t, output, "## Пропущено")
+}
+
+// TestGenerateLLMsTxtWithBudget проверяет приоритизацию и сводку пропущенного
+func TestGenerateLLMsTxtWithBudget(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Output.MaxTokens = 90
+	generator := markdown.New(cfg)
+
+	output := generator.GenerateLLMsTxt(createTestFileStructures(), "demo")
+
+	assert.Contains(t, output, "`main(argv)`", "Точка входа должна иметь наивысший приоритет")
+	assert.NotContains(t, output, "`internal()`", "Непубличный символ должен быть пропущен первым")
+	assert.Contains(t, output, "## Пропущено")
+	assert.Contains(t, output, "Бюджет в 90 токенов исчерпан")
+}
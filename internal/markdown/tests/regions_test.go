@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+
+	"code-telescope/internal/markdown"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplaceRegions проверяет замену только содержимого между маркерами
+func TestReplaceRegions(t *testing.T) {
+	doc := "# Архитектура\n\nВступление.\n\n" +
+		"<!-- code-telescope:start -->\nстарая карта\n<!-- code-telescope:end -->\n\n" +
+		"## Ядро\n\n<!-- code-telescope:start name=core -->\n<!-- code-telescope:end name=core -->\n\n" +
+		"<!-- code-telescope:start name=unknown -->\nне трогать\n<!-- code-telescope:end -->\n\nЗаключение.\n"
+
+	updated, replaced, err := markdown.ReplaceRegions(doc, func(name string) (string, bool) {
+		switch name {
+		case "":
+			return "новая карта\n", true
+		case "core":
+			return "карта ядра", true
+		default:
+			return "", false
+		}
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, replaced)
+	assert.Equal(t, "# Архитектура\n\nВступление.\n\n"+
+		"<!-- code-telescope:start -->\nновая карта\n<!-- code-telescope:end -->\n\n"+
+		"## Ядро\n\n<!-- code-telescope:start name=core -->\nкарта ядра\n<!-- code-telescope:end name=core -->\n\n"+
+		"<!-- code-telescope:start name=unknown -->\nне трогать\n<!-- code-telescope:end -->\n\nЗаключение.\n", updated)
+}
+
+// TestFindRegionsErrors проверяет обработку некорректных маркеров
+func TestFindRegionsErrors(t *testing.T) {
+	_, err := markdown.FindRegions("<!-- code-telescope:start -->\nбез конца\n")
+	assert.Error(t, err)
+
+	_, err = markdown.FindRegions("<!-- code-telescope:start name=a -->\n<!-- code-telescope:start name=b -->\n<!-- code-telescope:end -->\n<!-- code-telescope:end -->\n")
+	assert.Error(t, err)
+
+	_, err = markdown.FindRegions("<!-- code-telescope:start name=a -->\n<!-- code-telescope:end name=b -->\n")
+	assert.Error(t, err)
+
+	regions, err := markdown.FindRegions("нет маркеров")
+	require.NoError(t, err)
+	assert.Empty(t, regions)
+}
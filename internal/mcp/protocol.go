@@ -0,0 +1,87 @@
+package mcp
+
+import "encoding/json"
+
+// Версия протокола JSON-RPC
+const jsonRPCVersion = "2.0"
+
+// Поддерживаемые версии протокола MCP, первая — предпочтительная
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// Коды ошибок JSON-RPC
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// request представляет запрос или уведомление JSON-RPC.
+// У уведомления нет идентификатора, и ответ на него не отправляется.
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response представляет ответ JSON-RPC
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError представляет ошибку JSON-RPC
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// isNotification проверяет, что запрос является уведомлением
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+// initializeParams содержит параметры запроса initialize
+type initializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+// initializeResult представляет ответ на запрос initialize
+type initializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      serverInfo             `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+// serverInfo описывает сервер в ответе на initialize
+type serverInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// toolsListResult представляет ответ на запрос tools/list
+type toolsListResult struct {
+	Tools []Tool `json:"tools"`
+}
+
+// callToolParams содержит параметры запроса tools/call
+type callToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// callToolResult представляет результат вызова инструмента
+type callToolResult struct {
+	Content []textContent `json:"content"`
+	IsError bool          `json:"isError,omitempty"`
+}
+
+// textContent представляет текстовый блок результата инструмента
+type textContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
@@ -0,0 +1,240 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"code-telescope/internal/codeindex"
+	"code-telescope/pkg/models"
+)
+
+// Имя сервера в ответе на initialize
+const serverName = "code-telescope"
+
+// Максимальный размер одного сообщения
+const maxMessageSize = 16 * 1024 * 1024
+
+// Server реализует сервер Model Context Protocol поверх stdio: сообщения
+// JSON-RPC передаются по одному в строке. Инструменты отвечают по модели
+// карты кода, которую можно заменить во время работы.
+type Server struct {
+	version string
+	tools   []toolEntry
+
+	mu        sync.RWMutex
+	index     *codeindex.Index
+	ready     chan struct{}
+	readyOnce sync.Once
+}
+
+// New создает сервер MCP без модели. Вызовы инструментов ожидают
+// первого вызова Update.
+func New(version string) *Server {
+	return &Server{
+		version: version,
+		tools:   newTools(),
+		ready:   make(chan struct{}),
+	}
+}
+
+// Update заменяет модель карты кода, по которой отвечают инструменты
+func (s *Server) Update(codeMap *models.CodeMap) {
+	index := codeindex.New(codeMap)
+
+	s.mu.Lock()
+	s.index = index
+	s.mu.Unlock()
+
+	s.readyOnce.Do(func() { close(s.ready) })
+}
+
+// Tools возвращает описания инструментов сервера
+func (s *Server) Tools() []Tool {
+	tools := make([]Tool, 0, len(s.tools))
+	for _, entry := range s.tools {
+		tools = append(tools, entry.Tool)
+	}
+	return tools
+}
+
+// Serve читает запросы из in и пишет ответы в out, пока входной поток
+// не закончится или не будет отменен контекст
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	reader := bufio.NewReaderSize(in, 64*1024)
+	writer := bufio.NewWriter(out)
+
+	for {
+		line, err := readLine(reader)
+		if len(bytes.TrimSpace(line)) > 0 {
+			if resp := s.handleMessage(ctx, line); resp != nil {
+				if writeErr := writeMessage(writer, resp); writeErr != nil {
+					return fmt.Errorf("ошибка записи ответа: %w", writeErr)
+				}
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка чтения запроса: %w", err)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// handleMessage разбирает одно сообщение и возвращает ответ или nil для уведомлений
+func (s *Server) handleMessage(ctx context.Context, line []byte) *response {
+	var req request
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(json.RawMessage("null"), codeParseError, "некорректный JSON: "+err.Error())
+	}
+	if req.JSONRPC != jsonRPCVersion || req.Method == "" {
+		if req.isNotification() {
+			return nil
+		}
+		return errorResponse(req.ID, codeInvalidRequest, "некорректный запрос JSON-RPC")
+	}
+
+	result, rpcErr := s.dispatch(ctx, &req)
+	if req.isNotification() {
+		return nil
+	}
+	if rpcErr != nil {
+		return &response{JSONRPC: jsonRPCVersion, ID: req.ID, Error: rpcErr}
+	}
+	return &response{JSONRPC: jsonRPCVersion, ID: req.ID, Result: result}
+}
+
+// dispatch выполняет метод протокола
+func (s *Server) dispatch(ctx context.Context, req *request) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params), nil
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return toolsListResult{Tools: s.Tools()}, nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "неизвестный метод: " + req.Method}
+	}
+}
+
+// initialize согласует версию протокола и сообщает возможности сервера
+func (s *Server) initialize(raw json.RawMessage) initializeResult {
+	var params initializeParams
+	_ = json.Unmarshal(raw, &params)
+
+	version := supportedProtocolVersions[0]
+	for _, supported := range supportedProtocolVersions {
+		if params.ProtocolVersion == supported {
+			version = supported
+			break
+		}
+	}
+
+	return initializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]interface{}{
+			"tools": map[string]interface{}{},
+		},
+		ServerInfo: serverInfo{Name: serverName, Version: s.version},
+		Instructions: "Инструменты отвечают по карте кода проекта: списку файлов, структуре файлов, " +
+			"символам, зависимостям и описаниям из кэша. Используйте их, чтобы не читать файлы целиком.",
+	}
+}
+
+// callTool выполняет инструмент. Ошибки выполнения возвращаются в результате
+// с признаком isError, чтобы клиент мог показать их модели.
+func (s *Server) callTool(ctx context.Context, raw json.RawMessage) (interface{}, *rpcError) {
+	var params callToolParams
+	if err := json.Unmarshal(raw, &params); err != nil || params.Name == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "некорректные параметры tools/call"}
+	}
+
+	entry := s.findTool(params.Name)
+	if entry == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "неизвестный инструмент: " + params.Name}
+	}
+
+	// Модель строится при запуске; вызовы ждут ее готовности
+	select {
+	case <-s.ready:
+	case <-ctx.Done():
+		return nil, &rpcError{Code: codeInvalidRequest, Message: "сервер остановлен"}
+	}
+	s.mu.RLock()
+	index := s.index
+	s.mu.RUnlock()
+
+	args := params.Arguments
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+
+	value, err := entry.handle(index, args)
+	if err != nil {
+		return callToolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return callToolResult{Content: []textContent{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+	return callToolResult{Content: []textContent{{Type: "text", Text: string(text)}}}, nil
+}
+
+// findTool возвращает инструмент по имени
+func (s *Server) findTool(name string) *toolEntry {
+	for i := range s.tools {
+		if s.tools[i].Name == name {
+			return &s.tools[i]
+		}
+	}
+	return nil
+}
+
+// errorResponse формирует ответ с ошибкой
+func errorResponse(id json.RawMessage, code int, message string) *response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	return &response{JSONRPC: jsonRPCVersion, ID: id, Error: &rpcError{Code: code, Message: message}}
+}
+
+// readLine читает одну строку, ограничивая ее размер
+func readLine(reader *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := reader.ReadLine()
+		line = append(line, chunk...)
+		if len(line) > maxMessageSize {
+			return nil, fmt.Errorf("сообщение больше %d байт", maxMessageSize)
+		}
+		if err != nil || !isPrefix {
+			return line, err
+		}
+	}
+}
+
+// writeMessage записывает сообщение одной строкой
+func writeMessage(writer *bufio.Writer, resp *response) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return writer.Flush()
+}
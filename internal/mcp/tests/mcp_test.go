@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"code-telescope/internal/codeindex"
+	"code-telescope/internal/mcp"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rpcResponse представляет ответ сервера в тестах
+type rpcResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// toolResult представляет результат вызова инструмента
+type toolResult struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+// testCodeMap возвращает модель из двух файлов: main.py импортирует app/service.py
+func testCodeMap() *models.CodeMap {
+	return models.NewCodeMap("demo", []models.FileStructure{
+		{
+			Path:      "main.py",
+			Language:  "python",
+			LineCount: 12,
+			Imports:   []string{"app.service"},
+			Methods: []models.MethodInfo{
+				{Name: "main", Kind: "function", Signature: "def main()", Body: "service.run()"},
+			},
+		},
+		{
+			Path:      "app/service.py",
+			Language:  "python",
+			LineCount: 40,
+			Types: []models.TypeInfo{
+				{Name: "Service", Kind: "class", Description: "Сервис обработки заказов"},
+			},
+			Methods: []models.MethodInfo{
+				{
+					Name: "run", Kind: "method", BelongsTo: "Service", Signature: "def run(self)",
+					Description: "Запускает обработку очереди", Body: "while True: pass",
+					Position: models.Position{StartLine: 10, EndLine: 20},
+				},
+			},
+		},
+	})
+}
+
+// session отправляет запросы серверу и возвращает ответы по идентификаторам
+func session(t *testing.T, srv *mcp.Server, messages ...string) map[string]rpcResponse {
+	var out bytes.Buffer
+	require.NoError(t, srv.Serve(context.Background(), strings.NewReader(strings.Join(messages, "\n")+"\n"), &out))
+
+	responses := make(map[string]rpcResponse)
+	scanner := bufio.NewScanner(&out)
+	for scanner.Scan() {
+		var resp rpcResponse
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &resp), scanner.Text())
+		responses[string(resp.ID)] = resp
+	}
+	return responses
+}
+
+// callTool вызывает инструмент и возвращает его результат
+func callTool(t *testing.T, srv *mcp.Server, name string, args interface{}) toolResult {
+	data, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0", "id": 1, "method": "tools/call",
+		"params": map[string]interface{}{"name": name, "arguments": args},
+	})
+	require.NoError(t, err)
+
+	resp := session(t, srv, string(data))["1"]
+	require.Nil(t, resp.Error)
+	var result toolResult
+	require.NoError(t, json.Unmarshal(resp.Result, &result))
+	require.Len(t, result.Content, 1)
+	return result
+}
+
+// newServer создает сервер с тестовой моделью
+func newServer() *mcp.Server {
+	srv := mcp.New("test")
+	srv.Update(testCodeMap())
+	return srv
+}
+
+// TestProtocol проверяет initialize, tools/list, уведомления и ошибки протокола
+func TestProtocol(t *testing.T) {
+	responses := session(t, newServer(),
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"unknown"}}`,
+		`{not json`,
+	)
+	require.Len(t, responses, 6, "на уведомление ответ не отправляется")
+
+	var initialize struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name string `json:"name"`
+		} `json:"serverInfo"`
+	}
+	require.NoError(t, json.Unmarshal(responses["1"].Result, &initialize))
+	assert.Equal(t, "2024-11-05", initialize.ProtocolVersion)
+	assert.Equal(t, "code-telescope", initialize.ServerInfo.Name)
+
+	var list struct {
+		Tools []mcp.Tool `json:"tools"`
+	}
+	require.NoError(t, json.Unmarshal(responses["2"].Result, &list))
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+		assert.Equal(t, "object", tool.InputSchema["type"], tool.Name)
+	}
+	assert.Equal(t, []string{"list_files", "get_file_outline", "find_symbol", "get_dependencies", "get_description", "search"}, names)
+
+	assert.JSONEq(t, `{}`, string(responses["3"].Result))
+	require.NotNil(t, responses["4"].Error)
+	assert.Equal(t, -32601, responses["4"].Error.Code)
+	require.NotNil(t, responses["5"].Error)
+	assert.Equal(t, -32602, responses["5"].Error.Code)
+	require.NotNil(t, responses["null"].Error)
+	assert.Equal(t, -32700, responses["null"].Error.Code)
+}
+
+// TestFileTools проверяет list_files и get_file_outline
+func TestFileTools(t *testing.T) {
+	srv := newServer()
+
+	var files []codeindex.FileSummary
+	result := callTool(t, srv, "list_files", map[string]string{"prefix": "app/"})
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &files))
+	require.Len(t, files, 1)
+	assert.Equal(t, "app/service.py", files[0].Path)
+
+	result = callTool(t, srv, "get_file_outline", map[string]string{"path": "app/service.py"})
+	assert.False(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, `"signature": "def run(self)"`)
+	assert.Contains(t, result.Content[0].Text, `"start_line": 10`)
+	assert.NotContains(t, result.Content[0].Text, "while True", "исходный код не должен попадать в структуру файла")
+
+	result = callTool(t, srv, "get_file_outline", map[string]string{"path": "missing.py"})
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].Text, "missing.py")
+}
+
+// TestSymbolTools проверяет find_symbol, search и get_description
+func TestSymbolTools(t *testing.T) {
+	srv := newServer()
+
+	var symbols []codeindex.Symbol
+	result := callTool(t, srv, "find_symbol", map[string]string{"name": "service.run"})
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &symbols))
+	require.Len(t, symbols, 1)
+	assert.Equal(t, "app/service.py", symbols[0].File)
+
+	result = callTool(t, srv, "search", map[string]interface{}{"query": "заказ"})
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &symbols))
+	require.Len(t, symbols, 1)
+	assert.Equal(t, "Service", symbols[0].Name)
+
+	result = callTool(t, srv, "search", map[string]interface{}{"query": "run", "limit": 1000})
+	assert.True(t, result.IsError)
+
+	var description struct {
+		Description string `json:"description"`
+		Cached      bool   `json:"cached"`
+	}
+	result = callTool(t, srv, "get_description", map[string]string{"path": "app/service.py", "symbol": "Service.run"})
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &description))
+	assert.True(t, description.Cached)
+	assert.Equal(t, "Запускает обработку очереди", description.Description)
+
+	result = callTool(t, srv, "get_description", map[string]string{"path": "main.py", "symbol": "main"})
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &description))
+	assert.False(t, description.Cached)
+}
+
+// TestDependenciesTool проверяет get_dependencies
+func TestDependenciesTool(t *testing.T) {
+	srv := newServer()
+
+	var deps codeindex.Dependencies
+	result := callTool(t, srv, "get_dependencies", map[string]string{"path": "app/service.py"})
+	require.NoError(t, json.Unmarshal([]byte(result.Content[0].Text), &deps))
+	assert.Empty(t, deps.Dependencies)
+	assert.Equal(t, []string{"main.py"}, deps.Dependents)
+	assert.Len(t, deps.Edges, 1)
+}
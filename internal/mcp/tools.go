@@ -0,0 +1,361 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"code-telescope/internal/codeindex"
+	"code-telescope/pkg/models"
+)
+
+// Количество результатов поиска по умолчанию и максимальное
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 200
+)
+
+// Максимальная глубина окрестности в графе зависимостей
+const maxDependencyDepth = 5
+
+// Tool описывает инструмент в ответе на tools/list
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// toolEntry связывает описание инструмента с обработчиком
+type toolEntry struct {
+	Tool
+	handle func(index *codeindex.Index, args json.RawMessage) (interface{}, error)
+}
+
+// fileOutline представляет структуру файла без исходного кода
+type fileOutline struct {
+	Path        string          `json:"path"`
+	Language    string          `json:"language"`
+	LineCount   int             `json:"line_count"`
+	Description string          `json:"description,omitempty"`
+	Imports     []string        `json:"imports,omitempty"`
+	Types       []outlineSymbol `json:"types,omitempty"`
+	Methods     []outlineSymbol `json:"methods,omitempty"`
+}
+
+// outlineSymbol представляет символ в структуре файла
+type outlineSymbol struct {
+	ID          string   `json:"id,omitempty"`
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind,omitempty"`
+	BelongsTo   string   `json:"belongs_to,omitempty"`
+	Signature   string   `json:"signature,omitempty"`
+	Fields      []string `json:"fields,omitempty"`
+	StartLine   int      `json:"start_line"`
+	EndLine     int      `json:"end_line"`
+	Description string   `json:"description,omitempty"`
+}
+
+// symbolDescription представляет ответ инструмента get_description
+type symbolDescription struct {
+	File        string `json:"file"`
+	Symbol      string `json:"symbol"`
+	Signature   string `json:"signature,omitempty"`
+	Description string `json:"description"`
+	Cached      bool   `json:"cached"`
+}
+
+// newTools возвращает инструменты сервера
+func newTools() []toolEntry {
+	return []toolEntry{
+		{
+			Tool: Tool{
+				Name:        "list_files",
+				Description: "Список файлов проекта с языком, числом строк, типов и функций. Можно ограничить префиксом пути.",
+				InputSchema: objectSchema(map[string]interface{}{
+					"prefix": stringProperty("Префикс относительного пути, например internal/server/"),
+				}),
+			},
+			handle: listFiles,
+		},
+		{
+			Tool: Tool{
+				Name:        "get_file_outline",
+				Description: "Структура файла без исходного кода: импорты, типы, функции и методы с сигнатурами, строками и описаниями.",
+				InputSchema: objectSchema(map[string]interface{}{
+					"path": stringProperty("Относительный путь к файлу"),
+				}, "path"),
+			},
+			handle: getFileOutline,
+		},
+		{
+			Tool: Tool{
+				Name:        "find_symbol",
+				Description: "Находит определения типа, функции или метода по имени (Name или Type.Name) без учета регистра или по идентификатору символа.",
+				InputSchema: objectSchema(map[string]interface{}{
+					"name": stringProperty("Имя символа, Тип.метод или идентификатор символа"),
+				}, "name"),
+			},
+			handle: findSymbol,
+		},
+		{
+			Tool: Tool{
+				Name:        "get_dependencies",
+				Description: "Файлы, которые импортирует файл, файлы, которые импортируют его, и окрестность в графе зависимостей.",
+				InputSchema: objectSchema(map[string]interface{}{
+					"path":  stringProperty("Относительный путь к файлу"),
+					"depth": integerProperty("Глубина окрестности в графе зависимостей", 1, maxDependencyDepth),
+				}, "path"),
+			},
+			handle: getDependencies,
+		},
+		{
+			Tool: Tool{
+				Name:        "get_description",
+				Description: "Описание функции или метода из кэша описаний, сгенерированных ЛЛМ при построении карты кода.",
+				InputSchema: objectSchema(map[string]interface{}{
+					"path":   stringProperty("Относительный путь к файлу"),
+					"symbol": stringProperty("Имя функции или метода, Тип.метод или идентификатор символа"),
+				}, "path", "symbol"),
+			},
+			handle: getDescription,
+		},
+		{
+			Tool: Tool{
+				Name:        "search",
+				Description: "Поиск символов по подстроке в имени или описании без учета регистра; совпадения по имени идут первыми.",
+				InputSchema: objectSchema(map[string]interface{}{
+					"query": stringProperty("Строка поиска"),
+					"limit": integerProperty("Максимальное количество результатов", 1, maxSearchLimit),
+				}, "query"),
+			},
+			handle: search,
+		},
+	}
+}
+
+// listFiles возвращает список файлов проекта
+func listFiles(index *codeindex.Index, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Prefix string `json:"prefix"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+
+	files := index.Files()
+	if args.Prefix == "" {
+		return files, nil
+	}
+	prefix := strings.TrimPrefix(strings.ReplaceAll(args.Prefix, "\\", "/"), "./")
+	filtered := make([]codeindex.FileSummary, 0, len(files))
+	for _, file := range files {
+		if strings.HasPrefix(file.Path, prefix) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered, nil
+}
+
+// getFileOutline возвращает структуру файла без исходного кода
+func getFileOutline(index *codeindex.Index, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Path string `json:"path"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	file, err := requireFile(index, args.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	outline := fileOutline{
+		Path:        file.Path,
+		Language:    file.Language,
+		LineCount:   file.LineCount,
+		Description: file.Description,
+		Imports:     file.Imports,
+	}
+	for _, typ := range file.Types {
+		outline.Types = append(outline.Types, outlineSymbol{
+			ID:          typ.ID,
+			Name:        typ.Name,
+			Kind:        typ.Kind,
+			Fields:      typ.Fields,
+			StartLine:   typ.Position.StartLine,
+			EndLine:     typ.Position.EndLine,
+			Description: typ.Description,
+		})
+	}
+	for _, method := range file.Methods {
+		outline.Methods = append(outline.Methods, outlineSymbol{
+			ID:          method.ID,
+			Name:        method.Name,
+			Kind:        method.Kind,
+			BelongsTo:   method.BelongsTo,
+			Signature:   method.Signature,
+			StartLine:   method.Position.StartLine,
+			EndLine:     method.Position.EndLine,
+			Description: method.Description,
+		})
+	}
+	return outline, nil
+}
+
+// findSymbol возвращает определения символа по имени
+func findSymbol(index *codeindex.Index, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Name string `json:"name"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	if args.Name == "" {
+		return nil, fmt.Errorf("не указан параметр name")
+	}
+
+	symbols := index.FindSymbol(args.Name)
+	if len(symbols) == 0 {
+		return nil, fmt.Errorf("символ %s не найден; попробуйте инструмент search", args.Name)
+	}
+	return symbols, nil
+}
+
+// getDependencies возвращает окрестность файла в графе зависимостей
+func getDependencies(index *codeindex.Index, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Path  string `json:"path"`
+		Depth int    `json:"depth"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	file, err := requireFile(index, args.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case args.Depth == 0:
+		args.Depth = 1
+	case args.Depth < 0 || args.Depth > maxDependencyDepth:
+		return nil, fmt.Errorf("параметр depth должен быть от 1 до %d", maxDependencyDepth)
+	}
+	return index.Dependencies(file.Path, args.Depth), nil
+}
+
+// getDescription возвращает описание функции или метода из кэша описаний
+func getDescription(index *codeindex.Index, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Path   string `json:"path"`
+		Symbol string `json:"symbol"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	file, err := requireFile(index, args.Path)
+	if err != nil {
+		return nil, err
+	}
+	if args.Symbol == "" {
+		return nil, fmt.Errorf("не указан параметр symbol")
+	}
+
+	method := findMethod(file, args.Symbol)
+	if method == nil {
+		return nil, fmt.Errorf("символ %s не найден в файле %s", args.Symbol, file.Path)
+	}
+
+	result := symbolDescription{
+		File:        file.Path,
+		Symbol:      args.Symbol,
+		Signature:   method.Signature,
+		Description: method.Description,
+		Cached:      method.Description != "",
+	}
+	if !result.Cached {
+		result.Description = "Описание отсутствует в кэше. Выполните codetelescope generate, чтобы получить описания от ЛЛМ."
+	}
+	return result, nil
+}
+
+// search ищет символы по имени или описанию
+func search(index *codeindex.Index, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		Query string `json:"query"`
+		Limit int    `json:"limit"`
+	}
+	if err := decodeArguments(raw, &args); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(args.Query) == "" {
+		return nil, fmt.Errorf("не указан параметр query")
+	}
+
+	switch {
+	case args.Limit == 0:
+		args.Limit = defaultSearchLimit
+	case args.Limit < 0 || args.Limit > maxSearchLimit:
+		return nil, fmt.Errorf("параметр limit должен быть от 1 до %d", maxSearchLimit)
+	}
+
+	results := index.Search(args.Query, args.Limit)
+	if results == nil {
+		results = []codeindex.Symbol{}
+	}
+	return results, nil
+}
+
+// findMethod ищет функцию или метод файла по имени, в виде Тип.метод
+// или по идентификатору
+func findMethod(file *models.FileStructure, symbol string) *models.MethodInfo {
+	for i := range file.Methods {
+		method := &file.Methods[i]
+		if method.Name == symbol || method.BelongsTo+"."+method.Name == symbol || method.ID == symbol {
+			return method
+		}
+	}
+	return nil
+}
+
+// requireFile возвращает структуру файла или ошибку, если файла нет в карте кода
+func requireFile(index *codeindex.Index, filePath string) (*models.FileStructure, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("не указан параметр path")
+	}
+	file, ok := index.File(filePath)
+	if !ok {
+		return nil, fmt.Errorf("файл %s отсутствует в карте кода; список файлов возвращает list_files", filePath)
+	}
+	return file, nil
+}
+
+// decodeArguments разбирает аргументы инструмента
+func decodeArguments(raw json.RawMessage, target interface{}) error {
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("некорректные аргументы: %w", err)
+	}
+	return nil
+}
+
+// objectSchema формирует JSON Schema аргументов инструмента
+func objectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// stringProperty описывает строковый аргумент
+func stringProperty(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+// integerProperty описывает целочисленный аргумент с ограничениями
+func integerProperty(description string, min, max int) map[string]interface{} {
+	return map[string]interface{}{"type": "integer", "description": description, "minimum": min, "maximum": max}
+}
@@ -0,0 +1,168 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/markdown"
+	"code-telescope/internal/textdiff"
+	"code-telescope/pkg/models"
+)
+
+// Количество строк контекста вокруг различий в отчете check
+const checkDiffContext = 2
+
+// Заглушка, которой заменяются описания при сравнении без учета описаний
+const descriptionPlaceholder = "…"
+
+var (
+	// SHA коммита в постоянных ссылках на хостинг меняется с каждым коммитом
+	commitInLinkPattern = regexp.MustCompile(`/[0-9a-f]{40}/`)
+
+	// Строка символа компактного формата: "- `сигнатура`: описание" или "- [`сигнатура`](ссылка): описание"
+	llmsSymbolPattern = regexp.MustCompile("^(- (?:\\[`[^`]*`\\]\\([^)]*\\)|`[^`]*`)): .*$")
+)
+
+// CheckOptions задает параметры проверки актуальности карты кода
+type CheckOptions struct {
+	// Не сравнивать описания символов, сгенерированные ЛЛМ
+	IgnoreDescriptions bool
+}
+
+// CheckResult описывает результат проверки актуальности карты кода
+type CheckResult struct {
+	// Сохраненная карта кода отличается от актуальной
+	Stale bool
+
+	// Различия в формате unified diff после отбрасывания изменчивых частей
+	Diff string
+}
+
+// Check строит карту кода проекта в памяти и сравнивает ее с сохраненным
+// документом. Время генерации, даты изменения файлов и SHA коммитов в ссылках
+// не учитываются; описания символов не учитываются при IgnoreDescriptions.
+// В режиме inplace сравнивается документ с обновленными областями.
+func (o *Orchestrator) Check(projectPath, documentPath string, opts CheckOptions) (*CheckResult, error) {
+	data, err := os.ReadFile(documentPath)
+	if err != nil {
+		return nil, logger.LogError(logger.FileSystemError("ошибка чтения сохраненной карты кода", err))
+	}
+	saved := string(data)
+
+	codeMap, err := o.BuildModel(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	o.SetDocumentPath(documentPath)
+	o.SetLinkRoot(projectPath)
+	expected, err := o.Render(codeMap)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.config.Output.Mode == config.OutputModeInPlace {
+		var replaced int
+		expected, replaced, err = o.replaceRegions(saved, expected)
+		if err != nil {
+			return nil, logger.LogError(logger.OrchestratorError("некорректные маркеры областей в документе", err))
+		}
+		if replaced == 0 {
+			return nil, logger.LogError(logger.OrchestratorError(
+				fmt.Sprintf("в документе %s нет маркеров <!-- code-telescope:start -->", documentPath), nil))
+		}
+	}
+
+	diff := textdiff.Unified(documentPath, documentPath+" (актуальная)",
+		o.normalizeDocument(saved, opts), o.normalizeDocument(expected, opts), checkDiffContext)
+	return &CheckResult{Stale: diff != "", Diff: diff}, nil
+}
+
+// normalizeDocument убирает из документа части, которые меняются без изменения кода
+func (o *Orchestrator) normalizeDocument(document string, opts CheckOptions) string {
+	if o.config.Output.Format == config.OutputFormatJSON {
+		if normalized, ok := normalizeModel(document, opts); ok {
+			return normalized
+		}
+		// Сохраненный документ не является моделью: сравниваем как текст
+		return document
+	}
+
+	// Строки с датой изменения файла и описанием символа сравниваются
+	// особым образом; их подписи зависят от языка документа
+	templates := markdown.TemplatesFor(o.config.LLM.OutputLanguage)
+	modTimePrefix := templateLabel(templates.FileInfoModTime)
+	descriptionPrefix := templateLabel(templates.TypeDescription)
+
+	lines := strings.Split(strings.ReplaceAll(document, "\r\n", "\n"), "\n")
+	result := make([]string, 0, len(lines))
+	inDescription := false
+	for _, line := range lines {
+		line = commitInLinkPattern.ReplaceAllString(line, "/<commit>/")
+
+		// Продолжение многострочного описания заканчивается пустой строкой,
+		// заголовком или следующим пунктом символа
+		if inDescription {
+			if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "- **") {
+				continue
+			}
+			inDescription = false
+		}
+
+		switch {
+		case strings.HasPrefix(line, modTimePrefix):
+			continue
+		case opts.IgnoreDescriptions && strings.HasPrefix(line, descriptionPrefix):
+			line = descriptionPrefix + " " + descriptionPlaceholder
+			inDescription = true
+		case opts.IgnoreDescriptions && o.config.Output.Format == config.OutputFormatLLMs:
+			line = llmsSymbolPattern.ReplaceAllString(line, "$1")
+		}
+		result = append(result, line)
+	}
+	return strings.Join(result, "\n")
+}
+
+// templateLabel возвращает подпись пункта шаблона Markdown — текст до
+// первой подстановки, например "- **Описание**:"
+func templateLabel(template string) string {
+	label, _, _ := strings.Cut(template, "%")
+	return strings.TrimSpace(label)
+}
+
+// normalizeModel приводит JSON-модель к виду без времени генерации и дат
+// изменения файлов; возвращает false, если документ не является моделью
+func normalizeModel(document string, opts CheckOptions) (string, bool) {
+	var codeMap models.CodeMap
+	if err := json.Unmarshal([]byte(document), &codeMap); err != nil {
+		return "", false
+	}
+
+	codeMap.GeneratedAt = time.Time{}
+	for i := range codeMap.Files {
+		file := &codeMap.Files[i]
+		file.ModTime = time.Time{}
+		if !opts.IgnoreDescriptions {
+			continue
+		}
+		file.Description = ""
+		for j := range file.Methods {
+			file.Methods[j].Description = ""
+		}
+		for j := range file.Types {
+			file.Types[j].Description = ""
+		}
+	}
+	codeMap.Sort()
+
+	data, err := json.MarshalIndent(codeMap, "", "  ")
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
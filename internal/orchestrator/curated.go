@@ -0,0 +1,220 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"strings"
+
+	"code-telescope/internal/curated"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/parser"
+	"code-telescope/pkg/models"
+)
+
+// Состояния описаний из файла descriptions.overrides_file в отчете review
+const (
+	// ReviewOK — описание написано для текущей сигнатуры символа
+	ReviewOK = "ok"
+	// ReviewStale — сигнатура символа изменилась после написания описания
+	ReviewStale = "stale"
+	// ReviewUnpinned — у описания нет хэша сигнатуры, устаревание не отслеживается
+	ReviewUnpinned = "unpinned"
+	// ReviewMissing — символа с таким идентификатором в проекте нет
+	ReviewMissing = "missing"
+)
+
+// ReviewEntry описывает состояние одного описания из файла описаний
+type ReviewEntry struct {
+	// Идентификатор символа, например go:code-telescope/internal/llm.(*OpenAIProvider).GenerateText
+	ID string
+
+	// Состояние описания: ReviewOK, ReviewStale, ReviewUnpinned или ReviewMissing
+	Status string
+
+	// Текст описания
+	Description string
+
+	// Хэш текущей сигнатуры символа (пусто для ReviewMissing)
+	SignatureHash string
+}
+
+// ReviewResult содержит состояние всех описаний из файла описаний
+type ReviewResult struct {
+	// Путь к файлу описаний
+	Path string
+
+	// Описания в порядке идентификаторов
+	Entries []ReviewEntry
+
+	// Количество описаний, хэши которых записаны в файл
+	Pinned int
+}
+
+// NeedsReview проверяет, что в файле есть устаревшие описания или описания
+// несуществующих символов
+func (r *ReviewResult) NeedsReview() bool {
+	for _, entry := range r.Entries {
+		if entry.Status == ReviewStale || entry.Status == ReviewMissing {
+			return true
+		}
+	}
+	return false
+}
+
+// overridesPath возвращает путь к файлу описаний проекта
+func (o *Orchestrator) overridesPath(projectPath string) string {
+	path := o.config.Descriptions.OverridesFile
+	if path != "" && !filepath.IsAbs(path) {
+		path = filepath.Join(projectPath, path)
+	}
+	return path
+}
+
+// loadOverrides читает файл описаний проекта. Отсутствие файла не ошибка,
+// а ошибка в существующем файле прерывает генерацию, чтобы описания,
+// написанные вручную, не были молча заменены описаниями от ЛЛМ.
+func (o *Orchestrator) loadOverrides(projectPath string) error {
+	o.overrides = nil
+	path := o.overridesPath(projectPath)
+	if path == "" {
+		return nil
+	}
+
+	overrides, err := curated.Load(path)
+	if err != nil {
+		return logger.LogError(logger.OrchestratorError("не удалось загрузить файл описаний", err))
+	}
+	if len(overrides.Descriptions) > 0 {
+		logger.Infof("Файл описаний %s: %d описаний", path, len(overrides.Descriptions))
+	}
+	o.overrides = overrides
+	return nil
+}
+
+// applyOverrides заменяет описания символов файла описаниями, написанными
+// вручную. Аннотация telescope:describe в комментарии символа важнее файла
+// описаний. Описание из файла, написанное для другой сигнатуры, все равно
+// используется, но отмечается как устаревшее.
+func (o *Orchestrator) applyOverrides(codeStructure *models.CodeStructure) {
+	for _, target := range collectTargets(codeStructure, true) {
+		if o.config.Descriptions.Annotations {
+			if description, ok := curated.Annotation(target.info.Doc); ok {
+				*target.description = description
+				*target.override = &models.DescriptionOverride{Source: models.OverrideSourceAnnotation}
+				continue
+			}
+		}
+
+		id := target.info.ID
+		override, ok := o.overrides.Get(id)
+		if !ok {
+			continue
+		}
+		stale := override.Stale(curated.SignatureHash(target.signature))
+		if stale {
+			logger.Warnf("Сигнатура %s изменилась после написания описания, проверьте его командой review", id)
+		}
+		*target.description = override.Description
+		*target.override = &models.DescriptionOverride{Source: models.OverrideSourceFile, Stale: stale}
+	}
+}
+
+// fullSignature возвращает сигнатуру символа для хэша описания, написанного
+// вручную: с типами всех параметров, признаком вариативности и значениями
+// по умолчанию, которых нет в сигнатуре для промпта
+func fullSignature(name string, parameters []*models.Parameter, returnType string) string {
+	params := make([]string, 0, len(parameters))
+	for _, param := range parameters {
+		declaration := param.Name
+		// В Go признак вариативности уже входит в тип: "...T"
+		if param.IsVariadic && !strings.HasPrefix(param.Type, "...") {
+			declaration = "..." + declaration
+		}
+		if param.Type != "" {
+			declaration += ": " + param.Type
+		}
+		if param.DefaultValue != "" {
+			declaration += " = " + param.DefaultValue
+		}
+		params = append(params, declaration)
+	}
+	signature := name + "(" + strings.Join(params, ", ") + ")"
+	if returnType != "" {
+		signature += " " + returnType
+	}
+	return signature
+}
+
+// withoutOverrides возвращает символы, описания которых не написаны вручную
+func withoutOverrides(targets []describeTarget) []describeTarget {
+	pending := targets[:0]
+	for _, target := range targets {
+		if *target.override == nil {
+			pending = append(pending, target)
+		}
+	}
+	return pending
+}
+
+// ReviewOverrides сверяет описания из файла описаний с символами проекта.
+// Описания не запрашиваются у ЛЛМ. С pin в файл записываются хэши текущих
+// сигнатур найденных символов: устаревшие описания считаются проверенными.
+func (o *Orchestrator) ReviewOverrides(projectPath string, pin bool) (*ReviewResult, error) {
+	if err := o.loadOverrides(projectPath); err != nil {
+		return nil, err
+	}
+	result := &ReviewResult{Path: o.overridesPath(projectPath)}
+	if len(o.overrides.IDs()) == 0 {
+		return result, nil
+	}
+
+	files, _, err := o.Scan(projectPath)
+	if err != nil {
+		return nil, err
+	}
+	parser.ResetModuleCache()
+	hashes := make(map[string]string)
+	for _, file := range files {
+		currentParser, err := o.parserFactory.GetParserForFile(file.Path)
+		if err != nil {
+			continue
+		}
+		codeStructure, err := currentParser.Parse(file)
+		if err != nil {
+			logger.WithError(err).Warnf("Ошибка при парсинге файла %s", file.Path)
+			continue
+		}
+		for _, target := range collectTargets(codeStructure, true) {
+			hashes[target.info.ID] = curated.SignatureHash(target.signature)
+		}
+	}
+
+	pinned := make(map[string]string)
+	for _, id := range o.overrides.IDs() {
+		override, _ := o.overrides.Get(id)
+		entry := ReviewEntry{ID: id, Description: override.Description}
+		hash, found := hashes[id]
+		switch {
+		case !found:
+			entry.Status = ReviewMissing
+		case override.Stale(hash):
+			entry.Status = ReviewStale
+		case override.SignatureHash == "":
+			entry.Status = ReviewUnpinned
+		default:
+			entry.Status = ReviewOK
+		}
+		entry.SignatureHash = hash
+		if found && entry.Status != ReviewOK {
+			pinned[id] = hash
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	if pin && len(pinned) > 0 {
+		if err := curated.Pin(result.Path, pinned); err != nil {
+			return nil, logger.LogError(logger.OrchestratorError("не удалось обновить файл описаний", err))
+		}
+		result.Pinned = len(pinned)
+	}
+	return result, nil
+}
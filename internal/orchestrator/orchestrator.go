@@ -1,20 +1,31 @@
 package orchestrator
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"code-telescope/internal/cache"
 	"code-telescope/internal/config"
+	"code-telescope/internal/curated"
 	"code-telescope/internal/filesystem"
 	"code-telescope/internal/llm"
 	"code-telescope/internal/logger"
 	"code-telescope/internal/markdown"
 	"code-telescope/internal/parser"
+	"code-telescope/internal/quality"
+	"code-telescope/internal/redact"
+	"code-telescope/internal/usage"
 	"code-telescope/pkg/models"
+	"code-telescope/pkg/utils"
+
+	// Регистрация парсеров языков
+	_ "code-telescope/internal/parser/languages"
 )
 
 // Orchestrator координирует весь процесс генерации карты кода
@@ -26,6 +37,60 @@ type Orchestrator struct {
 	llmProvider   llm.LLMProvider
 	promptBuilder *llm.PromptBuilder
 	mdGenerator   *markdown.Generator
+
+	// Провайдеры ЛЛМ для моделей, переопределенных в поддиректориях
+	providers map[string]llm.LLMProvider
+
+	// Кассета записанных ответов ЛЛМ (nil, если llm.replay.mode не задан)
+	cassette *llm.Cassette
+
+	// Выключатели провайдеров цепочки, общие для всех моделей провайдера
+	breakers map[string]*llm.CircuitBreaker
+
+	// Кэш описаний символов проекта (nil, если кэш отключен)
+	cache *cache.DescriptionCache
+
+	// Скрытие секретов в коде перед отправкой в ЛЛМ и секреты, скрытые
+	// с момента последней записи журнала
+	redactor   *redact.Redactor
+	redactions []redact.AuditEntry
+
+	// Проверка качества описаний, полученных от ЛЛМ
+	checker *quality.Checker
+
+	// Описания символов из файла descriptions.overrides_file
+	overrides *curated.File
+
+	// Описания берутся только из кэша, запросы к ЛЛМ не выполняются
+	cachedOnly bool
+
+	// Запросы к ЛЛМ не выполняются, а только учитываются для оценки расхода
+	estimate bool
+
+	// Учет расхода токенов и бюджет запросов к ЛЛМ
+	usage *usage.Tracker
+
+	// Оценщики токенов моделей, уточняемые по usage ответов провайдеров
+	tokens map[usage.Source]*utils.TokenEstimator
+
+	// Предупреждение об исчерпании бюджета уже выведено
+	budgetWarned bool
+
+	// Директория состояния пакетных заданий; непустая, пока BuildModel
+	// собирает запросы для отправки одним пакетным заданием
+	batchStateDir string
+
+	// Запросы, ожидающие отправки пакетным заданием, и их оценка в токенах
+	pending       []describeRequest
+	pendingTokens int
+
+	// Путь сохраняемого документа: относительные ссылки на исходный код
+	// строятся от его директории (пусто — от корня проекта)
+	documentPath string
+
+	// Результаты последней генерации, используемые при обновлении именованных областей
+	fileStructures []models.FileStructure
+	projectName    string
 }
 
 // New создает новый экземпляр оркестратора
@@ -41,39 +106,218 @@ func New(cfg *config.Config, verbose bool) (*Orchestrator, error) {
 	scanner := filesystem.New(cfg)
 	parserFactory := parser.NewLanguageFactory(cfg)
 
-	// Создаем конфигурацию для LLM провайдера
-	llmConfig := map[string]interface{}{
-		"api_key":         cfg.LLM.APIKey,
-		"model":           cfg.LLM.Model,
-		"timeout_seconds": 60,
+	// Кассета открывается заранее, так как общая для всех моделей
+	var cassette *llm.Cassette
+	if cfg.LLM.Replay.Mode != "" {
+		var err error
+		cassette, err = llm.OpenCassette(cfg.LLM.Replay.Cassette)
+		if err != nil {
+			return nil, logger.LogError(logger.OrchestratorError("не удалось открыть кассету ответов ЛЛМ", err))
+		}
+		logger.Infof("Кассета ответов ЛЛМ %s (%s): %d записей", cfg.LLM.Replay.Cassette, cfg.LLM.Replay.Mode, cassette.Len())
 	}
 
-	// Инициализируем провайдера ЛЛМ
-	logger.Infof("Инициализация провайдера ЛЛМ: %s", cfg.LLM.Provider)
-	provider, err := llm.GetProvider(cfg.LLM.Provider, llmConfig)
+	// Создаем конструктор промптов
+	logger.Debug("Инициализация конструктора промптов")
+	templates, err := llm.LoadPromptTemplates(cfg.LLM.PromptTemplatesDir)
 	if err != nil {
-		err = logger.OrchestratorError("не удалось инициализировать провайдера ЛЛМ", err)
-		return nil, logger.LogError(err)
+		return nil, logger.LogError(logger.OrchestratorError("не удалось загрузить шаблоны промптов", err))
 	}
+	promptBuilder := llm.NewPromptBuilder(cfg.LLM.PromptTokensFor(cfg.LLM.Provider, cfg.LLM.Model)).
+		WithTemplates(templates).
+		WithInstructions(cfg.LLM.Instructions)
 
-	// Создаем конструктор промптов
-	logger.Debug("Инициализация конструктора промптов")
-	promptBuilder := llm.NewPromptBuilder(cfg.LLM.MaxTokens)
+	// Секреты в исходном коде скрываются перед отправкой в ЛЛМ
+	redactor, err := redact.New(cfg.Redaction)
+	if err != nil {
+		return nil, logger.LogError(logger.OrchestratorError("не удалось настроить скрытие секретов", err))
+	}
 
 	// Создаем генератор Markdown
 	logger.Debug("Инициализация генератора Markdown")
 	mdGenerator := markdown.New(cfg)
 
-	logger.Info("Оркестратор успешно инициализирован")
-	return &Orchestrator{
+	o := &Orchestrator{
 		config:        cfg,
 		verbose:       verbose,
 		scanner:       scanner,
 		parserFactory: parserFactory,
-		llmProvider:   provider,
 		promptBuilder: promptBuilder,
+		redactor:      redactor,
+		checker:       quality.NewChecker(cfg.LLM.Quality),
+		providers:     make(map[string]llm.LLMProvider),
+		cassette:      cassette,
+		breakers:      make(map[string]*llm.CircuitBreaker),
 		mdGenerator:   mdGenerator,
-	}, nil
+		usage:         newUsageTracker(cfg),
+		tokens:        make(map[usage.Source]*utils.TokenEstimator),
+	}
+
+	// Инициализируем провайдера ЛЛМ, если описания генерируются для всего проекта.
+	// Иначе провайдер создается при первом файле, для которого включены описания.
+	if cfg.LLM.Describe {
+		logger.Infof("Инициализация провайдера ЛЛМ: %s", cfg.LLM.Provider)
+		provider, err := o.newProvider(cfg.LLM.Model)
+		if err != nil {
+			err = logger.OrchestratorError("не удалось инициализировать провайдера ЛЛМ", err)
+			return nil, logger.LogError(err)
+		}
+		o.llmProvider = provider
+		o.providers[cfg.LLM.Model] = provider
+	}
+
+	logger.Info("Оркестратор успешно инициализирован")
+	return o, nil
+}
+
+// NewEstimator создает оркестратор, который не обращается к ЛЛМ, а оценивает
+// количество запросов, токены и стоимость генерации описаний. Провайдер ЛЛМ
+// не инициализируется, поэтому API ключ не требуется.
+func NewEstimator(cfg *config.Config, verbose bool) (*Orchestrator, error) {
+	describe := cfg.LLM.Describe
+	cfg.LLM.Describe = false
+	o, err := New(cfg, verbose)
+	cfg.LLM.Describe = describe
+	if err != nil {
+		return nil, err
+	}
+	o.estimate = true
+	return o, nil
+}
+
+// newUsageTracker создает учет расхода с бюджетом и ценами из конфигурации
+func newUsageTracker(cfg *config.Config) *usage.Tracker {
+	budget := usage.Budget{
+		MaxTokens: cfg.LLM.MaxBudgetTokens,
+		MaxUSD:    cfg.LLM.MaxBudgetUSD,
+	}
+	return usage.NewTracker(budget, func(source usage.Source) (usage.Price, bool) {
+		price, ok := cfg.LLM.PriceFor(source.Provider, source.Model)
+		if cfg.LLM.BatchAPI.Enabled && batchDiscountProviders[source.Provider] {
+			price.Input *= batchPriceFactor
+			price.Output *= batchPriceFactor
+		}
+		return usage.Price{Input: price.Input, Output: price.Output}, ok
+	})
+}
+
+// Пакетные задания OpenAI и Anthropic стоят вдвое дешевле обычных запросов
+const batchPriceFactor = 0.5
+
+// batchDiscountProviders содержит провайдеров со скидкой на пакетные задания
+var batchDiscountProviders = map[string]bool{
+	"openai":    true,
+	"anthropic": true,
+}
+
+// Usage возвращает расход токенов ЛЛМ по файлам и моделям с момента создания
+// оркестратора. В режиме оценки содержит ожидаемый расход.
+func (o *Orchestrator) Usage() usage.Report {
+	return o.usage.Report()
+}
+
+// newProvider создает провайдера ЛЛМ из конфигурации для указанной модели.
+// Если заданы резервные провайдеры, возвращается цепочка провайдеров.
+// Если задана кассета, провайдер оборачивается для записи или воспроизведения
+// ответов; при воспроизведении настоящий провайдер и API ключ не нужны.
+func (o *Orchestrator) newProvider(model string) (llm.LLMProvider, error) {
+	cfg := o.config
+	var provider llm.LLMProvider
+	if cfg.LLM.Replay.Mode != config.ReplayModeReplay {
+		var err error
+		provider, err = o.newChain(model)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if o.cassette == nil {
+		return provider, nil
+	}
+	return llm.NewReplayProvider(provider, o.cassette, cfg.LLM.Replay.Mode, model)
+}
+
+// newChain создает основного провайдера для модели, а при заданных
+// llm.fallbacks — цепочку из основного и резервных провайдеров
+func (o *Orchestrator) newChain(model string) (llm.LLMProvider, error) {
+	cfg := o.config
+	primary, err := llm.GetProvider(cfg.LLM.Provider, o.withBatchSettings(providerSettings(cfg.LLM.APIKey, model, cfg.LLM.BaseURL)))
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.LLM.Fallbacks) == 0 {
+		return primary, nil
+	}
+
+	links := []llm.ChainLink{{Provider: primary, Model: model, Breaker: o.breakerFor(cfg.LLM.Provider)}}
+	for _, fallback := range cfg.LLM.Fallbacks {
+		settings := o.withBatchSettings(providerSettings(fallback.ResolvedAPIKey(cfg.LLM), fallback.Model, fallback.BaseURL))
+		provider, err := llm.GetProvider(fallback.Provider, settings)
+		if err != nil {
+			return nil, fmt.Errorf("резервный провайдер %s/%s: %w", fallback.Provider, fallback.Model, err)
+		}
+		links = append(links, llm.ChainLink{Provider: provider, Model: fallback.Model, Breaker: o.breakerFor(fallback.Provider)})
+	}
+	return llm.NewChainProvider(links)
+}
+
+// providerSettings возвращает настройки для фабрики провайдера ЛЛМ
+func providerSettings(apiKey, model, baseURL string) map[string]interface{} {
+	settings := map[string]interface{}{
+		"api_key":         apiKey,
+		"model":           model,
+		"timeout_seconds": 60,
+	}
+	if baseURL != "" {
+		settings["base_url"] = baseURL
+	}
+	return settings
+}
+
+// withBatchSettings добавляет к настройкам провайдера параметры пакетных
+// заданий, если текущая генерация отправляет запросы пакетным заданием
+func (o *Orchestrator) withBatchSettings(settings map[string]interface{}) map[string]interface{} {
+	if o.batchStateDir == "" {
+		return settings
+	}
+	batch := o.config.LLM.BatchAPI
+	settings["batch_api"] = true
+	settings["batch_state_dir"] = o.batchStateDir
+	settings["batch_poll_interval"] = time.Duration(batch.PollIntervalSeconds) * time.Second
+	settings["batch_max_poll_interval"] = time.Duration(batch.MaxPollIntervalSeconds) * time.Second
+	return settings
+}
+
+// breakerFor возвращает выключатель провайдера, создавая его при первом обращении
+func (o *Orchestrator) breakerFor(provider string) *llm.CircuitBreaker {
+	breaker, ok := o.breakers[provider]
+	if !ok {
+		breaker = llm.NewCircuitBreaker(o.config.LLM.CircuitBreaker.FailureThreshold,
+			time.Duration(o.config.LLM.CircuitBreaker.CooldownSeconds)*time.Second)
+		o.breakers[provider] = breaker
+	}
+	return breaker
+}
+
+// providerFor возвращает провайдера ЛЛМ для модели, создавая его при первом обращении
+func (o *Orchestrator) providerFor(model string) (llm.LLMProvider, error) {
+	if provider, ok := o.providers[model]; ok {
+		return provider, nil
+	}
+
+	logger.WithField("model", model).Info("Инициализация провайдера ЛЛМ для модели поддиректории")
+	provider, err := o.newProvider(model)
+	if err != nil {
+		return nil, err
+	}
+	o.providers[model] = provider
+	return provider, nil
+}
+
+// UseCachedDescriptions включает режим, в котором описания символов берутся
+// только из кэша описаний независимо от llm.describe, а ЛЛМ не вызывается
+func (o *Orchestrator) UseCachedDescriptions() {
+	o.cachedOnly = true
 }
 
 // GenerateCodeMap генерирует карту кода для указанного проекта
@@ -83,161 +327,1110 @@ func (o *Orchestrator) GenerateCodeMap(projectPath string) (string, error) {
 		"project_path": projectPath,
 	}).Info("Запуск генерации карты кода")
 
-	// Шаг 1: Сканирование файловой системы
+	codeMap, err := o.BuildModel(projectPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Шаг 3: Генерация документа в выбранном формате
+	o.SetLinkRoot(projectPath)
+	codeMapContent, err := o.Render(codeMap)
+	if err != nil {
+		return "", err
+	}
+
+	// Расчет времени выполнения
+	elapsedTime := time.Since(startTime)
+	logger.WithFields(logger.Fields{
+		"elapsed_time": elapsedTime.String(),
+		"files_count":  len(codeMap.Files),
+	}).Info("Генерация карты кода завершена успешно")
+
+	return codeMapContent, nil
+}
+
+// Scan сканирует проект и возвращает файлы для анализа и пропущенные пути с причинами
+func (o *Orchestrator) Scan(projectPath string) ([]*models.FileMetadata, []filesystem.SkippedPath, error) {
 	logger.Infof("Сканирование файловой системы в директории: %s", projectPath)
-	files, err := o.scanner.ScanProject(projectPath)
+	scanned, err := o.scanner.ScanProject(projectPath)
 	if err != nil {
 		err = logger.OrchestratorError("ошибка при сканировании проекта", err)
-		return "", logger.LogError(err)
+		return nil, nil, logger.LogError(err)
+	}
+
+	files, skipped := o.filterParsable(scanned)
+	return files, append(o.scanner.Skipped(), skipped...), nil
+}
+
+// filterParsable отделяет файлы, для которых нет подходящего парсера
+func (o *Orchestrator) filterParsable(scanned []*models.FileMetadata) ([]*models.FileMetadata, []filesystem.SkippedPath) {
+	var skipped []filesystem.SkippedPath
+	files := make([]*models.FileMetadata, 0, len(scanned))
+	for _, file := range scanned {
+		if _, err := o.parserFactory.GetParserForFile(file.Path); err != nil {
+			skipped = append(skipped, filesystem.SkippedPath{Path: file.Path, Reason: "нет подходящего парсера"})
+			continue
+		}
+		files = append(files, file)
+	}
+	return files, skipped
+}
+
+// BuildModel сканирует и парсит проект, генерирует описания символов
+// и возвращает модель карты кода, из которой строится итоговый документ
+func (o *Orchestrator) BuildModel(projectPath string) (*models.CodeMap, error) {
+	// Шаг 1: Сканирование файловой системы
+	files, _, err := o.Scan(projectPath)
+	if err != nil {
+		return nil, err
 	}
 
 	logger.Infof("Найдено %d файлов для анализа", len(files))
+	for _, dir := range o.scanner.DirectoryConfigs().Dirs() {
+		logger.WithField("dir", dir).Info("Применена конфигурация поддиректории")
+	}
 
 	// Шаг 2: Парсинг кода и генерация описаний
 	ctx := context.Background()
+	parser.ResetModuleCache()
+	o.openCache(projectPath)
+	if err := o.loadOverrides(projectPath); err != nil {
+		return nil, err
+	}
+
+	// В пакетном режиме запросы к ЛЛМ собираются по всем файлам и
+	// отправляются одним заданием после парсинга
+	if o.config.LLM.BatchAPI.Enabled && !o.estimate && !o.cachedOnly {
+		o.batchStateDir = o.config.LLM.BatchAPI.StateDir
+		if !filepath.IsAbs(o.batchStateDir) {
+			o.batchStateDir = filepath.Join(projectPath, o.batchStateDir)
+		}
+		defer func() { o.batchStateDir = "" }()
+	}
 
 	// Подготовка коллекции файловых структур для генератора Markdown
 	fileStructures := make([]models.FileStructure, 0, len(files))
+	codeStructures := make([]*models.CodeStructure, 0, len(files))
 
 	logger.Info("Парсинг файлов и генерация описаний")
 	for _, file := range files {
-		logger.WithField("file", file.Path).Debug("Обработка файла")
+		codeStructure, fileStructure, err := o.processFile(ctx, file, nil)
+		if err != nil {
+			continue
+		}
+
+		// Добавляем структуру файла в коллекцию
+		fileStructures = append(fileStructures, fileStructure)
+		codeStructures = append(codeStructures, codeStructure)
+	}
+
+	// Описания из пакетного задания получены после преобразования структур
+	if o.describePending(ctx) {
+		for i, codeStructure := range codeStructures {
+			fileStructures[i] = o.convertFile(codeStructure)
+		}
+	}
+
+	o.saveCache()
+	if err := o.checkSecrets(projectPath); err != nil {
+		return nil, err
+	}
+
+	return models.NewCodeMap(filepath.Base(projectPath), fileStructures), nil
+}
+
+// processFile парсит файл, генерирует описания его символов и преобразует
+// структуру кода для генераторов вывода. known содержит уже известные описания
+// символов по ключу symbolKey; для них ЛЛМ не вызывается.
+func (o *Orchestrator) processFile(ctx context.Context, file *models.FileMetadata, known map[string]string) (*models.CodeStructure, models.FileStructure, error) {
+	logger.WithField("file", file.Path).Debug("Обработка файла")
+
+	// Получаем парсер для текущего файла
+	currentParser, err := o.parserFactory.GetParserForFile(file.Path)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"file":  file.Path,
+			"error": err.Error(),
+		}).Warn("Пропуск файла (нет подходящего парсера)")
+		return nil, models.FileStructure{}, err
+	}
+
+	// Парсим файл
+	logger.WithField("file", file.Path).Debug("Парсинг файла")
+	codeStructure, err := currentParser.Parse(file)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"file":  file.Path,
+			"error": err.Error(),
+		}).Warn("Ошибка при парсинге файла")
+		return nil, models.FileStructure{}, err
+	}
+
+	// Настройки с учетом вложенных файлов .code-telescope.yaml
+	fileConfig := o.scanner.ConfigFor(file.Path)
+
+	// Описания, написанные вручную, заменяют описания от ЛЛМ
+	o.applyOverrides(codeStructure)
+
+	// Генерируем описания функций и методов через ЛЛМ
+	if fileConfig.LLM.Describe || o.cachedOnly {
+		o.describeSymbols(ctx, codeStructure, fileConfig, known)
+	}
+
+	// Преобразуем CodeStructure в FileStructure
+	logger.WithField("file", file.Path).Debug("Преобразование CodeStructure в FileStructure")
+	return codeStructure, o.convertFile(codeStructure), nil
+}
+
+// convertFile преобразует структуру кода для генераторов вывода с учетом
+// конфигурации директории файла
+func (o *Orchestrator) convertFile(codeStructure *models.CodeStructure) models.FileStructure {
+	fileConfig := o.scanner.ConfigFor(codeStructure.Metadata.Path)
+	return models.ConvertToFileStructureWithOptions(codeStructure, models.ConvertOptions{
+		IncludePrivate: fileConfig.Parser.ParsePrivateMethods,
+	})
+}
+
+// ParseFile парсит один файл проекта с учетом конфигурации его директории
+func (o *Orchestrator) ParseFile(projectPath, filePath string) (*models.CodeStructure, *config.Config, error) {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, nil, logger.LogError(logger.FileSystemError("ошибка получения абсолютного пути", err))
+	}
+
+	metadata, err := models.NewFileMetadata(filePath, absProject)
+	if err != nil {
+		return nil, nil, logger.LogError(logger.FileSystemError("ошибка чтения файла", err))
+	}
+	if strings.HasPrefix(filepath.ToSlash(metadata.Path), "../") {
+		err = logger.OrchestratorError(fmt.Sprintf("файл %s находится вне проекта %s", filePath, projectPath), nil)
+		return nil, nil, logger.LogError(err)
+	}
+
+	fileConfig, err := o.scanner.LoadConfigFor(absProject, metadata.Path)
+	if err != nil {
+		return nil, nil, logger.LogError(logger.OrchestratorError("ошибка загрузки конфигурации поддиректории", err))
+	}
+
+	currentParser, err := o.parserFactory.GetParserForFile(metadata.Path)
+	if err != nil {
+		return nil, nil, logger.LogError(logger.OrchestratorError("нет подходящего парсера", err))
+	}
+
+	parser.ResetModuleCache()
+	codeStructure, err := currentParser.Parse(metadata)
+	if err != nil {
+		return nil, nil, logger.LogError(logger.OrchestratorError("ошибка при парсинге файла", err))
+	}
+
+	return codeStructure, fileConfig, nil
+}
+
+// DescribeSymbol запрашивает у ЛЛМ описание одного символа файла.
+// Символ задается именем функции или метода, в виде Тип.метод или
+// идентификатором символа.
+// Кэш описаний не используется: описание всегда запрашивается заново.
+func (o *Orchestrator) DescribeSymbol(ctx context.Context, projectPath, filePath, symbol string) (string, error) {
+	codeStructure, fileConfig, err := o.ParseFile(projectPath, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	var target *describeTarget
+	for _, candidate := range collectTargets(codeStructure, true) {
+		if candidate.info.Name == symbol || symbolName(candidate) == symbol || candidate.info.ID == symbol {
+			target = &candidate
+			break
+		}
+	}
+	if target == nil {
+		err = logger.OrchestratorError(fmt.Sprintf("символ %s не найден в файле %s", symbol, filePath), nil)
+		return "", logger.LogError(err)
+	}
+
+	// Описание, написанное вручную, не запрашивается у ЛЛМ
+	if err := o.loadOverrides(projectPath); err != nil {
+		return "", err
+	}
+	o.applyOverrides(codeStructure)
+	if *target.override != nil {
+		return *target.description, nil
+	}
+
+	provider, err := o.providerFor(fileConfig.LLM.Model)
+	if err != nil {
+		return "", logger.LogError(logger.OrchestratorError("не удалось инициализировать провайдера ЛЛМ", err))
+	}
+
+	content, _ := os.ReadFile(codeStructure.Metadata.AbsolutePath)
+	promptBuilder := o.promptBuilderFor(fileConfig)
+	targets := withSource([]describeTarget{*target}, content)
+	targets, fileContext, secrets := o.redactSecrets(codeStructure.Metadata.Path, content, targets,
+		buildFileContext(promptBuilder, codeStructure, content, targets))
+	if err := o.checkSecrets(projectPath); err != nil {
+		return "", err
+	}
+	if secrets {
+		logger.Warnf("Секреты в исходном коде символа %s скрыты перед отправкой в ЛЛМ", symbol)
+	}
+	prompt := promptBuilder.BuildMethodDescriptionPrompt(targets[0].info, fileContext)
+	response, err := provider.GenerateText(ctx, llm.LLMRequest{
+		Prompt:      prompt,
+		MaxTokens:   o.config.LLM.MaxTokens,
+		Temperature: o.config.LLM.Temperature,
+	})
+	if err != nil {
+		return "", logger.LogError(logger.OrchestratorError("ошибка при получении описания от ЛЛМ", err))
+	}
+	source := usage.Source{Provider: o.config.LLM.Provider, Model: fileConfig.LLM.Model}
+	if response.Provider != "" {
+		source = usage.Source{Provider: response.Provider, Model: response.Model}
+	}
+	o.observeTokens(source, prompt, response)
+
+	description := strings.TrimSpace(response.Text)
+	if o.config.LLM.Quality.Enabled {
+		request := describeRequest{
+			filePath:      codeStructure.Metadata.Path,
+			model:         fileConfig.LLM.Model,
+			promptBuilder: promptBuilder,
+			fileContext:   fileContext,
+		}
+		description, _, _ = o.checkDescription(ctx, provider, request, targets[0], description, response.Truncated, usage.Source{})
+	}
+	return description, nil
+}
+
+// openCache открывает кэш описаний проекта, если он включен в конфигурации.
+// Ошибка открытия кэша не прерывает генерацию: описания запрашиваются заново.
+func (o *Orchestrator) openCache(projectPath string) {
+	o.cache = nil
+	if !o.config.Cache.Enabled {
+		return
+	}
+
+	dir := o.config.Cache.Dir
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(projectPath, dir)
+	}
+
+	descriptionCache, err := cache.Open(dir)
+	if err != nil {
+		logger.WithError(err).Warn("Не удалось открыть кэш описаний, описания будут запрошены заново")
+		return
+	}
+	o.cache = descriptionCache
+}
+
+// saveCache сохраняет кэш описаний и выводит статистику попаданий
+func (o *Orchestrator) saveCache() {
+	if o.cache == nil {
+		return
+	}
+
+	stats := o.cache.Stats()
+	logger.WithFields(logger.Fields{
+		"hits":   stats.Hits,
+		"misses": stats.Misses,
+	}).Info("Статистика кэша описаний")
+
+	if err := o.cache.Save(); err != nil {
+		logger.WithError(err).Warn("Не удалось сохранить кэш описаний")
+	}
+}
+
+// Ожидаемое количество токенов описания одного символа при оценке расхода
+const estimatedOutputTokensPerSymbol = 60
+
+// describeTarget описывает символ, для которого запрашивается описание у ЛЛМ
+type describeTarget struct {
+	info        models.MethodInfo
+	owner       string
+	parameters  []string
+	signature   string
+	position    models.Position
+	description *string
+	quality     **models.DescriptionQuality
+	override    **models.DescriptionOverride
+	cacheKey    string
+}
+
+// describeRequest описывает запрос к ЛЛМ за описаниями пакета символов файла
+type describeRequest struct {
+	filePath      string
+	model         string
+	promptBuilder *llm.PromptBuilder
+	fileContext   string
+	targets       []describeTarget
+	methods       []models.MethodInfo
+	request       llm.LLMRequest
+	promptTokens  int
+}
+
+// collectTargets собирает функции и методы файла, для которых нужны описания
+func collectTargets(codeStructure *models.CodeStructure, includePrivate bool) []describeTarget {
+	var targets []describeTarget
+	for _, fn := range codeStructure.Functions {
+		if fn.IsPublic || includePrivate {
+			targets = append(targets, describeTarget{
+				info:        newPromptMethodInfo(fn.ID, fn.Name, fn.Parameters, fn.ReturnType, fn.Doc),
+				parameters:  parameterNames(fn.Parameters),
+				signature:   fullSignature(fn.Name, fn.Parameters, fn.ReturnType),
+				position:    fn.Position,
+				description: &fn.Description,
+				quality:     &fn.Quality,
+				override:    &fn.Override,
+			})
+		}
+	}
+	for _, method := range codeStructure.Methods {
+		if method.IsPublic || includePrivate {
+			targets = append(targets, describeTarget{
+				info:        newPromptMethodInfo(method.ID, method.Name, method.Parameters, method.ReturnType, method.Doc),
+				owner:       method.BelongsTo,
+				parameters:  parameterNames(method.Parameters),
+				signature:   fullSignature(method.Name, method.Parameters, method.ReturnType),
+				position:    method.Position,
+				description: &method.Description,
+				quality:     &method.Quality,
+				override:    &method.Override,
+			})
+		}
+	}
+	for _, typ := range codeStructure.Types {
+		for _, method := range typ.Methods {
+			if method.IsPublic || includePrivate {
+				targets = append(targets, describeTarget{
+					info:        newPromptMethodInfo(method.ID, method.Name, method.Parameters, method.ReturnType, method.Doc),
+					owner:       typ.Name,
+					parameters:  parameterNames(method.Parameters),
+					signature:   fullSignature(method.Name, method.Parameters, method.ReturnType),
+					position:    method.Position,
+					description: &method.Description,
+					quality:     &method.Quality,
+					override:    &method.Override,
+				})
+			}
+		}
+	}
+	return targets
+}
+
+// buildFileContext формирует контекст файла для промптов: путь, язык и
+// определения типов, к которым относятся описываемые символы или которые
+// упоминаются в их сигнатурах
+func buildFileContext(promptBuilder *llm.PromptBuilder, codeStructure *models.CodeStructure, content []byte, targets []describeTarget) string {
+	var definitions []string
+	for _, typ := range relevantTypes(codeStructure, targets) {
+		if definition := typeDefinition(content, typ); definition != "" {
+			definitions = append(definitions, definition)
+		}
+	}
+	return promptBuilder.BuildFileContext(codeStructure.Metadata.Path, codeStructure.Metadata.LanguageName(), definitions)
+}
+
+// redactSecrets скрывает секреты в документации, коде и сигнатурах символов
+// и в контексте файла и добавляет их в журнал. Строка секрета в журнале
+// определяется по его первому вхождению в файл. Возвращает true, если
+// секреты найдены. С redaction.fail_on_secret секреты ищутся, даже если
+// скрытие отключено.
+func (o *Orchestrator) redactSecrets(filePath string, content []byte, targets []describeTarget, fileContext string) ([]describeTarget, string, bool) {
+	if !o.config.Redaction.Enabled && !o.config.Redaction.FailOnSecret {
+		return targets, fileContext, false
+	}
+
+	found := false
+	record := func(symbol string, line int, findings []redact.Finding) {
+		for _, finding := range findings {
+			entry := redact.AuditEntry{
+				Time:        time.Now(),
+				File:        filePath,
+				Symbol:      symbol,
+				Line:        line,
+				Rule:        finding.Rule,
+				Fingerprint: finding.Fingerprint(),
+			}
+			if i := bytes.Index(content, []byte(finding.Secret)); i >= 0 {
+				entry.Line = 1 + bytes.Count(content[:i], []byte("\n"))
+			}
+			o.redactions = append(o.redactions, entry)
+			logger.Warnf("Скрыт секрет %s в %s:%d", entry.Rule, filePath, entry.Line)
+			found = true
+		}
+	}
+
+	redacted := make([]describeTarget, len(targets))
+	for i, target := range targets {
+		var body, doc, signature []redact.Finding
+		target.info.Body, body = o.redactor.Redact(target.info.Body)
+		target.info.Doc, doc = o.redactor.Redact(target.info.Doc)
+		target.info.Signature, signature = o.redactor.Redact(target.info.Signature)
+
+		symbol := target.info.Name
+		if target.owner != "" {
+			symbol = target.owner + "." + symbol
+		}
+		record(symbol, target.position.StartLine, append(append(body, doc...), signature...))
+		redacted[i] = target
+	}
+
+	fileContext, findings := o.redactor.Redact(fileContext)
+	record("", 0, findings)
+	return redacted, fileContext, found
+}
+
+// checkSecrets дописывает скрытые секреты в журнал redaction.audit_log.
+// С redaction.fail_on_secret возвращает ошибку, если секреты найдены.
+func (o *Orchestrator) checkSecrets(projectPath string) error {
+	secrets, path := o.writeRedactionAudit(projectPath)
+	if secrets == 0 || !o.config.Redaction.FailOnSecret {
+		return nil
+	}
+	err := logger.OrchestratorError(fmt.Sprintf("в исходном коде найдены секреты (%d), файлы с ними не отправлены в ЛЛМ; подробности в журнале %s", secrets, path), nil)
+	return logger.LogError(err)
+}
+
+// writeRedactionAudit дописывает скрытые секреты в журнал и возвращает
+// их количество и путь к журналу
+func (o *Orchestrator) writeRedactionAudit(projectPath string) (int, string) {
+	entries := o.redactions
+	o.redactions = nil
+
+	path := o.config.Redaction.AuditLog
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(projectPath, path)
+	}
+	if len(entries) == 0 {
+		return 0, path
+	}
+
+	if err := redact.AppendAudit(path, entries); err != nil {
+		logger.WithError(err).Warn("Не удалось записать журнал скрытых секретов")
+	} else {
+		logger.Infof("Скрыто секретов: %d, журнал: %s", len(entries), path)
+	}
+	return len(entries), path
+}
+
+// relevantTypes возвращает типы файла, которым принадлежат символы или
+// имена которых встречаются в сигнатурах символов, в порядке объявления
+func relevantTypes(codeStructure *models.CodeStructure, targets []describeTarget) []*models.Type {
+	var types []*models.Type
+	for _, typ := range codeStructure.Types {
+		for _, target := range targets {
+			if target.owner == typ.Name || containsIdentifier(target.info.Signature, typ.Name) {
+				types = append(types, typ)
+				break
+			}
+		}
+	}
+	return types
+}
+
+// containsIdentifier проверяет, что имя входит в текст отдельным идентификатором
+func containsIdentifier(text, name string) bool {
+	for offset := 0; name != ""; {
+		i := strings.Index(text[offset:], name)
+		if i < 0 {
+			return false
+		}
+		start, end := offset+i, offset+i+len(name)
+		if (start == 0 || !isIdentifierByte(text[start-1])) && (end == len(text) || !isIdentifierByte(text[end])) {
+			return true
+		}
+		offset = end
+	}
+	return false
+}
+
+// isIdentifierByte проверяет, может ли байт входить в идентификатор
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= 0x80
+}
+
+// typeDefinition возвращает исходный код определения типа вместе с
+// документирующим комментарием. У классов, методы которых объявлены
+// внутри определения, берется только часть до первого метода: методы
+// передаются в промпт отдельно.
+func typeDefinition(content []byte, typ *models.Type) string {
+	position := typ.Position
+	for _, method := range typ.Methods {
+		if method.Position.StartLine > position.StartLine && method.Position.StartLine <= position.EndLine {
+			position.EndLine = method.Position.StartLine - 1
+			position.EndByte = 0
+		}
+	}
+
+	// Документирующий комментарий первого метода к типу не относится
+	lines := strings.Split(strings.TrimRight(dedent(sourceSpan(content, position)), " \t\n"), "\n")
+	for len(lines) > 1 && isCommentLine(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	definition := strings.TrimRight(strings.Join(lines, "\n"), " \t\n")
+	if definition == "" {
+		return ""
+	}
+	if firstLine, _, _ := strings.Cut(typ.Doc, "\n"); typ.Doc != "" && !strings.Contains(definition, firstLine) {
+		definition = commentLines(typ.Doc) + "\n" + definition
+	}
+	return definition
+}
+
+// isCommentLine проверяет, что строка кода является строкой комментария
+func isCommentLine(line string) bool {
+	line = strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "/*", "*", "#"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// commentLines оформляет документацию как строки комментария
+func commentLines(doc string) string {
+	lines := strings.Split(doc, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace("// " + line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// withSource заполняет исходный код символов по их позициям в файле
+func withSource(targets []describeTarget, content []byte) []describeTarget {
+	for i := range targets {
+		targets[i].info.Body = symbolBody(content, targets[i].position)
+	}
+	return targets
+}
+
+// symbolBody возвращает исходный код символа без общего отступа строк.
+// Отступ первой строки включается в код, чтобы его можно было убрать
+// у всех строк метода класса.
+func symbolBody(content []byte, pos models.Position) string {
+	if pos.EndByte > pos.StartByte && pos.EndByte <= len(content) {
+		for pos.StartByte > 0 && (content[pos.StartByte-1] == ' ' || content[pos.StartByte-1] == '\t') {
+			pos.StartByte--
+		}
+	}
+	return dedent(sourceSpan(content, pos))
+}
+
+// dedent убирает общий для всех непустых строк отступ
+func dedent(text string) string {
+	lines := strings.Split(text, "\n")
+	indent, found := "", false
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lineIndent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		if !found {
+			indent, found = lineIndent, true
+			continue
+		}
+		for !strings.HasPrefix(lineIndent, indent) {
+			indent = indent[:len(indent)-1]
+		}
+	}
+	if indent == "" {
+		return text
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimPrefix(line, indent)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sourceSpan возвращает исходный код по смещениям в байтах, а если парсер
+// их не заполнил — по номерам строк. Для неизвестной позиции возвращается
+// пустая строка.
+func sourceSpan(content []byte, pos models.Position) string {
+	if pos.EndByte > pos.StartByte && pos.EndByte <= len(content) {
+		return string(content[pos.StartByte:pos.EndByte])
+	}
+	lines := strings.Split(string(content), "\n")
+	if pos.StartLine < 1 || pos.EndLine < pos.StartLine || pos.EndLine > len(lines) {
+		return ""
+	}
+	return strings.Join(lines[pos.StartLine-1:pos.EndLine], "\n")
+}
+
+// promptBuilderFor возвращает конструктор промптов с языком файла и бюджетом
+// промпта его модели. Конструктор считает токены оценкой EstimateTokens,
+// поэтому бюджет переводится в ее единицы поправкой оценщика модели.
+func (o *Orchestrator) promptBuilderFor(fileConfig *config.Config) *llm.PromptBuilder {
+	estimator := o.tokenEstimator(usage.Source{Provider: o.config.LLM.Provider, Model: fileConfig.LLM.Model})
+	return o.promptBuilder.
+		WithLanguage(fileConfig.LLM.OutputLanguage).
+		WithPromptTokens(estimator.Budget(fileConfig.LLM.PromptTokensFor(o.config.LLM.Provider, fileConfig.LLM.Model)))
+}
 
-		// Получаем парсер для текущего файла
-		currentParser, err := o.parserFactory.GetParserForFile(file.Path)
+// tokenEstimator возвращает оценщик токенов модели
+func (o *Orchestrator) tokenEstimator(source usage.Source) *utils.TokenEstimator {
+	estimator, ok := o.tokens[source]
+	if !ok {
+		estimator = utils.NewTokenEstimator()
+		o.tokens[source] = estimator
+	}
+	return estimator
+}
+
+// observeTokens уточняет оценщик токенов модели source по числу входных
+// токенов, которое провайдер вернул для промпта. Токены кэша промптов
+// провайдеры не включают во входные, но токенизируются они так же.
+func (o *Orchestrator) observeTokens(source usage.Source, prompt string, response llm.LLMResponse) {
+	if response.InputTokens <= 0 {
+		return
+	}
+	o.tokenEstimator(source).Observe(prompt, response.InputTokens+response.CacheReadTokens+response.CacheWriteTokens)
+}
+
+// describeSymbols запрашивает у ЛЛМ описания функций и методов файла
+// и записывает их в структуру кода. Непубличные символы описываются,
+// только если для файла включен разбор приватных методов. Символы,
+// описания которых есть в known, повторно не описываются.
+func (o *Orchestrator) describeSymbols(ctx context.Context, codeStructure *models.CodeStructure, fileConfig *config.Config, known map[string]string) {
+	filePath := codeStructure.Metadata.Path
+	includePrivate := fileConfig.Parser.ParsePrivateMethods
+
+	targets := withoutOverrides(collectTargets(codeStructure, includePrivate))
+	if len(targets) == 0 {
+		return
+	}
+	logger.Debugf("Найдено %d функций и методов в файле %s", len(targets), filePath)
+
+	// Описания из кэша и предыдущей генерации тоже получают оценку
+	defer o.scoreDescriptions(targets, fileConfig.LLM.OutputLanguage)
+
+	// Описания символов с неизменной сигнатурой берутся из предыдущей генерации
+	targets = applyKnown(targets, known)
+
+	// Исходный код передается в промпт и входит в ключ кэша
+	content, err := os.ReadFile(codeStructure.Metadata.AbsolutePath)
+	if err != nil {
+		logger.WithError(err).Warnf("Не удалось прочитать файл %s, исходный код не попадет в промпт", filePath)
+	}
+
+	// Описания неизмененных символов берутся из кэша
+	targets = o.applyCached(codeStructure, fileConfig, targets, content)
+	if len(targets) == 0 {
+		logger.Debugf("Все описания файла %s взяты из кэша", filePath)
+		return
+	}
+	if o.cachedOnly {
+		return
+	}
+
+	model := fileConfig.LLM.Model
+	var provider llm.LLMProvider
+	if !o.estimate && o.batchStateDir == "" {
+		var err error
+		provider, err = o.providerFor(model)
 		if err != nil {
-			logger.WithFields(logger.Fields{
-				"file":  file.Path,
-				"error": err.Error(),
-			}).Warn("Пропуск файла (нет подходящего парсера)")
+			logger.WithError(err).Warn("Не удалось инициализировать провайдера ЛЛМ, описания пропущены")
+			return
+		}
+	}
+	promptBuilder := o.promptBuilderFor(fileConfig)
+
+	// Если методов много, обрабатываем их пакетами
+	batchSize := o.config.LLM.BatchSize
+	if batchSize <= 0 {
+		batchSize = 5 // Значение по умолчанию
+	}
+
+	// Формируем контекст файла
+	targets = withSource(targets, content)
+	fileContext := buildFileContext(promptBuilder, codeStructure, content, targets)
+
+	// Секреты скрываются до построения промптов
+	var secrets bool
+	targets, fileContext, secrets = o.redactSecrets(filePath, content, targets, fileContext)
+	if secrets && o.config.Redaction.FailOnSecret {
+		logger.Warnf("Файл %s содержит секреты и не отправлен в ЛЛМ", filePath)
+		return
+	}
+
+	// Источник описаний по конфигурации
+	primary := usage.Source{Provider: o.config.LLM.Provider, Model: model}
+
+	logger.Debugf("Обработка методов пакетами по %d", batchSize)
+	for i := 0; i < len(targets); i += batchSize {
+		end := i + batchSize
+		if end > len(targets) {
+			end = len(targets)
+		}
+		batch := targets[i:end]
+
+		// Формируем пакет методов для ЛЛМ
+		batchMethods := make([]models.MethodInfo, 0, len(batch))
+		for _, target := range batch {
+			batchMethods = append(batchMethods, target.info)
+		}
+
+		// Инструкции и контекст файла общие для всех пакетов файла
+		llmRequest := promptBuilder.BuildBatchMethodRequest(batchMethods, fileContext)
+		request := describeRequest{
+			filePath:      filePath,
+			model:         model,
+			promptBuilder: promptBuilder,
+			fileContext:   fileContext,
+			targets:       batch,
+			methods:       batchMethods,
+			request:       llmRequest,
+			promptTokens:  o.tokenEstimator(primary).Estimate(llmRequest.FullPrompt()),
+		}
+
+		// После исчерпания бюджета оставшиеся символы остаются без описаний.
+		// Запросы, ожидающие пакетного задания, учитываются в бюджете заранее.
+		if !o.usage.Allow(primary, o.pendingTokens+request.promptTokens) {
+			if !o.budgetWarned {
+				logger.Warn("Бюджет запросов к ЛЛМ исчерпан, оставшиеся символы остаются без описаний")
+				o.budgetWarned = true
+			}
+			o.usage.Skip(filePath, primary, len(targets)-i)
+			return
+		}
+
+		if o.estimate {
+			outputTokens := min(len(batch)*estimatedOutputTokensPerSymbol, o.config.LLM.MaxTokens)
+			o.usage.Record(filePath, primary, request.promptTokens, outputTokens)
+			continue
+		}
+
+		if o.batchStateDir != "" {
+			o.pending = append(o.pending, request)
+			o.pendingTokens += request.promptTokens
 			continue
 		}
 
-		// Парсим файл
-		logger.WithField("file", file.Path).Debug("Парсинг файла")
-		codeStructure, err := currentParser.Parse(file)
+		logger.Debugf("Отправка запроса к ЛЛМ для пакета из %d методов", len(batchMethods))
+		response, err := provider.GenerateText(ctx, o.llmRequest(request))
 		if err != nil {
-			logger.WithFields(logger.Fields{
-				"file":  file.Path,
-				"error": err.Error(),
-			}).Warn("Ошибка при парсинге файла")
+			logger.WithError(err).Warn("Ошибка при получении описаний методов от ЛЛМ")
 			continue
 		}
+		o.applyResponse(ctx, provider, request, response)
+	}
+}
 
-		// Получаем публичные методы для обработки через ЛЛМ
-		logger.WithField("file", file.Path).Debug("Извлечение публичных методов")
-		publicMethods := codeStructure.GetPublicMethods()
-		if len(publicMethods) > 0 {
-			logger.Debugf("Найдено %d публичных методов в файле %s", len(publicMethods), file.Path)
+// llmRequest дополняет запрос параметрами генерации из конфигурации
+func (o *Orchestrator) llmRequest(request describeRequest) llm.LLMRequest {
+	llmRequest := request.request
+	llmRequest.MaxTokens = o.config.LLM.MaxTokens
+	llmRequest.Temperature = o.config.LLM.Temperature
+	return llmRequest
+}
 
-			// Если методов много, обрабатываем их пакетами
-			batchSize := o.config.LLM.BatchSize
-			if batchSize <= 0 {
-				batchSize = 5 // Значение по умолчанию
+// applyResponse учитывает расход запроса и записывает полученные описания
+// в символы пакета и в кэш. Если включена проверка описаний, непрошедшие
+// проверку описания запрашиваются у провайдера повторно.
+func (o *Orchestrator) applyResponse(ctx context.Context, provider llm.LLMProvider, request describeRequest, response llm.LLMResponse) {
+	// Цепочка провайдеров может ответить от имени резервного провайдера
+	primary := usage.Source{Provider: o.config.LLM.Provider, Model: request.model}
+	source := primary
+	if response.Provider != "" {
+		source = usage.Source{Provider: response.Provider, Model: response.Model}
+	}
+	o.observeTokens(source, request.request.FullPrompt(), response)
+	inputTokens, outputTokens := responseTokens(response, request.promptTokens, o.tokenEstimator(source))
+	o.usage.Record(request.filePath, source, inputTokens, outputTokens)
+	if response.CacheReadTokens > 0 || response.CacheWriteTokens > 0 {
+		o.usage.RecordCache(request.filePath, source, response.CacheReadTokens, response.CacheWriteTokens)
+	}
+
+	logger.Debug("Парсинг ответа от ЛЛМ")
+	methodDescriptions := request.promptBuilder.ParseBatchResponse(response.Text, request.methods)
+	truncated := ""
+	if response.Truncated {
+		truncated = lastDescribed(request.targets, methodDescriptions)
+	}
+
+	// Добавляем описания к методам
+	for _, target := range request.targets {
+		description, ok := methodDescriptions[target.info.Key()]
+		describedBy := source
+		if o.config.LLM.Quality.Enabled {
+			var result quality.Result
+			description, result, describedBy = o.checkDescription(ctx, provider, request, target, description, target.info.Key() == truncated, source)
+			ok = description != ""
+			if ok {
+				*target.quality = descriptionQuality(result)
 			}
+		}
+		if ok {
+			logger.Debugf("Добавлено описание для метода %s (%s)", target.info.Name, describedBy)
+			*target.description = description
+			o.usage.Describe(request.filePath, symbolName(target), describedBy)
 
-			logger.Debugf("Обработка методов пакетами по %d", batchSize)
-			for i := 0; i < len(publicMethods); i += batchSize {
-				end := i + batchSize
-				if end > len(publicMethods) {
-					end = len(publicMethods)
-				}
-
-				// Формируем пакет методов для ЛЛМ
-				batchMethods := make([]models.MethodInfo, 0, end-i)
-				for j := i; j < end; j++ {
-					method := publicMethods[j]
-
-					// Формируем параметры метода для промпта
-					paramStrings := make([]string, 0, len(method.Parameters))
-					for _, param := range method.Parameters {
-						paramStr := param.Name
-						if param.Type != "" {
-							paramStr += ": " + param.Type
-						}
-						paramStrings = append(paramStrings, paramStr)
-					}
-
-					// Создаем информацию о методе для ЛЛМ
-					methodInfo := models.MethodInfo{
-						Name:      method.Name,
-						Signature: method.Name + "(" + strings.Join(paramStrings, ", ") + ")",
-					}
-
-					// Добавляем возвращаемое значение, если оно есть
-					if method.ReturnType != "" {
-						methodInfo.Signature += " " + method.ReturnType
-					}
-
-					batchMethods = append(batchMethods, methodInfo)
-				}
-
-				// Формируем контекст файла
-				fileContext := fmt.Sprintf("Файл: %s\nЯзык: %s\n",
-					codeStructure.Metadata.Path,
-					codeStructure.Metadata.LanguageName())
-
-				// Получаем описания методов через ЛЛМ
-				if len(batchMethods) > 0 {
-					prompt := o.promptBuilder.BuildBatchMethodPrompt(batchMethods, fileContext)
-
-					llmRequest := llm.LLMRequest{
-						Prompt:      prompt,
-						MaxTokens:   o.config.LLM.MaxTokens,
-						Temperature: o.config.LLM.Temperature,
-					}
-
-					logger.Debugf("Отправка запроса к ЛЛМ для пакета из %d методов", len(batchMethods))
-					response, err := o.llmProvider.GenerateText(ctx, llmRequest)
-					if err != nil {
-						logger.WithError(err).Warn("Ошибка при получении описаний методов от ЛЛМ")
-						continue
-					}
-
-					logger.Debug("Парсинг ответа от ЛЛМ")
-					methodDescriptions := o.promptBuilder.ParseBatchResponse(response.Text, batchMethods)
-
-					// Добавляем описания к методам
-					logger.Debug("Применение описаний к методам")
-					for j := i; j < end && j-i < len(batchMethods); j++ {
-						methodInfo := batchMethods[j-i]
-						description, ok := methodDescriptions[methodInfo.Name]
-						if ok {
-							logger.Debugf("Добавлено описание для метода %s", methodInfo.Name)
-							publicMethods[j-i].Description = description
-						} else {
-							logger.Warnf("Не удалось получить описание для метода %s", methodInfo.Name)
-						}
-					}
-				}
+			// Описания резервных провайдеров не кэшируются, чтобы при следующем
+			// запуске их сгенерировал основной провайдер
+			if o.cache != nil && description != "" && describedBy == primary {
+				o.cache.Put(target.cacheKey, description, request.model)
 			}
+		} else {
+			logger.Warnf("Не удалось получить описание для метода %s", target.info.Name)
 		}
+	}
+}
 
-		// Преобразуем CodeStructure в FileStructure
-		logger.WithField("file", file.Path).Debug("Преобразование CodeStructure в FileStructure")
-		fileStructure := models.ConvertToFileStructure(codeStructure)
+// describePending отправляет собранные запросы пакетными заданиями, по одному
+// на модель, и записывает полученные описания. Возвращает true, если были
+// запросы, ожидающие отправки.
+func (o *Orchestrator) describePending(ctx context.Context) bool {
+	pending := o.pending
+	o.pending, o.pendingTokens = nil, 0
+	if len(pending) == 0 {
+		return false
+	}
 
-		// Добавляем структуру файла в коллекцию
-		fileStructures = append(fileStructures, fileStructure)
+	var order []string
+	byModel := make(map[string][]describeRequest)
+	for _, request := range pending {
+		if _, ok := byModel[request.model]; !ok {
+			order = append(order, request.model)
+		}
+		byModel[request.model] = append(byModel[request.model], request)
 	}
 
-	// Шаг 3: Генерация Markdown с использованием генератора
-	logger.Info("Генерация Markdown-документации")
-	projectName := filepath.Base(projectPath)
-	codeMapContent := o.mdGenerator.GenerateCodeMap(fileStructures, projectName)
+	for _, model := range order {
+		requests := byModel[model]
 
-	// Расчет времени выполнения
-	elapsedTime := time.Since(startTime)
-	logger.WithFields(logger.Fields{
-		"elapsed_time": elapsedTime.String(),
-		"files_count":  len(fileStructures),
-	}).Info("Генерация карты кода завершена успешно")
+		// Провайдер создается заново, чтобы получить настройки пакетных заданий
+		provider, err := o.newProvider(model)
+		if err != nil {
+			logger.WithError(err).Warn("Не удалось инициализировать провайдера ЛЛМ, описания пропущены")
+			continue
+		}
 
-	return codeMapContent, nil
+		llmRequests := make([]llm.LLMRequest, 0, len(requests))
+		for _, request := range requests {
+			llmRequests = append(llmRequests, o.llmRequest(request))
+		}
+
+		logger.WithFields(logger.Fields{
+			"model":    model,
+			"requests": len(llmRequests),
+		}).Info("Отправка запросов к ЛЛМ пакетным заданием")
+		responses, err := provider.BatchGenerateText(ctx, llmRequests)
+		if err != nil {
+			logger.WithError(err).Warn("Ошибка пакетного задания ЛЛМ, описания пропущены")
+			continue
+		}
+
+		for i, request := range requests {
+			if i >= len(responses) || responses[i].Text == "" {
+				logger.Warnf("Нет ответа пакетного задания для %d символов файла %s", len(request.targets), request.filePath)
+				continue
+			}
+			o.applyResponse(ctx, provider, request, responses[i])
+		}
+	}
+	return true
+}
+
+// responseTokens возвращает количество входных и выходных токенов запроса.
+// Если провайдер не разделил их, входные токены оцениваются по промпту,
+// а выходные, если провайдер не вернул usage, — оценщиком модели.
+func responseTokens(response llm.LLMResponse, promptTokens int, estimator *utils.TokenEstimator) (int, int) {
+	if response.InputTokens > 0 || response.OutputTokens > 0 {
+		return response.InputTokens, response.OutputTokens
+	}
+	if response.TokensUsed > 0 {
+		input := min(promptTokens, response.TokensUsed)
+		return input, response.TokensUsed - input
+	}
+	return promptTokens, estimator.Estimate(response.Text)
+}
+
+// symbolName возвращает имя символа для отчета: Name или Type.Name
+func symbolName(target describeTarget) string {
+	if target.owner == "" {
+		return target.info.Name
+	}
+	return target.owner + "." + target.info.Name
 }
 
-// SaveCodeMap сохраняет сгенерированную карту кода в файл
+// symbolKey возвращает ключ символа для сопоставления описаний между
+// генерациями: идентификатор и сигнатуру
+func symbolKey(target describeTarget) string {
+	return target.info.ID + " " + target.info.Signature
+}
+
+// knownDescriptions возвращает непустые описания символов структуры кода по ключу symbolKey
+func knownDescriptions(codeStructure *models.CodeStructure) map[string]string {
+	known := make(map[string]string)
+	for _, target := range collectTargets(codeStructure, true) {
+		if *target.description != "" {
+			known[symbolKey(target)] = *target.description
+		}
+	}
+	return known
+}
+
+// applyKnown заполняет известные описания и возвращает символы без описаний
+func applyKnown(targets []describeTarget, known map[string]string) []describeTarget {
+	if len(known) == 0 {
+		return targets
+	}
+
+	var pending []describeTarget
+	for _, target := range targets {
+		if description, ok := known[symbolKey(target)]; ok {
+			*target.description = description
+			continue
+		}
+		pending = append(pending, target)
+	}
+	return pending
+}
+
+// applyCached заполняет описания символов из кэша и возвращает символы,
+// для которых описание нужно запросить у ЛЛМ. Ключ кэша включает провайдера,
+// модель, версию промптов, идентификатор, сигнатуру и исходный код символа,
+// поэтому изменение любого из них приводит к повторному запросу. Перенос
+// функции Go в другой файл того же пакета идентификатор не меняет.
+func (o *Orchestrator) applyCached(codeStructure *models.CodeStructure, fileConfig *config.Config, targets []describeTarget, content []byte) []describeTarget {
+	if o.cache == nil {
+		return targets
+	}
+
+	var lines []string
+	if content != nil {
+		lines = strings.Split(string(content), "\n")
+	}
+
+	var pending []describeTarget
+	for _, target := range targets {
+		target.cacheKey = cache.Key(
+			o.config.LLM.Provider,
+			fileConfig.LLM.Model,
+			o.promptBuilderFor(fileConfig).Version(),
+			target.info.ID,
+			target.info.Signature,
+			symbolSource(lines, target.position),
+		)
+		if description, ok := o.cache.Get(target.cacheKey); ok {
+			*target.description = description
+			continue
+		}
+		pending = append(pending, target)
+	}
+	return pending
+}
+
+// symbolSource возвращает строки исходного кода символа. Если позиция
+// неизвестна, возвращается весь файл, чтобы любое изменение сбрасывало кэш.
+func symbolSource(lines []string, pos models.Position) string {
+	if pos.StartLine < 1 || pos.EndLine < pos.StartLine || pos.EndLine > len(lines) {
+		return strings.Join(lines, "\n")
+	}
+	return strings.Join(lines[pos.StartLine-1:pos.EndLine], "\n")
+}
+
+// newPromptMethodInfo формирует информацию о методе для промпта ЛЛМ
+func newPromptMethodInfo(id, name string, parameters []*models.Parameter, returnType, doc string) models.MethodInfo {
+	paramStrings := make([]string, 0, len(parameters))
+	for _, param := range parameters {
+		paramStr := param.Name
+		switch {
+		case paramStr == "":
+			// Параметр без имени (Go) представлен только типом
+			paramStr = param.Type
+		case param.Type != "":
+			paramStr += ": " + param.Type
+		}
+		paramStrings = append(paramStrings, paramStr)
+	}
+
+	methodInfo := models.MethodInfo{
+		ID:        id,
+		Name:      name,
+		Signature: name + "(" + strings.Join(paramStrings, ", ") + ")",
+		Doc:       doc,
+	}
+
+	// Добавляем возвращаемое значение, если оно есть
+	if returnType != "" {
+		methodInfo.Signature += " " + returnType
+	}
+
+	return methodInfo
+}
+
+// newLinkBuilder создает построитель ссылок на исходный код.
+// Если постоянные ссылки построить невозможно (нет .git или remote),
+// используются относительные ссылки.
+func (o *Orchestrator) newLinkBuilder(projectPath string) *markdown.LinkBuilder {
+	documentDir := ""
+	if o.documentPath != "" {
+		documentDir = filepath.Dir(o.documentPath)
+	}
+	links, err := markdown.NewLinkBuilder(o.config.Output.Links, projectPath, documentDir)
+	if err == nil {
+		return links
+	}
+
+	logger.WithError(err).Warn("Не удалось построить постоянные ссылки, используются относительные")
+	fallback := o.config.Output.Links
+	fallback.Mode = config.LinkModeRelative
+	links, _ = markdown.NewLinkBuilder(fallback, projectPath, documentDir)
+	return links
+}
+
+// SetLinkRoot задает корень проекта, от которого строятся ссылки на исходный код
+func (o *Orchestrator) SetLinkRoot(projectPath string) {
+	o.mdGenerator.SetLinkBuilder(o.newLinkBuilder(projectPath))
+}
+
+// SetDocumentPath задает путь, по которому будет сохранен документ:
+// относительные ссылки на исходный код строятся от его директории.
+// Вызывается до SetLinkRoot или GenerateCodeMap.
+func (o *Orchestrator) SetDocumentPath(documentPath string) {
+	o.documentPath = documentPath
+}
+
+// Render формирует итоговый документ из модели карты кода в формате,
+// указанном в конфигурации. Модель упорядочивается, чтобы документ был
+// воспроизводимым, и запоминается для обновления именованных областей.
+func (o *Orchestrator) Render(codeMap *models.CodeMap) (string, error) {
+	codeMap.Sort()
+	o.fileStructures = codeMap.Files
+	o.projectName = codeMap.Project
+
+	if o.config.Output.Format == config.OutputFormatJSON {
+		logger.Info("Сохранение модели карты кода в формате JSON")
+		data, err := json.MarshalIndent(codeMap, "", "  ")
+		if err != nil {
+			err = logger.OrchestratorError("ошибка сериализации модели карты кода", err)
+			return "", logger.LogError(err)
+		}
+		return string(data) + "\n", nil
+	}
+
+	return o.render(codeMap.Files, codeMap.Project), nil
+}
+
+// render формирует итоговый документ в формате, указанном в конфигурации
+func (o *Orchestrator) render(fileStructures []models.FileStructure, projectName string) string {
+	switch o.config.Output.Format {
+	case config.OutputFormatLLMs:
+		logger.Info("Генерация компактной карты кода в формате llms.txt")
+		return o.mdGenerator.GenerateLLMsTxt(fileStructures, projectName)
+	default:
+		logger.Info("Генерация Markdown-документации")
+		return o.mdGenerator.GenerateCodeMap(fileStructures, projectName)
+	}
+}
+
+// SaveCodeMap сохраняет сгенерированную карту кода в файл.
+// В режиме inplace обновляются только области между маркерами в существующем файле.
 func (o *Orchestrator) SaveCodeMap(codeMap, outputPath string) error {
+	if o.config.Output.Mode == config.OutputModeInPlace {
+		return o.updateRegions(codeMap, outputPath)
+	}
+
 	logger.WithField("output_path", outputPath).Info("Сохранение карты кода в файл")
 
 	// Создаем директории, если они не существуют
@@ -249,7 +1442,7 @@ func (o *Orchestrator) SaveCodeMap(codeMap, outputPath string) error {
 	}
 
 	logger.Debugf("Запись %d байт в файл", len(codeMap))
-	err := os.WriteFile(outputPath, []byte(codeMap), 0644)
+	err := writeFileAtomic(outputPath, []byte(codeMap))
 	if err != nil {
 		err = logger.FileSystemError("ошибка при записи в файл", err)
 		return logger.LogError(err)
@@ -258,3 +1451,108 @@ func (o *Orchestrator) SaveCodeMap(codeMap, outputPath string) error {
 	logger.Info("Карта кода успешно сохранена")
 	return nil
 }
+
+// updateRegions заменяет содержимое областей между маркерами в существующем документе.
+// Область по умолчанию получает всю карту кода, именованная область — карту
+// поддиректории, указанной для нее в output.regions.
+func (o *Orchestrator) updateRegions(codeMap, outputPath string) error {
+	logger.WithField("output_path", outputPath).Info("Обновление областей карты кода в документе")
+
+	data, err := os.ReadFile(outputPath)
+	if err != nil {
+		err = logger.FileSystemError("ошибка при чтении документа для обновления", err)
+		return logger.LogError(err)
+	}
+
+	updated, replaced, err := o.replaceRegions(string(data), codeMap)
+	if err != nil {
+		err = logger.OrchestratorError("некорректные маркеры областей в документе", err)
+		return logger.LogError(err)
+	}
+
+	if replaced == 0 {
+		err = logger.OrchestratorError(fmt.Sprintf("в документе %s нет маркеров <!-- code-telescope:start -->", outputPath), nil)
+		return logger.LogError(err)
+	}
+
+	if err := writeFileAtomic(outputPath, []byte(updated)); err != nil {
+		err = logger.FileSystemError("ошибка при записи в файл", err)
+		return logger.LogError(err)
+	}
+
+	logger.WithField("regions", replaced).Info("Области карты кода успешно обновлены")
+	return nil
+}
+
+// replaceRegions возвращает документ, в котором области между маркерами
+// заменены картой кода, и количество замененных областей
+func (o *Orchestrator) replaceRegions(document, codeMap string) (string, int, error) {
+	regionPaths := make(map[string]string, len(o.config.Output.Regions))
+	for _, region := range o.config.Output.Regions {
+		regionPaths[region.Name] = region.Path
+	}
+
+	return markdown.ReplaceRegions(document, func(name string) (string, bool) {
+		if name == "" {
+			return codeMap, true
+		}
+
+		regionPath, ok := regionPaths[name]
+		if !ok {
+			logger.WithField("region", name).Warn("Область не описана в output.regions, оставлена без изменений")
+			return "", false
+		}
+
+		logger.WithFields(logger.Fields{
+			"region": name,
+			"path":   regionPath,
+		}).Debug("Генерация карты кода для области")
+		return o.render(filterByPath(o.fileStructures, regionPath), o.projectName), true
+	})
+}
+
+// writeFileAtomic записывает файл через временный файл в той же директории,
+// чтобы читатели никогда не видели частично записанный документ
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+// filterByPath возвращает файлы, находящиеся внутри указанной поддиректории проекта
+func filterByPath(fileStructures []models.FileStructure, dir string) []models.FileStructure {
+	prefix := strings.Trim(filepath.ToSlash(filepath.Clean(dir)), "/")
+	if prefix == "" || prefix == "." {
+		return fileStructures
+	}
+
+	var filtered []models.FileStructure
+	for _, fs := range fileStructures {
+		p := filepath.ToSlash(fs.Path)
+		if p == prefix || strings.HasPrefix(p, prefix+"/") {
+			filtered = append(filtered, fs)
+		}
+	}
+	return filtered
+}
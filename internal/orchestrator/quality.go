@@ -0,0 +1,108 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+
+	"code-telescope/internal/llm"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/quality"
+	"code-telescope/internal/usage"
+	"code-telescope/pkg/models"
+)
+
+// checkDescription проверяет описание символа и, если оно не прошло проверку,
+// запрашивает его у ЛЛМ заново отдельным промптом с перечнем недостатков, не
+// больше llm.quality.max_retries раз. Возвращает лучшее из полученных
+// описаний, его оценку и источник. truncated сообщает, что ответ с описанием
+// обрезан по лимиту токенов.
+func (o *Orchestrator) checkDescription(ctx context.Context, provider llm.LLMProvider, request describeRequest, target describeTarget, description string, truncated bool, source usage.Source) (string, quality.Result, usage.Source) {
+	symbol := quality.Symbol{Name: target.info.Name, Owner: target.owner, Parameters: target.parameters}
+	language := request.promptBuilder.Language()
+	result := o.checker.Check(description, symbol, language, truncated)
+
+	maxRetries := o.config.LLM.Quality.MaxRetries
+	primary := usage.Source{Provider: o.config.LLM.Provider, Model: request.model}
+	for attempt := 1; attempt <= maxRetries && !result.Passed() && provider != nil; attempt++ {
+		prompt := request.promptBuilder.BuildMethodRetryPrompt(target.info, request.fileContext, description, result.Issues)
+		promptTokens := o.tokenEstimator(primary).Estimate(prompt)
+		if !o.usage.Allow(primary, promptTokens) {
+			break
+		}
+
+		logger.Infof("Описание %s не прошло проверку (%s), повторный запрос %d из %d",
+			symbolName(target), strings.Join(result.IssueStrings(), ", "), attempt, maxRetries)
+		response, err := provider.GenerateText(ctx, llm.LLMRequest{
+			Prompt:      prompt,
+			MaxTokens:   o.config.LLM.MaxTokens,
+			Temperature: o.config.LLM.Temperature,
+		})
+		if err != nil {
+			logger.WithError(err).Warnf("Ошибка повторного запроса описания %s", symbolName(target))
+			break
+		}
+
+		retrySource := primary
+		if response.Provider != "" {
+			retrySource = usage.Source{Provider: response.Provider, Model: response.Model}
+		}
+		o.observeTokens(retrySource, prompt, response)
+		inputTokens, outputTokens := responseTokens(response, promptTokens, o.tokenEstimator(retrySource))
+		o.usage.Record(request.filePath, retrySource, inputTokens, outputTokens)
+
+		// Остается лучшее из описаний: повторный ответ может оказаться хуже
+		candidate := strings.TrimSpace(response.Text)
+		if candidateResult := o.checker.Check(candidate, symbol, language, response.Truncated); candidateResult.Score >= result.Score {
+			description, result, source = candidate, candidateResult, retrySource
+		}
+	}
+
+	if !result.Passed() && description != "" {
+		logger.Warnf("Описание %s не прошло проверку: %s", symbolName(target), strings.Join(result.IssueStrings(), ", "))
+	}
+	return description, result, source
+}
+
+// scoreDescriptions оценивает описания символов, взятые из кэша или
+// предыдущей генерации. Обрезку ответа для них проверить нельзя, поэтому
+// описание считается обрезанным, только если обрывается на середине предложения.
+func (o *Orchestrator) scoreDescriptions(targets []describeTarget, language string) {
+	if !o.config.LLM.Quality.Enabled {
+		return
+	}
+	for _, target := range targets {
+		if *target.description == "" || *target.quality != nil {
+			continue
+		}
+		symbol := quality.Symbol{Name: target.info.Name, Owner: target.owner, Parameters: target.parameters}
+		*target.quality = descriptionQuality(o.checker.Check(*target.description, symbol, language, false))
+	}
+}
+
+// descriptionQuality преобразует результат проверки в оценку для модели карты кода
+func descriptionQuality(result quality.Result) *models.DescriptionQuality {
+	return &models.DescriptionQuality{Score: result.Score, Issues: result.IssueStrings()}
+}
+
+// lastDescribed возвращает ключ последнего символа пакета, описание которого
+// есть в ответе: при обрезке ответа по лимиту токенов обрезано именно оно
+func lastDescribed(targets []describeTarget, descriptions map[string]string) string {
+	for i := len(targets) - 1; i >= 0; i-- {
+		if _, ok := descriptions[targets[i].info.Key()]; ok {
+			return targets[i].info.Key()
+		}
+	}
+	return ""
+}
+
+// parameterNames возвращает имена параметров функции или метода.
+// Параметры без имени (Go) пропускаются.
+func parameterNames(parameters []*models.Parameter) []string {
+	names := make([]string, 0, len(parameters))
+	for _, param := range parameters {
+		if param.Name != "" {
+			names = append(names, param.Name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,59 @@
+package orchestrator
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"code-telescope/internal/git"
+	"code-telescope/internal/logger"
+	"code-telescope/pkg/models"
+)
+
+// BuildModelAt строит модель карты кода проекта в состоянии ревизии git.
+// Файлы проекта извлекаются из коммита во временную директорию напрямую
+// из .git, рабочее дерево не изменяется. Описания от ЛЛМ не запрашиваются.
+// Возвращает модель и SHA коммита, в который разрешилась ревизия.
+func (o *Orchestrator) BuildModelAt(projectPath, revision string) (*models.CodeMap, string, error) {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, "", logger.LogError(logger.FileSystemError("ошибка получения абсолютного пути", err))
+	}
+
+	repo, err := git.Open(absProject)
+	if err != nil {
+		return nil, "", logger.LogError(logger.OrchestratorError("не удалось открыть git-репозиторий проекта", err))
+	}
+	commit, err := repo.ResolveRevision(revision)
+	if err != nil {
+		return nil, "", logger.LogError(logger.OrchestratorError(fmt.Sprintf("не удалось определить ревизию %s", revision), err))
+	}
+	projectDir, err := filepath.Rel(repo.Root, absProject)
+	if err != nil {
+		return nil, "", logger.LogError(logger.FileSystemError("ошибка получения пути проекта в репозитории", err))
+	}
+
+	tempDir, err := os.MkdirTemp("", "code-telescope-rev-")
+	if err != nil {
+		return nil, "", logger.LogError(logger.FileSystemError("ошибка создания временной директории", err))
+	}
+	defer os.RemoveAll(tempDir)
+
+	if err := repo.ExtractTree(commit, projectDir, tempDir); err != nil {
+		return nil, "", logger.LogError(logger.OrchestratorError(fmt.Sprintf("не удалось извлечь файлы коммита %s", commit), err))
+	}
+
+	// Описания не нужны для сравнения структуры, а кэш временной директории сразу удаляется
+	describe, cacheEnabled := o.config.LLM.Describe, o.config.Cache.Enabled
+	o.config.LLM.Describe, o.config.Cache.Enabled = false, false
+	defer func() {
+		o.config.LLM.Describe, o.config.Cache.Enabled = describe, cacheEnabled
+	}()
+
+	codeMap, err := o.BuildModel(tempDir)
+	if err != nil {
+		return nil, "", err
+	}
+	codeMap.Project = filepath.Base(absProject)
+	return codeMap, commit, nil
+}
@@ -0,0 +1,167 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"code-telescope/internal/filesystem"
+	"code-telescope/internal/git"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/parser"
+	"code-telescope/pkg/models"
+)
+
+// SinceResult описывает результат инкрементального построения модели
+type SinceResult struct {
+	// Обновленная модель карты кода
+	CodeMap *models.CodeMap
+
+	// SHA коммита, с которым сравнивалось рабочее дерево
+	Commit string
+
+	// Изменения рабочего дерева относительно коммита
+	Changes filesystem.Changes
+
+	// Количество заново обработанных файлов
+	Processed int
+
+	// Количество файлов, взятых из исходной модели
+	Reused int
+}
+
+// BuildModelSince обновляет ранее сохраненную модель base, заново обрабатывая
+// только файлы, изменившиеся в рабочем дереве с ревизии since. Изменения
+// определяются чтением локального git-репозитория. Удаленные файлы убираются
+// из модели, переименованные без изменений переносятся под новым путем
+// вместе с идентификаторами символов.
+// Файлы, которых нет в base, обрабатываются, даже если они не менялись.
+func (o *Orchestrator) BuildModelSince(projectPath, since string, base *models.CodeMap) (*SinceResult, error) {
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return nil, logger.LogError(logger.FileSystemError("ошибка получения абсолютного пути", err))
+	}
+
+	repo, err := git.Open(absProject)
+	if err != nil {
+		return nil, logger.LogError(logger.OrchestratorError("не удалось открыть git-репозиторий проекта", err))
+	}
+	commit, err := repo.ResolveRevision(since)
+	if err != nil {
+		return nil, logger.LogError(logger.OrchestratorError(fmt.Sprintf("не удалось определить ревизию %s", since), err))
+	}
+
+	// Файлы проекта в коммите, пути относительно корня проекта
+	projectDir, err := filepath.Rel(repo.Root, absProject)
+	if err != nil {
+		return nil, logger.LogError(logger.FileSystemError("ошибка получения пути проекта в репозитории", err))
+	}
+	treeFiles, err := repo.TreeFiles(commit, projectDir)
+	if err != nil {
+		return nil, logger.LogError(logger.OrchestratorError(
+			fmt.Sprintf("не удалось прочитать файлы коммита %s (в неглубоком клоне история может отсутствовать)", commit), err))
+	}
+
+	files, _, err := o.Scan(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Сравнение рабочего дерева с коммитом по SHA объектов blob
+	old := make(filesystem.Snapshot, len(treeFiles))
+	for path, sha := range treeFiles {
+		old[filepath.FromSlash(path)] = filesystem.FileState{Hash: sha}
+	}
+	current := filesystem.NewSnapshot(files)
+	changes := filesystem.Diff(old, current, func(relPath string) (string, error) {
+		return git.BlobHash(filepath.Join(absProject, relPath))
+	})
+
+	logger.WithFields(logger.Fields{
+		"since":    since,
+		"commit":   commit,
+		"added":    len(changes.Added),
+		"modified": len(changes.Modified),
+		"removed":  len(changes.Removed),
+		"renamed":  len(changes.Renamed),
+	}).Info("Определены изменения относительно ревизии")
+
+	return o.mergeChanges(projectPath, base, files, changes, commit)
+}
+
+// mergeChanges строит модель из файлов исходной модели и заново обработанных измененных файлов
+func (o *Orchestrator) mergeChanges(projectPath string, base *models.CodeMap, files []*models.FileMetadata, changes filesystem.Changes, commit string) (*SinceResult, error) {
+	baseFiles := make(map[string]models.FileStructure, len(base.Files))
+	for _, file := range base.Files {
+		baseFiles[modelPath(file.Path)] = file
+	}
+
+	changed := make(map[string]bool, len(changes.Added)+len(changes.Modified))
+	for _, path := range append(append([]string{}, changes.Added...), changes.Modified...) {
+		changed[modelPath(path)] = true
+	}
+	renamedFrom := make(map[string]string, len(changes.Renamed))
+	for oldPath, newPath := range changes.Renamed {
+		renamedFrom[modelPath(newPath)] = modelPath(oldPath)
+	}
+
+	ctx := context.Background()
+	parser.ResetModuleCache()
+	o.openCache(projectPath)
+	if err := o.loadOverrides(projectPath); err != nil {
+		return nil, err
+	}
+	result := &SinceResult{Commit: commit, Changes: changes}
+	fileStructures := make([]models.FileStructure, 0, len(files))
+
+	for _, file := range files {
+		path := modelPath(file.Path)
+
+		if !changed[path] {
+			// Содержимое не менялось: структура берется из исходной модели
+			source := path
+			if oldPath, ok := renamedFrom[path]; ok {
+				source = oldPath
+			}
+			// Идентификаторы символов переносятся в область нового пути и текущего
+			// go.mod; если это невозможно, файл обрабатывается заново
+			structure, ok := baseFiles[source]
+			if ok {
+				_, ok = parser.RescopeSymbolIDs(&structure, parser.SymbolScope(file, structure.Package))
+			}
+			if ok {
+				structure.Path = file.Path
+				structure.Size = file.Size
+				structure.ModTime = file.ModTime
+				fileStructures = append(fileStructures, structure)
+				result.Reused++
+				continue
+			}
+		}
+
+		_, fileStructure, err := o.processFile(ctx, file, nil)
+		if err != nil {
+			continue
+		}
+		fileStructures = append(fileStructures, fileStructure)
+		result.Processed++
+	}
+
+	o.saveCache()
+	if err := o.checkSecrets(projectPath); err != nil {
+		return nil, err
+	}
+
+	project := base.Project
+	if project == "" {
+		project = filepath.Base(projectPath)
+	}
+	result.CodeMap = models.NewCodeMap(project, fileStructures)
+	return result, nil
+}
+
+// modelPath приводит путь файла к виду, в котором он хранится в JSON-модели
+func modelPath(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(path), "./")
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"code-telescope/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFakeOpenAIBatchServer имитирует OpenAI Batch API и отвечает на пакетные
+// промпты описаниями из scriptedDescriptions. Задание завершается сразу.
+// Возвращает сервер и счетчики созданных заданий и обычных запросов.
+func newFakeOpenAIBatchServer(t *testing.T) (*httptest.Server, *int32, *int32) {
+	var batches, completions int32
+	var input []byte
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/chat/completions", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&completions, 1)
+		http.Error(w, "ожидается пакетное задание", http.StatusBadRequest)
+	})
+	mux.HandleFunc("POST /v1/files", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		require.NoError(t, err)
+		input, _ = io.ReadAll(file)
+		fmt.Fprint(w, `{"id":"file-in"}`)
+	})
+	mux.HandleFunc("POST /v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&batches, 1)
+		fmt.Fprint(w, `{"id":"batch_1","status":"validating"}`)
+	})
+	mux.HandleFunc("GET /v1/batches/batch_1", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"batch_1","status":"completed","output_file_id":"file-out"}`)
+	})
+	mux.HandleFunc("GET /v1/files/file-out/content", func(w http.ResponseWriter, r *http.Request) {
+		for _, line := range strings.Split(strings.TrimSpace(string(input)), "\n") {
+			var request struct {
+				CustomID string `json:"custom_id"`
+				Body     struct {
+					Messages []struct {
+						Content string `json:"content"`
+					} `json:"messages"`
+				} `json:"body"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(line), &request))
+
+			var sb strings.Builder
+			for _, match := range batchMethodPattern.FindAllStringSubmatch(request.Body.Messages[0].Content, -1) {
+				fmt.Fprintf(&sb, "Метод %s: %s\n", match[1], scriptedDescriptions[match[2]])
+			}
+			data, _ := json.Marshal(map[string]interface{}{
+				"custom_id": request.CustomID,
+				"response": map[string]interface{}{"status_code": 200, "body": map[string]interface{}{
+					"choices": []map[string]interface{}{{"message": map[string]string{"content": sb.String()}}},
+					"usage":   map[string]int{"prompt_tokens": 100, "completion_tokens": 20, "total_tokens": 120},
+				}},
+			})
+			fmt.Fprintln(w, string(data))
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, &batches, &completions
+}
+
+// TestGenerateWithBatchAPI проверяет, что в пакетном режиме все промпты
+// проекта отправляются одним заданием, а описания попадают в карту кода
+func TestGenerateWithBatchAPI(t *testing.T) {
+	server, batches, completions := newFakeOpenAIBatchServer(t)
+
+	projectPath := t.TempDir()
+	source, err := os.ReadFile(filepath.Join("testdata", "calc", "calc.go"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "calc.go"), source, 0644))
+
+	cfg := replayConfig("", "")
+	cfg.LLM.Provider = "openai"
+	cfg.LLM.Model = "gpt-4o-mini"
+	cfg.LLM.APIKey = "test-key"
+	cfg.LLM.BaseURL = server.URL + "/v1/chat/completions"
+	cfg.LLM.BatchSize = 1
+	cfg.LLM.BatchAPI = config.BatchAPIConfig{
+		Enabled:                true,
+		PollIntervalSeconds:    1,
+		MaxPollIntervalSeconds: 1,
+		StateDir:               config.DefaultBatchStateDir,
+	}
+
+	codeMap := generate(t, cfg, projectPath)
+
+	for name, description := range scriptedDescriptions {
+		assert.Contains(t, codeMap, description, "описание %s из пакетного задания", name)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(batches), "все промпты отправлены одним заданием")
+	assert.Zero(t, atomic.LoadInt32(completions), "обычные запросы не отправляются")
+
+	states, err := filepath.Glob(filepath.Join(projectPath, config.DefaultBatchStateDir, "*.json"))
+	require.NoError(t, err)
+	assert.Empty(t, states, "состояние завершенного задания удаляется")
+}
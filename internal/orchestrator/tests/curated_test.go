@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"code-telescope/internal/curated"
+	"code-telescope/internal/orchestrator"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// curatedProject создает проект с аннотацией telescope:describe и файлом
+// описаний: описание Sub написано для другой сигнатуры, символа Gone нет
+func curatedProject(t *testing.T) string {
+	projectPath := t.TempDir()
+	source := `package calc
+
+// Add складывает числа.
+// telescope:describe "Складывает два целых числа и возвращает сумму."
+func Add(a, b int) int { return a + b }
+
+func Sub(a, b int) int { return a - b }
+
+func Mul(a, b int) int { return a * b }
+`
+	overrides := `descriptions:
+  go:example.com/calc.Sub:
+    description: "Вычитает b из a."
+    signature_hash: "000000000000"
+  go:example.com/calc.Gone:
+    description: "Удаленная функция."
+`
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "go.mod"), []byte("module example.com/calc\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "calc.go"), []byte(source), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, ".code-telescope"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, ".code-telescope", "descriptions.yaml"), []byte(overrides), 0644))
+	return projectPath
+}
+
+// TestBuildModelAppliesOverrides проверяет, что описания, написанные
+// вручную, заменяют описания от ЛЛМ и не запрашиваются у модели
+func TestBuildModelAppliesOverrides(t *testing.T) {
+	projectPath := curatedProject(t)
+	orch, err := orchestrator.New(replayConfig("", ""), false)
+	require.NoError(t, err)
+
+	scripted.prompts = nil
+	codeMap, err := orch.BuildModel(projectPath)
+	require.NoError(t, err)
+	methods := methodsByName(codeMap)
+
+	assert.Equal(t, "Складывает два целых числа и возвращает сумму.", methods["Add"].Description)
+	assert.Equal(t, &models.DescriptionOverride{Source: models.OverrideSourceAnnotation}, methods["Add"].Override)
+	assert.Nil(t, methods["Add"].Quality, "описания, написанные вручную, не оцениваются")
+
+	assert.Equal(t, "Вычитает b из a.", methods["Sub"].Description)
+	assert.Equal(t, &models.DescriptionOverride{Source: models.OverrideSourceFile, Stale: true}, methods["Sub"].Override)
+
+	assert.Nil(t, methods["Mul"].Override)
+	prompts := strings.Join(scripted.prompts, "\n")
+	assert.Regexp(t, regexp.MustCompile(`Метод \d+: Mul\n`), prompts)
+	assert.NotRegexp(t, regexp.MustCompile(`Метод \d+: (Add|Sub)\n`), prompts)
+}
+
+// TestReviewOverrides проверяет отчет об устаревших описаниях и запись
+// хэшей текущих сигнатур
+func TestReviewOverrides(t *testing.T) {
+	projectPath := curatedProject(t)
+	orch, err := orchestrator.New(replayConfig("", ""), false)
+	require.NoError(t, err)
+
+	result, err := orch.ReviewOverrides(projectPath, false)
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 2)
+	assert.Equal(t, "go:example.com/calc.Gone", result.Entries[0].ID)
+	assert.Equal(t, orchestrator.ReviewMissing, result.Entries[0].Status)
+	assert.Equal(t, "go:example.com/calc.Sub", result.Entries[1].ID)
+	assert.Equal(t, orchestrator.ReviewStale, result.Entries[1].Status)
+	assert.True(t, result.NeedsReview())
+
+	result, err = orch.ReviewOverrides(projectPath, true)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Pinned)
+
+	overrides, err := curated.Load(result.Path)
+	require.NoError(t, err)
+	sub, _ := overrides.Get("go:example.com/calc.Sub")
+	assert.Equal(t, result.Entries[1].SignatureHash, sub.SignatureHash)
+
+	result, err = orch.ReviewOverrides(projectPath, false)
+	require.NoError(t, err)
+	assert.Equal(t, orchestrator.ReviewOK, result.Entries[1].Status)
+}
+
+// TestReviewOverridesDetectsParameterChanges проверяет, что описание
+// устаревает при изменении параметров из группы "key, value string"
+// и вариативного параметра
+func TestReviewOverridesDetectsParameterChanges(t *testing.T) {
+	for _, change := range [][2]string{
+		{"func Put(key, value string) {}", "func Put(key, value, model string) {}"},
+		{"func Put(key, value string) {}", "func Put(key string, value []byte) {}"},
+		{"func Put(key string, values ...string) {}", "func Put(key string, values ...int) {}"},
+	} {
+		projectPath := t.TempDir()
+		source := filepath.Join(projectPath, "store.go")
+		require.NoError(t, os.WriteFile(filepath.Join(projectPath, "go.mod"), []byte("module example.com/store\n"), 0644))
+		require.NoError(t, os.WriteFile(source, []byte("package store\n\n"+change[0]+"\n"), 0644))
+		require.NoError(t, os.MkdirAll(filepath.Join(projectPath, ".code-telescope"), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(projectPath, ".code-telescope", "descriptions.yaml"),
+			[]byte("descriptions:\n  go:example.com/store.Put:\n    description: \"Сохраняет значение по ключу.\"\n"), 0644))
+
+		orch, err := orchestrator.New(replayConfig("", ""), false)
+		require.NoError(t, err)
+		result, err := orch.ReviewOverrides(projectPath, true)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.Pinned, change[0])
+
+		require.NoError(t, os.WriteFile(source, []byte("package store\n\n"+change[1]+"\n"), 0644))
+		result, err = orch.ReviewOverrides(projectPath, false)
+		require.NoError(t, err)
+		require.Len(t, result.Entries, 1)
+		assert.Equal(t, orchestrator.ReviewStale, result.Entries[0].Status, "%s → %s", change[0], change[1])
+	}
+}
+
+// TestReviewOverridesDetectsDefaultChange проверяет, что описание
+// устаревает при изменении значения параметра по умолчанию
+func TestReviewOverridesDetectsDefaultChange(t *testing.T) {
+	projectPath := t.TempDir()
+	source := filepath.Join(projectPath, "store.py")
+	require.NoError(t, os.WriteFile(source, []byte("def put(key, timeout=10):\n    pass\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, ".code-telescope"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, ".code-telescope", "descriptions.yaml"),
+		[]byte("descriptions:\n  py:store.put:\n    description: \"Сохраняет ключ, ожидая не дольше 10 секунд.\"\n"), 0644))
+
+	orch, err := orchestrator.New(replayConfig("", ""), false)
+	require.NoError(t, err)
+	result, err := orch.ReviewOverrides(projectPath, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Pinned)
+
+	require.NoError(t, os.WriteFile(source, []byte("def put(key, timeout=None):\n    pass\n"), 0644))
+	result, err = orch.ReviewOverrides(projectPath, false)
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 1)
+	assert.Equal(t, orchestrator.ReviewStale, result.Entries[0].Status)
+}
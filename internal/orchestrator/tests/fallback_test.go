@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/llm"
+	"code-telescope/internal/orchestrator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имя тестового провайдера, который всегда перегружен
+const overloadedProviderName = "overloaded"
+
+// overloadedProvider отвечает ошибкой на любой запрос
+type overloadedProvider struct{}
+
+func (overloadedProvider) Name() string { return overloadedProviderName }
+
+func (overloadedProvider) GenerateText(context.Context, llm.LLMRequest) (llm.LLMResponse, error) {
+	return llm.LLMResponse{}, errors.New("overloaded_error: сервис перегружен")
+}
+
+func (overloadedProvider) BatchGenerateText(context.Context, []llm.LLMRequest) ([]llm.LLMResponse, error) {
+	return nil, errors.New("overloaded_error: сервис перегружен")
+}
+
+func init() {
+	llm.RegisterProvider(overloadedProviderName, func(map[string]interface{}) (llm.LLMProvider, error) {
+		return overloadedProvider{}, nil
+	})
+}
+
+// TestGenerateFallsBackToNextProvider проверяет, что при недоступном основном
+// провайдере описания получает резервный, а отчет показывает их источник
+func TestGenerateFallsBackToNextProvider(t *testing.T) {
+	cfg := replayConfig("", "")
+	cfg.LLM.Provider = overloadedProviderName
+	cfg.LLM.Model = "claude-sonnet-4"
+	cfg.LLM.Fallbacks = []config.FallbackConfig{{Provider: scriptedProviderName, Model: "gpt-4o"}}
+
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+	codeMap, err := orch.GenerateCodeMap(filepath.Join("testdata", "calc"))
+	require.NoError(t, err)
+	assert.Contains(t, codeMap, scriptedDescriptions["Push"])
+
+	report := orch.Usage()
+	require.NotEmpty(t, report.Descriptions)
+	for _, description := range report.Descriptions {
+		assert.Equal(t, scriptedProviderName, description.Provider, "символ %s", description.Symbol)
+		assert.Equal(t, "gpt-4o", description.Model)
+	}
+	assert.Equal(t, len(scriptedDescriptions), report.Total.Described)
+}
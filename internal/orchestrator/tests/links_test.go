@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/orchestrator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Ссылка на строки исходного кода в документе: [calc.go#L3-L5](../calc.go#L3-L5)
+var sourceLinkPattern = regexp.MustCompile(`\]\(([^)#]+)#L\d+(?:-L\d+)?\)`)
+
+// TestRelativeLinksFromOutputDir проверяет, что относительные ссылки в
+// документе, сохраненном в поддиректорию проекта, ведут на исходные файлы
+func TestRelativeLinksFromOutputDir(t *testing.T) {
+	projectPath := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "calc"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "calc", "calc.go"),
+		[]byte("package calc\n\n// Add складывает числа\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"), 0644))
+
+	cfg := replayConfig("", "")
+	cfg.LLM.Describe = false
+	cfg.Output.Links.Mode = config.LinkModeRelative
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+
+	documentPath := filepath.Join(projectPath, "docs", "ARCHITECTURE.md")
+	orch.SetDocumentPath(documentPath)
+	document, err := orch.GenerateCodeMap(projectPath)
+	require.NoError(t, err)
+	require.NoError(t, orch.SaveCodeMap(document, documentPath))
+
+	links := sourceLinkPattern.FindAllStringSubmatch(document, -1)
+	require.NotEmpty(t, links)
+	for _, link := range links {
+		assert.True(t, strings.HasPrefix(link[1], "../"), link[0])
+		assert.FileExists(t, filepath.Join(filepath.Dir(documentPath), filepath.FromSlash(link[1])), link[0])
+	}
+}
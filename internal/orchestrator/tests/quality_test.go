@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"code-telescope/internal/llm"
+	"code-telescope/internal/orchestrator"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имя тестового провайдера, отвечающего на пакетный промпт плохими описаниями
+const sloppyProviderName = "sloppy"
+
+// Плохие описания символов в пакетном ответе
+var sloppyDescriptions = map[string]string{
+	"Add":    "Складывает.",
+	"Divide": "Делит a на b; параметр `divisor` не может быть нулем.",
+	"Push":   "Прибавляет значение к накопленной",
+}
+
+// Строка метода в промпте описания одного метода: "Метод: Add"
+var singleMethodPattern = regexp.MustCompile(`(?m)^Метод: (\S+)$`)
+
+// sloppyProvider отвечает на пакетный промпт плохими описаниями, а на
+// повторные запросы — описаниями из scriptedDescriptions
+type sloppyProvider struct {
+	retries []string
+}
+
+func (p *sloppyProvider) Name() string { return sloppyProviderName }
+
+func (p *sloppyProvider) GenerateText(_ context.Context, request llm.LLMRequest) (llm.LLMResponse, error) {
+	if match := singleMethodPattern.FindStringSubmatch(request.Prompt); match != nil {
+		p.retries = append(p.retries, request.Prompt)
+		return llm.LLMResponse{Text: scriptedDescriptions[match[1]]}, nil
+	}
+
+	var sb strings.Builder
+	for _, match := range batchMethodPattern.FindAllStringSubmatch(request.Prompt, -1) {
+		fmt.Fprintf(&sb, "Метод %s: %s\n", match[1], sloppyDescriptions[match[2]])
+	}
+	return llm.LLMResponse{Text: sb.String()}, nil
+}
+
+func (p *sloppyProvider) BatchGenerateText(ctx context.Context, requests []llm.LLMRequest) ([]llm.LLMResponse, error) {
+	responses := make([]llm.LLMResponse, 0, len(requests))
+	for _, request := range requests {
+		response, _ := p.GenerateText(ctx, request)
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+var sloppy = &sloppyProvider{}
+
+// Имя тестового провайдера, описания которого упоминают параметры
+// из групп и вариативные параметры
+const paramsProviderName = "params"
+
+// Описания, упоминающие параметры по именам
+var paramsDescriptions = map[string]string{
+	"Put": "Сохраняет параметр `value` под ключом, переданным в параметре `key`.",
+	"Log": "Записывает в журнал строку `format`, подставляя в нее параметр `args`.",
+}
+
+// paramsProvider отвечает описаниями из paramsDescriptions и запоминает
+// повторные запросы
+type paramsProvider struct {
+	retries int
+}
+
+func (p *paramsProvider) Name() string { return paramsProviderName }
+
+func (p *paramsProvider) GenerateText(_ context.Context, request llm.LLMRequest) (llm.LLMResponse, error) {
+	if singleMethodPattern.MatchString(request.Prompt) {
+		p.retries++
+	}
+	var sb strings.Builder
+	for _, match := range batchMethodPattern.FindAllStringSubmatch(request.Prompt, -1) {
+		fmt.Fprintf(&sb, "Метод %s: %s\n", match[1], paramsDescriptions[match[2]])
+	}
+	return llm.LLMResponse{Text: sb.String()}, nil
+}
+
+func (p *paramsProvider) BatchGenerateText(ctx context.Context, requests []llm.LLMRequest) ([]llm.LLMResponse, error) {
+	responses := make([]llm.LLMResponse, 0, len(requests))
+	for _, request := range requests {
+		response, _ := p.GenerateText(ctx, request)
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+var params = &paramsProvider{}
+
+func init() {
+	llm.RegisterProvider(sloppyProviderName, func(map[string]interface{}) (llm.LLMProvider, error) {
+		return sloppy, nil
+	})
+	llm.RegisterProvider(paramsProviderName, func(map[string]interface{}) (llm.LLMProvider, error) {
+		return params, nil
+	})
+}
+
+// methodsByName возвращает методы модели карты кода по именам
+func methodsByName(codeMap *models.CodeMap) map[string]models.MethodInfo {
+	methods := make(map[string]models.MethodInfo)
+	for _, file := range codeMap.Files {
+		for _, method := range file.Methods {
+			methods[method.Name] = method
+		}
+	}
+	return methods
+}
+
+// TestBuildModelRetriesFailedDescriptions проверяет, что описания, не
+// прошедшие проверку, запрашиваются повторно по одному с указанием недостатков
+func TestBuildModelRetriesFailedDescriptions(t *testing.T) {
+	cfg := replayConfig("", "")
+	cfg.LLM.Provider = sloppyProviderName
+
+	sloppy.retries = nil
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+	codeMap, err := orch.BuildModel(filepath.Join("testdata", "calc"))
+	require.NoError(t, err)
+
+	require.Len(t, sloppy.retries, len(sloppyDescriptions))
+	retries := strings.Join(sloppy.retries, "\n")
+	assert.Contains(t, retries, "Предыдущее описание: "+sloppyDescriptions["Add"])
+	assert.Contains(t, retries, "описание упоминает параметр divisor, которого нет в сигнатуре")
+	assert.Contains(t, retries, "описание обрывается на середине предложения")
+
+	for name, method := range methodsByName(codeMap) {
+		assert.Equal(t, scriptedDescriptions[name], method.Description, name)
+		require.NotNil(t, method.Quality, name)
+		assert.Equal(t, 1.0, method.Quality.Score, name)
+		assert.Empty(t, method.Quality.Issues, name)
+	}
+}
+
+// TestBuildModelScoresWithoutRetries проверяет, что без повторных запросов
+// описания остаются с оценкой и перечнем недостатков
+func TestBuildModelScoresWithoutRetries(t *testing.T) {
+	cfg := replayConfig("", "")
+	cfg.LLM.Provider = sloppyProviderName
+	cfg.LLM.Quality.MaxRetries = 0
+
+	sloppy.retries = nil
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+	codeMap, err := orch.BuildModel(filepath.Join("testdata", "calc"))
+	require.NoError(t, err)
+	assert.Empty(t, sloppy.retries)
+
+	methods := methodsByName(codeMap)
+	require.NotNil(t, methods["Divide"].Quality)
+	assert.Equal(t, []string{"unknown_parameter: divisor"}, methods["Divide"].Quality.Issues)
+	assert.Equal(t, 0.6, methods["Divide"].Quality.Score)
+	require.NotNil(t, methods["Push"].Quality)
+	assert.Equal(t, []string{"truncated"}, methods["Push"].Quality.Issues)
+	require.NotNil(t, methods["Add"].Quality)
+	assert.Contains(t, methods["Add"].Quality.Issues, "low_information")
+}
+
+// TestBuildModelKnowsGroupedAndVariadicParameters проверяет, что упоминание
+// параметра из группы "key, value string" и вариативного параметра не
+// считается упоминанием неизвестного параметра и не вызывает повторного запроса
+func TestBuildModelKnowsGroupedAndVariadicParameters(t *testing.T) {
+	projectPath := t.TempDir()
+	source := "package store\n\n" +
+		"// Put сохраняет значение\nfunc Put(key, value string) {}\n\n" +
+		"// Log пишет в журнал\nfunc Log(format string, args ...interface{}) {}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "store.go"), []byte(source), 0644))
+
+	cfg := replayConfig("", "")
+	cfg.LLM.Provider = paramsProviderName
+
+	params.retries = 0
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+	codeMap, err := orch.BuildModel(projectPath)
+	require.NoError(t, err)
+	assert.Zero(t, params.retries)
+
+	methods := methodsByName(codeMap)
+	for name, description := range paramsDescriptions {
+		assert.Equal(t, description, methods[name].Description, name)
+		require.NotNil(t, methods[name].Quality, name)
+		assert.Empty(t, methods[name].Quality.Issues, name)
+	}
+}
@@ -0,0 +1,168 @@
+package tests
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"code-telescope/internal/orchestrator"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// commitProject записывает файлы в проект и создает из них коммит HEAD
+// в директории .git без использования git
+func commitProject(t *testing.T, projectPath string, files map[string]string) {
+	object := func(objectType string, content []byte) []byte {
+		data := append([]byte(fmt.Sprintf("%s %d\x00", objectType, len(content))), content...)
+		sum := sha1.Sum(data)
+		sha := hex.EncodeToString(sum[:])
+
+		var compressed bytes.Buffer
+		writer := zlib.NewWriter(&compressed)
+		_, err := writer.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		dir := filepath.Join(projectPath, ".git", "objects", sha[:2])
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, sha[2:]), compressed.Bytes(), 0644))
+		return sum[:]
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tree []byte
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(projectPath, name), []byte(files[name]), 0644))
+		tree = append(tree, []byte("100644 "+name+"\x00")...)
+		tree = append(tree, object("blob", []byte(files[name]))...)
+	}
+	commit := fmt.Sprintf("tree %x\nauthor a <a@example.com> 0 +0000\ncommitter a <a@example.com> 0 +0000\n\nmessage\n",
+		object("tree", tree))
+	sha := hex.EncodeToString(object("commit", []byte(commit)))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, ".git", "refs", "heads"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, ".git", "refs", "heads", "main"), []byte(sha+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, ".git", "HEAD"), []byte("ref: refs/heads/main\n"), 0644))
+}
+
+// symbolIDs возвращает идентификаторы символов файла модели
+func symbolIDs(t *testing.T, codeMap *models.CodeMap, path string) []string {
+	for _, file := range codeMap.Files {
+		if filepath.ToSlash(file.Path) != path {
+			continue
+		}
+		var ids []string
+		for _, method := range file.Methods {
+			ids = append(ids, method.ID)
+		}
+		for _, typ := range file.Types {
+			ids = append(ids, typ.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	t.Fatalf("файла %s нет в модели", path)
+	return nil
+}
+
+// TestBuildModelSinceRescopesRenamedFile проверяет, что у переименованного
+// файла, взятого из исходной модели, идентификаторы символов соответствуют
+// новому пути
+func TestBuildModelSinceRescopesRenamedFile(t *testing.T) {
+	projectPath := t.TempDir()
+	commitProject(t, projectPath, map[string]string{
+		"util.py": "def helper():\n    return 1\n\n\nclass K:\n    def run(self):\n        return 2\n",
+	})
+
+	cfg := replayConfig("", "")
+	cfg.LLM.Describe = false
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+	base, err := orch.BuildModel(projectPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"py:util.K", "py:util.K.run", "py:util.helper"}, symbolIDs(t, base, "util.py"))
+
+	require.NoError(t, os.Rename(filepath.Join(projectPath, "util.py"), filepath.Join(projectPath, "tools.py")))
+	result, err := orch.BuildModelSince(projectPath, "HEAD", base)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{"util.py": "tools.py"}, result.Changes.Renamed)
+	assert.Equal(t, 1, result.Reused, "переименованный файл не парсится заново")
+	assert.Equal(t, []string{"py:tools.K", "py:tools.K.run", "py:tools.helper"}, symbolIDs(t, result.CodeMap, "tools.py"))
+}
+
+// TestWatchRescopesSymbolIDs проверяет, что в режиме наблюдения
+// идентификаторы символов следуют за переименованием файла и изменением go.mod
+func TestWatchRescopesSymbolIDs(t *testing.T) {
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "go.mod"), []byte("module example.com/app\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "a"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(projectPath, "b"), 0755))
+	source := "package util\n\ntype T struct{}\n\nfunc Helper() {}\n\nfunc (t *T) Run() {}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "a", "util.go"), []byte(source), 0644))
+
+	cfg := replayConfig("", "")
+	cfg.LLM.Describe = false
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+
+	updates := make(chan *models.CodeMap, 16)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- orch.Watch(ctx, projectPath, orchestrator.WatchOptions{
+			Interval: 10 * time.Millisecond,
+			Debounce: 20 * time.Millisecond,
+			OnModel:  func(codeMap *models.CodeMap) { updates <- codeMap },
+		})
+	}()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	// waitFor ожидает модель, в которой у файла path есть символ с префиксом
+	waitFor := func(path, prefix string) []string {
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case codeMap := <-updates:
+				for _, file := range codeMap.Files {
+					if filepath.ToSlash(file.Path) == path && len(file.Types) > 0 && strings.HasPrefix(file.Types[0].ID, prefix) {
+						return symbolIDs(t, codeMap, path)
+					}
+				}
+			case <-deadline:
+				t.Fatalf("нет модели с символами %s в %s", prefix, path)
+			}
+		}
+	}
+
+	assert.Equal(t, []string{"go:example.com/app/a.(*T).Run", "go:example.com/app/a.Helper", "go:example.com/app/a.T"},
+		waitFor("a/util.go", "go:example.com/app/a."))
+
+	require.NoError(t, os.Rename(filepath.Join(projectPath, "a", "util.go"), filepath.Join(projectPath, "b", "util.go")))
+	assert.Equal(t, []string{"go:example.com/app/b.(*T).Run", "go:example.com/app/b.Helper", "go:example.com/app/b.T"},
+		waitFor("b/util.go", "go:example.com/app/b."))
+
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "go.mod"), []byte("module example.com/other\n"), 0644))
+	assert.Equal(t, []string{"go:example.com/other/b.(*T).Run", "go:example.com/other/b.Helper", "go:example.com/other/b.T"},
+		waitFor("b/util.go", "go:example.com/other/b."))
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/llm"
+	"code-telescope/internal/orchestrator"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имя тестового провайдера, отвечающего заранее заданными описаниями
+const scriptedProviderName = "scripted"
+
+// Описания символов, которые возвращает тестовый провайдер
+var scriptedDescriptions = map[string]string{
+	"Add":    "Складывает два целых числа и возвращает их сумму.",
+	"Divide": "Делит a на b; при нулевом делителе возвращает ErrDivisionByZero.",
+	"Push":   "Прибавляет значение к накопленной сумме калькулятора.",
+}
+
+// Строка метода в пакетном промпте: "Метод 1: Add"
+var batchMethodPattern = regexp.MustCompile(`(?m)^Метод (\d+): (\S+)\nСигнатура:`)
+
+// scriptedProvider отвечает на пакетный промпт описаниями из scriptedDescriptions
+type scriptedProvider struct {
+	requests int
+	prompts  []string
+}
+
+func (p *scriptedProvider) Name() string { return scriptedProviderName }
+
+func (p *scriptedProvider) GenerateText(_ context.Context, request llm.LLMRequest) (llm.LLMResponse, error) {
+	p.requests++
+	p.prompts = append(p.prompts, request.System+request.Prompt)
+	var sb strings.Builder
+	for _, match := range batchMethodPattern.FindAllStringSubmatch(request.Prompt, -1) {
+		fmt.Fprintf(&sb, "Метод %s: %s\n", match[1], scriptedDescriptions[match[2]])
+	}
+	return llm.LLMResponse{Text: sb.String(), InputTokens: 100, OutputTokens: 20}, nil
+}
+
+func (p *scriptedProvider) BatchGenerateText(ctx context.Context, requests []llm.LLMRequest) ([]llm.LLMResponse, error) {
+	responses := make([]llm.LLMResponse, 0, len(requests))
+	for _, request := range requests {
+		response, _ := p.GenerateText(ctx, request)
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+var scripted = &scriptedProvider{}
+
+func init() {
+	llm.RegisterProvider(scriptedProviderName, func(map[string]interface{}) (llm.LLMProvider, error) {
+		return scripted, nil
+	})
+}
+
+// replayConfig возвращает конфигурацию с описаниями, без кэша и с кассетой
+func replayConfig(mode, cassette string) *config.Config {
+	cfg := config.DefaultConfig()
+	cfg.LLM.Provider = scriptedProviderName
+	cfg.LLM.APIKey = ""
+	cfg.LLM.Describe = true
+	cfg.LLM.Replay = config.ReplayConfig{Mode: mode, Cassette: cassette}
+	cfg.Cache.Enabled = false
+	cfg.Output.Links.Mode = config.LinkModeNone
+	return cfg
+}
+
+// generate строит карту кода проекта с указанной конфигурацией
+func generate(t *testing.T, cfg *config.Config, projectPath string) string {
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+	codeMap, err := orch.GenerateCodeMap(projectPath)
+	require.NoError(t, err)
+	return codeMap
+}
+
+// TestGenerateReplaysCassette проверяет генерацию карты по сохраненной кассете
+// без обращения к провайдеру ЛЛМ
+func TestGenerateReplaysCassette(t *testing.T) {
+	cfg := replayConfig(config.ReplayModeReplay, filepath.Join("testdata", "calc.cassette.json"))
+	cfg.LLM.Provider = "openai"
+
+	codeMap := generate(t, cfg, filepath.Join("testdata", "calc"))
+
+	for name, description := range scriptedDescriptions {
+		assert.Contains(t, codeMap, description, "описание %s из кассеты", name)
+	}
+}
+
+// TestRecordThenReplay проверяет, что записанная кассета воспроизводит ту же карту
+// кода, а промпты, которых нет в кассете, остаются без описаний
+func TestRecordThenReplay(t *testing.T) {
+	projectPath := t.TempDir()
+	source, err := os.ReadFile(filepath.Join("testdata", "calc", "calc.go"))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "calc.go"), source, 0644))
+	cassettePath := filepath.Join(t.TempDir(), "calc.cassette.json")
+
+	scripted.requests = 0
+	recorded := generate(t, replayConfig(config.ReplayModeRecord, cassettePath), projectPath)
+	require.Positive(t, scripted.requests)
+	assert.Contains(t, recorded, scriptedDescriptions["Divide"])
+
+	cassette, err := llm.OpenCassette(cassettePath)
+	require.NoError(t, err)
+	assert.Equal(t, scripted.requests, cassette.Len())
+
+	requests := scripted.requests
+	replayed := generate(t, replayConfig(config.ReplayModeReplay, cassettePath), projectPath)
+	assert.Equal(t, requests, scripted.requests, "при воспроизведении провайдер не вызывается")
+	assert.Equal(t, recorded, replayed)
+
+	// Новая функция меняет промпт, которого нет в кассете
+	changed := string(source) + "\n// Negate меняет знак числа\nfunc Negate(a int) int {\n\treturn -a\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "calc.go"), []byte(changed), 0644))
+	replayed = generate(t, replayConfig(config.ReplayModeReplay, cassettePath), projectPath)
+	assert.NotContains(t, replayed, scriptedDescriptions["Add"], "пакет с новой функцией отсутствует в кассете")
+}
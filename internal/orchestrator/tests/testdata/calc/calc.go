@@ -0,0 +1,29 @@
+package calc
+
+import "errors"
+
+// ErrDivisionByZero возвращается при делении на ноль
+var ErrDivisionByZero = errors.New("деление на ноль")
+
+// Calculator накапливает сумму чисел
+type Calculator struct {
+	Total int
+}
+
+// Add возвращает сумму двух чисел
+func Add(a, b int) int {
+	return a + b
+}
+
+// Divide делит a на b
+func Divide(a, b int) (int, error) {
+	if b == 0 {
+		return 0, ErrDivisionByZero
+	}
+	return a / b, nil
+}
+
+// Push добавляет число к сумме
+func (c *Calculator) Push(value int) {
+	c.Total += value
+}
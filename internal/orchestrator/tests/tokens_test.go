@@ -0,0 +1,161 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/llm"
+	"code-telescope/internal/orchestrator"
+	"code-telescope/internal/usage"
+	"code-telescope/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Имя тестового провайдера, токенизатор которого дает вдвое больше
+// токенов, чем оценка EstimateTokens
+const denseProviderName = "dense"
+
+// denseProvider сообщает в usage вдвое больше входных токенов, чем оценка
+// EstimateTokens промпта
+type denseProvider struct {
+	prompts []string
+}
+
+func (p *denseProvider) Name() string { return denseProviderName }
+
+func (p *denseProvider) GenerateText(_ context.Context, request llm.LLMRequest) (llm.LLMResponse, error) {
+	prompt := request.FullPrompt()
+	p.prompts = append(p.prompts, prompt)
+	return llm.LLMResponse{Text: "Метод 1: Описание.", InputTokens: 2 * utils.EstimateTokens(prompt), OutputTokens: 5}, nil
+}
+
+func (p *denseProvider) BatchGenerateText(ctx context.Context, requests []llm.LLMRequest) ([]llm.LLMResponse, error) {
+	responses := make([]llm.LLMResponse, 0, len(requests))
+	for _, request := range requests {
+		response, _ := p.GenerateText(ctx, request)
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+var dense = &denseProvider{}
+
+// Имя тестового провайдера, большая часть промпта которого читается из
+// кэша промптов
+const cachedProviderName = "cached"
+
+// cachedProvider отвечает описанием для каждого метода пакета и сообщает,
+// что большая часть промпта прочитана из кэша
+type cachedProvider struct {
+	prompts []string
+}
+
+func (p *cachedProvider) Name() string { return cachedProviderName }
+
+func (p *cachedProvider) GenerateText(_ context.Context, request llm.LLMRequest) (llm.LLMResponse, error) {
+	prompt := request.FullPrompt()
+	p.prompts = append(p.prompts, prompt)
+	var sb strings.Builder
+	for _, match := range batchMethodPattern.FindAllStringSubmatch(request.Prompt, -1) {
+		fmt.Fprintf(&sb, "Метод %s: Описывает функцию %s из пакета calc.\n", match[1], match[2])
+	}
+	tokens := utils.EstimateTokens(prompt)
+	return llm.LLMResponse{Text: sb.String(), InputTokens: tokens / 2, CacheReadTokens: 2 * tokens, OutputTokens: 5}, nil
+}
+
+func (p *cachedProvider) BatchGenerateText(ctx context.Context, requests []llm.LLMRequest) ([]llm.LLMResponse, error) {
+	responses := make([]llm.LLMResponse, 0, len(requests))
+	for _, request := range requests {
+		response, _ := p.GenerateText(ctx, request)
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+var cached = &cachedProvider{}
+
+func init() {
+	llm.RegisterProvider(denseProviderName, func(map[string]interface{}) (llm.LLMProvider, error) {
+		return dense, nil
+	})
+	llm.RegisterProvider(cachedProviderName, func(map[string]interface{}) (llm.LLMProvider, error) {
+		return cached, nil
+	})
+}
+
+// budgetProject создает проект из короткой функции и функции, код которой
+// не помещается в бюджет промпта целиком
+func budgetProject(t *testing.T) string {
+	projectPath := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "a.go"),
+		[]byte("package calc\n\n// Add складывает числа\nfunc Add(a, b int) int {\n\treturn a + b\n}\n"), 0644))
+	var body strings.Builder
+	for i := 0; i < 400; i++ {
+		fmt.Fprintf(&body, "\ttotal += values[%d] * weights[%d]\n", i, i)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(projectPath, "b.go"),
+		[]byte("package calc\n\n// Sum считает взвешенную сумму\nfunc Sum(values, weights []int) int {\n\ttotal := 0\n"+body.String()+"\treturn total\n}\n"), 0644))
+	return projectPath
+}
+
+// TestPromptBudgetCalibratedByUsage проверяет, что после ответа провайдера
+// с usage бюджет промпта пересчитывается по фактическому числу токенов
+func TestPromptBudgetCalibratedByUsage(t *testing.T) {
+	projectPath := budgetProject(t)
+
+	cfg := replayConfig("", "")
+	cfg.LLM.Provider = denseProviderName
+	cfg.LLM.ContextTokens = cfg.LLM.MaxTokens + 2000
+	cfg.LLM.Quality.Enabled = false
+	dense.prompts = nil
+	orch, err := orchestrator.New(cfg, false)
+	require.NoError(t, err)
+	_, err = orch.BuildModel(projectPath)
+	require.NoError(t, err)
+
+	require.Len(t, dense.prompts, 2)
+	assert.Contains(t, dense.prompts[1], "func Sum")
+	assert.LessOrEqual(t, 2*utils.EstimateTokens(dense.prompts[1]), 2000,
+		"промпт укладывается в бюджет по токенам провайдера")
+	assert.Greater(t, 2*utils.EstimateTokens(dense.prompts[1]), 1000,
+		"бюджет используется, а не обрезается сверх поправки")
+}
+
+// TestReplayCalibratesLikeRecording проверяет, что при воспроизведении
+// кассеты поправка оценки токенов учитывает токены кэша промптов так же,
+// как при записи: все промпты воспроизведения находятся в кассете, а расход
+// совпадает с записанным
+func TestReplayCalibratesLikeRecording(t *testing.T) {
+	projectPath := budgetProject(t)
+	cassettePath := filepath.Join(t.TempDir(), "budget.cassette.json")
+
+	run := func(mode string) (string, usage.Report) {
+		cfg := replayConfig(mode, cassettePath)
+		cfg.LLM.Provider = cachedProviderName
+		cfg.LLM.ContextTokens = cfg.LLM.MaxTokens + 2000
+		cfg.LLM.Quality.Enabled = false
+		orch, err := orchestrator.New(cfg, false)
+		require.NoError(t, err)
+		codeMap, err := orch.GenerateCodeMap(projectPath)
+		require.NoError(t, err)
+		return codeMap, orch.Usage()
+	}
+
+	cached.prompts = nil
+	recorded, recordedUsage := run(config.ReplayModeRecord)
+	require.Len(t, cached.prompts, 2)
+	assert.Contains(t, cached.prompts[1], "[код обрезан", "код Sum обрезан по бюджету с учетом токенов кэша")
+
+	replayed, replayedUsage := run(config.ReplayModeReplay)
+	assert.Len(t, cached.prompts, 2, "при воспроизведении провайдер не вызывается")
+	assert.Equal(t, recordedUsage, replayedUsage)
+	assert.Equal(t, recorded, replayed)
+	assert.Contains(t, replayed, "Описывает функцию Sum")
+}
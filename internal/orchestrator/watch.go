@@ -0,0 +1,305 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"code-telescope/internal/filesystem"
+	"code-telescope/internal/logger"
+	"code-telescope/internal/parser"
+	"code-telescope/pkg/models"
+)
+
+// Интервалы наблюдения по умолчанию
+const (
+	DefaultWatchInterval = time.Second
+	DefaultWatchDebounce = 500 * time.Millisecond
+)
+
+// WatchOptions задает параметры режима наблюдения
+type WatchOptions struct {
+	// Путь для сохранения карты кода (пустой — документ не сохраняется)
+	OutputPath string
+
+	// Интервал опроса файловой системы
+	Interval time.Duration
+
+	// Время без изменений, после которого серия изменений обрабатывается
+	Debounce time.Duration
+
+	// Вызывается после каждого обновления карты кода (может быть nil)
+	OnUpdate func(changes filesystem.Changes)
+
+	// Вызывается с моделью карты кода после первоначальной генерации
+	// и каждого обновления (может быть nil)
+	OnModel func(codeMap *models.CodeMap)
+}
+
+// watchedFile хранит результат обработки файла между обновлениями
+type watchedFile struct {
+	structure models.FileStructure
+
+	// Описания символов по ключу symbolKey для повторного использования
+	known map[string]string
+}
+
+// Watch генерирует карту кода и поддерживает ее в актуальном состоянии,
+// опрашивая файловую систему проекта до отмены контекста. Серии изменений
+// объединяются, повторно парсятся только измененные файлы, а описания
+// у ЛЛМ запрашиваются только для символов с новой или измененной сигнатурой.
+func (o *Orchestrator) Watch(ctx context.Context, projectPath string, opts WatchOptions) error {
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWatchInterval
+	}
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultWatchDebounce
+	}
+
+	absProject, err := filepath.Abs(projectPath)
+	if err != nil {
+		return logger.LogError(logger.FileSystemError("ошибка получения абсолютного пути", err))
+	}
+	hash := func(relPath string) (string, error) {
+		return filesystem.HashFile(filepath.Join(absProject, relPath))
+	}
+
+	// Первоначальная генерация
+	files, metadata, err := o.scanParsable(projectPath)
+	if err != nil {
+		return err
+	}
+	current := watchSnapshot(absProject, files)
+	filesystem.Diff(filesystem.Snapshot{}, current, hash)
+
+	parser.ResetModuleCache()
+	o.openCache(projectPath)
+	if err := o.loadOverrides(projectPath); err != nil {
+		return err
+	}
+	o.SetDocumentPath(opts.OutputPath)
+	o.SetLinkRoot(projectPath)
+	state := make(map[string]*watchedFile, len(files))
+	for _, file := range files {
+		o.updateWatched(ctx, state, file, nil)
+	}
+	if err := o.saveWatched(state, projectPath, opts); err != nil {
+		return err
+	}
+	logger.WithField("files_count", len(state)).Info("Режим наблюдения запущен")
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+
+	observed := current
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Режим наблюдения остановлен")
+			return nil
+		case <-ticker.C:
+		}
+
+		scanned, scannedMetadata, err := o.scanParsable(projectPath)
+		if err != nil {
+			logger.WithError(err).Warn("Ошибка при сканировании проекта, повтор на следующем шаге")
+			continue
+		}
+
+		// Каждое новое изменение откладывает обработку на время debounce
+		snapshot := watchSnapshot(absProject, scanned)
+		if !snapshot.SameFiles(observed) {
+			observed = snapshot
+			metadata = scannedMetadata
+			lastChange = time.Now()
+			continue
+		}
+		if lastChange.IsZero() || time.Since(lastChange) < opts.Debounce {
+			continue
+		}
+		lastChange = time.Time{}
+
+		changes := filesystem.Diff(current, observed, hash)
+		current = observed
+		if changes.Empty() {
+			continue
+		}
+
+		o.applyChanges(ctx, state, changes, metadata)
+		if err := o.saveWatched(state, projectPath, opts); err != nil {
+			logger.WithError(err).Warn("Не удалось обновить карту кода")
+			continue
+		}
+		if opts.OnUpdate != nil {
+			opts.OnUpdate(changes)
+		}
+	}
+}
+
+// scanParsable сканирует проект и возвращает файлы с подходящим парсером
+func (o *Orchestrator) scanParsable(projectPath string) ([]*models.FileMetadata, map[string]*models.FileMetadata, error) {
+	scanned, err := o.scanner.ScanProject(projectPath)
+	if err != nil {
+		err = logger.OrchestratorError("ошибка при сканировании проекта", err)
+		return nil, nil, logger.LogError(err)
+	}
+
+	files, _ := o.filterParsable(scanned)
+	metadata := make(map[string]*models.FileMetadata, len(files))
+	for _, file := range files {
+		metadata[file.Path] = file
+	}
+	return files, metadata, nil
+}
+
+// watchSnapshot создает снимок файлов проекта для отслеживания изменений.
+// В снимок входят и файлы go.mod в директориях файлов Go и их родителях
+// внутри проекта: от них зависят идентификаторы символов.
+func watchSnapshot(absProject string, files []*models.FileMetadata) filesystem.Snapshot {
+	snapshot := filesystem.NewSnapshot(files)
+	checked := make(map[string]bool)
+	for _, file := range files {
+		if filepath.Ext(file.Path) != ".go" {
+			continue
+		}
+		for dir := filepath.Dir(file.Path); !checked[dir]; dir = filepath.Dir(dir) {
+			checked[dir] = true
+			modPath := filepath.Join(dir, "go.mod")
+			if info, err := os.Stat(filepath.Join(absProject, modPath)); err == nil {
+				snapshot[modPath] = filesystem.FileState{Size: info.Size(), ModTime: info.ModTime()}
+			}
+			if dir == "." {
+				break
+			}
+		}
+	}
+	return snapshot
+}
+
+// applyChanges обновляет состояние файлов по списку изменений
+func (o *Orchestrator) applyChanges(ctx context.Context, state map[string]*watchedFile, changes filesystem.Changes, metadata map[string]*models.FileMetadata) {
+	logger.WithFields(logger.Fields{
+		"added":    len(changes.Added),
+		"modified": len(changes.Modified),
+		"removed":  len(changes.Removed),
+		"renamed":  len(changes.Renamed),
+	}).Info("Обнаружены изменения в проекте")
+
+	parser.ResetModuleCache()
+	for _, path := range changes.Removed {
+		delete(state, path)
+	}
+
+	// Содержимое переименованного файла не изменилось, поэтому повторный парсинг не нужен:
+	// идентификаторы символов переносятся под новый путь ниже
+	for oldPath, newPath := range changes.Renamed {
+		watched, ok := state[oldPath]
+		delete(state, oldPath)
+		if !ok {
+			if file, ok := metadata[newPath]; ok {
+				o.updateWatched(ctx, state, file, nil)
+			}
+			continue
+		}
+		watched.structure.Path = newPath
+		if file, ok := metadata[newPath]; ok {
+			watched.structure.ModTime = file.ModTime
+		}
+		state[newPath] = watched
+	}
+
+	for _, path := range append(append([]string{}, changes.Added...), changes.Modified...) {
+		file, ok := metadata[path]
+		if !ok {
+			continue
+		}
+		var known map[string]string
+		if previous, ok := state[path]; ok {
+			known = previous.known
+		}
+		o.updateWatched(ctx, state, file, known)
+	}
+
+	// Идентификаторы символов неизмененных файлов переносятся в область
+	// нового пути и текущего go.mod
+	paths := make([]string, 0, len(state))
+	for path := range state {
+		paths = append(paths, path)
+	}
+	for _, path := range paths {
+		file, ok := metadata[path]
+		if !ok {
+			continue
+		}
+		if watched := state[path]; !watched.rescope(parser.SymbolScope(file, watched.structure.Package)) {
+			o.updateWatched(ctx, state, file, watched.known)
+		}
+	}
+}
+
+// rescope переносит идентификаторы символов и ключи известных описаний
+// в область scope. Возвращает false, если файл нужно разобрать заново.
+func (w *watchedFile) rescope(scope string) bool {
+	oldScope, ok := parser.RescopeSymbolIDs(&w.structure, scope)
+	if !ok {
+		return false
+	}
+	if oldScope == scope || oldScope == "" {
+		return true
+	}
+	known := make(map[string]string, len(w.known))
+	for key, description := range w.known {
+		if key, ok := parser.RescopeID(key, oldScope, scope); ok {
+			known[key] = description
+		}
+	}
+	w.known = known
+	return true
+}
+
+// updateWatched обрабатывает файл и сохраняет результат в состоянии наблюдения.
+// Если файл не удалось разобрать, сохраняется предыдущий результат.
+func (o *Orchestrator) updateWatched(ctx context.Context, state map[string]*watchedFile, file *models.FileMetadata, known map[string]string) {
+	codeStructure, fileStructure, err := o.processFile(ctx, file, known)
+	if err != nil {
+		return
+	}
+	state[file.Path] = &watchedFile{
+		structure: fileStructure,
+		known:     knownDescriptions(codeStructure),
+	}
+}
+
+// saveWatched строит модель и документ из текущего состояния, передает модель
+// в OnModel и атомарно сохраняет документ
+func (o *Orchestrator) saveWatched(state map[string]*watchedFile, projectPath string, opts WatchOptions) error {
+	paths := make([]string, 0, len(state))
+	for path := range state {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	fileStructures := make([]models.FileStructure, 0, len(paths))
+	for _, path := range paths {
+		fileStructures = append(fileStructures, state[path].structure)
+	}
+
+	codeMap := models.NewCodeMap(filepath.Base(projectPath), fileStructures)
+	o.saveCache()
+	o.writeRedactionAudit(projectPath)
+	if opts.OnModel != nil {
+		opts.OnModel(codeMap)
+	}
+	if opts.OutputPath == "" {
+		return nil
+	}
+
+	content, err := o.Render(codeMap)
+	if err != nil {
+		return err
+	}
+	return o.SaveCodeMap(content, opts.OutputPath)
+}
@@ -47,6 +47,8 @@ func (p *BaseTreeSitterParser) Parse(fileMetadata *models.FileMetadata) (*models
 		return nil, fmt.Errorf("ошибка чтения файла %s: %w", fileMetadata.Path, err)
 	}
 
+	fileMetadata.LineCount = models.CountLines(content)
+
 	parser := sitter.NewParser()
 	parser.SetLanguage(p.Language)
 
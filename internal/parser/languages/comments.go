@@ -0,0 +1,96 @@
+package languages
+
+import (
+	"strings"
+
+	sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Узлы-обертки, перед которыми стоит комментарий вложенного объявления:
+// export function ..., const fn = () => ..., @decorator def ...
+var docCommentWrappers = map[string]bool{
+	"export_statement":     true,
+	"lexical_declaration":  true,
+	"variable_declaration": true,
+	"variable_declarator":  true,
+	"decorated_definition": true,
+}
+
+// docComment возвращает текст комментариев, непосредственно предшествующих
+// объявлению. Комментарии, отделенные пустой строкой или стоящие в конце
+// строки предыдущего кода, к объявлению не относятся.
+func docComment(node *sitter.Node, content []byte) string {
+	for parent := node.Parent(); parent != nil && docCommentWrappers[parent.Type()]; parent = parent.Parent() {
+		node = parent
+	}
+
+	var comments []string
+	line := node.StartPoint().Row
+	for prev := node.PrevNamedSibling(); prev != nil && prev.Type() == "comment"; prev = prev.PrevNamedSibling() {
+		if prev.EndPoint().Row+1 < line {
+			break
+		}
+		if before := prev.PrevNamedSibling(); before != nil && before.EndPoint().Row == prev.StartPoint().Row {
+			break
+		}
+		comments = append(comments, cleanComment(prev.Content(content)))
+		line = prev.StartPoint().Row
+	}
+
+	// Комментарии собраны снизу вверх
+	for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
+		comments[i], comments[j] = comments[j], comments[i]
+	}
+	return strings.TrimSpace(strings.Join(comments, "\n"))
+}
+
+// cleanComment убирает из комментария маркеры //, #, /* */ и * в начале строк
+func cleanComment(comment string) string {
+	comment = strings.TrimSpace(comment)
+	block := strings.HasPrefix(comment, "/*")
+	if block {
+		comment = strings.TrimSuffix(strings.TrimLeft(comment, "/*"), "*/")
+	}
+
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case block:
+			line = strings.TrimPrefix(line, "*")
+		case strings.HasPrefix(line, "//"):
+			line = strings.TrimPrefix(line, "//")
+		case strings.HasPrefix(line, "#"):
+			line = strings.TrimPrefix(line, "#")
+		}
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// pythonDocstring возвращает строку документации Python — строковый литерал,
+// с которого начинается тело функции или класса
+func pythonDocstring(node *sitter.Node, content []byte) string {
+	body := node.ChildByFieldName("body")
+	if body == nil || body.NamedChildCount() == 0 {
+		return ""
+	}
+	statement := body.NamedChild(0)
+	if statement.Type() != "expression_statement" || statement.NamedChildCount() == 0 || statement.NamedChild(0).Type() != "string" {
+		return ""
+	}
+
+	docstring := strings.TrimLeft(statement.NamedChild(0).Content(content), "rRuUbB")
+	for _, quote := range []string{`"""`, `'''`, `"`, `'`} {
+		if strings.HasPrefix(docstring, quote) && strings.HasSuffix(docstring, quote) && len(docstring) >= 2*len(quote) {
+			docstring = docstring[len(quote) : len(docstring)-len(quote)]
+			break
+		}
+	}
+
+	lines := strings.Split(docstring, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -11,6 +11,13 @@ import (
 	"code-telescope/pkg/models"
 )
 
+func init() {
+	// Регистрация парсера
+	parser.RegisterParser("Go", []string{".go"}, func(cfg *config.Config) parser.Parser {
+		return NewGoParser(cfg)
+	})
+}
+
 // GetGoLanguage возвращает язык Go для tree-sitter
 func GetGoLanguage() *sitter.Language {
 	return golang_ts.GetLanguage()
@@ -19,6 +26,7 @@ func GetGoLanguage() *sitter.Language {
 // GoParser реализует парсер для языка Go
 type GoParser struct {
 	parser.BaseTreeSitterParser
+	treeParser *parser.TreeSitterParser
 }
 
 // NewGoParser создает новый экземпляр парсера Go
@@ -26,9 +34,18 @@ func NewGoParser(cfg *config.Config) *GoParser {
 	language := GetGoLanguage()
 	extensions := []string{".go"}
 
-	return &GoParser{
+	goParser := &GoParser{
 		BaseTreeSitterParser: *parser.NewBaseTreeSitterParser(cfg, language, extensions, "Go"),
 	}
+	// Базовый парсер не может вызвать переопределенный ParseTreeNode,
+	// поэтому разбор выполняется через TreeSitterParser, как в остальных языках
+	goParser.treeParser = parser.NewTreeSitterParser(language, goParser.ParseTreeNode)
+	return goParser
+}
+
+// Parse разбирает файл Go и извлекает его структуру
+func (p *GoParser) Parse(fileMetadata *models.FileMetadata) (*models.CodeStructure, error) {
+	return p.treeParser.Parse(fileMetadata)
 }
 
 // ParseTreeNode разбирает узлы дерева Go кода
@@ -48,7 +65,11 @@ func (p *GoParser) ParseTreeNode(node *sitter.Node, structure *models.CodeStruct
 
 		switch nodeType {
 		case "package_clause":
-			// Обработать объявление пакета
+			for i := 0; i < int(current.NamedChildCount()); i++ {
+				if child := current.NamedChild(i); child.Type() == "package_identifier" {
+					structure.Package = child.Content(content)
+				}
+			}
 		case "import_declaration":
 			p.parseImport(current, structure, content)
 		case "function_declaration":
@@ -158,11 +179,14 @@ func (p *GoParser) parseFunction(node *sitter.Node, structure *models.CodeStruct
 		IsIIFE:      isIIFE,
 		Parameters:  params,
 		ReturnType:  returnType,
+		Doc:         docComment(node, content),
 		Position: models.Position{
 			StartLine:   startLine + 1,
 			StartColumn: startCol + 1,
 			EndLine:     endLine + 1,
 			EndColumn:   endCol + 1,
+			StartByte:   int(node.StartByte()),
+			EndByte:     int(node.EndByte()),
 		},
 	}
 	structure.AddFunction(fn)
@@ -187,10 +211,11 @@ func (p *GoParser) parseMethod(node *sitter.Node, structure *models.CodeStructur
 	// Извлекаем тип, к которому привязан метод
 	receiverNode := node.ChildByFieldName("receiver")
 	var belongsTo string
+	var pointerReceiver bool
 
 	if receiverNode != nil {
 		// Извлекаем имя типа
-		belongsTo = p.parseReceiverType(receiverNode, content)
+		belongsTo, pointerReceiver = p.parseReceiverType(receiverNode, content)
 	}
 
 	// Извлекаем параметры
@@ -210,22 +235,26 @@ func (p *GoParser) parseMethod(node *sitter.Node, structure *models.CodeStructur
 	endCol := int(node.EndPoint().Column)
 
 	method := &models.Method{
-		Name:          name,
-		IsPublic:      isPublic,
-		IsStatic:      isStatic,
-		IsAsync:       isAsync,
-		IsGenerator:   isGenerator,
-		IsDecorator:   isDecorator,
-		IsConstructor: isConstructor,
-		Kind:          kind,
-		BelongsTo:     belongsTo,
-		Parameters:    params,
-		ReturnType:    returnType,
+		Name:              name,
+		IsPublic:          isPublic,
+		IsStatic:          isStatic,
+		IsAsync:           isAsync,
+		IsGenerator:       isGenerator,
+		IsDecorator:       isDecorator,
+		IsConstructor:     isConstructor,
+		Kind:              kind,
+		BelongsTo:         belongsTo,
+		IsPointerReceiver: pointerReceiver,
+		Parameters:        params,
+		ReturnType:        returnType,
+		Doc:               docComment(node, content),
 		Position: models.Position{
 			StartLine:   startLine + 1,
 			StartColumn: startCol + 1,
 			EndLine:     endLine + 1,
 			EndColumn:   endCol + 1,
+			StartByte:   int(node.StartByte()),
+			EndByte:     int(node.EndByte()),
 		},
 	}
 	structure.AddMethod(method)
@@ -248,6 +277,9 @@ func (p *GoParser) parseType(node *sitter.Node, structure *models.CodeStructure,
 		if current.Type() == "type_spec" {
 			nameNode := current.ChildByFieldName("name")
 			if nameNode == nil {
+				if !cursor.GoToNextSibling() {
+					break
+				}
 				continue
 			}
 
@@ -294,11 +326,14 @@ func (p *GoParser) parseType(node *sitter.Node, structure *models.CodeStructure,
 				GenericParameters: genericParameters,
 				Properties:        properties,
 				Methods:           methods,
+				Doc:               typeDoc(current, node, content),
 				Position: models.Position{
 					StartLine:   int(current.StartPoint().Row) + 1,
 					StartColumn: int(current.StartPoint().Column) + 1,
 					EndLine:     int(current.EndPoint().Row) + 1,
 					EndColumn:   int(current.EndPoint().Column) + 1,
+					StartByte:   int(current.StartByte()),
+					EndByte:     int(current.EndByte()),
 				},
 			}
 
@@ -321,54 +356,47 @@ func (p *GoParser) parseVariable(node *sitter.Node, structure *models.CodeStruct
 	// Реализация извлечения переменных (заглушка)
 }
 
-// parseParameters извлекает параметры функции/метода
+// parseParameters извлекает параметры функции/метода. Параметры группы
+// "a, b int" становятся отдельными параметрами с общим типом, у параметров
+// без имени сохраняется только тип, тип вариативного параметра записывается
+// как в исходном коде: "...T".
 func (p *GoParser) parseParameters(node *sitter.Node, content []byte) []*models.Parameter {
 	var parameters []*models.Parameter
 
-	cursor := sitter.NewTreeCursor(node)
-	defer cursor.Close()
-
-	if !cursor.GoToFirstChild() {
-		return parameters
-	}
-
-	for {
-		current := cursor.CurrentNode()
-
-		if current.Type() == "parameter_declaration" {
-			nameNode := current.ChildByFieldName("name")
-			typeNode := current.ChildByFieldName("type")
-
-			if nameNode != nil && typeNode != nil {
-				name := string(content[nameNode.StartByte():nameNode.EndByte()])
-				typeName := string(content[typeNode.StartByte():typeNode.EndByte()])
-				isRequired := true // В Go все параметры обязательны
-				isVariadic := false
-				isDestructuredObject := false
-				isDestructuredArray := false
-
-				// Проверяем на вариативные параметры (...)
-				if strings.HasPrefix(typeName, "...") {
-					isVariadic = true
-					typeName = strings.TrimPrefix(typeName, "...")
-				}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		declaration := node.NamedChild(i)
+		isVariadic := declaration.Type() == "variadic_parameter_declaration"
+		if declaration.Type() != "parameter_declaration" && !isVariadic {
+			continue
+		}
 
-				param := &models.Parameter{
-					Name:                 name,
-					Type:                 typeName,
-					IsRequired:           isRequired,
-					DefaultValue:         "", // Go не поддерживает значения по умолчанию
-					IsVariadic:           isVariadic,
-					IsDestructuredObject: isDestructuredObject,
-					IsDestructuredArray:  isDestructuredArray,
-				}
+		typeNode := declaration.ChildByFieldName("type")
+		if typeNode == nil {
+			continue
+		}
+		typeName := typeNode.Content(content)
+		if isVariadic {
+			typeName = "..." + typeName
+		}
 
-				parameters = append(parameters, param)
+		// В группе "a, b int" у объявления несколько полей name
+		var names []string
+		for j := 0; j < int(declaration.ChildCount()); j++ {
+			if declaration.FieldNameForChild(j) == "name" {
+				names = append(names, declaration.Child(j).Content(content))
 			}
 		}
+		if len(names) == 0 {
+			names = []string{""}
+		}
 
-		if !cursor.GoToNextSibling() {
-			break
+		for _, name := range names {
+			parameters = append(parameters, &models.Parameter{
+				Name:       name,
+				Type:       typeName,
+				IsRequired: true, // В Go все параметры обязательны
+				IsVariadic: isVariadic,
+			})
 		}
 	}
 
@@ -384,13 +412,14 @@ func (p *GoParser) parseResultType(node *sitter.Node, content []byte) string {
 	return ""
 }
 
-// parseReceiverType извлекает тип получателя метода
-func (p *GoParser) parseReceiverType(node *sitter.Node, content []byte) string {
+// parseReceiverType извлекает тип получателя метода и признак того,
+// что получатель — указатель
+func (p *GoParser) parseReceiverType(node *sitter.Node, content []byte) (string, bool) {
 	cursor := sitter.NewTreeCursor(node)
 	defer cursor.Close()
 
 	if !cursor.GoToFirstChild() {
-		return ""
+		return "", false
 	}
 
 	for {
@@ -401,8 +430,9 @@ func (p *GoParser) parseReceiverType(node *sitter.Node, content []byte) string {
 			if typeNode != nil {
 				typeName := string(content[typeNode.StartByte():typeNode.EndByte()])
 				// Удаляем символы указателя, если есть
+				pointer := strings.HasPrefix(typeName, "*")
 				typeName = strings.TrimPrefix(typeName, "*")
-				return typeName
+				return typeName, pointer
 			}
 		}
 
@@ -411,13 +441,21 @@ func (p *GoParser) parseReceiverType(node *sitter.Node, content []byte) string {
 		}
 	}
 
-	return ""
+	return "", false
 }
 
 // parseStructFields извлекает поля структуры
 func (p *GoParser) parseStructFields(node *sitter.Node, content []byte) []*models.Property {
 	var properties []*models.Property
 
+	// Поля структуры находятся внутри field_declaration_list
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		if child := node.NamedChild(i); child.Type() == "field_declaration_list" {
+			node = child
+			break
+		}
+	}
+
 	cursor := sitter.NewTreeCursor(node)
 	defer cursor.Close()
 
@@ -469,6 +507,15 @@ func (p *GoParser) parseStructFields(node *sitter.Node, content []byte) []*model
 	return properties
 }
 
+// typeDoc возвращает комментарий типа: перед спецификацией внутри группы
+// type (...) либо перед объявлением type с единственной спецификацией
+func typeDoc(spec, declaration *sitter.Node, content []byte) string {
+	if doc := docComment(spec, content); doc != "" || declaration.NamedChildCount() > 1 {
+		return doc
+	}
+	return docComment(declaration, content)
+}
+
 // isPublicName проверяет, является ли имя публичным по правилам Go
 func isPublicName(name string) bool {
 	if len(name) == 0 {
@@ -283,11 +283,14 @@ func (p *JavaScriptParser) parseFunction(node *sitter.Node, structure *models.Co
 			IsIIFE:      isIIFE,
 			Parameters:  params,
 			ReturnType:  "", // В JS тип возвращаемого значения обычно не указывается статически
+			Doc:         docComment(node, content),
 			Position: models.Position{
 				StartLine:   startLine + 1,
 				StartColumn: startCol + 1,
 				EndLine:     endLine + 1,
 				EndColumn:   endCol + 1,
+				StartByte:   int(node.StartByte()),
+				EndByte:     int(node.EndByte()),
 			},
 		}
 		structure.AddFunction(fn)
@@ -331,11 +334,14 @@ func (p *JavaScriptParser) parseClass(node *sitter.Node, structure *models.CodeS
 		Parent:            parent,
 		Implements:        implements,
 		GenericParameters: make([]string, 0),
+		Doc:               docComment(node, content),
 		Position: models.Position{
 			StartLine:   startLine + 1,
 			StartColumn: startCol + 1,
 			EndLine:     endLine + 1,
 			EndColumn:   endCol + 1,
+			StartByte:   int(node.StartByte()),
+			EndByte:     int(node.EndByte()),
 		},
 		Methods:    make([]*models.Method, 0),
 		Properties: make([]*models.Property, 0),
@@ -435,11 +441,14 @@ func (p *JavaScriptParser) parseMethod(node *sitter.Node, classModel *models.Typ
 		BelongsTo:     classModel.Name,
 		Parameters:    params,
 		ReturnType:    "", // Не извлекаем для JS
+		Doc:           docComment(node, content),
 		Position: models.Position{
 			StartLine:   startLine + 1,
 			StartColumn: startCol + 1,
 			EndLine:     endLine + 1,
 			EndColumn:   endCol + 1,
+			StartByte:   int(node.StartByte()),
+			EndByte:     int(node.EndByte()),
 		},
 	}
 	classModel.Methods = append(classModel.Methods, method)
@@ -526,6 +535,7 @@ func (p *JavaScriptParser) parseVariableOrLexicalDeclaration(node *sitter.Node,
 							Name:       name,
 							IsPublic:   true, // В JS всё публичное по умолчанию
 							Parameters: p.parseParameters(findFirstChildOfType(valueNode, "formal_parameters"), content),
+							Doc:        docComment(declarator, content),
 							Position:   getNodePosition(declarator),
 						})
 
@@ -655,5 +665,7 @@ func getNodePosition(node *sitter.Node) models.Position {
 		StartColumn: startCol + 1,
 		EndLine:     endLine + 1,
 		EndColumn:   endCol + 1,
+		StartByte:   int(node.StartByte()),
+		EndByte:     int(node.EndByte()),
 	}
 }
@@ -91,60 +91,26 @@ func (p *PythonParser) ParseTreeNode(node *sitter.Node, structure *models.CodeSt
 	return nil
 }
 
-// parseImport извлекает импорты
+// parseImport извлекает импорты `import module [as alias]`
 func (p *PythonParser) parseImport(node *sitter.Node, structure *models.CodeStructure, content []byte) {
-	cursor := sitter.NewTreeCursor(node)
-	defer cursor.Close()
-
-	if !cursor.GoToFirstChild() {
-		return
-	}
-
-	for {
-		currentNode := cursor.CurrentNode()
-		alias := ""
-		path := ""
-		isDynamic := false
-		isNamespace := false
-		isTypeImport := false
-
-		if currentNode.Type() == "dotted_name" {
-			path = currentNode.Content(content)
-			if cursor.GoToNextSibling() && cursor.CurrentNode().Type() == "as" {
-				if cursor.GoToNextSibling() && cursor.CurrentNode().Type() == "identifier" {
-					alias = cursor.CurrentNode().Content(content)
-				}
-			} else {
-				cursor.GoToParent()
-				cursor.GoToNextSibling()
-			}
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		switch child.Type() {
+		case "dotted_name":
 			structure.AddImport(&models.Import{
-				Path:         path,
-				Alias:        alias,
-				IsDynamic:    isDynamic,
-				IsNamespace:  isNamespace,
-				IsTypeImport: isTypeImport,
-				Position:     getNodePosition(currentNode),
+				Path:     child.Content(content),
+				Position: getNodePosition(child),
 			})
-		} else if currentNode.Type() == "aliased_import" {
-			nameNode := currentNode.ChildByFieldName("name")
-			aliasNode := currentNode.ChildByFieldName("alias")
+		case "aliased_import":
+			nameNode := child.ChildByFieldName("name")
+			aliasNode := child.ChildByFieldName("alias")
 			if nameNode != nil && aliasNode != nil {
 				structure.AddImport(&models.Import{
-					Path:         nameNode.Content(content),
-					Alias:        aliasNode.Content(content),
-					IsDynamic:    isDynamic,
-					IsNamespace:  isNamespace,
-					IsTypeImport: isTypeImport,
-					Position:     getNodePosition(currentNode),
+					Path:     nameNode.Content(content),
+					Alias:    aliasNode.Content(content),
+					Position: getNodePosition(child),
 				})
 			}
-		} else if currentNode.Type() == "," {
-			continue
-		}
-
-		if !cursor.GoToNextSibling() {
-			break
 		}
 	}
 }
@@ -157,70 +123,36 @@ func (p *PythonParser) parseImportFrom(node *sitter.Node, structure *models.Code
 	}
 	modulePath := moduleNameNode.Content(content)
 
-	cursor := sitter.NewTreeCursor(node)
-	defer cursor.Close()
-	if !cursor.GoToFirstChild() {
-		return
-	}
-
-	foundImportKeyword := false
-	for {
-		currentNode := cursor.CurrentNode()
-		if currentNode.Type() == "import" {
-			foundImportKeyword = true
-			continue
-		}
-		if !foundImportKeyword {
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		// Имя модуля тоже является dotted_name, пропускаем его
+		if child.StartByte() == moduleNameNode.StartByte() && child.EndByte() == moduleNameNode.EndByte() {
 			continue
 		}
 
-		if currentNode.Type() == "wildcard_import" {
+		switch child.Type() {
+		case "wildcard_import":
 			structure.AddImport(&models.Import{
-				Path:         modulePath + ".*",
-				Alias:        "*",
-				IsDynamic:    false,
-				IsNamespace:  true,
-				IsTypeImport: false,
-				Position:     getNodePosition(currentNode),
+				Path:        modulePath + ".*",
+				Alias:       "*",
+				IsNamespace: true,
+				Position:    getNodePosition(child),
 			})
-		} else if currentNode.Type() == "dotted_name" {
-			name := currentNode.Content(content)
-			alias := ""
-			if cursor.GoToNextSibling() && cursor.CurrentNode().Type() == "as" {
-				if cursor.GoToNextSibling() && cursor.CurrentNode().Type() == "identifier" {
-					alias = cursor.CurrentNode().Content(content)
-				}
-			} else {
-				cursor.GoToParent()
-				cursor.GoToNextSibling()
-			}
+		case "dotted_name":
 			structure.AddImport(&models.Import{
-				Path:         modulePath + "." + name,
-				Alias:        alias,
-				IsDynamic:    false,
-				IsNamespace:  false,
-				IsTypeImport: false,
-				Position:     getNodePosition(currentNode),
+				Path:     modulePath + "." + child.Content(content),
+				Position: getNodePosition(child),
 			})
-		} else if currentNode.Type() == "aliased_import" {
-			nameNode := currentNode.ChildByFieldName("name")
-			aliasNode := currentNode.ChildByFieldName("alias")
+		case "aliased_import":
+			nameNode := child.ChildByFieldName("name")
+			aliasNode := child.ChildByFieldName("alias")
 			if nameNode != nil && aliasNode != nil {
 				structure.AddImport(&models.Import{
-					Path:         modulePath + "." + nameNode.Content(content),
-					Alias:        aliasNode.Content(content),
-					IsDynamic:    false,
-					IsNamespace:  false,
-					IsTypeImport: false,
-					Position:     getNodePosition(currentNode),
+					Path:     modulePath + "." + nameNode.Content(content),
+					Alias:    aliasNode.Content(content),
+					Position: getNodePosition(child),
 				})
 			}
-		} else if currentNode.Type() == "(" || currentNode.Type() == ")" || currentNode.Type() == "," {
-			continue
-		}
-
-		if !cursor.GoToNextSibling() {
-			break
 		}
 	}
 }
@@ -266,6 +198,12 @@ func (p *PythonParser) parseFunctionOrMethod(node *sitter.Node, structure *model
 	endLine := int(node.EndPoint().Row)
 	endCol := int(node.EndPoint().Column)
 
+	// Строка документации, а без нее — комментарии перед определением
+	doc := pythonDocstring(node, content)
+	if doc == "" {
+		doc = docComment(node, content)
+	}
+
 	if classModel != nil {
 		// Это метод класса
 		method := &models.Method{
@@ -279,11 +217,14 @@ func (p *PythonParser) parseFunctionOrMethod(node *sitter.Node, structure *model
 			BelongsTo:     classModel.Name,
 			Parameters:    params,
 			ReturnType:    returnType,
+			Doc:           doc,
 			Position: models.Position{
 				StartLine:   startLine + 1,
 				StartColumn: startCol + 1,
 				EndLine:     endLine + 1,
 				EndColumn:   endCol + 1,
+				StartByte:   int(node.StartByte()),
+				EndByte:     int(node.EndByte()),
 			},
 		}
 		classModel.Methods = append(classModel.Methods, method)
@@ -298,11 +239,14 @@ func (p *PythonParser) parseFunctionOrMethod(node *sitter.Node, structure *model
 			IsIIFE:      isIIFE,
 			Parameters:  params,
 			ReturnType:  returnType,
+			Doc:         doc,
 			Position: models.Position{
 				StartLine:   startLine + 1,
 				StartColumn: startCol + 1,
 				EndLine:     endLine + 1,
 				EndColumn:   endCol + 1,
+				StartByte:   int(node.StartByte()),
+				EndByte:     int(node.EndByte()),
 			},
 		}
 		structure.AddFunction(fn)
@@ -364,6 +308,11 @@ func (p *PythonParser) parseClass(node *sitter.Node, structure *models.CodeStruc
 	endLine := int(node.EndPoint().Row)
 	endCol := int(node.EndPoint().Column)
 
+	doc := pythonDocstring(node, content)
+	if doc == "" {
+		doc = docComment(node, content)
+	}
+
 	classModel := &models.Type{
 		Name:              className,
 		IsPublic:          isPublic,
@@ -376,11 +325,14 @@ func (p *PythonParser) parseClass(node *sitter.Node, structure *models.CodeStruc
 		Parent:            parent,
 		Implements:        implements,
 		GenericParameters: make([]string, 0),
+		Doc:               doc,
 		Position: models.Position{
 			StartLine:   startLine + 1,
 			StartColumn: startCol + 1,
 			EndLine:     endLine + 1,
 			EndColumn:   endCol + 1,
+			StartByte:   int(node.StartByte()),
+			EndByte:     int(node.EndByte()),
 		},
 		Methods:    make([]*models.Method, 0),
 		Properties: make([]*models.Property, 0),
@@ -552,6 +504,9 @@ func (p *PythonParser) parseParameters(node *sitter.Node, content []byte) []*mod
 			}
 			isRequired = false
 		} else if nodeType == "," || nodeType == "(" || nodeType == ")" {
+			if !cursor.GoToNextSibling() {
+				break
+			}
 			continue
 		}
 
@@ -548,3 +548,44 @@ func (er *EnhancedReader) ReadAll() ([]byte, error) {
 	assert.NotNil(t, readAllMethod, "Метод ReadAll должен быть извлечен")
 	assert.Equal(t, "[]byte, error", readAllMethod.ReturnType, "Метод ReadAll должен возвращать ([]byte, error)")
 }
+
+// TestGoParserDocCommentsAndSpans проверяет извлечение документирующих
+// комментариев и смещений символов в байтах
+func TestGoParserDocCommentsAndSpans(t *testing.T) {
+	content := `package example
+
+// Counter считает события.
+// Безопасен только в одной горутине.
+type Counter struct {
+	n int
+}
+
+var total int // не документация
+
+// Inc увеличивает счетчик
+func (c *Counter) Inc() {
+	c.n++
+}
+
+// Комментарий, отделенный пустой строкой
+
+func Reset() {}
+`
+	tmpfile, _ := createTempFile(t, content, ".go")
+	defer os.Remove(tmpfile.Name())
+
+	structure, err := languages.NewGoParser(config.DefaultConfig()).Parse(createFileMetadata(t, tmpfile.Name()))
+	assert.NoError(t, err)
+
+	if assert.Len(t, structure.Types, 1) {
+		assert.Equal(t, "Counter считает события.\nБезопасен только в одной горутине.", structure.Types[0].Doc)
+	}
+	if assert.Len(t, structure.Methods, 1) {
+		inc := structure.Methods[0]
+		assert.Equal(t, "Inc увеличивает счетчик", inc.Doc)
+		assert.Equal(t, "func (c *Counter) Inc() {\n\tc.n++\n}", content[inc.Position.StartByte:inc.Position.EndByte])
+	}
+	if assert.Len(t, structure.Functions, 1) {
+		assert.Empty(t, structure.Functions[0].Doc, "комментарий отделен пустой строкой")
+	}
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/parser/languages"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeProjectFile записывает файл проекта и возвращает его метаданные
+func writeProjectFile(t *testing.T, projectPath, relPath, content string) *models.FileMetadata {
+	path := filepath.Join(projectPath, relPath)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	metadata, err := models.NewFileMetadata(path, projectPath)
+	require.NoError(t, err)
+	return metadata
+}
+
+// TestGoSymbolIDs проверяет идентификаторы символов Go: путь импорта пакета
+// из go.mod, получатели-указатели и одноименные методы разных типов
+func TestGoSymbolIDs(t *testing.T) {
+	projectPath := t.TempDir()
+	writeProjectFile(t, projectPath, "go.mod", "module example.com/shop\n\ngo 1.23\n")
+	metadata := writeProjectFile(t, projectPath, "internal/cart/cart.go", `package cart
+
+type Cart struct {
+	Items []string
+}
+
+type Item struct{}
+
+type Stack[T any] struct{}
+
+func NewCart() *Cart { return &Cart{} }
+
+func (c *Cart) String() string { return "" }
+
+func (i Item) String() string { return "" }
+
+func (s *Stack[T]) Push(v T) {}
+`)
+
+	structure, err := languages.NewGoParser(config.DefaultConfig()).Parse(metadata)
+	require.NoError(t, err)
+
+	ids := make(map[string]bool)
+	for _, fn := range structure.Functions {
+		ids[fn.ID] = true
+	}
+	for _, method := range structure.Methods {
+		ids[method.ID] = true
+	}
+	for _, typ := range structure.Types {
+		ids[typ.ID] = true
+		for _, property := range typ.Properties {
+			ids[property.ID] = true
+		}
+	}
+
+	for _, id := range []string{
+		"go:example.com/shop/internal/cart.NewCart",
+		"go:example.com/shop/internal/cart.(*Cart).String",
+		"go:example.com/shop/internal/cart.Item.String",
+		"go:example.com/shop/internal/cart.(*Stack).Push",
+		"go:example.com/shop/internal/cart.Cart",
+		"go:example.com/shop/internal/cart.Cart.Items",
+	} {
+		assert.True(t, ids[id], "нет идентификатора %s среди %v", id, ids)
+	}
+}
+
+// TestPythonAndJavaScriptSymbolIDs проверяет идентификаторы символов модулей
+// Python и JavaScript
+func TestPythonAndJavaScriptSymbolIDs(t *testing.T) {
+	projectPath := t.TempDir()
+	cfg := config.DefaultConfig()
+
+	pyMetadata := writeProjectFile(t, projectPath, "shop/cart.py", `class Cart:
+    def total(self):
+        return 0
+
+def make_cart():
+    return Cart()
+`)
+	structure, err := languages.NewPythonParser(cfg).Parse(pyMetadata)
+	require.NoError(t, err)
+	require.NotEmpty(t, structure.Functions)
+	require.NotEmpty(t, structure.Types)
+	assert.Equal(t, "py:shop.cart.make_cart", structure.Functions[0].ID)
+	assert.Equal(t, "py:shop.cart.Cart", structure.Types[0].ID)
+	require.NotEmpty(t, structure.Types[0].Methods)
+	assert.Equal(t, "py:shop.cart.Cart.total", structure.Types[0].Methods[0].ID)
+
+	jsMetadata := writeProjectFile(t, projectPath, "src/cart.js", `export class Cart {
+  total() { return 0; }
+}
+
+export function makeCart() { return new Cart(); }
+`)
+	structure, err = languages.NewJavaScriptParser(cfg).Parse(jsMetadata)
+	require.NoError(t, err)
+	require.NotEmpty(t, structure.Functions)
+	require.NotEmpty(t, structure.Types)
+	assert.Equal(t, "js:src/cart.makeCart", structure.Functions[0].ID)
+	require.NotEmpty(t, structure.Types[0].Methods)
+	assert.Equal(t, "js:src/cart.Cart.total", structure.Types[0].Methods[0].ID)
+}
+
+// TestGoSymbolIDsWithoutModule проверяет, что без go.mod идентификаторы не
+// зависят от директории, в которую извлечен проект
+func TestGoSymbolIDsWithoutModule(t *testing.T) {
+	goParser := languages.NewGoParser(config.DefaultConfig())
+	for _, projectPath := range []string{t.TempDir(), t.TempDir()} {
+		structure, err := goParser.Parse(writeProjectFile(t, projectPath, "main.go", "package main\n\nfunc Run(a int) {}\n"))
+		require.NoError(t, err)
+		require.NotEmpty(t, structure.Functions)
+		assert.Equal(t, "main", structure.Package)
+		assert.Equal(t, "go:main.Run", structure.Functions[0].ID)
+
+		structure, err = goParser.Parse(writeProjectFile(t, projectPath, "internal/calc/calc.go", "package calc\n\nfunc Add(a, b int) int { return a + b }\n"))
+		require.NoError(t, err)
+		require.NotEmpty(t, structure.Functions)
+		assert.Equal(t, "go:internal/calc.Add", structure.Functions[0].ID)
+	}
+}
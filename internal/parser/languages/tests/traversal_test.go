@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/parser"
+	"code-telescope/internal/parser/languages"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGoParserFromFactory проверяет, что парсер Go, полученный из фабрики,
+// разбирает файл через ParseTreeNode и не зацикливается на группе типов
+// с комментарием внутри
+func TestGoParserFromFactory(t *testing.T) {
+	content := `package shapes
+
+type (
+	// Point — точка на плоскости
+	Point struct {
+		X, Y int
+	}
+
+	Size int
+)
+
+func Area(s Size) int { return int(s) * int(s) }
+`
+	tmpfile, _ := createTempFile(t, content, ".go")
+	defer os.Remove(tmpfile.Name())
+
+	goParser, err := parser.NewLanguageFactory(config.DefaultConfig()).GetParserForFile(tmpfile.Name())
+	require.NoError(t, err)
+	structure, err := goParser.Parse(createFileMetadata(t, tmpfile.Name()))
+	require.NoError(t, err)
+
+	require.Len(t, structure.Functions, 1)
+	assert.Equal(t, "Area", structure.Functions[0].Name)
+
+	var types []string
+	for _, typ := range structure.Types {
+		types = append(types, typ.Name)
+	}
+	assert.ElementsMatch(t, []string{"Point", "Size"}, types)
+}
+
+// TestPythonParserImportsAndParameters проверяет разбор импортов через
+// запятую, с псевдонимами и в скобках, а также параметров функции
+func TestPythonParserImportsAndParameters(t *testing.T) {
+	content := `import os, sys as system
+from collections import (OrderedDict, defaultdict as dd)
+from typing import *
+
+
+def build(name, size=1, *args, **kwargs):
+    return name
+`
+	tmpfile, _ := createTempFile(t, content, ".py")
+	defer os.Remove(tmpfile.Name())
+
+	structure, err := languages.NewPythonParser(config.DefaultConfig()).Parse(createFileMetadata(t, tmpfile.Name()))
+	require.NoError(t, err)
+
+	imports := make(map[string]string)
+	for _, imp := range structure.Imports {
+		imports[imp.Path] = imp.Alias
+	}
+	assert.Equal(t, map[string]string{
+		"os":                      "",
+		"sys":                     "system",
+		"collections.OrderedDict": "",
+		"collections.defaultdict": "dd",
+		"typing.*":                "*",
+	}, imports)
+
+	require.Len(t, structure.Functions, 1)
+	var params []string
+	for _, param := range structure.Functions[0].Parameters {
+		params = append(params, param.Name)
+	}
+	assert.Len(t, params, 4, "параметры: %v", params)
+	assert.Contains(t, params, "name")
+}
@@ -0,0 +1,273 @@
+package parser
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"code-telescope/pkg/models"
+)
+
+// Параметры типа в имени получателя метода Go: Stack[T] -> Stack
+var typeParametersPattern = regexp.MustCompile(`\[.*\]$`)
+
+// Префиксы идентификаторов символов по расширению файла
+var symbolIDLanguages = map[string]string{
+	".go":  "go",
+	".py":  "py",
+	".js":  "js",
+	".jsx": "js",
+	".mjs": "js",
+	".cjs": "js",
+	".ts":  "ts",
+	".tsx": "ts",
+}
+
+// Суффикс повторяющегося идентификатора в файле: #2, #3 и т.д.
+var duplicateSuffixPattern = regexp.MustCompile(`#\d+$`)
+
+// Пути модулей Go по директориям go.mod; пустая строка означает, что
+// go.mod в директории нет. Кэш очищается в начале каждой сборки модели
+// (см. ResetModuleCache).
+var goModules sync.Map
+
+// AssignSymbolIDs заполняет стабильные идентификаторы символов структуры
+// кода. Идентификатор состоит из префикса языка и квалифицированного имени:
+//
+//	go:<путь импорта пакета>.Func, go:<пакет>.(*Type).Method, go:<пакет>.Type.Field
+//	py:<модуль через точку>.Class.method
+//	js:<путь к файлу без расширения>.Class.method
+//
+// Путь импорта пакета Go определяется по ближайшему go.mod; если его нет,
+// используется директория файла относительно корня проекта, а для файлов
+// в корне — имя пакета из объявления package. Символы с
+// одинаковым идентификатором в одном файле (например, геттер и сеттер)
+// получают суффикс #2, #3 и т.д. в порядке объявления.
+func AssignSymbolIDs(structure *models.CodeStructure) {
+	if structure == nil || structure.Metadata == nil {
+		return
+	}
+	scope := SymbolScope(structure.Metadata, structure.Package)
+	seen := make(map[string]int)
+	unique := func(id string) string {
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			return id + "#" + strconv.Itoa(n)
+		}
+		return id
+	}
+
+	for _, fn := range structure.Functions {
+		fn.ID = unique(scope + "." + fn.Name)
+	}
+	for _, method := range structure.Methods {
+		method.ID = unique(methodID(scope, method.BelongsTo, method))
+	}
+	for _, typ := range structure.Types {
+		typ.ID = unique(scope + "." + typ.Name)
+		for _, property := range typ.Properties {
+			property.ID = unique(typ.ID + "." + property.Name)
+		}
+		for _, method := range typ.Methods {
+			owner := method.BelongsTo
+			if owner == "" {
+				owner = typ.Name
+			}
+			method.ID = unique(methodID(scope, owner, method))
+		}
+	}
+	for _, variable := range structure.Variables {
+		variable.ID = unique(scope + "." + variable.Name)
+	}
+	for _, constant := range structure.Constants {
+		constant.ID = unique(scope + "." + constant.Name)
+	}
+}
+
+// methodID возвращает идентификатор метода типа owner. Для Go получатель
+// записывается так же, как в выражениях методов: (*Type).Method или Type.Method.
+func methodID(scope, owner string, method *models.Method) string {
+	if owner == "" {
+		return scope + "." + method.Name
+	}
+	if strings.HasPrefix(scope, "go:") {
+		owner = typeParametersPattern.ReplaceAllString(owner, "")
+		if method.IsPointerReceiver {
+			owner = "(*" + owner + ")"
+		}
+	}
+	return scope + "." + owner + "." + method.Name
+}
+
+// SymbolScope возвращает префикс идентификаторов символов файла: язык
+// и пакет Go, модуль Python или путь к файлу для остальных языков.
+// packageName — имя пакета Go из объявления package.
+func SymbolScope(metadata *models.FileMetadata, packageName string) string {
+	relPath := filepath.ToSlash(metadata.Path)
+	extension := strings.ToLower(filepath.Ext(relPath))
+	language, ok := symbolIDLanguages[extension]
+	if !ok {
+		language = strings.TrimPrefix(extension, ".")
+	}
+
+	switch language {
+	case "go":
+		return "go:" + goPackagePath(metadata, packageName)
+	case "py":
+		module := strings.ReplaceAll(strings.TrimSuffix(relPath, extension), "/", ".")
+		module = strings.TrimSuffix(module, ".__init__")
+		return "py:" + module
+	default:
+		return language + ":" + strings.TrimSuffix(relPath, extension)
+	}
+}
+
+// goPackagePath возвращает путь импорта пакета файла Go по ближайшему go.mod
+func goPackagePath(metadata *models.FileMetadata, packageName string) string {
+	dir := filepath.Dir(metadata.AbsolutePath)
+	for current := dir; ; current = filepath.Dir(current) {
+		if module := goModulePath(current); module != "" {
+			rel, err := filepath.Rel(current, dir)
+			if err != nil || rel == "." {
+				return module
+			}
+			return module + "/" + filepath.ToSlash(rel)
+		}
+		if parent := filepath.Dir(current); parent == current {
+			break
+		}
+	}
+
+	// Без go.mod пакет определяется директорией относительно корня проекта.
+	// Имя директории проекта не используется: оно зависит от места checkout'а
+	// и меняется, например, при сравнении ревизий во временных директориях.
+	relDir := path.Dir(filepath.ToSlash(metadata.Path))
+	if relDir == "." {
+		return packageName
+	}
+	return relDir
+}
+
+// ResetModuleCache очищает кэш путей модулей Go, чтобы изменения go.mod
+// учитывались в следующей сборке модели
+func ResetModuleCache() {
+	goModules.Range(func(dir, _ interface{}) bool {
+		goModules.Delete(dir)
+		return true
+	})
+}
+
+// goModulePath возвращает путь модуля из go.mod в директории или пустую строку
+func goModulePath(dir string) string {
+	if cached, ok := goModules.Load(dir); ok {
+		return cached.(string)
+	}
+
+	module := ""
+	if file, err := os.Open(filepath.Join(dir, "go.mod")); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if len(fields) >= 2 && fields[0] == "module" {
+				module = strings.Trim(fields[1], `"`)
+				break
+			}
+		}
+		file.Close()
+	}
+	goModules.Store(dir, module)
+	return module
+}
+
+// RescopeSymbolIDs переносит идентификаторы символов структуры файла
+// в область scope без повторного парсинга, например после переименования
+// файла или изменения go.mod. Прежняя область определяется по самим
+// идентификаторам и возвращается вызывающему. Если у символов нет
+// идентификаторов или они не относятся к одной области, структура не
+// меняется и возвращается false: такой файл нужно разобрать заново.
+func RescopeSymbolIDs(structure *models.FileStructure, scope string) (string, bool) {
+	oldScope, ok := fileScope(structure)
+	if !ok {
+		return "", false
+	}
+	if oldScope == scope || oldScope == "" {
+		return oldScope, true
+	}
+
+	methods := make([]models.MethodInfo, len(structure.Methods))
+	for i, method := range structure.Methods {
+		method.ID, _ = RescopeID(method.ID, oldScope, scope)
+		methods[i] = method
+	}
+	types := make([]models.TypeInfo, len(structure.Types))
+	for i, typ := range structure.Types {
+		typ.ID, _ = RescopeID(typ.ID, oldScope, scope)
+		types[i] = typ
+	}
+	structure.Methods = methods
+	structure.Types = types
+	return oldScope, true
+}
+
+// RescopeID заменяет область oldScope в начале идентификатора символа
+// (или ключа, который с него начинается) на scope
+func RescopeID(id, oldScope, scope string) (string, bool) {
+	if !strings.HasPrefix(id, oldScope+".") {
+		return id, false
+	}
+	return scope + strings.TrimPrefix(id, oldScope), true
+}
+
+// fileScope определяет область идентификаторов символов структуры файла.
+// Пустая область означает, что в файле нет символов.
+func fileScope(structure *models.FileStructure) (string, bool) {
+	scope := ""
+	for _, typ := range structure.Types {
+		if scope = trimSymbol(typ.ID, "."+typ.Name); scope != "" {
+			break
+		}
+	}
+	for _, method := range structure.Methods {
+		if scope != "" {
+			break
+		}
+		if method.BelongsTo == "" {
+			scope = trimSymbol(method.ID, "."+method.Name)
+			continue
+		}
+		owner := typeParametersPattern.ReplaceAllString(method.BelongsTo, "")
+		scope = trimSymbol(method.ID, "."+owner+"."+method.Name, ".(*"+owner+")."+method.Name)
+	}
+	if scope == "" {
+		return "", len(structure.Types) == 0 && len(structure.Methods) == 0
+	}
+
+	for _, method := range structure.Methods {
+		if !strings.HasPrefix(method.ID, scope+".") {
+			return "", false
+		}
+	}
+	for _, typ := range structure.Types {
+		if !strings.HasPrefix(typ.ID, scope+".") {
+			return "", false
+		}
+	}
+	return scope, true
+}
+
+// trimSymbol отрезает от идентификатора квалифицированное имя символа
+// и возвращает область или пустую строку, если ни один суффикс не подошел
+func trimSymbol(id string, suffixes ...string) string {
+	id = duplicateSuffixPattern.ReplaceAllString(id, "")
+	for _, suffix := range suffixes {
+		if scope := strings.TrimSuffix(id, suffix); scope != id && scope != "" {
+			return scope
+		}
+	}
+	return ""
+}
@@ -46,6 +46,8 @@ func (p *TreeSitterParser) Parse(fileMetadata *models.FileMetadata) (*models.Cod
 		return nil, err
 	}
 
+	fileMetadata.LineCount = models.CountLines(content)
+
 	tree := p.parser.Parse(nil, content)
 	// Важно закрыть дерево после использования, чтобы избежать утечек памяти CGO
 	// Однако, если root узел используется после этого вызова (например, в parseTreeNodeFunc),
@@ -76,6 +78,7 @@ func (p *TreeSitterParser) Parse(fileMetadata *models.FileMetadata) (*models.Cod
 		return nil, err
 	}
 
+	AssignSymbolIDs(codeStructure)
 	return codeStructure, nil
 }
 
@@ -0,0 +1,335 @@
+package quality
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"code-telescope/internal/config"
+)
+
+// Недостатки описаний
+const (
+	// IssueTruncated — описание обрезано: ответ ЛЛМ уперся в лимит токенов
+	// или описание обрывается на середине предложения
+	IssueTruncated = "truncated"
+	// IssueLowInformation — описание слишком короткое или повторяет имя символа
+	IssueLowInformation = "low_information"
+	// IssueUnknownParameter — описание упоминает параметр, которого нет в сигнатуре
+	IssueUnknownParameter = "unknown_parameter"
+	// IssueLanguage — описание написано не на языке llm.output_language
+	IssueLanguage = "language_mismatch"
+	// IssueFormatting — в описании остались Markdown, нумерация или подписи
+	IssueFormatting = "formatting"
+)
+
+// Насколько недостаток снижает оценку описания
+var issuePenalties = map[string]float64{
+	IssueTruncated:        0.5,
+	IssueLowInformation:   0.5,
+	IssueUnknownParameter: 0.4,
+	IssueLanguage:         0.6,
+	IssueFormatting:       0.2,
+}
+
+var (
+	// Упоминание параметра: "параметр `ctx`", "the argument userID"
+	parameterMentionPattern = regexp.MustCompile("(?i)(?:^|[^\\p{L}\\p{N}_])(?:параметр|аргумент|param|arg)\\p{L}*\\s+([`'\"«]?)([A-Za-z_][A-Za-z0-9_]*)")
+
+	// Идентификатор в обратных кавычках
+	codeSpanPattern = regexp.MustCompile("`[^`]*`")
+
+	// Остатки форматирования ответа
+	formattingPatterns = []*regexp.Regexp{
+		regexp.MustCompile("```"),
+		regexp.MustCompile(`\*\*`),
+		regexp.MustCompile(`^(?:#+\s|[-*•]\s|\d+[.)]\s)`),
+		regexp.MustCompile(`(?i)^(?:метод|method|methode|функция|function|funktion)\s+\d+\s*:`),
+		regexp.MustCompile(`(?i)^(?:описание|description|beschreibung)\s*:`),
+		regexp.MustCompile(`^\[.*\]$|^".*"$|^«.*»$`),
+	}
+
+	// Слова, по которым английский текст отличается от немецкого
+	englishWords = wordSet("the and is are with for of to this that from returns if it by be or not")
+	germanWords  = wordSet("der die das und ist sind mit für von zu den dem ein eine einen wird werden gibt zurück nicht oder auf")
+)
+
+// Symbol описывает символ, к которому относится описание
+type Symbol struct {
+	// Имя функции или метода
+	Name string
+	// Тип, которому принадлежит метод
+	Owner string
+	// Имена параметров
+	Parameters []string
+}
+
+// Issue описывает недостаток описания
+type Issue struct {
+	// Код недостатка: IssueTruncated, IssueLowInformation и т.д.
+	Code string
+	// Подробности, например имя несуществующего параметра
+	Detail string
+}
+
+// String возвращает недостаток в виде "код" или "код: подробности"
+func (i Issue) String() string {
+	if i.Detail == "" {
+		return i.Code
+	}
+	return i.Code + ": " + i.Detail
+}
+
+// Result содержит оценку описания и найденные недостатки
+type Result struct {
+	// Оценка от 0 до 1
+	Score float64
+	// Найденные недостатки
+	Issues []Issue
+}
+
+// Passed проверяет, что у описания нет недостатков
+func (r Result) Passed() bool {
+	return len(r.Issues) == 0
+}
+
+// IssueStrings возвращает недостатки в виде строк для JSON-модели
+func (r Result) IssueStrings() []string {
+	if len(r.Issues) == 0 {
+		return nil
+	}
+	issues := make([]string, 0, len(r.Issues))
+	for _, issue := range r.Issues {
+		issues = append(issues, issue.String())
+	}
+	return issues
+}
+
+// Checker проверяет описания символов, полученные от ЛЛМ. Проверка
+// выполняется локально и не требует запросов к модели.
+type Checker struct {
+	minWords int
+}
+
+// NewChecker создает проверку описаний с настройками llm.quality
+func NewChecker(cfg config.QualityConfig) *Checker {
+	return &Checker{minWords: cfg.MinWords}
+}
+
+// Check оценивает описание символа. truncated сообщает, что ответ ЛЛМ
+// с этим описанием обрезан по лимиту токенов; language — ожидаемый язык
+// описания (ru, en, de).
+func (c *Checker) Check(description string, symbol Symbol, language string, truncated bool) Result {
+	description = strings.TrimSpace(description)
+	var result Result
+	add := func(code, detail string) {
+		result.Issues = append(result.Issues, Issue{Code: code, Detail: detail})
+	}
+
+	if truncated || !endsSentence(description) {
+		add(IssueTruncated, "")
+	}
+	if informativeWords(description, symbol) < c.minWords {
+		add(IssueLowInformation, "")
+	}
+	for _, name := range unknownParameters(description, symbol) {
+		add(IssueUnknownParameter, name)
+	}
+	if !matchesLanguage(description, language) {
+		add(IssueLanguage, language)
+	}
+	if hasFormatting(description) {
+		add(IssueFormatting, "")
+	}
+
+	score := 1.0
+	penalized := make(map[string]bool)
+	for _, issue := range result.Issues {
+		// Несколько несуществующих параметров снижают оценку один раз
+		if !penalized[issue.Code] {
+			score -= issuePenalties[issue.Code]
+			penalized[issue.Code] = true
+		}
+	}
+	result.Score = math.Round(math.Max(score, 0)*100) / 100
+	return result
+}
+
+// endsSentence проверяет, что описание заканчивается концом предложения
+func endsSentence(description string) bool {
+	if description == "" {
+		return false
+	}
+	last := []rune(description)[len([]rune(description))-1]
+	return strings.ContainsRune(".!?…)\"'»`", last)
+}
+
+// informativeWords считает слова описания не короче двух букв, кроме
+// слов, из которых состоят имена символа и его типа
+func informativeWords(description string, symbol Symbol) int {
+	nameParts := make(map[string]bool)
+	for _, name := range []string{symbol.Name, symbol.Owner} {
+		nameParts[strings.ToLower(name)] = true
+		for _, part := range splitIdentifier(name) {
+			nameParts[part] = true
+		}
+	}
+
+	count := 0
+	for _, word := range words(description) {
+		if len([]rune(word)) >= 2 && !nameParts[strings.ToLower(word)] {
+			count++
+		}
+	}
+	return count
+}
+
+// unknownParameters возвращает упомянутые как параметры идентификаторы,
+// которых нет среди параметров символа. В русском тексте проверяется
+// любой идентификатор после слов «параметр» и «аргумент», в остальных —
+// только идентификаторы в кавычках или с подчеркиванием, цифрой или
+// заглавной буквой внутри, чтобы не принять за имя обычное слово.
+func unknownParameters(description string, symbol Symbol) []string {
+	known := make(map[string]bool, len(symbol.Parameters))
+	for _, name := range symbol.Parameters {
+		known[strings.ToLower(strings.TrimLeft(name, ".*&"))] = true
+	}
+
+	var unknown []string
+	seen := make(map[string]bool)
+	for _, match := range parameterMentionPattern.FindAllStringSubmatch(description, -1) {
+		quoted, name := match[1] != "", match[2]
+		if !quoted && !looksLikeIdentifier(name) && !startsWithCyrillic(match[0]) {
+			continue
+		}
+		if key := strings.ToLower(name); !known[key] && !seen[key] {
+			seen[key] = true
+			unknown = append(unknown, name)
+		}
+	}
+	return unknown
+}
+
+// looksLikeIdentifier проверяет, что слово похоже на имя из кода, а не на
+// обычное слово: содержит подчеркивание, цифру или заглавную букву внутри
+func looksLikeIdentifier(word string) bool {
+	if strings.ContainsAny(word, "_0123456789") {
+		return true
+	}
+	for i, r := range word {
+		if i > 0 && unicode.IsUpper(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// startsWithCyrillic проверяет, что упоминание начинается с русского слова
+func startsWithCyrillic(mention string) bool {
+	for _, r := range mention {
+		if unicode.IsLetter(r) {
+			return unicode.Is(unicode.Cyrillic, r)
+		}
+	}
+	return false
+}
+
+// matchesLanguage проверяет язык описания по доле кириллицы, а английский
+// и немецкий различает по частым служебным словам. Код в обратных
+// кавычках и слова, похожие на имена из кода, не учитываются.
+func matchesLanguage(description, language string) bool {
+	text := codeSpanPattern.ReplaceAllString(description, " ")
+	var cyrillic, latin int
+	for _, word := range words(text) {
+		if looksLikeIdentifier(word) {
+			continue
+		}
+		for _, r := range word {
+			switch {
+			case unicode.Is(unicode.Cyrillic, r):
+				cyrillic++
+			case unicode.Is(unicode.Latin, r):
+				latin++
+			}
+		}
+	}
+	if cyrillic+latin == 0 {
+		return true
+	}
+	share := float64(cyrillic) / float64(cyrillic+latin)
+
+	var english, german int
+	for _, word := range words(text) {
+		word = strings.ToLower(word)
+		if englishWords[word] {
+			english++
+		}
+		if germanWords[word] {
+			german++
+		}
+	}
+
+	switch language {
+	case config.OutputLanguageRussian:
+		return share >= 0.5
+	case config.OutputLanguageEnglish:
+		return share < 0.2 && (german < 2 || german <= english)
+	case config.OutputLanguageGerman:
+		return share < 0.2 && (english < 2 || english <= german)
+	}
+	return true
+}
+
+// hasFormatting проверяет, что в описании остались Markdown, нумерация
+// пакетного ответа или подпись «Описание:»
+func hasFormatting(description string) bool {
+	for _, pattern := range formattingPatterns {
+		if pattern.MatchString(description) {
+			return true
+		}
+	}
+	return false
+}
+
+// words разбивает текст на слова из букв и цифр
+func words(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// splitIdentifier разбивает имя в camelCase или snake_case на слова
+// в нижнем регистре
+func splitIdentifier(name string) []string {
+	var parts []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			parts = append(parts, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	runes := []rune(name)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsNumber(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			flush()
+		}
+		current = append(current, r)
+	}
+	flush()
+	return parts
+}
+
+// wordSet создает множество слов из строки через пробел
+func wordSet(list string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range strings.Fields(list) {
+		set[word] = true
+	}
+	return set
+}
@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"testing"
+
+	"code-telescope/internal/config"
+	"code-telescope/internal/quality"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// issueCodes возвращает коды недостатков результата проверки
+func issueCodes(result quality.Result) []string {
+	var codes []string
+	for _, issue := range result.Issues {
+		codes = append(codes, issue.Code)
+	}
+	return codes
+}
+
+// TestCheckDescription проверяет обнаружение недостатков описаний
+func TestCheckDescription(t *testing.T) {
+	symbol := quality.Symbol{Name: "GetUserName", Owner: "Store", Parameters: []string{"ctx", "userID"}}
+
+	tests := []struct {
+		name        string
+		description string
+		language    string
+		truncated   bool
+		issues      []string
+	}{
+		{"хорошее описание", "Возвращает имя пользователя по userID из хранилища; при отсутствии записи возвращает ErrNotFound.", "ru", false, nil},
+		{"английское описание", "Looks up the user by `userID` and returns the display name stored for it.", "en", false, nil},
+		{"немецкое описание", "Sucht den Benutzer anhand von userID und gibt den gespeicherten Namen zurück.", "de", false, nil},
+		{"ответ обрезан", "Возвращает имя пользователя по userID из хранилища.", "ru", true, []string{quality.IssueTruncated}},
+		{"обрыв предложения", "Возвращает имя пользователя по userID из", "ru", false, []string{quality.IssueTruncated}},
+		{"повтор имени", "Get user name.", "en", false, []string{quality.IssueLowInformation}},
+		{"несуществующий параметр", "Возвращает имя пользователя; параметр userName задает формат вывода имени.", "ru", false, []string{quality.IssueUnknownParameter}},
+		{"несуществующий аргумент в кавычках", "Returns the user name; the argument `timeout` limits the lookup time.", "en", false, []string{quality.IssueUnknownParameter}},
+		{"другой язык", "Returns the user name for the given identifier from the store.", "ru", false, []string{quality.IssueLanguage}},
+		{"английский вместо немецкого", "Returns the name of the user that is stored for the given identifier.", "de", false, []string{quality.IssueLanguage}},
+		{"нумерация ответа", "Метод 2: Возвращает имя пользователя по userID из хранилища.", "ru", false, []string{quality.IssueFormatting}},
+		{"Markdown", "Возвращает **имя** пользователя по userID из хранилища.", "ru", false, []string{quality.IssueFormatting}},
+	}
+
+	checker := quality.NewChecker(config.DefaultConfig().LLM.Quality)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := checker.Check(tt.description, symbol, tt.language, tt.truncated)
+			assert.Equal(t, tt.issues, issueCodes(result))
+			assert.Equal(t, len(tt.issues) == 0, result.Passed())
+			if result.Passed() {
+				assert.Equal(t, 1.0, result.Score)
+			} else {
+				assert.Less(t, result.Score, 1.0)
+			}
+		})
+	}
+}
+
+// TestCheckEmptyDescription проверяет оценку пустого описания
+func TestCheckEmptyDescription(t *testing.T) {
+	checker := quality.NewChecker(config.DefaultConfig().LLM.Quality)
+	result := checker.Check("", quality.Symbol{Name: "Run"}, "ru", false)
+	assert.Equal(t, []string{quality.IssueTruncated, quality.IssueLowInformation}, issueCodes(result))
+	assert.Equal(t, 0.0, result.Score)
+}
@@ -0,0 +1,43 @@
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// AuditEntry описывает скрытый секрет в журнале. Сам секрет в журнал не
+// записывается: повторы одного секрета сопоставляются по отпечатку.
+type AuditEntry struct {
+	Time        time.Time `json:"time"`
+	File        string    `json:"file"`
+	Symbol      string    `json:"symbol,omitempty"`
+	Line        int       `json:"line,omitempty"`
+	Rule        string    `json:"rule"`
+	Fingerprint string    `json:"fingerprint"`
+}
+
+// AppendAudit дописывает записи в журнал скрытых секретов в формате JSON Lines
+func AppendAudit(path string, entries []AuditEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("ошибка создания директории журнала: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("ошибка открытия журнала: %w", err)
+	}
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			return fmt.Errorf("ошибка записи журнала: %w", err)
+		}
+	}
+	return file.Close()
+}
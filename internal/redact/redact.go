@@ -0,0 +1,214 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"code-telescope/internal/config"
+)
+
+// Имя правила для строковых литералов с высокой энтропией
+const highEntropyRule = "high_entropy"
+
+// rule задает правило поиска секрета. Если в выражении есть группы,
+// скрывается текст первой совпавшей группы, иначе все совпадение.
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// Встроенные правила в порядке приоритета: при пересечении совпадений
+// скрывается более раннее, а при одинаковом начале — более длинное
+var builtinRules = []rule{
+	{"private_key", regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY-----`)},
+	{"aws_access_key", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"github_token", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"api_key", regexp.MustCompile(`\bsk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{"slack_token", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{"google_api_key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}`)},
+	{"stripe_key", regexp.MustCompile(`\b[rsp]k_(?:live|test)_[0-9A-Za-z]{16,}`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{"bearer_token", regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/=-]{16,})`)},
+	{"url_credentials", regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s/:@'"]+:([^\s/@'"]+)@`)},
+	{"password", regexp.MustCompile("(?i)(?:password|passwd|pwd|secret|token|api_?key|access_?key|private_?key|credential)[a-z0-9_]*[\"']?\\s*(?::=|=>|=|:)\\s*(?:\"([^\"\\s]{4,})\"|'([^'\\s]{4,})'|`([^`\\s]{4,})`)")},
+	{"email", regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+}
+
+var (
+	// Строковый литерал без пробелов в двойных, одинарных или обратных кавычках
+	stringLiteralPattern = regexp.MustCompile("\"([^\"\\\\\\s]+)\"|'([^'\\\\\\s]+)'|`([^`\\s]+)`")
+
+	// Символы ключей и токенов: base64, base64url и hex
+	tokenCharsPattern = regexp.MustCompile(`^[A-Za-z0-9+/=_\-.~]+$`)
+)
+
+// Finding описывает скрытый секрет
+type Finding struct {
+	// Правило, по которому найден секрет
+	Rule string
+
+	// Скрытый текст. Не выводится в журнал и логи.
+	Secret string
+}
+
+// Fingerprint возвращает отпечаток секрета, по которому повторы одного
+// секрета можно сопоставить, не раскрывая его
+func (f Finding) Fingerprint() string {
+	hash := sha256.Sum256([]byte(f.Secret))
+	return hex.EncodeToString(hash[:])[:12]
+}
+
+// Placeholder возвращает заглушку, которой заменяется секрет
+func Placeholder(rule string) string {
+	return "[REDACTED:" + rule + "]"
+}
+
+// Redactor находит в тексте секреты и персональные данные и заменяет их
+// заглушками. Работает локально, без обращения к внешним сервисам.
+type Redactor struct {
+	rules            []rule
+	entropyThreshold float64
+	entropyMinLength int
+}
+
+// New создает Redactor со встроенными правилами, кроме отключенных
+// в redaction.disabled_rules, и собственными правилами redaction.patterns
+func New(cfg config.RedactionConfig) (*Redactor, error) {
+	disabled := make(map[string]bool, len(cfg.DisabledRules))
+	for _, name := range cfg.DisabledRules {
+		if !isBuiltinRule(name) {
+			return nil, fmt.Errorf("неизвестное встроенное правило %q, допустимые значения: %s", name, strings.Join(RuleNames(), ", "))
+		}
+		disabled[name] = true
+	}
+
+	r := &Redactor{
+		entropyThreshold: cfg.EntropyThreshold,
+		entropyMinLength: cfg.EntropyMinLength,
+	}
+	for _, builtin := range builtinRules {
+		if !disabled[builtin.name] {
+			r.rules = append(r.rules, builtin)
+		}
+	}
+	if disabled[highEntropyRule] {
+		r.entropyThreshold = 0
+	}
+	for _, custom := range cfg.Patterns {
+		pattern, err := regexp.Compile(custom.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное выражение правила %s: %w", custom.Name, err)
+		}
+		r.rules = append(r.rules, rule{name: custom.Name, pattern: pattern})
+	}
+	return r, nil
+}
+
+// RuleNames возвращает имена встроенных правил
+func RuleNames() []string {
+	names := make([]string, 0, len(builtinRules)+1)
+	for _, builtin := range builtinRules {
+		names = append(names, builtin.name)
+	}
+	return append(names, highEntropyRule)
+}
+
+// isBuiltinRule проверяет, что правило с таким именем встроено
+func isBuiltinRule(name string) bool {
+	for _, builtin := range RuleNames() {
+		if builtin == name {
+			return true
+		}
+	}
+	return false
+}
+
+// span описывает найденный секрет в тексте
+type span struct {
+	start, end int
+	rule       string
+}
+
+// Redact заменяет найденные в тексте секреты заглушками [REDACTED:правило]
+// и возвращает измененный текст и список скрытых секретов
+func (r *Redactor) Redact(text string) (string, []Finding) {
+	var spans []span
+	for _, rule := range r.rules {
+		for _, match := range rule.pattern.FindAllStringSubmatchIndex(text, -1) {
+			if start, end := secretBounds(match); start < end {
+				spans = append(spans, span{start: start, end: end, rule: rule.name})
+			}
+		}
+	}
+	if r.entropyThreshold > 0 {
+		for _, match := range stringLiteralPattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := secretBounds(match)
+			if value := text[start:end]; len(value) >= r.entropyMinLength && looksLikeToken(value) && entropy(value) >= r.entropyThreshold {
+				spans = append(spans, span{start: start, end: end, rule: highEntropyRule})
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return text, nil
+	}
+
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+
+	var sb strings.Builder
+	var findings []Finding
+	last := 0
+	for _, s := range spans {
+		// Пересекается с уже скрытым секретом
+		if s.start < last {
+			continue
+		}
+		sb.WriteString(text[last:s.start])
+		sb.WriteString(Placeholder(s.rule))
+		findings = append(findings, Finding{Rule: s.rule, Secret: text[s.start:s.end]})
+		last = s.end
+	}
+	sb.WriteString(text[last:])
+	return sb.String(), findings
+}
+
+// secretBounds возвращает границы первой совпавшей группы или всего совпадения
+func secretBounds(match []int) (int, int) {
+	for i := 2; i+1 < len(match); i += 2 {
+		if match[i] >= 0 {
+			return match[i], match[i+1]
+		}
+	}
+	return match[0], match[1]
+}
+
+// looksLikeToken проверяет, что строка состоит из символов ключей и токенов
+// и содержит и буквы, и цифры, как случайные ключи, а не слова и пути
+func looksLikeToken(value string) bool {
+	return tokenCharsPattern.MatchString(value) &&
+		strings.ContainsAny(value, "0123456789") &&
+		strings.ContainsAny(strings.ToLower(value), "abcdefghijklmnopqrstuvwxyz")
+}
+
+// entropy возвращает энтропию Шеннона строки в битах на символ
+func entropy(value string) float64 {
+	counts := make(map[byte]int)
+	for i := 0; i < len(value); i++ {
+		counts[value[i]]++
+	}
+	var result float64
+	for _, count := range counts {
+		p := float64(count) / float64(len(value))
+		result -= p * math.Log2(p)
+	}
+	return result
+}
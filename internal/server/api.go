@@ -0,0 +1,115 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"code-telescope/internal/codeindex"
+)
+
+// apiError представляет ошибку в ответе API
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// handleAPIFiles возвращает список файлов карты кода
+func (s *Server) handleAPIFiles(w http.ResponseWriter, r *http.Request) {
+	index := s.currentIndex()
+	if index == nil {
+		writeNotReady(w)
+		return
+	}
+	writeJSON(w, http.StatusOK, index.Files())
+}
+
+// handleAPIFile возвращает структуру одного файла
+func (s *Server) handleAPIFile(w http.ResponseWriter, r *http.Request) {
+	index := s.currentIndex()
+	if index == nil {
+		writeNotReady(w)
+		return
+	}
+
+	file, ok := index.File(r.PathValue("path"))
+	if !ok {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "файл не найден: " + r.PathValue("path")})
+		return
+	}
+	writeJSON(w, http.StatusOK, file)
+}
+
+// handleAPISearch ищет символы по имени или описанию (параметры q и limit)
+func (s *Server) handleAPISearch(w http.ResponseWriter, r *http.Request) {
+	index := s.currentIndex()
+	if index == nil {
+		writeNotReady(w)
+		return
+	}
+
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "не указан параметр q"})
+		return
+	}
+	limit, ok := intParam(r, "limit", defaultSearchLimit, 1, maxSearchLimit)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "некорректный параметр limit"})
+		return
+	}
+
+	results := index.Search(query, limit)
+	if results == nil {
+		results = []codeindex.Symbol{}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// handleAPIDeps возвращает окрестность файла в графе зависимостей (параметр depth)
+func (s *Server) handleAPIDeps(w http.ResponseWriter, r *http.Request) {
+	index := s.currentIndex()
+	if index == nil {
+		writeNotReady(w)
+		return
+	}
+
+	if _, ok := index.File(r.PathValue("path")); !ok {
+		writeJSON(w, http.StatusNotFound, apiError{Error: "файл не найден: " + r.PathValue("path")})
+		return
+	}
+	depth, ok := intParam(r, "depth", 1, 1, 10)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, apiError{Error: "некорректный параметр depth"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, index.Dependencies(r.PathValue("path"), depth))
+}
+
+// intParam читает целочисленный параметр запроса в заданных пределах
+func intParam(r *http.Request, name string, defaultValue, min, max int) (int, bool) {
+	raw := r.URL.Query().Get(name)
+	if raw == "" {
+		return defaultValue, true
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil || value < min || value > max {
+		return 0, false
+	}
+	return value, true
+}
+
+// writeNotReady сообщает, что карта кода еще строится
+func writeNotReady(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", "1")
+	writeJSON(w, http.StatusServiceUnavailable, apiError{Error: "карта кода еще строится"})
+}
+
+// writeJSON записывает ответ в формате JSON
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(value)
+}
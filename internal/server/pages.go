@@ -0,0 +1,161 @@
+package server
+
+import (
+	"html/template"
+	"net/http"
+
+	"code-telescope/internal/codeindex"
+	"code-telescope/pkg/models"
+)
+
+// Шаблоны HTML-страниц. Стили встроены, чтобы сервер не зависел от внешних ресурсов.
+var pageTemplates = template.Must(template.New("layout").Funcs(template.FuncMap{
+	"anchor": models.SymbolAnchor,
+}).Parse(`{{define "header"}}<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 70em; padding: 0 1em; }
+code, pre { background: #f4f4f4; padding: 0.1em 0.3em; }
+table { border-collapse: collapse; width: 100%; }
+td, th { border-bottom: 1px solid #ddd; padding: 0.3em; text-align: left; vertical-align: top; }
+.muted { color: #777; }
+</style>
+</head>
+<body>
+<nav><a href="/">Файлы</a> ·
+<form action="/search" method="get" style="display:inline"><input name="q" value="{{.Query}}" placeholder="Поиск символов"></form></nav>
+<h1>{{.Title}}</h1>
+{{end}}
+{{define "footer"}}{{if .Generated}}<p class="muted">Модель построена: {{.Generated}}</p>{{end}}
+</body>
+</html>
+{{end}}
+{{define "index"}}{{template "header" .}}
+<table>
+<tr><th>Файл</th><th>Язык</th><th>Строк</th><th>Типов</th><th>Функций</th></tr>
+{{range .Files}}<tr><td><a href="/files/{{.Path}}">{{.Path}}</a></td><td>{{.Language}}</td><td>{{.LineCount}}</td><td>{{len .Types}}</td><td>{{len .Methods}}</td></tr>
+{{end}}</table>
+{{template "footer" .}}{{end}}
+{{define "file"}}{{template "header" .}}
+{{with .File}}
+<p class="muted">{{.Language}}, строк: {{.LineCount}}</p>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+{{if .Types}}<h2>Типы</h2>
+<table>
+{{range .Types}}<tr{{if .ID}} id="{{anchor .ID}}"{{end}}><td><code>{{.Name}}</code></td><td>{{.Kind}}</td><td>строка {{.Position.StartLine}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>{{end}}
+{{if .Methods}}<h2>Функции и методы</h2>
+<table>
+{{range .Methods}}<tr{{if .ID}} id="{{anchor .ID}}"{{end}}><td><code>{{.Signature}}</code></td><td>строка {{.Position.StartLine}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>{{end}}
+{{end}}
+{{with .Deps}}
+{{if .Dependencies}}<h2>Зависимости</h2>
+<ul>{{range .Dependencies}}<li><a href="/files/{{.}}">{{.}}</a></li>{{end}}</ul>{{end}}
+{{if .Dependents}}<h2>Используется в</h2>
+<ul>{{range .Dependents}}<li><a href="/files/{{.}}">{{.}}</a></li>{{end}}</ul>{{end}}
+{{end}}
+{{template "footer" .}}{{end}}
+{{define "search"}}{{template "header" .}}
+{{if .Query}}{{if .Results}}
+<table>
+<tr><th>Символ</th><th>Вид</th><th>Файл</th><th>Описание</th></tr>
+{{range .Results}}<tr><td><code>{{if .BelongsTo}}{{.BelongsTo}}.{{end}}{{.Name}}</code></td><td>{{.Kind}}</td><td><a href="/files/{{.File}}{{if .ID}}#{{anchor .ID}}{{end}}">{{.File}}</a>:{{.Position.StartLine}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{else}}<p>Ничего не найдено.</p>{{end}}{{end}}
+{{template "footer" .}}{{end}}
+{{define "not_ready"}}<!DOCTYPE html>
+<html lang="ru"><head><meta charset="utf-8"><meta http-equiv="refresh" content="1"><title>Карта кода строится</title></head>
+<body><p>Карта кода еще строится, страница обновится автоматически.</p></body></html>
+{{end}}`))
+
+// pageData содержит данные для HTML-шаблонов
+type pageData struct {
+	Title     string
+	Query     string
+	Generated string
+	Files     []models.FileStructure
+	File      *models.FileStructure
+	Deps      *codeindex.Dependencies
+	Results   []codeindex.Symbol
+}
+
+// handleIndexPage отображает список файлов проекта
+func (s *Server) handleIndexPage(w http.ResponseWriter, r *http.Request) {
+	index := s.currentIndex()
+	if index == nil {
+		renderNotReady(w)
+		return
+	}
+	codeMap := index.CodeMap()
+
+	renderPage(w, http.StatusOK, "index", pageData{
+		Title:     "Карта кода: " + codeMap.Project,
+		Generated: generatedAt(codeMap),
+		Files:     codeMap.Files,
+	})
+}
+
+// handleFilePage отображает структуру файла и его зависимости
+func (s *Server) handleFilePage(w http.ResponseWriter, r *http.Request) {
+	index := s.currentIndex()
+	if index == nil {
+		renderNotReady(w)
+		return
+	}
+
+	file, ok := index.File(r.PathValue("path"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	deps := index.Dependencies(file.Path, 1)
+	renderPage(w, http.StatusOK, "file", pageData{
+		Title:     file.Path,
+		Generated: generatedAt(index.CodeMap()),
+		File:      file,
+		Deps:      &deps,
+	})
+}
+
+// handleSearchPage отображает результаты поиска символов
+func (s *Server) handleSearchPage(w http.ResponseWriter, r *http.Request) {
+	index := s.currentIndex()
+	if index == nil {
+		renderNotReady(w)
+		return
+	}
+	codeMap := index.CodeMap()
+
+	query := r.URL.Query().Get("q")
+	renderPage(w, http.StatusOK, "search", pageData{
+		Title:     "Поиск символов",
+		Query:     query,
+		Generated: generatedAt(codeMap),
+		Results:   index.Search(query, defaultSearchLimit),
+	})
+}
+
+// renderPage выполняет HTML-шаблон страницы
+func renderPage(w http.ResponseWriter, status int, name string, data pageData) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = pageTemplates.ExecuteTemplate(w, name, data)
+}
+
+// renderNotReady отображает страницу ожидания до построения первой модели
+func renderNotReady(w http.ResponseWriter) {
+	renderPage(w, http.StatusServiceUnavailable, "not_ready", pageData{})
+}
+
+// generatedAt возвращает время построения модели для подвала страницы
+func generatedAt(codeMap *models.CodeMap) string {
+	if codeMap.GeneratedAt.IsZero() {
+		return ""
+	}
+	return codeMap.GeneratedAt.Format("2006-01-02 15:04:05 UTC")
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	"code-telescope/internal/codeindex"
+	"code-telescope/pkg/models"
+)
+
+// Количество результатов поиска по умолчанию и максимальное
+const (
+	defaultSearchLimit = 50
+	maxSearchLimit     = 500
+)
+
+// Server отдает карту кода в виде HTML-страниц и JSON API.
+// Модель можно заменить во время работы, не останавливая сервер.
+type Server struct {
+	mu    sync.RWMutex
+	index *codeindex.Index
+}
+
+// New создает сервер без модели. До первого вызова Update
+// запросы к API завершаются с кодом 503.
+func New() *Server {
+	return &Server{}
+}
+
+// Update заменяет модель карты кода, которую отдает сервер
+func (s *Server) Update(codeMap *models.CodeMap) {
+	index := codeindex.New(codeMap)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index = index
+}
+
+// Handler возвращает обработчик HTTP-запросов сервера
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	// JSON API
+	mux.HandleFunc("GET /api/files", s.handleAPIFiles)
+	mux.HandleFunc("GET /api/files/{path...}", s.handleAPIFile)
+	mux.HandleFunc("GET /api/search", s.handleAPISearch)
+	mux.HandleFunc("GET /api/deps/{path...}", s.handleAPIDeps)
+
+	// HTML-страницы
+	mux.HandleFunc("GET /{$}", s.handleIndexPage)
+	mux.HandleFunc("GET /files/{path...}", s.handleFilePage)
+	mux.HandleFunc("GET /search", s.handleSearchPage)
+
+	return checkHost(mux)
+}
+
+// checkHost отклоняет запросы с заголовком Host, отличным от локального адреса.
+// Сервер слушает только локальный интерфейс, но без этой проверки страница
+// в браузере могла бы прочитать карту кода через DNS rebinding.
+func checkHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackHost(r.Host) {
+			writeJSON(w, http.StatusForbidden, apiError{Error: "недопустимый заголовок Host: " + r.Host})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isLoopbackHost проверяет, что значение заголовка Host (с портом или без)
+// указывает на localhost или локальный IP-адрес
+func isLoopbackHost(hostport string) bool {
+	host := hostport
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		host = h
+	}
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// CheckLoopback проверяет, что адрес прослушивания относится к локальному интерфейсу
+func CheckLoopback(addr string) error {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("некорректный адрес %s: %w", addr, err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("сервер может слушать только локальный интерфейс (127.0.0.1, ::1, localhost), получено: %s", host)
+}
+
+// currentIndex возвращает индекс текущей модели или nil, если модели еще нет
+func (s *Server) currentIndex() *codeindex.Index {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.index
+}
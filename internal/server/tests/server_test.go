@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code-telescope/internal/codeindex"
+	"code-telescope/internal/server"
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCodeMap возвращает модель из трех файлов: main.js -> lib/util.js -> lib/base.js
+func testCodeMap() *models.CodeMap {
+	return models.NewCodeMap("demo", []models.FileStructure{
+		{
+			Path:      "main.js",
+			Language:  "javascript",
+			LineCount: 10,
+			Imports:   []string{"./lib/util"},
+			Methods: []models.MethodInfo{
+				{Name: "run", Kind: "function", Signature: "function run()", Description: "Запускает приложение"},
+			},
+		},
+		{
+			Path:      "lib/util.js",
+			Language:  "javascript",
+			LineCount: 20,
+			Imports:   []string{"./base"},
+			Types: []models.TypeInfo{
+				{Name: "Parser", Kind: "class", Description: "Разбирает входные данные"},
+			},
+			Methods: []models.MethodInfo{
+				{Name: "parse", Kind: "method", BelongsTo: "Parser", Signature: "parse(input)", Description: "Возвращает дерево"},
+			},
+		},
+		{
+			Path:      "lib/base.js",
+			Language:  "javascript",
+			LineCount: 5,
+			Methods: []models.MethodInfo{
+				{Name: "helper", Kind: "function", Signature: "function helper()", Description: "Вызывает parse для строки"},
+			},
+		},
+	})
+}
+
+// newTestServer создает HTTP-сервер с тестовой моделью
+func newTestServer(t *testing.T) *httptest.Server {
+	srv := server.New()
+	srv.Update(testCodeMap())
+	ts := httptest.NewServer(srv.Handler())
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// getJSON выполняет GET-запрос и декодирует ответ
+func getJSON(t *testing.T, url string, target interface{}) int {
+	resp, err := http.Get(url)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Contains(t, resp.Header.Get("Content-Type"), "application/json")
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(target))
+	return resp.StatusCode
+}
+
+// TestAPINotReady проверяет ответ до построения первой модели
+func TestAPINotReady(t *testing.T) {
+	ts := httptest.NewServer(server.New().Handler())
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/files")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+// TestAPIFiles проверяет список файлов и структуру отдельного файла
+func TestAPIFiles(t *testing.T) {
+	ts := newTestServer(t)
+
+	var files []codeindex.FileSummary
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/files", &files))
+	require.Len(t, files, 3)
+	assert.Equal(t, "lib/base.js", files[0].Path, "файлы упорядочены по пути")
+	assert.Equal(t, "main.js", files[2].Path)
+	assert.Equal(t, 1, files[2].Methods)
+
+	var file models.FileStructure
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/files/lib/util.js", &file))
+	assert.Equal(t, "lib/util.js", file.Path)
+	require.Len(t, file.Types, 1)
+	assert.Equal(t, "Parser", file.Types[0].Name)
+
+	var apiErr map[string]string
+	assert.Equal(t, http.StatusNotFound, getJSON(t, ts.URL+"/api/files/missing.js", &apiErr))
+	assert.Contains(t, apiErr["error"], "missing.js")
+}
+
+// TestAPISearch проверяет поиск по имени и описанию: совпадения по имени идут первыми
+func TestAPISearch(t *testing.T) {
+	ts := newTestServer(t)
+
+	var results []codeindex.Symbol
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/search?q=PARSE", &results))
+	require.Len(t, results, 3)
+	assert.Equal(t, "parse", results[0].Name)
+	assert.Equal(t, "Parser", results[0].BelongsTo)
+	assert.Equal(t, "Parser", results[1].Name)
+	assert.Equal(t, "helper", results[2].Name, "совпадение по описанию должно идти последним")
+
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/search?q=parse&limit=1", &results))
+	assert.Len(t, results, 1)
+
+	var apiErr map[string]string
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts.URL+"/api/search", &apiErr))
+	assert.Equal(t, http.StatusBadRequest, getJSON(t, ts.URL+"/api/search?q=x&limit=0", &apiErr))
+}
+
+// TestAPIDeps проверяет окрестность файла в графе зависимостей
+func TestAPIDeps(t *testing.T) {
+	ts := newTestServer(t)
+
+	var deps codeindex.Dependencies
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/deps/lib/util.js", &deps))
+	assert.Equal(t, []string{"lib/base.js"}, deps.Dependencies)
+	assert.Equal(t, []string{"main.js"}, deps.Dependents)
+	assert.Len(t, deps.Nodes, 3)
+	assert.Len(t, deps.Edges, 2)
+
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/deps/main.js?depth=1", &deps))
+	assert.Len(t, deps.Nodes, 2, "на глубине 1 base.js недоступен из main.js")
+
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/deps/main.js?depth=2", &deps))
+	assert.Len(t, deps.Nodes, 3)
+	assert.Equal(t, 2, deps.Nodes[2].Distance)
+}
+
+// TestUpdateReplacesModel проверяет, что сервер отдает новую модель после обновления
+func TestUpdateReplacesModel(t *testing.T) {
+	srv := server.New()
+	srv.Update(testCodeMap())
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	srv.Update(models.NewCodeMap("demo", []models.FileStructure{{Path: "only.go", Language: "go"}}))
+
+	var files []codeindex.FileSummary
+	require.Equal(t, http.StatusOK, getJSON(t, ts.URL+"/api/files", &files))
+	require.Len(t, files, 1)
+	assert.Equal(t, "only.go", files[0].Path)
+}
+
+// TestPages проверяет HTML-страницы списка файлов, файла и поиска
+func TestPages(t *testing.T) {
+	ts := newTestServer(t)
+
+	pages := map[string]string{
+		"/":                  `href="/files/lib/util.js"`,
+		"/files/lib/util.js": `href="/files/main.js"`,
+		"/search?q=parser":   "Разбирает входные данные",
+	}
+	for page, expected := range pages {
+		resp, err := http.Get(ts.URL + page)
+		require.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode, page)
+		assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html"), page)
+		assert.Contains(t, string(body), expected, page)
+	}
+}
+
+// TestCheckLoopback проверяет, что сервер может слушать только локальный интерфейс
+func TestCheckLoopback(t *testing.T) {
+	for _, addr := range []string{"127.0.0.1:8080", "localhost:0", "[::1]:9000"} {
+		assert.NoError(t, server.CheckLoopback(addr), addr)
+	}
+	for _, addr := range []string{"0.0.0.0:8080", ":8080", "192.168.1.10:80", "example.com:80", "bad"} {
+		assert.Error(t, server.CheckLoopback(addr), addr)
+	}
+}
+
+// TestRejectsForeignHost проверяет, что запросы с чужим заголовком Host
+// отклоняются (защита от DNS rebinding)
+func TestRejectsForeignHost(t *testing.T) {
+	srv := server.New()
+	srv.Update(testCodeMap())
+	handler := srv.Handler()
+
+	for host, status := range map[string]int{
+		"127.0.0.1:8080":      http.StatusOK,
+		"localhost:8080":      http.StatusOK,
+		"localhost":           http.StatusOK,
+		"[::1]:8080":          http.StatusOK,
+		"attacker.example":    http.StatusForbidden,
+		"attacker.example:80": http.StatusForbidden,
+		"192.168.1.10:8080":   http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/files", nil)
+		req.Host = host
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, status, rec.Code, host)
+	}
+}
@@ -0,0 +1,26 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"code-telescope/internal/textdiff"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUnified проверяет формат различий и объединение близких изменений
+func TestUnified(t *testing.T) {
+	assert.Empty(t, textdiff.Unified("a", "b", "x\ny\n", "x\ny\n", 2))
+
+	oldText := strings.Join([]string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10"}, "\n")
+	newText := strings.Join([]string{"1", "2", "3", "4", "five", "6", "7", "8", "9", "10", "11"}, "\n")
+
+	expected := "--- old\n+++ new\n" +
+		"@@ -3,5 +3,5 @@\n 3\n 4\n-5\n+five\n 6\n 7\n" +
+		"@@ -9,2 +9,3 @@\n 9\n 10\n+11\n"
+	assert.Equal(t, expected, textdiff.Unified("old", "new", oldText, newText, 2))
+
+	merged := textdiff.Unified("old", "new", oldText, newText, 3)
+	assert.Equal(t, 1, strings.Count(merged, "@@ -"), "изменения, разделенные не более чем 2*context строками, выводятся одним фрагментом")
+}
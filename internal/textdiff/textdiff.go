@@ -0,0 +1,172 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Максимальный размер таблицы сравнения (строки старого × строки нового текста).
+// Для больших изменений различающийся фрагмент выводится целиком как замена.
+const maxTableCells = 4 << 20
+
+// opKind вид строки в результате сравнения
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+// op представляет строку результата сравнения
+type op struct {
+	kind opKind
+	line string
+	// Номера строки в старом и новом тексте, начиная с 1
+	oldLine int
+	newLine int
+}
+
+// Unified сравнивает тексты построчно и возвращает различия в формате unified
+// diff с context строками контекста вокруг изменений. Для одинаковых текстов
+// возвращается пустая строка.
+func Unified(oldName, newName, oldText, newText string, context int) string {
+	ops := compare(splitLines(oldText), splitLines(newText))
+
+	var sb strings.Builder
+	for start := 0; start < len(ops); {
+		// Ищем следующее изменение
+		first := start
+		for first < len(ops) && ops[first].kind == opEqual {
+			first++
+		}
+		if first == len(ops) {
+			break
+		}
+
+		// Расширяем фрагмент, пока изменения разделены не более чем 2*context строками
+		from := max(first-context, start)
+		to := first
+		for i := first; i < len(ops); i++ {
+			if ops[i].kind != opEqual {
+				to = i
+				continue
+			}
+			if i-to > 2*context {
+				break
+			}
+		}
+		end := min(to+context+1, len(ops))
+
+		if sb.Len() == 0 {
+			sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+		}
+		writeHunk(&sb, ops[from:end])
+		start = end
+	}
+	return sb.String()
+}
+
+// writeHunk записывает фрагмент с заголовком @@ -a,b +c,d @@
+func writeHunk(sb *strings.Builder, ops []op) {
+	oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+	for _, o := range ops {
+		if o.kind != opInsert {
+			if oldStart == 0 {
+				oldStart = o.oldLine
+			}
+			oldCount++
+		}
+		if o.kind != opDelete {
+			if newStart == 0 {
+				newStart = o.newLine
+			}
+			newCount++
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+	for _, o := range ops {
+		sb.WriteByte(byte(o.kind))
+		sb.WriteString(o.line)
+		sb.WriteByte('\n')
+	}
+}
+
+// compare строит последовательность операций, превращающую a в b.
+// Общие начало и конец отбрасываются, середина сравнивается по наибольшей
+// общей подпоследовательности.
+func compare(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		ops = append(ops, op{kind: opEqual, line: a[i], oldLine: i + 1, newLine: i + 1})
+	}
+	ops = append(ops, compareMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix], prefix, prefix)...)
+	for i := 0; i < suffix; i++ {
+		ai, bi := len(a)-suffix+i, len(b)-suffix+i
+		ops = append(ops, op{kind: opEqual, line: a[ai], oldLine: ai + 1, newLine: bi + 1})
+	}
+	return ops
+}
+
+// compareMiddle сравнивает различающиеся части текстов; offsetA и offsetB —
+// количество строк перед ними
+func compareMiddle(a, b []string, offsetA, offsetB int) []op {
+	var ops []op
+	if len(a)*len(b) > maxTableCells {
+		for i, line := range a {
+			ops = append(ops, op{kind: opDelete, line: line, oldLine: offsetA + i + 1})
+		}
+		for j, line := range b {
+			ops = append(ops, op{kind: opInsert, line: line, newLine: offsetB + j + 1})
+		}
+		return ops
+	}
+
+	// lcs[i][j] — длина общей подпоследовательности a[i:] и b[j:]
+	width := len(b) + 1
+	lcs := make([]int32, (len(a)+1)*width)
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i*width+j] = lcs[(i+1)*width+j+1] + 1
+			} else {
+				lcs[i*width+j] = max(lcs[(i+1)*width+j], lcs[i*width+j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{kind: opEqual, line: a[i], oldLine: offsetA + i + 1, newLine: offsetB + j + 1})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[(i+1)*width+j] >= lcs[i*width+j+1]):
+			ops = append(ops, op{kind: opDelete, line: a[i], oldLine: offsetA + i + 1})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, line: b[j], newLine: offsetB + j + 1})
+			j++
+		}
+	}
+	return ops
+}
+
+// splitLines разбивает текст на строки без завершающих переводов строки
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n"), "\n")
+}
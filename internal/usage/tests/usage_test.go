@@ -0,0 +1,106 @@
+package tests
+
+import (
+	"bytes"
+	"testing"
+
+	"code-telescope/internal/usage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Источники запросов в тестах
+var (
+	mini  = usage.Source{Provider: "openai", Model: "gpt-4o-mini"}
+	local = usage.Source{Provider: "ollama", Model: "llama3.1"}
+)
+
+// prices возвращает цену только для модели gpt-4o-mini
+func prices(source usage.Source) (usage.Price, bool) {
+	if source == mini {
+		return usage.Price{Input: 1, Output: 2}, true
+	}
+	return usage.Price{}, false
+}
+
+// TestTrackerReport проверяет агрегацию расхода по файлам и моделям
+func TestTrackerReport(t *testing.T) {
+	tracker := usage.NewTracker(usage.Budget{}, prices)
+	tracker.Record("b.go", mini, 500_000, 250_000)
+	tracker.Record("a.go", mini, 100, 50)
+	tracker.Record("a.go", local, 10, 5)
+
+	report := tracker.Report()
+	require.Len(t, report.Files, 2)
+	assert.Equal(t, "a.go", report.Files[0].Path, "файлы упорядочены по пути")
+	assert.Equal(t, 2, report.Files[0].Requests)
+	assert.False(t, report.Files[0].CostKnown, "цена локальной модели неизвестна")
+	assert.True(t, report.Files[1].CostKnown)
+	assert.InDelta(t, 1.0, report.Files[1].Cost, 1e-9)
+
+	require.Len(t, report.Models, 2)
+	assert.Equal(t, local, report.Models[0].Source, "модели упорядочены по провайдеру")
+	assert.Equal(t, 3, report.Total.Requests)
+	assert.Equal(t, 750_165, report.Total.Tokens())
+	assert.False(t, report.BudgetExhausted)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, "Расход ЛЛМ:"))
+	assert.Contains(t, out.String(), "$1.0000")
+	assert.Contains(t, out.String(), "?")
+	assert.NotContains(t, out.String(), "Символ", "описаний нет, источники не выводятся")
+}
+
+// TestTrackerDescriptionSources проверяет вывод источника описаний от разных моделей
+func TestTrackerDescriptionSources(t *testing.T) {
+	tracker := usage.NewTracker(usage.Budget{}, prices)
+	tracker.Describe("a.go", "Run", mini)
+	tracker.Describe("a.go", "Server.Stop", local)
+
+	report := tracker.Report()
+	assert.Equal(t, 2, report.Total.Described)
+	require.Len(t, report.Descriptions, 2)
+	assert.Equal(t, local, report.Descriptions[1].Source)
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, "Расход ЛЛМ:"))
+	assert.Regexp(t, `Server\.Stop\s+ollama/llama3\.1`, out.String())
+}
+
+// TestTrackerCache проверяет учет и стоимость токенов кэша промптов
+func TestTrackerCache(t *testing.T) {
+	tracker := usage.NewTracker(usage.Budget{}, prices)
+	tracker.Record("a.go", mini, 100_000, 0)
+	tracker.RecordCache("a.go", mini, 1_000_000, 400_000)
+
+	report := tracker.Report()
+	assert.Equal(t, 1, report.Total.Requests)
+	assert.Equal(t, 1_500_000, report.Total.Tokens())
+	assert.InDelta(t, 0.1+0.1+0.5, report.Total.Cost, 1e-9, "чтение в 10 раз дешевле входа, запись на 25% дороже")
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out, "Расход ЛЛМ:"))
+	assert.Contains(t, out.String(), "Кэш промптов: прочитано 1000000, записано 400000 токенов")
+}
+
+// TestTrackerBudget проверяет остановку запросов после исчерпания бюджета
+func TestTrackerBudget(t *testing.T) {
+	tracker := usage.NewTracker(usage.Budget{MaxTokens: 1000}, prices)
+	require.True(t, tracker.Allow(mini, 600))
+	tracker.Record("a.go", mini, 600, 100)
+
+	assert.False(t, tracker.Allow(mini, 400), "запрос превышает лимит токенов")
+	assert.False(t, tracker.Allow(mini, 1), "после отказа бюджет остается исчерпанным")
+	tracker.Skip("a.go", mini, 3)
+
+	report := tracker.Report()
+	assert.True(t, report.BudgetExhausted)
+	assert.Equal(t, 3, report.Total.Skipped)
+	assert.Equal(t, 1, report.Total.Requests)
+
+	tracker = usage.NewTracker(usage.Budget{MaxUSD: 0.5}, prices)
+	assert.True(t, tracker.Allow(mini, 400_000))
+	tracker.Record("a.go", mini, 400_000, 0)
+	assert.False(t, tracker.Allow(mini, 200_000), "запрос превышает лимит стоимости")
+}
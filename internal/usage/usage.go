@@ -0,0 +1,350 @@
+package usage
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+// Количество токенов, за которое в таблице цен указана стоимость
+const tokensPerPrice = 1_000_000
+
+// Price задает цену модели в долларах США за миллион токенов
+type Price struct {
+	Input  float64
+	Output float64
+}
+
+// Множители цены входных токенов для кэша промптов: запись в кэш дороже
+// обычного входа, чтение из кэша — в десять раз дешевле
+const (
+	cacheWritePriceFactor = 1.25
+	cacheReadPriceFactor  = 0.1
+)
+
+// Cost возвращает стоимость указанного количества токенов в долларах
+func (p Price) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / tokensPerPrice
+}
+
+// CacheCost возвращает стоимость токенов, прочитанных из кэша промптов и записанных в него
+func (p Price) CacheCost(readTokens, writeTokens int) float64 {
+	return (float64(readTokens)*cacheReadPriceFactor + float64(writeTokens)*cacheWritePriceFactor) * p.Input / tokensPerPrice
+}
+
+// PriceFunc возвращает цену модели провайдера или false, если цена неизвестна
+type PriceFunc func(source Source) (Price, bool)
+
+// Source определяет провайдера и модель, выполнившие запрос
+type Source struct {
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model"`
+}
+
+// String возвращает источник в виде "провайдер/модель"
+func (s Source) String() string {
+	if s.Provider == "" {
+		return s.Model
+	}
+	return s.Provider + "/" + s.Model
+}
+
+// Budget задает ограничения расхода. Нулевые значения означают отсутствие ограничения.
+type Budget struct {
+	MaxTokens int
+	MaxUSD    float64
+}
+
+// Usage содержит расход токенов и его стоимость
+type Usage struct {
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Cost         float64 `json:"cost_usd"`
+
+	// Токены промпта, прочитанные из кэша провайдера и записанные в него
+	CacheReadTokens  int `json:"cache_read_tokens,omitempty"`
+	CacheWriteTokens int `json:"cache_write_tokens,omitempty"`
+
+	// Цена известна для всех учтенных запросов
+	CostKnown bool `json:"cost_known"`
+
+	// Количество полученных описаний символов
+	Described int `json:"described,omitempty"`
+
+	// Символы, оставшиеся без описаний из-за исчерпания бюджета
+	Skipped int `json:"skipped,omitempty"`
+}
+
+// Tokens возвращает общее количество токенов, включая токены кэша промптов
+func (u Usage) Tokens() int {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
+
+// add добавляет расход другой записи
+func (u *Usage) add(other Usage) {
+	u.Requests += other.Requests
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.CacheReadTokens += other.CacheReadTokens
+	u.CacheWriteTokens += other.CacheWriteTokens
+	u.Cost += other.Cost
+	u.CostKnown = u.CostKnown && other.CostKnown
+	u.Described += other.Described
+	u.Skipped += other.Skipped
+}
+
+// FileUsage содержит расход для одного файла
+type FileUsage struct {
+	Path string `json:"path"`
+	Usage
+}
+
+// ModelUsage содержит расход для одной модели провайдера
+type ModelUsage struct {
+	Source
+	Usage
+}
+
+// DescriptionSource указывает, какой провайдер и модель описали символ
+type DescriptionSource struct {
+	File   string `json:"file"`
+	Symbol string `json:"symbol"`
+	Source
+}
+
+// Report содержит итоговый расход по файлам и моделям
+type Report struct {
+	Files  []FileUsage  `json:"files"`
+	Models []ModelUsage `json:"models"`
+	Total  Usage        `json:"total"`
+
+	// Источники описаний символов в порядке получения
+	Descriptions []DescriptionSource `json:"descriptions,omitempty"`
+
+	// Запросы были остановлены из-за исчерпания бюджета
+	BudgetExhausted bool `json:"budget_exhausted"`
+}
+
+// Tracker учитывает расход токенов и проверяет бюджет. Безопасен для
+// использования из нескольких горутин.
+type Tracker struct {
+	mu           sync.Mutex
+	budget       Budget
+	prices       PriceFunc
+	files        map[string]*Usage
+	models       map[Source]*Usage
+	total        Usage
+	descriptions []DescriptionSource
+	exhausted    bool
+}
+
+// NewTracker создает учет расхода с бюджетом и таблицей цен
+func NewTracker(budget Budget, prices PriceFunc) *Tracker {
+	return &Tracker{
+		budget: budget,
+		prices: prices,
+		files:  make(map[string]*Usage),
+		models: make(map[Source]*Usage),
+		total:  Usage{CostKnown: true},
+	}
+}
+
+// Cost возвращает стоимость токенов модели и false, если цена неизвестна
+func (t *Tracker) Cost(source Source, inputTokens, outputTokens int) (float64, bool) {
+	if t.prices == nil {
+		return 0, false
+	}
+	price, ok := t.prices(source)
+	if !ok {
+		return 0, false
+	}
+	return price.Cost(inputTokens, outputTokens), true
+}
+
+// Allow проверяет, можно ли отправить запрос к модели с промптом размером
+// promptTokens, не выходя за бюджет. После первого отказа все следующие
+// запросы также запрещаются.
+func (t *Tracker) Allow(source Source, promptTokens int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.exhausted {
+		return false
+	}
+	if t.budget.MaxTokens > 0 && t.total.Tokens()+promptTokens > t.budget.MaxTokens {
+		t.exhausted = true
+	}
+	if t.budget.MaxUSD > 0 {
+		cost, _ := t.Cost(source, promptTokens, 0)
+		if t.total.Cost+cost > t.budget.MaxUSD {
+			t.exhausted = true
+		}
+	}
+	return !t.exhausted
+}
+
+// Exhausted сообщает, что бюджет исчерпан
+func (t *Tracker) Exhausted() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exhausted
+}
+
+// Record учитывает запрос к модели для файла
+func (t *Tracker) Record(file string, source Source, inputTokens, outputTokens int) {
+	cost, known := t.Cost(source, inputTokens, outputTokens)
+	t.add(file, source, Usage{
+		Requests:     1,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		Cost:         cost,
+		CostKnown:    known,
+	})
+}
+
+// RecordCache учитывает токены кэша промптов, прочитанные и записанные
+// запросом к модели для файла. Сам запрос учитывается через Record.
+func (t *Tracker) RecordCache(file string, source Source, readTokens, writeTokens int) {
+	cost, known := 0.0, false
+	if t.prices != nil {
+		var price Price
+		if price, known = t.prices(source); known {
+			cost = price.CacheCost(readTokens, writeTokens)
+		}
+	}
+	t.add(file, source, Usage{
+		CacheReadTokens:  readTokens,
+		CacheWriteTokens: writeTokens,
+		Cost:             cost,
+		CostKnown:        known,
+	})
+}
+
+// Skip учитывает символы файла, оставшиеся без описаний из-за бюджета
+func (t *Tracker) Skip(file string, source Source, symbols int) {
+	t.add(file, source, Usage{Skipped: symbols, CostKnown: true})
+}
+
+// Describe учитывает описание символа файла, полученное от источника
+func (t *Tracker) Describe(file, symbol string, source Source) {
+	t.add(file, source, Usage{Described: 1, CostKnown: true})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.descriptions = append(t.descriptions, DescriptionSource{File: file, Symbol: symbol, Source: source})
+}
+
+// add добавляет расход к файлу, модели и общему итогу
+func (t *Tracker) add(file string, source Source, usage Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	fileUsage, ok := t.files[file]
+	if !ok {
+		fileUsage = &Usage{CostKnown: true}
+		t.files[file] = fileUsage
+	}
+	fileUsage.add(usage)
+
+	modelUsage, ok := t.models[source]
+	if !ok {
+		modelUsage = &Usage{CostKnown: true}
+		t.models[source] = modelUsage
+	}
+	modelUsage.add(usage)
+
+	t.total.add(usage)
+}
+
+// Report возвращает расход, упорядоченный по пути файла и имени модели
+func (t *Tracker) Report() Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := Report{
+		Files:           make([]FileUsage, 0, len(t.files)),
+		Models:          make([]ModelUsage, 0, len(t.models)),
+		Total:           t.total,
+		Descriptions:    append([]DescriptionSource(nil), t.descriptions...),
+		BudgetExhausted: t.exhausted,
+	}
+	for path, usage := range t.files {
+		report.Files = append(report.Files, FileUsage{Path: path, Usage: *usage})
+	}
+	for source, usage := range t.models {
+		report.Models = append(report.Models, ModelUsage{Source: source, Usage: *usage})
+	}
+	sort.Slice(report.Files, func(i, j int) bool { return report.Files[i].Path < report.Files[j].Path })
+	sort.Slice(report.Models, func(i, j int) bool {
+		return report.Models[i].Source.String() < report.Models[j].Source.String()
+	})
+	return report
+}
+
+// Empty сообщает, что не было ни запросов, ни пропущенных символов
+func (r Report) Empty() bool {
+	return r.Total.Requests == 0 && r.Total.Skipped == 0
+}
+
+// Write выводит отчет о расходе таблицами по файлам и моделям
+func (r Report) Write(w io.Writer, title string) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\n", title)
+
+	fmt.Fprintf(tw, "Файл\tЗапросы\tВход\tВыход\tСтоимость\tОписано\tБез описаний\t\n")
+	for _, file := range r.Files {
+		writeRow(tw, file.Path, file.Usage)
+	}
+	writeRow(tw, "Итого", r.Total)
+
+	fmt.Fprintf(tw, "\nМодель\tЗапросы\tВход\tВыход\tСтоимость\tОписано\tБез описаний\t\n")
+	for _, model := range r.Models {
+		writeRow(tw, model.Source.String(), model.Usage)
+	}
+
+	// Если описания получены от разных моделей, например при переключении на
+	// резервного провайдера, выводится источник каждого описания
+	if r.describedBySeveralSources() {
+		fmt.Fprintf(tw, "\nФайл\tСимвол\tМодель\t\n")
+		for _, description := range r.Descriptions {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t\n", description.File, description.Symbol, description.Source)
+		}
+	}
+
+	if cache := r.Total; cache.CacheReadTokens > 0 || cache.CacheWriteTokens > 0 {
+		fmt.Fprintf(tw, "\nКэш промптов: прочитано %d, записано %d токенов\n", cache.CacheReadTokens, cache.CacheWriteTokens)
+	}
+
+	if r.BudgetExhausted {
+		fmt.Fprintf(tw, "\nБюджет исчерпан: %d символов оставлены без описаний\n", r.Total.Skipped)
+	}
+	return tw.Flush()
+}
+
+// describedBySeveralSources сообщает, что описания получены от нескольких моделей
+func (r Report) describedBySeveralSources() bool {
+	for _, description := range r.Descriptions {
+		if description.Source != r.Descriptions[0].Source {
+			return true
+		}
+	}
+	return false
+}
+
+// writeRow выводит строку таблицы расхода
+func writeRow(w io.Writer, name string, usage Usage) {
+	fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\t%d\t%d\t\n",
+		name, usage.Requests, usage.InputTokens, usage.OutputTokens, FormatCost(usage.Cost, usage.CostKnown),
+		usage.Described, usage.Skipped)
+}
+
+// FormatCost форматирует стоимость в долларах; неизвестная цена выводится как "?"
+func FormatCost(cost float64, known bool) string {
+	if !known {
+		return "?"
+	}
+	return fmt.Sprintf("$%.4f", cost)
+}
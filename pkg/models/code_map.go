@@ -0,0 +1,66 @@
+package models
+
+import (
+	"sort"
+	"time"
+)
+
+// CodeMapVersion версия формата JSON-модели карты кода
+const CodeMapVersion = 1
+
+// CodeMap представляет модель карты кода проекта, из которой строятся
+// итоговые документы. Сохраняется в JSON, чтобы документ можно было
+// построить заново без повторного парсинга и запросов к ЛЛМ.
+type CodeMap struct {
+	// Версия формата модели
+	Version int `json:"version"`
+
+	// Имя проекта
+	Project string `json:"project"`
+
+	// Время построения модели
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// Структуры файлов проекта
+	Files []FileStructure `json:"files"`
+}
+
+// NewCodeMap создает модель карты кода проекта с упорядоченными файлами и символами
+func NewCodeMap(project string, files []FileStructure) *CodeMap {
+	codeMap := &CodeMap{
+		Version:     CodeMapVersion,
+		Project:     project,
+		GeneratedAt: time.Now().UTC().Truncate(time.Second),
+		Files:       files,
+	}
+	codeMap.Sort()
+	return codeMap
+}
+
+// Sort упорядочивает файлы по пути, а типы, функции, методы и экспорты
+// внутри файла по имени, чтобы документ не зависел от порядка обхода
+// файловой системы и порядка объявлений в исходном коде
+func (c *CodeMap) Sort() {
+	sort.SliceStable(c.Files, func(i, j int) bool { return c.Files[i].Path < c.Files[j].Path })
+
+	for i := range c.Files {
+		file := &c.Files[i]
+		sort.SliceStable(file.Types, func(a, b int) bool {
+			if file.Types[a].Name != file.Types[b].Name {
+				return file.Types[a].Name < file.Types[b].Name
+			}
+			return file.Types[a].Position.StartLine < file.Types[b].Position.StartLine
+		})
+		sort.SliceStable(file.Methods, func(a, b int) bool {
+			ma, mb := file.Methods[a], file.Methods[b]
+			if ma.BelongsTo != mb.BelongsTo {
+				return ma.BelongsTo < mb.BelongsTo
+			}
+			if ma.Name != mb.Name {
+				return ma.Name < mb.Name
+			}
+			return ma.Position.StartLine < mb.Position.StartLine
+		})
+		sort.Strings(file.Exports)
+	}
+}
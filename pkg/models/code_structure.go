@@ -5,6 +5,9 @@ type CodeStructure struct {
 	// Метаданные файла
 	Metadata *FileMetadata
 
+	// Имя пакета из объявления package (Go)
+	Package string
+
 	// Импорты файла
 	Imports []*Import
 
@@ -74,6 +77,11 @@ type Function struct {
 	// Имя функции
 	Name string
 
+	// Стабильный квалифицированный идентификатор функции с учетом языка,
+	// например go:code-telescope/internal/llm.NewPromptBuilder. Заполняется
+	// парсером и не меняется, пока не изменились имя, пакет или модуль.
+	ID string
+
 	// Параметры функции
 	Parameters []*Parameter
 
@@ -98,8 +106,17 @@ type Function struct {
 	// Позиция функции в файле
 	Position Position
 
+	// Документирующий комментарий функции
+	Doc string
+
 	// Описание функции
 	Description string
+
+	// Оценка качества описания
+	Quality *DescriptionQuality
+
+	// Описание написано вручную
+	Override *DescriptionOverride
 }
 
 // Method представляет метод класса
@@ -107,6 +124,10 @@ type Method struct {
 	// Имя метода
 	Name string
 
+	// Стабильный идентификатор метода, например
+	// go:code-telescope/internal/llm.(*OpenAIProvider).GenerateText
+	ID string
+
 	// Параметры метода
 	Parameters []*Parameter
 
@@ -137,11 +158,23 @@ type Method struct {
 	// Позиция метода в файле
 	Position Position
 
+	// Документирующий комментарий метода
+	Doc string
+
 	// Описание метода
 	Description string
 
+	// Оценка качества описания
+	Quality *DescriptionQuality
+
+	// Описание написано вручную
+	Override *DescriptionOverride
+
 	// Принадлежность к классу/типу
 	BelongsTo string
+
+	// Получатель метода — указатель (Go)
+	IsPointerReceiver bool
 }
 
 // Parameter представляет параметр метода или функции
@@ -173,6 +206,9 @@ type Type struct {
 	// Имя типа
 	Name string
 
+	// Стабильный идентификатор типа, например go:code-telescope/pkg/models.Type
+	ID string
+
 	// Тип сущности (class, interface, struct, enum, etc.)
 	Kind string
 
@@ -197,6 +233,9 @@ type Type struct {
 	// Позиция в файле
 	Position Position
 
+	// Документирующий комментарий типа
+	Doc string
+
 	// Свойства типа
 	Properties []*Property
 
@@ -218,6 +257,9 @@ type Property struct {
 	// Имя свойства
 	Name string
 
+	// Стабильный идентификатор свойства: идентификатор типа и имя свойства
+	ID string
+
 	// Тип свойства
 	Type string
 
@@ -245,6 +287,9 @@ type Variable struct {
 	// Имя переменной
 	Name string
 
+	// Стабильный идентификатор переменной
+	ID string
+
 	// Тип переменной
 	Type string
 
@@ -260,6 +305,9 @@ type Constant struct {
 	// Имя константы
 	Name string
 
+	// Стабильный идентификатор константы
+	ID string
+
 	// Тип константы
 	Type string
 
@@ -273,16 +321,21 @@ type Constant struct {
 // Position представляет позицию в файле
 type Position struct {
 	// Начальная строка
-	StartLine int
+	StartLine int `json:"start_line"`
 
 	// Начальная колонка
-	StartColumn int
+	StartColumn int `json:"start_column"`
 
 	// Конечная строка
-	EndLine int
+	EndLine int `json:"end_line"`
 
 	// Конечная колонка
-	EndColumn int
+	EndColumn int `json:"end_column"`
+
+	// Смещения начала и конца символа в файле в байтах (конец не включается).
+	// Нулевые значения означают, что парсер не заполнил смещения.
+	StartByte int `json:"start_byte,omitempty"`
+	EndByte   int `json:"end_byte,omitempty"`
 }
 
 // NewCodeStructure создает новую структуру кода для файла
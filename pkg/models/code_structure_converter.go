@@ -1,13 +1,29 @@
 package models
 
+// ConvertOptions задает параметры преобразования CodeStructure в FileStructure
+type ConvertOptions struct {
+	// Включать непубличные функции, методы и типы
+	IncludePrivate bool
+}
+
 // ConvertToFileStructure преобразует CodeStructure в FileStructure
 // для совместимости с модулем генерации Markdown
 func ConvertToFileStructure(cs *CodeStructure) FileStructure {
+	return ConvertToFileStructureWithOptions(cs, ConvertOptions{})
+}
+
+// ConvertToFileStructureWithOptions преобразует CodeStructure в FileStructure
+// с указанными параметрами
+func ConvertToFileStructureWithOptions(cs *CodeStructure, opts ConvertOptions) FileStructure {
 	// Создаем базовую структуру
 	fs := FileStructure{
-		Path:     cs.Metadata.Path,
-		Language: cs.Metadata.LanguageName(),
-		Content:  "", // Содержимое файла в FileStructure не используется
+		Path:      cs.Metadata.Path,
+		Language:  cs.Metadata.LanguageName(),
+		Package:   cs.Package,
+		Size:      cs.Metadata.Size,
+		ModTime:   cs.Metadata.ModTime,
+		LineCount: cs.Metadata.LineCount,
+		Content:   "", // Содержимое файла в FileStructure не используется
 	}
 
 	// Преобразуем импорты
@@ -32,72 +48,147 @@ func ConvertToFileStructure(cs *CodeStructure) FileStructure {
 	}
 	fs.Exports = exports
 
-	// Преобразуем методы
-	methods := make([]MethodInfo, 0, len(cs.Methods))
-	for _, method := range cs.Methods {
-		if !method.IsPublic {
-			continue // Пропускаем непубличные методы
+	// Преобразуем функции верхнего уровня и методы
+	methods := make([]MethodInfo, 0, len(cs.Functions)+len(cs.Methods))
+	for _, fn := range cs.Functions {
+		if !fn.IsPublic && !opts.IncludePrivate {
+			continue // Пропускаем непубличные функции
 		}
+		methodInfo := convertCallable(fn.Name, fn.Parameters, fn.ReturnType, fn.Description)
+		methodInfo.ID = fn.ID
+		methodInfo.Kind = "function"
+		methodInfo.Quality = fn.Quality
+		methodInfo.Override = fn.Override
+		methodInfo.IsPublic = fn.IsPublic
+		methodInfo.Position = fn.Position
+		methods = append(methods, methodInfo)
+	}
 
-		// Формируем параметры
-		params := make([]string, 0, len(method.Parameters))
-		for _, param := range method.Parameters {
-			paramStr := param.Name
-			if param.Type != "" {
-				paramStr += ": " + param.Type
-			}
-			params = append(params, paramStr)
+	for _, method := range cs.Methods {
+		if !method.IsPublic && !opts.IncludePrivate {
+			continue // Пропускаем непубличные методы
 		}
+		methods = append(methods, convertMethod(method, ""))
+	}
 
-		// Формируем сигнатуру
-		signature := method.Name + "("
-		if len(method.Parameters) > 0 {
-			paramStrs := make([]string, 0, len(method.Parameters))
-			for _, param := range method.Parameters {
-				paramStr := param.Name
-				if param.Type != "" {
-					paramStr += " " + param.Type
-				}
-				paramStrs = append(paramStrs, paramStr)
+	// Методы, сохраненные парсером внутри типов (JavaScript, Python)
+	for _, typ := range cs.Types {
+		for _, method := range typ.Methods {
+			if !method.IsPublic && !opts.IncludePrivate {
+				continue
 			}
-			signature += joinStrings(paramStrs, ", ")
-		}
-		signature += ")"
-		if method.ReturnType != "" {
-			signature += " " + method.ReturnType
+			methods = append(methods, convertMethod(method, typ.Name))
 		}
-
-		// Создаем MethodInfo
-		methodInfo := MethodInfo{
-			Name:      method.Name,
-			Signature: signature,
-			Params:    params,
-			Returns:   []string{method.ReturnType},
-		}
-
-		// Если метод имеет описание, добавляем его
-		if method.Description != "" {
-			methodInfo.Body = method.Description
-		} else {
-			methodInfo.Body = "Нет описания"
-		}
-
-		methods = append(methods, methodInfo)
 	}
 	fs.Methods = methods
 
 	// Группируем классы/типы
 	classes := make([]string, 0, len(cs.Types))
+	types := make([]TypeInfo, 0, len(cs.Types))
 	for _, typ := range cs.Types {
-		if typ.IsPublic {
+		if typ.IsPublic || opts.IncludePrivate {
 			classes = append(classes, typ.Name)
+			types = append(types, TypeInfo{
+				ID:       typ.ID,
+				Name:     typ.Name,
+				Kind:     typ.Kind,
+				IsPublic: typ.IsPublic,
+				Position: typ.Position,
+				Fields:   convertProperties(typ.Properties),
+			})
 		}
 	}
 	fs.Classes = classes
+	fs.Types = types
 
 	return fs
 }
 
+// convertMethod преобразует метод в MethodInfo, подставляя владельца,
+// если парсер не заполнил BelongsTo
+func convertMethod(method *Method, owner string) MethodInfo {
+	methodInfo := convertCallable(method.Name, method.Parameters, method.ReturnType, method.Description)
+	methodInfo.ID = method.ID
+	methodInfo.Kind = "method"
+	methodInfo.Quality = method.Quality
+	methodInfo.Override = method.Override
+	methodInfo.BelongsTo = method.BelongsTo
+	if methodInfo.BelongsTo == "" {
+		methodInfo.BelongsTo = owner
+	}
+	methodInfo.IsPublic = method.IsPublic
+	methodInfo.Position = method.Position
+	return methodInfo
+}
+
+// convertProperties формирует список публичных полей типа в виде "имя: тип"
+func convertProperties(properties []*Property) []string {
+	fields := make([]string, 0, len(properties))
+	for _, prop := range properties {
+		if !prop.IsPublic {
+			continue
+		}
+		field := prop.Name
+		if prop.Type != "" {
+			field += ": " + prop.Type
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
+
+// convertCallable формирует MethodInfo из общих для функций и методов данных
+func convertCallable(name string, parameters []*Parameter, returnType, description string) MethodInfo {
+	// Формируем параметры
+	params := make([]string, 0, len(parameters))
+	for _, param := range parameters {
+		paramStr := param.Name
+		if param.Type != "" {
+			paramStr += ": " + param.Type
+		}
+		params = append(params, paramStr)
+	}
+
+	// Формируем сигнатуру
+	signature := name + "("
+	if len(parameters) > 0 {
+		paramStrs := make([]string, 0, len(parameters))
+		for _, param := range parameters {
+			paramStr := param.Name
+			if param.Type != "" && paramStr != "" {
+				paramStr += " " + param.Type
+			} else if param.Type != "" {
+				// Параметр без имени (Go) представлен только типом
+				paramStr = param.Type
+			}
+			paramStrs = append(paramStrs, paramStr)
+		}
+		signature += joinStrings(paramStrs, ", ")
+	}
+	signature += ")"
+	if returnType != "" {
+		signature += " " + returnType
+	}
+
+	// Создаем MethodInfo
+	methodInfo := MethodInfo{
+		Name:        name,
+		Signature:   signature,
+		Params:      params,
+		Returns:     []string{returnType},
+		Description: description,
+	}
+
+	// Если метод имеет описание, добавляем его
+	if description != "" {
+		methodInfo.Body = description
+	} else {
+		methodInfo.Body = "Нет описания"
+	}
+
+	return methodInfo
+}
+
 // joinStrings объединяет строки с указанным разделителем
 func joinStrings(strings []string, separator string) string {
 	if len(strings) == 0 {
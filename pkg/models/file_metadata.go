@@ -1,6 +1,7 @@
 package models
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"time"
@@ -28,6 +29,9 @@ type FileMetadata struct {
 
 	// Родительская директория
 	Directory string
+
+	// Количество строк (заполняется парсером после чтения файла)
+	LineCount int
 }
 
 // NewFileMetadata создает новый экземпляр FileMetadata из пути к файлу и корня проекта
@@ -59,6 +63,18 @@ func NewFileMetadata(filePath, projectRoot string) (*FileMetadata, error) {
 	}, nil
 }
 
+// CountLines возвращает количество строк в содержимом файла
+func CountLines(content []byte) int {
+	if len(content) == 0 {
+		return 0
+	}
+	lines := bytes.Count(content, []byte("\n"))
+	if content[len(content)-1] != '\n' {
+		lines++
+	}
+	return lines
+}
+
 // IsSupported проверяет, поддерживается ли этот тип файла
 func (fm *FileMetadata) IsSupported() bool {
 	// Список поддерживаемых расширений файлов
@@ -1,21 +1,28 @@
 package models
 
+import "time"
+
 // FileStructure представляет структурную информацию о файле кода
 type FileStructure struct {
-	Path        string       // Путь к файлу
-	Language    string       // Язык программирования
-	Imports     []string     // Импорты файла
-	Exports     []string     // Экспорты файла
-	Methods     []MethodInfo // Методы файла
-	Classes     []string     // Классы в файле (для объектно-ориентированных языков)
-	Content     string       // Содержимое файла
-	Description string       // Описание файла (может быть заполнено с помощью ЛЛМ)
+	Path        string       `json:"path"`                  // Путь к файлу
+	Language    string       `json:"language"`              // Язык программирования
+	Package     string       `json:"package,omitempty"`     // Имя пакета (Go)
+	Size        int64        `json:"size"`                  // Размер файла в байтах
+	ModTime     time.Time    `json:"mod_time"`              // Дата последнего изменения
+	LineCount   int          `json:"line_count"`            // Количество строк
+	Imports     []string     `json:"imports,omitempty"`     // Импорты файла
+	Exports     []string     `json:"exports,omitempty"`     // Экспорты файла
+	Methods     []MethodInfo `json:"methods,omitempty"`     // Методы файла
+	Classes     []string     `json:"classes,omitempty"`     // Классы в файле (для объектно-ориентированных языков)
+	Types       []TypeInfo   `json:"types,omitempty"`       // Публичные типы файла с позициями
+	Content     string       `json:"content,omitempty"`     // Содержимое файла
+	Description string       `json:"description,omitempty"` // Описание файла (может быть заполнено с помощью ЛЛМ)
 }
 
 // GetPublicMethods возвращает все публичные методы файла
 // В случае с Go, это методы, имена которых начинаются с заглавной буквы
 func (fs *FileStructure) GetPublicMethods() []MethodInfo {
-	// В текущей реализации просто вернём все методы, так как фильтрация по публичности 
+	// В текущей реализации просто вернём все методы, так как фильтрация по публичности
 	// уже должна была произойти в парсере
 	return fs.Methods
 }
@@ -2,12 +2,47 @@ package models
 
 // MethodInfo представляет информацию о методе
 type MethodInfo struct {
-	Name        string   // Имя метода
-	Signature   string   // Полная сигнатура метода
-	Body        string   // Тело метода
-	Params      []string // Параметры метода (для внутреннего использования)
-	Returns     []string // Возвращаемые значения (для внутреннего использования)
-	Parameters  []string // Параметры метода (для совместимости с оркестратором)
-	ReturnType  []string // Типы возвращаемых значений (для совместимости с оркестратором)
-	Description string   // Описание метода (может быть заполнено с помощью ЛЛМ)
+	ID          string               `json:"id,omitempty"`          // Стабильный идентификатор символа
+	Name        string               `json:"name"`                  // Имя метода
+	Signature   string               `json:"signature"`             // Полная сигнатура метода
+	Body        string               `json:"body,omitempty"`        // Тело метода
+	Doc         string               `json:"doc,omitempty"`         // Документирующий комментарий метода
+	Params      []string             `json:"params,omitempty"`      // Параметры метода (для внутреннего использования)
+	Returns     []string             `json:"returns,omitempty"`     // Возвращаемые значения (для внутреннего использования)
+	Parameters  []string             `json:"parameters,omitempty"`  // Параметры метода (для совместимости с оркестратором)
+	ReturnType  []string             `json:"return_type,omitempty"` // Типы возвращаемых значений (для совместимости с оркестратором)
+	Description string               `json:"description,omitempty"` // Описание метода (может быть заполнено с помощью ЛЛМ)
+	Quality     *DescriptionQuality  `json:"quality,omitempty"`     // Оценка качества описания
+	Override    *DescriptionOverride `json:"override,omitempty"`    // Описание написано вручную
+	Kind        string               `json:"kind,omitempty"`        // Вид символа (function, method)
+	BelongsTo   string               `json:"belongs_to,omitempty"`  // Тип, которому принадлежит метод (пусто для функций)
+	IsPublic    bool                 `json:"is_public"`             // Является ли символ публичным
+	Position    Position             `json:"position"`              // Позиция символа в исходном файле
+}
+
+// DescriptionQuality представляет оценку описания символа, полученного от ЛЛМ
+type DescriptionQuality struct {
+	Score  float64  `json:"score"`            // Оценка от 0 до 1
+	Issues []string `json:"issues,omitempty"` // Недостатки описания, оставшиеся после повторных запросов
+}
+
+// Источники описаний, написанных вручную
+const (
+	OverrideSourceFile       = "file"       // Файл descriptions.overrides_file
+	OverrideSourceAnnotation = "annotation" // Аннотация telescope:describe в комментарии
+)
+
+// DescriptionOverride отмечает описание символа, написанное вручную
+type DescriptionOverride struct {
+	Source string `json:"source"`          // Источник описания: file или annotation
+	Stale  bool   `json:"stale,omitempty"` // Сигнатура символа изменилась после написания описания
+}
+
+// Key возвращает идентификатор метода, а если он не заполнен (модели,
+// сохраненные до появления идентификаторов) — имя метода
+func (m MethodInfo) Key() string {
+	if m.ID != "" {
+		return m.ID
+	}
+	return m.Name
 }
@@ -0,0 +1,17 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Символы идентификатора, недопустимые в якоре
+var anchorUnsafePattern = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// SymbolAnchor возвращает якорь символа в документе по его идентификатору:
+// go:code-telescope/internal/llm.(*OpenAIProvider).GenerateText ->
+// go-code-telescope-internal-llm-OpenAIProvider-GenerateText. Регистр
+// сохраняется, так как в Go Run и run — разные символы.
+func SymbolAnchor(id string) string {
+	return strings.Trim(anchorUnsafePattern.ReplaceAllString(id, "-"), "-")
+}
@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"testing"
+
+	"code-telescope/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewCodeMapSorts проверяет, что порядок файлов и символов модели не зависит от порядка обхода
+func TestNewCodeMapSorts(t *testing.T) {
+	codeMap := models.NewCodeMap("demo", []models.FileStructure{
+		{Path: "b.go"},
+		{
+			Path:    "a.go",
+			Exports: []string{"Z", "A"},
+			Types:   []models.TypeInfo{{Name: "Server"}, {Name: "Config"}},
+			Methods: []models.MethodInfo{
+				{Name: "Run", BelongsTo: "Server"},
+				{Name: "Load"},
+				{Name: "Addr", BelongsTo: "Server"},
+				{Name: "Default"},
+			},
+		},
+	})
+
+	assert.Equal(t, "a.go", codeMap.Files[0].Path)
+	file := codeMap.Files[0]
+	assert.Equal(t, []string{"A", "Z"}, file.Exports)
+	assert.Equal(t, "Config", file.Types[0].Name)
+
+	var names []string
+	for _, method := range file.Methods {
+		names = append(names, method.BelongsTo+"."+method.Name)
+	}
+	assert.Equal(t, []string{".Default", ".Load", "Server.Addr", "Server.Run"}, names)
+}
@@ -0,0 +1,12 @@
+package models
+
+// TypeInfo представляет информацию о типе или классе для генераторов вывода
+type TypeInfo struct {
+	ID          string   `json:"id,omitempty"`          // Стабильный идентификатор типа
+	Name        string   `json:"name"`                  // Имя типа
+	Kind        string   `json:"kind"`                  // Вид типа (class, struct, interface и т.д.)
+	IsPublic    bool     `json:"is_public"`             // Является ли тип публичным
+	Position    Position `json:"position"`              // Позиция типа в исходном файле
+	Fields      []string `json:"fields,omitempty"`      // Публичные поля и свойства типа
+	Description string   `json:"description,omitempty"` // Описание типа (может быть заполнено с помощью ЛЛМ)
+}
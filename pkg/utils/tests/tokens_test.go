@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"code-telescope/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Тексты разного состава для проверки оценок
+var tokenSamples = []string{
+	"func Add(a, b int) int {\n\treturn a + b\n}\n",
+	"Складывает два целых числа и возвращает их сумму.",
+	strings.Repeat("total += values[i] * weights[i]\n", 40),
+	"Метод 1: Add\nСигнатура: func Add(a, b int) int\nОписание: сумма",
+}
+
+// TestTokenEstimatorWithoutUsage проверяет, что без ответов провайдера
+// оценщик совпадает с EstimateTokens, в том числе нулевой указатель
+func TestTokenEstimatorWithoutUsage(t *testing.T) {
+	var missing *utils.TokenEstimator
+	estimator := utils.NewTokenEstimator()
+	for _, sample := range tokenSamples {
+		assert.Equal(t, utils.EstimateTokens(sample), estimator.Estimate(sample))
+		assert.Equal(t, utils.EstimateTokens(sample), missing.Estimate(sample))
+	}
+	assert.Equal(t, 1.0, estimator.Ratio())
+	assert.Equal(t, 500, estimator.Budget(500))
+	assert.Equal(t, 0, estimator.Estimate(""))
+}
+
+// TestTokenEstimatorCalibrates проверяет, что после ответов провайдера
+// ошибка оценки не превышает 5% от фактического числа токенов, а текст,
+// уложенный в Budget, укладывается в бюджет по оценке Estimate
+func TestTokenEstimatorCalibrates(t *testing.T) {
+	// Токенизатор модели дает в 1.6 раза больше токенов, чем EstimateTokens
+	actual := func(text string) int { return utils.EstimateTokens(text) * 8 / 5 }
+
+	estimator := utils.NewTokenEstimator()
+	for _, sample := range tokenSamples[:2] {
+		estimator.Observe(sample, actual(sample))
+	}
+	assert.InDelta(t, 1.6, estimator.Ratio(), 0.05)
+
+	for _, sample := range tokenSamples[2:] {
+		assert.InEpsilon(t, actual(sample), estimator.Estimate(sample), 0.05, sample)
+	}
+
+	for _, maxTokens := range []int{1, 7, 100, 333} {
+		truncated, _ := utils.TruncateTokens(tokenSamples[2], estimator.Budget(maxTokens))
+		assert.LessOrEqual(t, estimator.Estimate(truncated), maxTokens)
+	}
+}
+
+// TestTokenEstimatorIgnoresBrokenUsage проверяет, что ответы без usage не
+// меняют поправку, а неправдоподобное отношение ограничивается
+func TestTokenEstimatorIgnoresBrokenUsage(t *testing.T) {
+	estimator := utils.NewTokenEstimator()
+	estimator.Observe(tokenSamples[0], 0)
+	estimator.Observe("", 10)
+	assert.Equal(t, 1.0, estimator.Ratio())
+
+	estimator.Observe(tokenSamples[0], 1000*utils.EstimateTokens(tokenSamples[0]))
+	assert.Equal(t, 4.0, estimator.Ratio())
+}
@@ -0,0 +1,152 @@
+package utils
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Границы поправочного коэффициента TokenEstimator. Отношения за их
+// пределами говорят скорее об ошибке в usage провайдера, чем о токенизаторе.
+const (
+	minTokenRatio = 0.25
+	maxTokenRatio = 4.0
+)
+
+// EstimateTokens приблизительно оценивает количество токенов в тексте без
+// токенизатора модели: ~4 ASCII-символа на токен и ~2 остальных символа
+// (например, кириллицы) на токен. Для кода на BPE-токенизаторах оценка
+// обычно завышена, для текста с редкими символами может быть занижена,
+// поэтому там, где известна модель, используется TokenEstimator.
+func EstimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+
+	asciiChars := 0
+	otherChars := 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			asciiChars++
+		} else {
+			otherChars++
+		}
+	}
+
+	tokens := (asciiChars+3)/4 + (otherChars+1)/2
+	if tokens == 0 {
+		tokens = 1
+	}
+	return tokens
+}
+
+// TokenEstimator оценивает количество токенов для одной модели. Оценки
+// EstimateTokens умножаются на отношение числа входных токенов, которое
+// провайдер вернул в usage, к оценке EstimateTokens тех же промптов.
+// До первого ответа с usage оценки совпадают с EstimateTokens.
+// Нулевой указатель ведет себя как оценщик без поправки.
+type TokenEstimator struct {
+	mu        sync.Mutex
+	estimated int
+	actual    int
+}
+
+// NewTokenEstimator создает оценщик токенов без поправки
+func NewTokenEstimator() *TokenEstimator {
+	return &TokenEstimator{}
+}
+
+// Observe учитывает промпт text, для которого провайдер сообщил
+// actualTokens входных токенов
+func (e *TokenEstimator) Observe(text string, actualTokens int) {
+	estimated := EstimateTokens(text)
+	if e == nil || actualTokens <= 0 || estimated == 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.estimated += estimated
+	e.actual += actualTokens
+}
+
+// Ratio возвращает поправочный коэффициент к оценке EstimateTokens
+func (e *TokenEstimator) Ratio() float64 {
+	if e == nil {
+		return 1
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.estimated == 0 {
+		return 1
+	}
+	return math.Min(math.Max(float64(e.actual)/float64(e.estimated), minTokenRatio), maxTokenRatio)
+}
+
+// Estimate оценивает количество токенов в тексте с учетом поправки модели
+func (e *TokenEstimator) Estimate(text string) int {
+	tokens := EstimateTokens(text)
+	if tokens == 0 {
+		return 0
+	}
+	// Допуск не дает ошибке округления вывести из бюджета текст,
+	// уложенный в него через Budget
+	return int(math.Ceil(float64(tokens)*e.Ratio() - 1e-9))
+}
+
+// Budget переводит бюджет в токенах модели в единицы EstimateTokens:
+// текст, оценка EstimateTokens которого не превышает Budget(maxTokens),
+// по оценке Estimate укладывается в maxTokens
+func (e *TokenEstimator) Budget(maxTokens int) int {
+	if maxTokens <= 0 {
+		return maxTokens
+	}
+	return int(float64(maxTokens) / e.Ratio())
+}
+
+// TruncateTokens обрезает текст так, чтобы оценка EstimateTokens не
+// превышала maxTokens. Текст обрезается по границе строки, а если не
+// помещается даже первая строка — по границе символа. Возвращает
+// обрезанный текст и признак того, что текст был обрезан.
+func TruncateTokens(text string, maxTokens int) (string, bool) {
+	if EstimateTokens(text) <= maxTokens {
+		return text, false
+	}
+	if maxTokens <= 0 {
+		return "", true
+	}
+
+	// Сумма оценок строк не меньше оценки их объединения,
+	// поэтому набранные целиком строки гарантированно укладываются в лимит
+	end, used := 0, 0
+	for end < len(text) {
+		lineEnd := len(text)
+		if i := strings.IndexByte(text[end:], '\n'); i >= 0 {
+			lineEnd = end + i + 1
+		}
+		lineTokens := EstimateTokens(text[end:lineEnd])
+		if used+lineTokens > maxTokens {
+			break
+		}
+		used += lineTokens
+		end = lineEnd
+	}
+	if end > 0 {
+		return strings.TrimRight(text[:end], "\n"), true
+	}
+
+	asciiChars, otherChars := 0, 0
+	for i, r := range text {
+		if r < utf8.RuneSelf {
+			asciiChars++
+		} else {
+			otherChars++
+		}
+		if (asciiChars+3)/4+(otherChars+1)/2 > maxTokens {
+			return text[:i], true
+		}
+	}
+	return text, false
+}